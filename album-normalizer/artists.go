@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ArtistFix is a group of tracks whose artist tag should be rewritten to the canonical spelling
+type ArtistFix struct {
+	From   string
+	To     string
+	Tracks []MusicFile
+}
+
+// parseAliasFlag parses "alias=canonical"
+func parseAliasFlag(value string) (string, string, error) {
+	alias, canonical, found := strings.Cut(value, "=")
+	alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+	if !found || alias == "" || canonical == "" {
+		return "", "", fmt.Errorf("expected \"alias=canonical\", got %q", value)
+	}
+	if strings.EqualFold(alias, canonical) {
+		return "", "", fmt.Errorf("alias and canonical artist are the same: %q", alias)
+	}
+	return alias, canonical, nil
+}
+
+func fetchArtistAliases(ctx context.Context, collection *mongo.Collection) ([]models.ArtistAlias, error) {
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"alias": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var aliases []models.ArtistAlias
+	if err := cursor.All(ctx, &aliases); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+func addArtistAlias(ctx context.Context, collection *mongo.Collection, alias, canonical string) error {
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"alias": models.NormalizeArtist(alias)},
+		bson.M{
+			"$set": bson.M{
+				"canonical": models.NormalizeArtist(canonical),
+				"suggested": false,
+			},
+			"$setOnInsert": bson.M{
+				"_id":        uuid.Must(uuid.NewV4()).String(),
+				"created_at": time.Now().Unix(),
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func listArtistAliases(aliases []models.ArtistAlias) {
+	if len(aliases) == 0 {
+		fmt.Println("No artist aliases defined")
+		return
+	}
+
+	fmt.Printf("=== Artist Aliases (%d) ===\n\n", len(aliases))
+	for _, alias := range aliases {
+		status := ""
+		if alias.Suggested {
+			status = " (suggested)"
+		}
+		fmt.Printf("  %q → %q%s\n", alias.Alias, alias.Canonical, status)
+	}
+}
+
+// findArtistFixes finds tracks whose artist resolves to a different canonical
+// spelling through the alias table. The canonical spelling is taken from the
+// library itself when some track already uses it, so the original casing is kept.
+func findArtistFixes(files []MusicFile, aliases models.ArtistAliases) []ArtistFix {
+	preferred := make(map[string]string)
+	for _, f := range files {
+		normalized := models.NormalizeArtist(f.Artist)
+		if _, ok := preferred[normalized]; !ok {
+			preferred[normalized] = f.Artist
+		}
+	}
+
+	fixes := make(map[string]*ArtistFix)
+	for _, f := range files {
+		canonical := aliases.Canonical(f.Artist)
+		if canonical == models.NormalizeArtist(f.Artist) {
+			continue
+		}
+
+		target, ok := preferred[canonical]
+		if !ok {
+			target = canonical
+		}
+
+		fix, ok := fixes[f.Artist]
+		if !ok {
+			fix = &ArtistFix{From: f.Artist, To: target}
+			fixes[f.Artist] = fix
+		}
+		fix.Tracks = append(fix.Tracks, f)
+	}
+
+	result := make([]ArtistFix, 0, len(fixes))
+	for _, fix := range fixes {
+		result = append(result, *fix)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].From < result[j].From
+	})
+
+	return result
+}
+
+func printArtistFixes(fixes []ArtistFix) {
+	fmt.Printf("=== Artist Spelling Report ===\n\n")
+	if len(fixes) == 0 {
+		fmt.Println("All artists already use their canonical spelling!")
+		return
+	}
+
+	total := 0
+	for _, fix := range fixes {
+		fmt.Printf("  %q → %q (%d tracks)\n", fix.From, fix.To, len(fix.Tracks))
+		total += len(fix.Tracks)
+	}
+	fmt.Printf("\n%d artist spellings across %d tracks can be normalized\n", len(fixes), total)
+}
+
+// applyArtistFixes rewrites the artist field in the database. File tags are left as they are.
+func applyArtistFixes(ctx context.Context, collection *mongo.Collection, fixes []ArtistFix) error {
+	for _, fix := range fixes {
+		ids := make([]string, 0, len(fix.Tracks))
+		for _, t := range fix.Tracks {
+			ids = append(ids, t.ID)
+		}
+
+		result, err := collection.UpdateMany(
+			ctx,
+			bson.M{"_id": bson.M{"$in": ids}},
+			bson.M{"$set": bson.M{
+				"artist":     fix.To,
+				"updated_at": time.Now().Unix(),
+			}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update artist %q: %w", fix.From, err)
+		}
+
+		fmt.Printf("  ✓ %q → %q: modified %d documents\n", fix.From, fix.To, result.ModifiedCount)
+	}
+
+	return nil
+}
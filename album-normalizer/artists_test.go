@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+)
+
+func TestParseAliasFlag(t *testing.T) {
+	alias, canonical, err := parseAliasFlag(" weeknd = The Weeknd ")
+	if err != nil || alias != "weeknd" || canonical != "The Weeknd" {
+		t.Fatalf("unexpected result: %q, %q, %v", alias, canonical, err)
+	}
+
+	for _, value := range []string{"weeknd", "= The Weeknd", "weeknd =", "Weeknd = weeknd"} {
+		if _, _, err := parseAliasFlag(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestFindArtistFixes(t *testing.T) {
+	aliases := models.ArtistAliases{"weeknd": "the weeknd", "beatles": "the beatles"}
+	files := []MusicFile{
+		{ID: "1", Artist: "The Weeknd", Title: "Starboy"},
+		{ID: "2", Artist: "Weeknd", Title: "Blinding Lights"},
+		{ID: "3", Artist: "weeknd", Title: "After Hours"},
+		{ID: "4", Artist: "Beatles", Title: "Help!"},
+		{ID: "5", Artist: "Radiohead", Title: "Creep"},
+	}
+
+	fixes := findArtistFixes(files, aliases)
+	if len(fixes) != 3 {
+		t.Fatalf("expected three fixes, got %+v", fixes)
+	}
+
+	// sorted by the spelling being replaced, the casing the library already
+	// uses wins over the lowercase canonical form
+	expected := []struct{ from, to, id string }{
+		{"Beatles", "the beatles", "4"},
+		{"Weeknd", "The Weeknd", "2"},
+		{"weeknd", "The Weeknd", "3"},
+	}
+	for i, want := range expected {
+		fix := fixes[i]
+		if fix.From != want.from || fix.To != want.to || len(fix.Tracks) != 1 || fix.Tracks[0].ID != want.id {
+			t.Errorf("fix %d = %+v, want %s → %s with track %s", i, fix, want.from, want.to, want.id)
+		}
+	}
+}
+
+func TestFindArtistFixesWithoutAliases(t *testing.T) {
+	files := []MusicFile{{ID: "1", Artist: "Weeknd", Title: "Blinding Lights"}}
+	if fixes := findArtistFixes(files, nil); len(fixes) != 0 {
+		t.Fatalf("expected no fixes without aliases, got %+v", fixes)
+	}
+}
@@ -18,15 +18,17 @@ import (
 	"github.com/go-flac/flacvorbis"
 	"github.com/kelseyhightower/envconfig"
 	mp4tag "github.com/Sorrow446/go-mp4tag"
+	models "github.com/supperdoggy/spot-models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Config struct {
-	DatabaseURL    string `envconfig:"DATABASE_URL" required:"true"`
-	DatabaseName   string `envconfig:"DATABASE_NAME" required:"true"`
-	CollectionName string `envconfig:"COLLECTION_NAME" default:"music-files"`
+	DatabaseURL     string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseName    string `envconfig:"DATABASE_NAME" required:"true"`
+	CollectionName  string `envconfig:"COLLECTION_NAME" default:"music-files"`
+	AliasCollection string `envconfig:"ALIAS_COLLECTION_NAME" default:"artist_aliases"`
 }
 
 type MusicFile struct {
@@ -84,6 +86,10 @@ func main() {
 	updateFiles := flag.Bool("update-files", false, "Also update album metadata in the actual music files (requires -dry-run=false)")
 	restoreBackup := flag.String("restore", "", "Restore album metadata from backup file (path to backup JSON file)")
 	backupFile := flag.String("backup-file", "album-normalizer-backup.json", "Path to backup file for storing original album names")
+	addAlias := flag.String("add-alias", "", "Add an artist alias as \"alias=canonical\" (e.g. \"weeknd=the weeknd\")")
+	listAliases := flag.Bool("aliases", false, "List artist aliases")
+	artistReport := flag.Bool("artists", false, "Show artists whose spelling differs from the canonical one in the alias table")
+	fixArtists := flag.Bool("fix-artists", false, "Rewrite artists to their canonical spelling in the database (requires -dry-run=false)")
 	flag.Parse()
 
 	cfg, err := loadConfig()
@@ -102,6 +108,40 @@ func main() {
 	}
 	defer client.Disconnect(ctx)
 
+	aliasCollection := client.Database(cfg.DatabaseName).Collection(cfg.AliasCollection)
+
+	if *addAlias != "" {
+		alias, canonical, err := parseAliasFlag(*addAlias)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid alias: %v\n", err)
+			os.Exit(1)
+		}
+		if *dryRun {
+			fmt.Printf("Would add artist alias %q → %q (run with -dry-run=false to save)\n", alias, canonical)
+			return
+		}
+		if err := addArtistAlias(ctx, aliasCollection, alias, canonical); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add artist alias: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Added artist alias %q → %q\n", alias, canonical)
+		return
+	}
+
+	var artistAliases []models.ArtistAlias
+	if *listAliases || *artistReport || *fixArtists {
+		artistAliases, err = fetchArtistAliases(ctx, aliasCollection)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fetch artist aliases: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *listAliases {
+		listArtistAliases(artistAliases)
+		return
+	}
+
 	fmt.Println("Fetching music files from database...")
 	files, err := fetchAllMusicFiles(ctx, collection)
 	if err != nil {
@@ -125,6 +165,25 @@ func main() {
 		return
 	}
 
+	// Artist spelling normalization through the alias table
+	if *artistReport || *fixArtists {
+		fixes := findArtistFixes(files, models.NewArtistAliases(artistAliases))
+		printArtistFixes(fixes)
+
+		if *fixArtists && len(fixes) > 0 {
+			if *dryRun {
+				fmt.Println("\nRun with -fix-artists -dry-run=false to apply")
+				return
+			}
+			fmt.Println("\nUpdating artists...")
+			if err := applyArtistFixes(ctx, collection, fixes); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to fix artists: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
 	// Show Navidrome-style grouping
 	if *showNavidrome {
 		showNavidromeGrouping(files)
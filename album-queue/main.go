@@ -12,7 +12,13 @@ import (
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/config"
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/handler"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/ingest"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/notifier"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/telegram"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.uber.org/zap"
 	"gopkg.in/tucnak/telebot.v2"
 )
@@ -34,14 +40,31 @@ func main() {
 
 	log.Info("Loaded config")
 
+	var poller telebot.Poller = &telebot.LongPoller{Timeout: 10 * time.Second}
+	var updatesWebhook *telegram.Webhook
+	if cfg.TelegramWebhookURL != "" {
+		updatesWebhook, err = telegram.NewWebhook(cfg.TelegramWebhookURL, cfg.TelegramWebhookSecret, log)
+		if err != nil {
+			log.Fatal("Failed to configure telegram webhook", zap.Error(err))
+		}
+		poller = updatesWebhook
+	}
+
 	bot, err := telebot.NewBot(telebot.Settings{
 		Token:  cfg.BotToken,
-		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
+		Poller: poller,
 	})
 	if err != nil {
 		log.Fatal("Failed to create bot", zap.Error(err))
 	}
 
+	if updatesWebhook == nil {
+		// getUpdates fails while a webhook from an earlier webhook mode run is set
+		if err := bot.RemoveWebhook(); err != nil {
+			log.Warn("Failed to remove telegram webhook", zap.Error(err))
+		}
+	}
+
 	database, err := db.NewDatabase(ctx, log, cfg.DatabaseURL, cfg.DatabaseName)
 	if err != nil {
 		log.Fatal("Failed to create database connection", zap.Error(err))
@@ -49,9 +72,55 @@ func main() {
 
 	log.Info("Database connection established")
 
-	spotifyService := spotify.NewSpotifyService(ctx, cfg.SpotifyClientID, cfg.SpotifyClientSecret, log)
+	spotifyEndpoints := spotify.WithEndpoints(spotify.Endpoints{
+		AuthURL:    cfg.SpotifyAuthURL,
+		TokenURL:   cfg.SpotifyTokenURL,
+		APIBaseURL: cfg.SpotifyAPIURL,
+	})
+	spotifyService := spotify.NewSpotifyService(ctx, cfg.SpotifyClientID, cfg.SpotifyClientSecret, log, spotifyEndpoints)
 	log.Info("Spotify service initialized")
 
+	var spotifyAuth *spotify.Authenticator
+	if cfg.SpotifyRedirectURL != "" {
+		stateSecret := cfg.SpotifyStateSecret
+		if stateSecret == "" {
+			stateSecret = cfg.SpotifyClientSecret
+		}
+		spotifyAuth = spotify.NewAuthenticator(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.SpotifyRedirectURL, stateSecret, log, spotifyEndpoints)
+		log.Info("Spotify account linking enabled", zap.String("redirect_url", cfg.SpotifyRedirectURL))
+	}
+
+	sourceProvider := source.NewYtDlpProvider(log, source.WithBinary(cfg.YtDlpPath))
+
+	limits := quota.Limits{
+		ActiveRequests: cfg.QuotaActiveRequests,
+		TracksPerDay:   cfg.QuotaTracksPerDay,
+		Subscriptions:  cfg.QuotaSubscriptions,
+	}
+
+	// Webhook subscriptions are managed with /webhooks, deliveries are logged to webhook_deliveries
+	webhooks := webhook.NewDispatcher(database, log)
+
+	statsCache := db.NewStatsCache(database, cfg.StatsCacheTTL)
+
+	// files indexed before sizes were recorded would be missing from the library size
+	go func() {
+		updated, err := database.BackfillMusicFileSizes(ctx)
+		if err != nil {
+			log.Error("Failed to backfill music file sizes", zap.Error(err))
+			return
+		}
+		log.Info("Backfilled music file sizes", zap.Int("files", updated))
+	}()
+
+	var ingester *ingest.Ingester
+	if cfg.InboxPath != "" && cfg.MusicLibraryPath != "" {
+		ingester = ingest.NewIngester(database, cfg.InboxPath, cfg.MusicLibraryPath, log)
+		log.Info("Audio uploads go into the library", zap.String("inbox", cfg.InboxPath), zap.String("library", cfg.MusicLibraryPath))
+	}
+
+	h := handler.NewHandler(database, spotifyService, spotifyAuth, sourceProvider, limits, log, bot, webhooks, statsCache, ingester, cfg.BotWhitelist)
+
 	// Health check server with graceful shutdown
 	srv := &http.Server{Addr: ":8080"}
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -63,7 +132,7 @@ func main() {
 		_, _ = w.Write([]byte("Ready"))
 	})
 	http.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
-		stats, err := database.GetStats(r.Context())
+		stats, err := statsCache.Get(r.Context())
 		if err != nil {
 			log.Error("Failed to get stats", zap.Error(err))
 			http.Error(w, "Failed to get stats", http.StatusInternalServerError)
@@ -72,6 +141,11 @@ func main() {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(stats)
 	})
+	http.HandleFunc("/spotify/callback", h.SpotifyCallback)
+	if updatesWebhook != nil {
+		http.Handle(telegram.UpdatesPath, updatesWebhook)
+		log.Info("Receiving telegram updates over webhook", zap.String("path", telegram.UpdatesPath))
+	}
 
 	go func() {
 		log.Info("Starting health check server on :8080")
@@ -80,6 +154,34 @@ func main() {
 		}
 	}()
 
+	// Deliver completion notifications from the workers
+	sendMessage := func(userID int64, text string) error {
+		_, err := bot.Send(&telebot.User{ID: userID}, text)
+		return err
+	}
+	go notifier.NewNotifier(database, sendMessage, log, cfg.PlaylistBaseURL).Run(ctx, cfg.NotifyInterval)
+
+	// Alias suggestions touch every unresolved failed track, so /aliases only reads what this collects
+	go func() {
+		ticker := time.NewTicker(cfg.AliasSuggestInterval)
+		defer ticker.Stop()
+
+		for {
+			suggestions, err := database.SuggestArtistAliases(ctx)
+			if err != nil {
+				log.Error("Failed to suggest artist aliases", zap.Error(err))
+			} else {
+				log.Info("Collected artist alias suggestions", zap.Int("pending", len(suggestions)))
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	// Periodic stats logging (every 30 minutes)
 	go func() {
 		ticker := time.NewTicker(5 * time.Minute)
@@ -105,20 +207,70 @@ func main() {
 		}
 	}()
 
-	h := handler.NewHandler(database, spotifyService, log, bot, cfg.WebhookURL, cfg.BotWhitelist)
-
-	bot.Handle("/start", h.Start)
-	bot.Handle(telebot.OnText, h.HandleText)
-	bot.Handle("/queue", h.HandleQueue)
-	bot.Handle("/failed", h.HandleFailed)
-	bot.Handle(handler.FailedPageCallbackEndpoint(), h.HandleFailedPage)
-	bot.Handle("/redownload", h.HandleRedownload)
-	bot.Handle("/deactivate", h.HandleDeactivate)
-	bot.Handle("/p", h.HandlePlaylist)
-	bot.Handle("/pnp", h.HandlePlaylistNoPull)
-	bot.Handle("/subscribe", h.HandleSubscribe)
-	bot.Handle("/unsubscribe", h.HandleUnsubscribe)
-	bot.Handle("/subscriptions", h.HandleListSubscriptions)
+	// every handler goes through the role check, see handler.Authorize
+	handle := func(endpoint string, fn func(m *telebot.Message)) {
+		bot.Handle(endpoint, h.Authorize(endpoint, fn))
+	}
+	handleCallback := func(endpoint telebot.CallbackEndpoint, fn func(c *telebot.Callback)) {
+		bot.Handle(endpoint, h.AuthorizeCallback(endpoint, fn))
+	}
+
+	handle("/start", h.Start)
+	handle(telebot.OnText, h.HandleText)
+	handle(telebot.OnDocument, h.HandleDocument)
+	handle(telebot.OnAudio, h.HandleAudio)
+	handleCallback(handler.PreviewConfirmCallbackEndpoint(), h.HandlePreviewConfirm)
+	handleCallback(handler.PreviewCancelCallbackEndpoint(), h.HandlePreviewCancel)
+	handle("/queue", h.HandleQueue)
+	handleCallback(handler.QueueCancelCallbackEndpoint(), h.HandleQueueCancel)
+	handleCallback(handler.QueueRetryCallbackEndpoint(), h.HandleQueueRetry)
+	handleCallback(handler.QueueBumpCallbackEndpoint(), h.HandleQueueBump)
+	handleCallback(handler.QueueMissingCallbackEndpoint(), h.HandleQueueMissing)
+	handle("/failed", h.HandleFailed)
+	handleCallback(handler.FailedPageCallbackEndpoint(), h.HandleFailedPage)
+	handle("/history", h.HandleHistory)
+	handleCallback(handler.HistoryPageCallbackEndpoint(), h.HandleHistoryPage)
+	handle("/redownload", h.HandleRedownload)
+	handle("/deactivate", h.HandleDeactivate)
+	handle("/p", h.HandlePlaylist)
+	handle("/pnp", h.HandlePlaylistNoPull)
+	handle("/subscribe", h.HandleSubscribe)
+	handle("/unsubscribe", h.HandleUnsubscribe)
+	handle("/subscriptions", h.HandleListSubscriptions)
+	handleCallback(handler.SubscriptionIntervalCallbackEndpoint(), h.HandleSubscriptionInterval)
+	handleCallback(handler.SubscriptionPullCallbackEndpoint(), h.HandleSubscriptionPull)
+	handleCallback(handler.SubscriptionPauseCallbackEndpoint(), h.HandleSubscriptionPause)
+	handleCallback(handler.SubscriptionSyncCallbackEndpoint(), h.HandleSubscriptionSync)
+	handleCallback(handler.SubscriptionNotifyCallbackEndpoint(), h.HandleSubscriptionNotify)
+	handle("/changes", h.HandleChanges)
+	handle("/follow", h.HandleFollow)
+	handle("/unfollow", h.HandleUnfollow)
+	handle("/following", h.HandleFollowing)
+	handle("/radar", h.HandleRadar)
+	handleCallback(handler.RadarAcceptCallbackEndpoint(), h.HandleRadarAccept)
+	handleCallback(handler.RadarDismissCallbackEndpoint(), h.HandleRadarDismiss)
+	handle("/alias", h.HandleAlias)
+	handle("/aliases", h.HandleAliases)
+	handle("/aliasaccept", h.HandleAliasAccept)
+	handle("/link", h.HandleLink)
+	handle("/unlink", h.HandleUnlink)
+	handle("/lang", h.HandleLang)
+	handle("/quota", h.HandleQuota)
+	handle("/stats", h.HandleStats)
+	handle("/getplaylist", h.HandleGetPlaylist)
+	handle("/send", h.HandleSend)
+	handleCallback(handler.SendFileCallbackEndpoint(), h.HandleSendFile)
+	handle("/search", h.HandleSearch)
+	handleCallback(handler.SearchPageCallbackEndpoint(), h.HandleSearchPage)
+	handleCallback(handler.SearchAlbumCallbackEndpoint(), h.HandleSearchAlbum)
+	handleCallback(handler.SearchQueueCallbackEndpoint(), h.HandleSearchQueue)
+	handle("/adduser", h.HandleAddUser)
+	handle("/role", h.HandleRole)
+	handle("/users", h.HandleUsers)
+	handle("/removeuser", h.HandleRemoveUser)
+	handle("/webhooks", h.HandleWebhooks)
+	handle("/webhookadd", h.HandleWebhookAdd)
+	handle("/webhookdel", h.HandleWebhookDelete)
 
 	// Graceful shutdown
 	shutdownDone := make(chan struct{})
@@ -152,5 +304,6 @@ func main() {
 
 	// Wait for shutdown to complete before exiting
 	<-shutdownDone
+	webhooks.Wait()
 	log.Info("Shutdown complete")
 }
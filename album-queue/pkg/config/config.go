@@ -1,6 +1,10 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type Config struct {
 	DatabaseURL  string `envconfig:"DATABASE_URL" required:"true"`
@@ -9,10 +13,45 @@ type Config struct {
 	BotToken     string  `envconfig:"BOT_TOKEN" required:"true"`
 	BotWhitelist []int64 `envconfig:"BOT_WHITELIST" required:"true"`
 
-	WebhookURL string `envconfig:"WEBHOOK_URL" required:"true"`
+	// Updates come over a webhook on the :8080 server when the URL is set,
+	// otherwise the bot long polls
+	TelegramWebhookURL    string `envconfig:"TELEGRAM_WEBHOOK_URL"`
+	TelegramWebhookSecret string `envconfig:"TELEGRAM_WEBHOOK_SECRET"`
 
 	SpotifyClientID     string `envconfig:"SPOTIFY_CLIENT_ID" required:"true"`
 	SpotifyClientSecret string `envconfig:"SPOTIFY_CLIENT_SECRET" required:"true"`
+
+	// Account linking for private playlists and Liked Songs, disabled when the redirect URL is empty
+	SpotifyRedirectURL string `envconfig:"SPOTIFY_REDIRECT_URL"`
+	SpotifyStateSecret string `envconfig:"SPOTIFY_STATE_SECRET"`
+
+	// Endpoint overrides, used to point the bot at a stub server
+	SpotifyAuthURL  string `envconfig:"SPOTIFY_AUTH_URL"`
+	SpotifyTokenURL string `envconfig:"SPOTIFY_TOKEN_URL"`
+	SpotifyAPIURL   string `envconfig:"SPOTIFY_API_URL"`
+
+	// yt-dlp resolves YouTube Music, SoundCloud and Bandcamp links
+	YtDlpPath string `envconfig:"YT_DLP_PATH" default:"yt-dlp"`
+
+	// Per-user limits for everyone but admins, 0 disables a limit
+	QuotaActiveRequests int `envconfig:"QUOTA_ACTIVE_REQUESTS" default:"10"`
+	QuotaTracksPerDay   int `envconfig:"QUOTA_TRACKS_PER_DAY" default:"1000"`
+	QuotaSubscriptions  int `envconfig:"QUOTA_SUBSCRIPTIONS" default:"20"`
+
+	// Completion notifications written by spotdl-wapper
+	NotifyInterval  time.Duration `envconfig:"NOTIFY_INTERVAL" default:"30s"`
+	PlaylistBaseURL string        `envconfig:"PLAYLIST_BASE_URL"`
+
+	// Audio sent to the bot is downloaded to the inbox and moved into the
+	// library, uploads are refused unless both are set
+	InboxPath        string `envconfig:"INBOX_PATH"`
+	MusicLibraryPath string `envconfig:"MUSIC_LIBRARY_PATH"`
+
+	// Alias suggestions are collected from unresolved failed tracks this often
+	AliasSuggestInterval time.Duration `envconfig:"ALIAS_SUGGEST_INTERVAL" default:"1h"`
+
+	// /stats aggregates over the whole library, the result is reused for this long
+	StatsCacheTTL time.Duration `envconfig:"STATS_CACHE_TTL" default:"1m"`
 }
 
 func NewConfig() (*Config, error) {
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	uuid "github.com/satori/go.uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const nearMissCandidatesPerTrack = 5
+
+func (d *db) GetArtistAliases(ctx context.Context) (models.ArtistAliases, error) {
+	var aliases []models.ArtistAlias
+
+	cursor, err := d.artistAliasesCollection.Find(ctx, bson.M{"suggested": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find artist aliases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode artist aliases: %w", err)
+	}
+
+	return models.NewArtistAliases(aliases), nil
+}
+
+func (d *db) NewArtistAlias(ctx context.Context, alias, canonical string, creatorID int64) error {
+	_, err := d.artistAliasesCollection.UpdateOne(
+		ctx,
+		bson.M{"alias": models.NormalizeArtist(alias)},
+		bson.M{
+			"$set": bson.M{
+				"canonical":  models.NormalizeArtist(canonical),
+				"suggested":  false,
+				"creator_id": creatorID,
+			},
+			"$setOnInsert": bson.M{
+				"_id":        uuid.NewV4().String(),
+				"created_at": time.Now().Unix(),
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert artist alias: %w", err)
+	}
+
+	return nil
+}
+
+func (d *db) ConfirmArtistAlias(ctx context.Context, alias string, creatorID int64) error {
+	result, err := d.artistAliasesCollection.UpdateOne(
+		ctx,
+		bson.M{"alias": models.NormalizeArtist(alias), "suggested": true},
+		bson.M{"$set": bson.M{"suggested": false, "creator_id": creatorID}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to confirm artist alias: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (d *db) GetSuggestedArtistAliases(ctx context.Context) ([]models.ArtistAlias, error) {
+	var aliases []models.ArtistAlias
+
+	cursor, err := d.artistAliasesCollection.Find(ctx, bson.M{"suggested": true}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suggested artist aliases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode suggested artist aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// SuggestArtistAliases looks for unresolved failed tracks whose title exists in
+// the library under a slightly different artist spelling and stores those
+// pairs as suggested aliases. Aliases that already exist are left untouched.
+func (d *db) SuggestArtistAliases(ctx context.Context) ([]models.ArtistAlias, error) {
+	failedTracks, err := d.GetUnresolvedFailedTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := suggestAliasesFromFailedTracks(ctx, failedTracks, d.findMusicFilesByTitle)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, suggestion := range suggestions {
+		_, err := d.artistAliasesCollection.UpdateOne(
+			ctx,
+			bson.M{"alias": suggestion.Alias},
+			bson.M{"$setOnInsert": bson.M{
+				"_id":        uuid.NewV4().String(),
+				"canonical":  suggestion.Canonical,
+				"suggested":  true,
+				"created_at": time.Now().Unix(),
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store artist alias suggestion: %w", err)
+		}
+	}
+
+	return d.GetSuggestedArtistAliases(ctx)
+}
+
+func (d *db) findMusicFilesByTitle(ctx context.Context, title string) ([]models.MusicFile, error) {
+	files := make([]models.MusicFile, 0)
+	if strings.TrimSpace(title) == "" {
+		return files, nil
+	}
+
+	cursor, err := d.musicFilesCollection.Find(
+		ctx,
+		bson.M{"title": bson.M{"$regex": "^" + regexp.QuoteMeta(title) + "$", "$options": "i"}},
+		options.Find().SetProjection(bson.M{"meta_data": 0}).SetLimit(nearMissCandidatesPerTrack),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find music files by title: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode music files by title: %w", err)
+	}
+
+	return files, nil
+}
+
+func suggestAliasesFromFailedTracks(
+	ctx context.Context,
+	failedTracks []FailedTrack,
+	findByTitle func(context.Context, string) ([]models.MusicFile, error),
+) ([]models.ArtistAlias, error) {
+	suggestions := make(map[string]models.ArtistAlias)
+
+	for _, track := range failedTracks {
+		files, err := findByTitle(ctx, track.Title)
+		if err != nil {
+			return nil, err
+		}
+
+		alias := models.NormalizeArtist(track.Artist)
+		for _, file := range files {
+			canonical := models.NormalizeArtist(file.Artist)
+			if alias == canonical || !isNearMissArtist(alias, canonical) {
+				continue
+			}
+			if _, exists := suggestions[alias]; !exists {
+				suggestions[alias] = models.ArtistAlias{Alias: alias, Canonical: canonical, Suggested: true}
+			}
+			break
+		}
+	}
+
+	result := make([]models.ArtistAlias, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		result = append(result, suggestion)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Alias < result[j].Alias })
+
+	return result, nil
+}
+
+// isNearMissArtist reports whether two artist spellings plausibly refer to the
+// same artist: equal after dropping a leading "the" and punctuation, one is the
+// first credited artist of the other, or they differ by a couple of letters.
+func isNearMissArtist(a, b string) bool {
+	a, b = models.NormalizeArtist(a), models.NormalizeArtist(b)
+	if a == "" || b == "" {
+		return false
+	}
+
+	firstA, _, _ := strings.Cut(a, ", ")
+	firstB, _, _ := strings.Cut(b, ", ")
+	if firstA == firstB {
+		return true
+	}
+
+	squashedA, squashedB := squashArtist(firstA), squashArtist(firstB)
+	if squashedA == squashedB {
+		return true
+	}
+
+	if len(squashedA) < 5 || len(squashedB) < 5 {
+		return false
+	}
+
+	return levenshtein(squashedA, squashedB) <= 2
+}
+
+func squashArtist(artist string) string {
+	artist = strings.TrimPrefix(artist, "the ")
+
+	var b strings.Builder
+	for _, r := range artist {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// artistRegex builds a case-insensitive exact-match regex over every known
+// spelling of the artist.
+func artistRegex(aliases models.ArtistAliases, artist string) bson.M {
+	variants := aliases.Variants(artist)
+	escaped := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		escaped = append(escaped, regexp.QuoteMeta(variant))
+	}
+
+	return bson.M{"$regex": "^(?:" + strings.Join(escaped, "|") + ")$", "$options": "i"}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewArtistSubscription follows the artist for the user, dynamic-playlists
+// queues the releases that come out from now on
+func (d *db) NewArtistSubscription(ctx context.Context, url string, creatorID int64, name string) error {
+	now := time.Now().Unix()
+	artist := models.ArtistSubscription{
+		ID:         uuid.NewV4().String(),
+		CreatorID:  creatorID,
+		SpotifyURL: url,
+		Name:       name,
+		Active:     true,
+		FollowedAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if _, err := d.artistSubscriptionsCollection.InsertOne(ctx, artist); err != nil {
+		return fmt.Errorf("failed to insert artist subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (d *db) GetArtistSubscriptions(ctx context.Context, creatorID int64) ([]models.ArtistSubscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := d.artistSubscriptionsCollection.Find(ctx, bson.M{"creator_id": creatorID, "active": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find artist subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var artists []models.ArtistSubscription
+	if err := cursor.All(ctx, &artists); err != nil {
+		return nil, fmt.Errorf("failed to decode artist subscriptions: %w", err)
+	}
+
+	return artists, nil
+}
+
+func (d *db) DeleteArtistSubscription(ctx context.Context, url string, creatorID int64) error {
+	result, err := d.artistSubscriptionsCollection.UpdateMany(
+		ctx,
+		bson.M{"spotify_url": url, "creator_id": creatorID, "active": true},
+		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete artist subscription: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("artist subscription not found")
+	}
+
+	return nil
+}
+
+func (d *db) CheckArtistSubscriptionExists(ctx context.Context, url string, creatorID int64) (bool, error) {
+	count, err := d.artistSubscriptionsCollection.CountDocuments(ctx, bson.M{
+		"spotify_url": url,
+		"creator_id":  creatorID,
+		"active":      true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check artist subscription existence: %w", err)
+	}
+	return count > 0, nil
+}
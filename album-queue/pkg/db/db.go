@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
@@ -9,8 +10,11 @@ import (
 	"time"
 
 	uuid "github.com/satori/go.uuid"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
 	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,23 +22,70 @@ import (
 )
 
 type Database interface {
-	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) error
+	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, src source.Source, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) (string, error)
+	NewTracksRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) (string, error)
 	GetActiveRequests(ctx context.Context) ([]models.DownloadQueueRequest, error)
 	GetUnresolvedFailedTracks(ctx context.Context) ([]FailedTrack, error)
 	GetUnresolvedFailedTrackByURL(ctx context.Context, trackURL string) (FailedTrack, error)
 	HasActiveRequestByURL(ctx context.Context, trackURL string) (bool, error)
+	GetDownloadRequest(ctx context.Context, id string) (models.DownloadQueueRequest, error)
 	DeactivateRequest(ctx context.Context, id string) error
-	NewPlaylistRequest(ctx context.Context, url string, creatorID int64, noPull bool) error
+	RetryRequest(ctx context.Context, id string) error
+	BumpRequestPriority(ctx context.Context, id string) (int, error)
+	GetUserHistory(ctx context.Context, creatorID int64, offset, limit int64) ([]HistoryEntry, int64, error)
+	NewPlaylistRequest(ctx context.Context, url string, creatorID int64, noPull bool, trackCount int) (string, error)
 	GetActivePlaylists(ctx context.Context) ([]models.PlaylistRequest, error)
 	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	SearchMusicFiles(ctx context.Context, query string, limit int) ([]models.MusicFile, error)
+	IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error)
+	GetMusicFile(ctx context.Context, id string) (models.MusicFile, error)
+	GetAlbumFiles(ctx context.Context, artist, album string) ([]models.MusicFile, error)
 	UpdateDownloadRequest(ctx context.Context, request models.DownloadQueueRequest) error
-	NewSubscribedPlaylist(ctx context.Context, url string, creatorID int64, name string, refreshInterval string, noPull bool) error
+	NewSubscribedPlaylist(ctx context.Context, url string, creatorID int64, name string, refreshInterval string, noPull, notifyChanges bool, removalPolicy models.RemovalPolicy) error
 	GetSubscribedPlaylists(ctx context.Context, creatorID int64) ([]models.SubscribedPlaylist, error)
+	GetPlaylistFiles(ctx context.Context, creatorID int64) ([]PlaylistFile, error)
+	SaveImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) error
 	DeleteSubscribedPlaylist(ctx context.Context, url string, creatorID int64) error
 	CheckSubscriptionExists(ctx context.Context, url string, creatorID int64) (bool, error)
+	UpdateSubscribedPlaylist(ctx context.Context, id string, creatorID int64, update SubscriptionUpdate) (models.SubscribedPlaylist, error)
+	GetSubscriptionChanges(ctx context.Context, creatorID int64, limit int64) ([]models.SubscriptionSnapshot, error)
+	NewArtistSubscription(ctx context.Context, url string, creatorID int64, name string) error
+	GetArtistSubscriptions(ctx context.Context, creatorID int64) ([]models.ArtistSubscription, error)
+	DeleteArtistSubscription(ctx context.Context, url string, creatorID int64) error
+	CheckArtistSubscriptionExists(ctx context.Context, url string, creatorID int64) (bool, error)
+	GetPendingReleaseSuggestions(ctx context.Context, limit int64) ([]models.ReleaseSuggestion, error)
+	GetReleaseSuggestion(ctx context.Context, id string) (models.ReleaseSuggestion, error)
+	DecideReleaseSuggestion(ctx context.Context, id string, status models.ReleaseSuggestionStatus, userID int64) error
 	Close(ctx context.Context) error
 	Ping(ctx context.Context) error
 	GetStats(ctx context.Context) (*Stats, error)
+	GetLibraryStats(ctx context.Context) (*LibraryStats, error)
+	BackfillMusicFileSizes(ctx context.Context) (int, error)
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	NewArtistAlias(ctx context.Context, alias, canonical string, creatorID int64) error
+	ConfirmArtistAlias(ctx context.Context, alias string, creatorID int64) error
+	GetSuggestedArtistAliases(ctx context.Context) ([]models.ArtistAlias, error)
+	SuggestArtistAliases(ctx context.Context) ([]models.ArtistAlias, error)
+	GetSpotifyToken(ctx context.Context, userID int64) (spotify.UserToken, error)
+	SaveSpotifyToken(ctx context.Context, token spotify.UserToken) error
+	DeleteSpotifyToken(ctx context.Context, userID int64) error
+	GetUserLanguage(ctx context.Context, userID int64) (string, error)
+	SetUserLanguage(ctx context.Context, userID int64, language string) error
+	GetUser(ctx context.Context, userID int64) (models.User, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	SetUserRole(ctx context.Context, userID int64, role models.Role, addedBy int64) error
+	SetUserName(ctx context.Context, userID int64, name string) error
+	DeleteUser(ctx context.Context, userID int64) error
+	GetUsage(ctx context.Context, creatorID int64, since int64) (quota.Usage, error)
+	GetPendingNotifications(ctx context.Context, limit int) ([]models.Notification, error)
+	MarkNotificationSent(ctx context.Context, id string) error
+	MarkNotificationFailed(ctx context.Context, id string, giveUp bool) error
+	NewWebhookSubscription(ctx context.Context, url string, events []webhook.Event, createdBy int64) (webhook.Subscription, error)
+	GetWebhookSubscriptions(ctx context.Context, event webhook.Event) ([]webhook.Subscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	SaveWebhookDelivery(ctx context.Context, delivery webhook.Delivery) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID string, limit int64) ([]webhook.Delivery, error)
 }
 
 type Stats struct {
@@ -44,6 +95,18 @@ type Stats struct {
 	ActivePlaylists       int64 `json:"active_playlists"`
 }
 
+// SubscriptionUpdate holds the subscription settings to change, nil fields
+// are left as they are
+type SubscriptionUpdate struct {
+	RefreshInterval *string
+	NoPull          *bool
+	Paused          *bool
+	NotifyChanges   *bool
+	// SyncNow makes the subscription due on the next dynamic-playlists run,
+	// which then fetches the playlist even if Spotify reports it unchanged
+	SyncNow bool
+}
+
 type FailedTrack struct {
 	SpotifyURL     string `json:"spotify_url"`
 	Artist         string `json:"artist"`
@@ -51,6 +114,8 @@ type FailedTrack struct {
 	FailedAttempts int    `json:"failed_attempts"`
 	SourceCount    int    `json:"source_count"`
 	LastSeenAt     int64  `json:"last_seen_at"`
+	// Source is the provider of the request the track last failed in
+	Source source.Source `json:"source"`
 }
 
 type db struct {
@@ -61,7 +126,18 @@ type db struct {
 	downloadQueueRequestCollection *mongo.Collection
 	playlistRequestCollection      *mongo.Collection
 	subscribedPlaylistsCollection  *mongo.Collection
+	dynamicPlaylistsCollection     *mongo.Collection
+	importedPlaylistsCollection    *mongo.Collection
 	musicFilesCollection           *mongo.Collection
+	artistAliasesCollection        *mongo.Collection
+	spotifyTokensCollection        *mongo.Collection
+	usersCollection                *mongo.Collection
+	notificationsCollection        *mongo.Collection
+	snapshotsCollection            *mongo.Collection
+	artistSubscriptionsCollection  *mongo.Collection
+	releaseSuggestionsCollection   *mongo.Collection
+	webhookSubscriptionsCollection *mongo.Collection
+	webhookDeliveriesCollection    *mongo.Collection
 	dbname                         string
 }
 
@@ -84,7 +160,18 @@ func NewDatabase(ctx context.Context, log *zap.Logger, url, dbname string) (Data
 		downloadQueueRequestCollection: conn.Database(dbname).Collection("download-queue-requests"),
 		playlistRequestCollection:      conn.Database(dbname).Collection("playlist-requests"),
 		subscribedPlaylistsCollection:  conn.Database(dbname).Collection("subscribed_playlists"),
+		dynamicPlaylistsCollection:     conn.Database(dbname).Collection("dynamic_playlists"),
+		importedPlaylistsCollection:    conn.Database(dbname).Collection("imported_playlists"),
 		musicFilesCollection:           conn.Database(dbname).Collection("music-files"),
+		artistAliasesCollection:        conn.Database(dbname).Collection("artist_aliases"),
+		spotifyTokensCollection:        conn.Database(dbname).Collection("spotify_tokens"),
+		usersCollection:                conn.Database(dbname).Collection("users"),
+		notificationsCollection:        conn.Database(dbname).Collection("notifications"),
+		snapshotsCollection:            conn.Database(dbname).Collection("subscription_snapshots"),
+		artistSubscriptionsCollection:  conn.Database(dbname).Collection("artist_subscriptions"),
+		releaseSuggestionsCollection:   conn.Database(dbname).Collection("release_suggestions"),
+		webhookSubscriptionsCollection: conn.Database(dbname).Collection("webhook_subscriptions"),
+		webhookDeliveriesCollection:    conn.Database(dbname).Collection("webhook_deliveries"),
 	}, nil
 }
 
@@ -96,11 +183,12 @@ func (d *db) Ping(ctx context.Context) error {
 	return d.conn.Ping(ctx, nil)
 }
 
-func (d *db) NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) error {
+func (d *db) NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, src source.Source, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) (string, error) {
 	id := uuid.NewV4()
 	request := models.DownloadQueueRequest{
 		SpotifyURL:         url,
 		ObjectType:         objectType,
+		Source:             src,
 		Name:               name,
 		Active:             true,
 		ID:                 id.String(),
@@ -114,35 +202,67 @@ func (d *db) NewDownloadRequest(ctx context.Context, url, name string, creatorID
 
 	_, err := d.downloadQueueRequestCollection.InsertOne(ctx, request)
 	if err != nil {
-		return fmt.Errorf("failed to insert download request: %w", err)
+		return "", fmt.Errorf("failed to insert download request: %w", err)
 	}
 
-	return nil
+	return request.ID, nil
 }
 
-func (d *db) NewPlaylistRequest(ctx context.Context, url string, creatorID int64, noPull bool) error {
+// NewTracksRequest queues only the given tracks of an album or playlist, the
+// downloader fetches them one by one instead of the whole URL
+func (d *db) NewTracksRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) (string, error) {
+	now := time.Now().Unix()
+	request := models.DownloadQueueRequest{
+		ID:                 uuid.NewV4().String(),
+		CreatorID:          creatorID,
+		SpotifyURL:         url,
+		ObjectType:         objectType,
+		Source:             source.Spotify,
+		Name:               name,
+		Active:             true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		ExpectedTrackCount: len(tracks),
+		TrackMetadata:      tracks,
+		TracksOnly:         true,
+	}
+
+	if _, err := d.downloadQueueRequestCollection.InsertOne(ctx, request); err != nil {
+		return "", fmt.Errorf("failed to insert tracks request: %w", err)
+	}
+
+	return request.ID, nil
+}
+
+func (d *db) NewPlaylistRequest(ctx context.Context, url string, creatorID int64, noPull bool, trackCount int) (string, error) {
 	id := uuid.NewV4()
+	now := time.Now().Unix()
 	request := models.PlaylistRequest{
 		SpotifyURL: url,
 		Active:     true,
 		ID:         id.String(),
-		CreatedAt:  time.Now().Unix(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
 		CreatorID:  creatorID,
 		NoPull:     noPull,
+
+		ExpectedTrackCount: trackCount,
 	}
 
 	_, err := d.playlistRequestCollection.InsertOne(ctx, request)
 	if err != nil {
-		return fmt.Errorf("failed to insert playlist request: %w", err)
+		return "", fmt.Errorf("failed to insert playlist request: %w", err)
 	}
 
-	return nil
+	return request.ID, nil
 }
 
 func (d *db) GetActiveRequests(ctx context.Context) ([]models.DownloadQueueRequest, error) {
 	var requests []models.DownloadQueueRequest
 
-	cursor, err := d.downloadQueueRequestCollection.Find(ctx, bson.M{"active": true})
+	// same order the downloader picks them up in
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "errored", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := d.downloadQueueRequestCollection.Find(ctx, bson.M{"active": true}, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find active requests: %w", err)
 	}
@@ -175,7 +295,16 @@ func (d *db) GetUnresolvedFailedTracks(ctx context.Context) ([]FailedTrack, erro
 		return nil, fmt.Errorf("failed to decode requests with unresolved tracks: %w", err)
 	}
 
-	tracks, err := filterUnresolvedFailedTracks(ctx, requests, d.musicFileExistsInsensitive, d.HasActiveRequestByURL)
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	musicFileExists := func(ctx context.Context, artist, title string) (bool, error) {
+		return d.musicFileExistsInsensitive(ctx, aliases, artist, title)
+	}
+
+	tracks, err := filterUnresolvedFailedTracks(ctx, requests, musicFileExists, d.HasActiveRequestByURL)
 	if err != nil {
 		return nil, err
 	}
@@ -220,11 +349,26 @@ func (d *db) GetActivePlaylists(ctx context.Context) ([]models.PlaylistRequest,
 	return playlists, nil
 }
 
+// GetDownloadRequest returns the request with the given id, or mongo.ErrNoDocuments
+func (d *db) GetDownloadRequest(ctx context.Context, id string) (models.DownloadQueueRequest, error) {
+	var request models.DownloadQueueRequest
+
+	err := d.downloadQueueRequestCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.DownloadQueueRequest{}, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return models.DownloadQueueRequest{}, fmt.Errorf("failed to find download request: %w", err)
+	}
+
+	return request, nil
+}
+
 func (d *db) DeactivateRequest(ctx context.Context, id string) error {
 	result, err := d.downloadQueueRequestCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now().Unix()}},
+		bson.M{"$set": bson.M{"active": false, "cancelled": true, "updated_at": time.Now().Unix()}},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to deactivate request: %w", err)
@@ -237,16 +381,75 @@ func (d *db) DeactivateRequest(ctx context.Context, id string) error {
 	return nil
 }
 
+// RetryRequest resets the retry counters of an active request so the
+// downloader picks it up again as a fresh one
+func (d *db) RetryRequest(ctx context.Context, id string) error {
+	result, err := d.downloadQueueRequestCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "active": true},
+		bson.M{"$set": bson.M{
+			"errored":     false,
+			"retry_count": 0,
+			"sync_count":  0,
+			"updated_at":  time.Now().Unix(),
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to retry request: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// BumpRequestPriority moves an active request to the front of the queue and
+// returns its new priority
+func (d *db) BumpRequestPriority(ctx context.Context, id string) (int, error) {
+	var top models.DownloadQueueRequest
+	err := d.downloadQueueRequestCollection.FindOne(
+		ctx,
+		bson.M{"active": true},
+		options.FindOne().SetSort(bson.M{"priority": -1}).SetProjection(bson.M{"priority": 1}),
+	).Decode(&top)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, fmt.Errorf("failed to find top priority: %w", err)
+	}
+
+	priority := top.Priority + 1
+	result, err := d.downloadQueueRequestCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "active": true},
+		bson.M{"$set": bson.M{"priority": priority, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to bump request priority: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return 0, mongo.ErrNoDocuments
+	}
+
+	return priority, nil
+}
+
 func (d *db) FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error) {
 	if len(artists) != len(titles) {
 		return nil, fmt.Errorf("artists and titles must have the same length")
 	}
 
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		d.log.Warn("failed to load artist aliases, matching exact artists only", zap.Error(err))
+	}
+
 	orPairs := make([]bson.M, 0, len(artists))
 	for i := range artists {
 		orPairs = append(orPairs, bson.M{
-			"artist": artists[i],
-			"title":  titles[i],
+			"artist": artistRegex(aliases, artists[i]),
+			"title":  bson.M{"$regex": "^" + regexp.QuoteMeta(titles[i]) + "$", "$options": "i"},
 		})
 	}
 
@@ -332,7 +535,7 @@ func (d *db) GetStats(ctx context.Context) (*Stats, error) {
 	return stats, nil
 }
 
-func (d *db) NewSubscribedPlaylist(ctx context.Context, url string, creatorID int64, name string, refreshInterval string, noPull bool) error {
+func (d *db) NewSubscribedPlaylist(ctx context.Context, url string, creatorID int64, name string, refreshInterval string, noPull, notifyChanges bool, removalPolicy models.RemovalPolicy) error {
 	id := uuid.NewV4()
 	playlist := models.SubscribedPlaylist{
 		ID:              id.String(),
@@ -342,6 +545,8 @@ func (d *db) NewSubscribedPlaylist(ctx context.Context, url string, creatorID in
 		Active:          true,
 		RefreshInterval: refreshInterval,
 		NoPull:          noPull,
+		NotifyChanges:   notifyChanges,
+		RemovalPolicy:   removalPolicy,
 		LastSynced:      0,
 		LastTrackCount:  0,
 		CreatedAt:       time.Now().Unix(),
@@ -401,17 +606,57 @@ func (d *db) CheckSubscriptionExists(ctx context.Context, url string, creatorID
 	return count > 0, nil
 }
 
-func (d *db) musicFileExistsInsensitive(ctx context.Context, artist, title string) (bool, error) {
+// UpdateSubscribedPlaylist changes the settings of an active subscription of
+// the user and returns it as updated. It returns mongo.ErrNoDocuments when the
+// user has no such subscription.
+func (d *db) UpdateSubscribedPlaylist(ctx context.Context, id string, creatorID int64, update SubscriptionUpdate) (models.SubscribedPlaylist, error) {
+	set := bson.M{"updated_at": time.Now().Unix()}
+	if update.RefreshInterval != nil {
+		set["refresh_interval"] = *update.RefreshInterval
+	}
+	if update.NoPull != nil {
+		set["no_pull"] = *update.NoPull
+		// missing tracks are only requested on a full sync
+		set["snapshot_id"] = ""
+	}
+	if update.Paused != nil {
+		set["paused"] = *update.Paused
+	}
+	if update.NotifyChanges != nil {
+		set["notify_changes"] = *update.NotifyChanges
+	}
+	if update.SyncNow {
+		set["last_synced"] = 0
+		set["snapshot_id"] = ""
+	}
+
+	var playlist models.SubscribedPlaylist
+	err := d.subscribedPlaylistsCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id, "creator_id": creatorID, "active": true},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&playlist)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.SubscribedPlaylist{}, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return models.SubscribedPlaylist{}, fmt.Errorf("failed to update subscribed playlist: %w", err)
+	}
+
+	return playlist, nil
+}
+
+func (d *db) musicFileExistsInsensitive(ctx context.Context, aliases models.ArtistAliases, artist, title string) (bool, error) {
 	if strings.TrimSpace(artist) == "" || strings.TrimSpace(title) == "" {
 		return false, nil
 	}
 
-	escapedArtist := regexp.QuoteMeta(artist)
 	escapedTitle := regexp.QuoteMeta(title)
 
 	count, err := d.musicFilesCollection.CountDocuments(ctx, bson.M{
 		"$and": []bson.M{
-			{"artist": bson.M{"$regex": "^" + escapedArtist + "$", "$options": "i"}},
+			{"artist": artistRegex(aliases, artist)},
 			{"title": bson.M{"$regex": "^" + escapedTitle + "$", "$options": "i"}},
 		},
 	})
@@ -466,6 +711,7 @@ func filterUnresolvedFailedTracks(
 					FailedAttempts: track.FailedAttempts,
 					SourceCount:    1,
 					LastSeenAt:     lastSeen,
+					Source:         request.Source,
 				}
 				continue
 			}
@@ -478,6 +724,7 @@ func filterUnresolvedFailedTracks(
 				current.LastSeenAt = lastSeen
 				current.Artist = track.Artist
 				current.Title = track.Title
+				current.Source = request.Source
 			}
 			failedByURL[track.SpotifyURL] = current
 		}
@@ -116,3 +116,49 @@ func TestSelectFailedTrackByURLReturnsNotFoundForResolvedOrMissing(t *testing.T)
 		t.Fatalf("expected mongo.ErrNoDocuments, got %v", err)
 	}
 }
+
+func TestIsNearMissArtist(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "Weeknd", b: "The Weeknd", expected: true},
+		{a: "AC/DC", b: "acdc", expected: true},
+		{a: "Daft Punk, Pharrell Williams", b: "daft punk", expected: true},
+		{a: "Beyonce", b: "Beyoncé", expected: true},
+		{a: "Muse", b: "Mute", expected: false},
+		{a: "Radiohead", b: "Portishead", expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := isNearMissArtist(tt.a, tt.b); got != tt.expected {
+			t.Errorf("isNearMissArtist(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestSuggestAliasesFromFailedTracks(t *testing.T) {
+	failed := []FailedTrack{
+		{SpotifyURL: "https://open.spotify.com/track/1", Artist: "Weeknd", Title: "Starboy"},
+		{SpotifyURL: "https://open.spotify.com/track/2", Artist: "Radiohead", Title: "Creep"},
+	}
+	library := map[string][]models.MusicFile{
+		"Starboy": {{Artist: "The Weeknd", Title: "starboy"}},
+		"Creep":   {{Artist: "Stone Temple Pilots", Title: "Creep"}},
+	}
+
+	suggestions, err := suggestAliasesFromFailedTracks(
+		context.Background(),
+		failed,
+		func(_ context.Context, title string) ([]models.MusicFile, error) { return library[title], nil },
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected one suggestion, got %+v", suggestions)
+	}
+	if suggestions[0].Alias != "weeknd" || suggestions[0].Canonical != "the weeknd" || !suggestions[0].Suggested {
+		t.Fatalf("unexpected suggestion: %+v", suggestions[0])
+	}
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// HistoryEntry is a download or playlist request of the user, exactly one of
+// the two is set
+type HistoryEntry struct {
+	Download *models.DownloadQueueRequest
+	Playlist *models.PlaylistRequest
+}
+
+func (e HistoryEntry) CreatedAt() int64 {
+	if e.Download != nil {
+		return e.Download.CreatedAt
+	}
+	return e.Playlist.CreatedAt
+}
+
+// FinishedAt is when a request that is no longer active was last processed,
+// zero while it's active
+func (e HistoryEntry) FinishedAt() int64 {
+	if r := e.Download; r != nil {
+		if r.Active {
+			return 0
+		}
+		return r.UpdatedAt
+	}
+	if e.Playlist.Active {
+		return 0
+	}
+	return e.Playlist.UpdatedAt
+}
+
+// GetUserHistory returns a page of the download and playlist requests created
+// by the user, newest first, together with their total count. Both
+// collections are merged and paged in one aggregation. Track metadata is left
+// out, the stored counts are enough for the history.
+func (d *db) GetUserHistory(ctx context.Context, creatorID int64, offset, limit int64) ([]HistoryEntry, int64, error) {
+	filter := bson.M{"creator_id": creatorID}
+
+	cursor, err := d.downloadQueueRequestCollection.Aggregate(ctx, bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$project": bson.M{"track_metadata": 0}},
+		bson.M{"$addFields": bson.M{"kind": "download"}},
+		bson.M{"$unionWith": bson.M{
+			"coll": d.playlistRequestCollection.Name(),
+			"pipeline": bson.A{
+				bson.M{"$match": filter},
+				bson.M{"$addFields": bson.M{"kind": "playlist"}},
+			},
+		}},
+		bson.M{"$sort": bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$facet": bson.M{
+			"entries": bson.A{bson.M{"$skip": offset}, bson.M{"$limit": limit}},
+			"total":   bson.A{bson.M{"$count": "count"}},
+		}},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to aggregate user history: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var pages []struct {
+		Entries []bson.Raw `bson:"entries"`
+		Total   []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &pages); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode user history: %w", err)
+	}
+	if len(pages) == 0 || len(pages[0].Total) == 0 {
+		return nil, 0, nil
+	}
+
+	entries := make([]HistoryEntry, 0, len(pages[0].Entries))
+	for _, raw := range pages[0].Entries {
+		entry, err := decodeHistoryEntry(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, pages[0].Total[0].Count, nil
+}
+
+func decodeHistoryEntry(raw bson.Raw) (HistoryEntry, error) {
+	if kind, _ := raw.Lookup("kind").StringValueOK(); kind == "playlist" {
+		var playlist models.PlaylistRequest
+		if err := bson.Unmarshal(raw, &playlist); err != nil {
+			return HistoryEntry{}, fmt.Errorf("failed to decode user playlist: %w", err)
+		}
+		return HistoryEntry{Playlist: &playlist}, nil
+	}
+
+	var download models.DownloadQueueRequest
+	if err := bson.Unmarshal(raw, &download); err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to decode user request: %w", err)
+	}
+	return HistoryEntry{Download: &download}, nil
+}
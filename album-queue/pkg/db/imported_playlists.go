@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveImportedPlaylist stores a playlist uploaded as a file, replacing the
+// user's playlist of the same name so a newer export updates it.
+// dynamic-playlists writes its M3U and queues its missing tracks on the next run.
+func (d *db) SaveImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) error {
+	now := time.Now().Unix()
+	playlist.ID = uuid.NewV4().String()
+	playlist.Active = true
+	playlist.CreatedAt = now
+	playlist.UpdatedAt = now
+
+	var existing models.ImportedPlaylist
+	err := d.importedPlaylistsCollection.FindOne(ctx, bson.M{"creator_id": playlist.CreatorID, "name": playlist.Name}).Decode(&existing)
+	switch {
+	case err == nil:
+		playlist.ID = existing.ID
+		playlist.CreatedAt = existing.CreatedAt
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("failed to find imported playlist: %w", err)
+	}
+
+	_, err = d.importedPlaylistsCollection.ReplaceOne(ctx, bson.M{"_id": playlist.ID}, playlist, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save imported playlist: %w", err)
+	}
+
+	return nil
+}
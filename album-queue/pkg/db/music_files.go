@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SearchMusicFiles finds library files where every word of query appears in
+// the artist, title or album, sorted by artist, album and title
+func (d *db) SearchMusicFiles(ctx context.Context, query string, limit int) ([]models.MusicFile, error) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	and := make([]bson.M, 0, len(words))
+	for _, word := range words {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(word), "$options": "i"}
+		and = append(and, bson.M{"$or": []bson.M{
+			{"artist": pattern},
+			{"title": pattern},
+			{"album": pattern},
+		}})
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"meta_data": 0}).
+		SetSort(bson.D{{Key: "artist", Value: 1}, {Key: "album", Value: 1}, {Key: "title", Value: 1}}).
+		SetLimit(int64(limit))
+
+	return d.findMusicFiles(ctx, bson.M{"$and": and}, opts)
+}
+
+// GetMusicFile returns the file with the given id or mongo.ErrNoDocuments
+func (d *db) GetMusicFile(ctx context.Context, id string) (models.MusicFile, error) {
+	var file models.MusicFile
+
+	err := d.musicFilesCollection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"meta_data": 0})).Decode(&file)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.MusicFile{}, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to find music file: %w", err)
+	}
+
+	return file, nil
+}
+
+// GetAlbumFiles returns the library files of an album, matching artist and album case-insensitively
+func (d *db) GetAlbumFiles(ctx context.Context, artist, album string) ([]models.MusicFile, error) {
+	filter := bson.M{
+		"artist": bson.M{"$regex": "^" + regexp.QuoteMeta(artist) + "$", "$options": "i"},
+		"album":  bson.M{"$regex": "^" + regexp.QuoteMeta(album) + "$", "$options": "i"},
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"meta_data": 0}).
+		SetSort(bson.D{{Key: "title", Value: 1}})
+
+	return d.findMusicFiles(ctx, filter, opts)
+}
+
+// IndexMusicFile adds a file that was put into the library to music-files
+func (d *db) IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error) {
+	now := time.Now().Unix()
+	file.ID = uuid.NewV4().String()
+	file.CreatedAt = now
+	file.UpdatedAt = now
+
+	if _, err := d.musicFilesCollection.InsertOne(ctx, file); err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to index music file: %w", err)
+	}
+
+	return file, nil
+}
+
+// BackfillMusicFileSizes records the size of the library files indexed
+// without one and returns how many it updated. Files that can't be read here
+// are left for the next run.
+func (d *db) BackfillMusicFileSizes(ctx context.Context) (int, error) {
+	files, err := d.findMusicFiles(ctx,
+		bson.M{"size": bson.M{"$not": bson.M{"$gt": 0}}},
+		options.Find().SetProjection(bson.M{"path": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, file := range files {
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			continue
+		}
+
+		_, err = d.musicFilesCollection.UpdateOne(ctx,
+			bson.M{"_id": file.ID},
+			bson.M{"$set": bson.M{"size": info.Size(), "updated_at": time.Now().Unix()}},
+		)
+		if err != nil {
+			return updated, fmt.Errorf("failed to set music file size: %w", err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+func (d *db) findMusicFiles(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.MusicFile, error) {
+	cur, err := d.musicFilesCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find music files: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	files := make([]models.MusicFile, 0)
+	if err := cur.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode music files: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPendingNotifications returns the oldest notifications that were neither
+// delivered nor given up on
+func (d *db) GetPendingNotifications(ctx context.Context, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := d.notificationsCollection.Find(ctx, bson.M{"sent_at": 0, "failed": bson.M{"$ne": true}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending notifications: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to decode notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (d *db) MarkNotificationSent(ctx context.Context, id string) error {
+	_, err := d.notificationsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"sent_at": time.Now().Unix()}, "$inc": bson.M{"attempts": 1}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkNotificationFailed records a failed delivery, giveUp stops further attempts
+func (d *db) MarkNotificationFailed(ctx context.Context, id string, giveUp bool) error {
+	_, err := d.notificationsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"failed": giveUp}, "$inc": bson.M{"attempts": 1}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PlaylistFile is a playlist dynamic-playlists writes to disk as M3U
+type PlaylistFile struct {
+	Name string
+	// Path is empty until the playlist has been generated
+	Path string
+	// Subscribed is set for the user's subscribed playlists, unset for
+	// dynamic playlists
+	Subscribed bool
+	// Imported is set for the playlists the user imported from files
+	Imported bool
+}
+
+// GetPlaylistFiles returns the user's subscribed playlists and imported
+// playlists followed by the active dynamic playlists, each sorted by name
+func (d *db) GetPlaylistFiles(ctx context.Context, creatorID int64) ([]PlaylistFile, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "output_path": 1}).
+		SetSort(bson.D{{Key: "name", Value: 1}})
+
+	var subscribed []models.SubscribedPlaylist
+	cursor, err := d.subscribedPlaylistsCollection.Find(ctx, bson.M{"creator_id": creatorID, "active": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscribed playlists: %w", err)
+	}
+	if err := cursor.All(ctx, &subscribed); err != nil {
+		return nil, fmt.Errorf("failed to decode subscribed playlists: %w", err)
+	}
+
+	var imported []models.ImportedPlaylist
+	cursor, err = d.importedPlaylistsCollection.Find(ctx, bson.M{"creator_id": creatorID, "active": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find imported playlists: %w", err)
+	}
+	if err := cursor.All(ctx, &imported); err != nil {
+		return nil, fmt.Errorf("failed to decode imported playlists: %w", err)
+	}
+
+	var dynamic []models.DynamicPlaylist
+	cursor, err = d.dynamicPlaylistsCollection.Find(ctx, bson.M{"active": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dynamic playlists: %w", err)
+	}
+	if err := cursor.All(ctx, &dynamic); err != nil {
+		return nil, fmt.Errorf("failed to decode dynamic playlists: %w", err)
+	}
+
+	files := make([]PlaylistFile, 0, len(subscribed)+len(imported)+len(dynamic))
+	for _, playlist := range subscribed {
+		files = append(files, PlaylistFile{Name: playlist.Name, Path: playlist.OutputPath, Subscribed: true})
+	}
+	for _, playlist := range imported {
+		files = append(files, PlaylistFile{Name: playlist.Name, Path: playlist.OutputPath, Imported: true})
+	}
+	for _, playlist := range dynamic {
+		files = append(files, PlaylistFile{Name: playlist.Name, Path: playlist.OutputPath})
+	}
+
+	return files, nil
+}
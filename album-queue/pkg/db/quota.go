@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetUsage returns what the user currently takes up of their quota. Tracks are
+// counted for download and playlist requests created at or after since. The
+// track requests of an imported playlist count as one active request.
+func (d *db) GetUsage(ctx context.Context, creatorID int64, since int64) (quota.Usage, error) {
+	var usage quota.Usage
+
+	activeRequests, err := d.downloadQueueRequestCollection.CountDocuments(ctx, bson.M{
+		"creator_id":           creatorID,
+		"active":               true,
+		"imported_playlist_id": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return usage, fmt.Errorf("failed to count active requests: %w", err)
+	}
+
+	activeImports, err := d.downloadQueueRequestCollection.Distinct(ctx, "imported_playlist_id", bson.M{
+		"creator_id":           creatorID,
+		"active":               true,
+		"imported_playlist_id": bson.M{"$exists": true},
+	})
+	if err != nil {
+		return usage, fmt.Errorf("failed to count active imported playlists: %w", err)
+	}
+	activeRequests += int64(len(activeImports))
+
+	activePlaylists, err := d.playlistRequestCollection.CountDocuments(ctx, bson.M{"creator_id": creatorID, "active": true})
+	if err != nil {
+		return usage, fmt.Errorf("failed to count active playlists: %w", err)
+	}
+	usage.ActiveRequests = int(activeRequests + activePlaylists)
+
+	for _, collection := range []*mongo.Collection{d.downloadQueueRequestCollection, d.playlistRequestCollection} {
+		tracks, err := sumExpectedTracks(ctx, collection, creatorID, since)
+		if err != nil {
+			return usage, err
+		}
+		usage.TracksToday += tracks
+	}
+
+	subscriptions, err := d.subscribedPlaylistsCollection.CountDocuments(ctx, bson.M{"creator_id": creatorID, "active": true})
+	if err != nil {
+		return usage, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+	usage.Subscriptions = int(subscriptions)
+
+	return usage, nil
+}
+
+func sumExpectedTracks(ctx context.Context, collection *mongo.Collection, creatorID int64, since int64) (int, error) {
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"creator_id": creatorID, "created_at": bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{"_id": nil, "tracks": bson.M{"$sum": "$expected_track_count"}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum queued tracks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		Tracks int `bson:"tracks"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return 0, fmt.Errorf("failed to decode queued tracks: %w", err)
+	}
+	if len(totals) == 0 {
+		return 0, nil
+	}
+
+	return totals[0].Tracks, nil
+}
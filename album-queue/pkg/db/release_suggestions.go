@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPendingReleaseSuggestions returns the release radar suggestions nobody
+// decided on yet, newest releases first
+func (d *db) GetPendingReleaseSuggestions(ctx context.Context, limit int64) ([]models.ReleaseSuggestion, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "release_date", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := d.releaseSuggestionsCollection.Find(ctx, bson.M{"status": models.ReleaseSuggestionPending}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find release suggestions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var suggestions []models.ReleaseSuggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode release suggestions: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// GetReleaseSuggestion returns the suggestion, or mongo.ErrNoDocuments when it
+// doesn't exist
+func (d *db) GetReleaseSuggestion(ctx context.Context, id string) (models.ReleaseSuggestion, error) {
+	var suggestion models.ReleaseSuggestion
+	err := d.releaseSuggestionsCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&suggestion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.ReleaseSuggestion{}, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return models.ReleaseSuggestion{}, fmt.Errorf("failed to get release suggestion: %w", err)
+	}
+	return suggestion, nil
+}
+
+// DecideReleaseSuggestion accepts or dismisses a pending suggestion. It
+// returns mongo.ErrNoDocuments when someone decided on it already.
+func (d *db) DecideReleaseSuggestion(ctx context.Context, id string, status models.ReleaseSuggestionStatus, userID int64) error {
+	result, err := d.releaseSuggestionsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": models.ReleaseSuggestionPending},
+		bson.M{"$set": bson.M{"status": status, "decided_by": userID, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update release suggestion: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
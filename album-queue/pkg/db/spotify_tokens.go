@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *db) GetSpotifyToken(ctx context.Context, userID int64) (spotify.UserToken, error) {
+	var token spotify.UserToken
+
+	err := d.spotifyTokensCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return spotify.UserToken{}, spotify.ErrTokenNotFound
+	}
+	if err != nil {
+		return spotify.UserToken{}, fmt.Errorf("failed to find spotify token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (d *db) SaveSpotifyToken(ctx context.Context, token spotify.UserToken) error {
+	if token.CreatedAt == 0 {
+		token.CreatedAt = time.Now().Unix()
+	}
+	token.UpdatedAt = time.Now().Unix()
+
+	_, err := d.spotifyTokensCollection.ReplaceOne(
+		ctx,
+		bson.M{"user_id": token.UserID},
+		token,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save spotify token: %w", err)
+	}
+
+	return nil
+}
+
+func (d *db) DeleteSpotifyToken(ctx context.Context, userID int64) error {
+	result, err := d.spotifyTokensCollection.DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete spotify token: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return spotify.ErrTokenNotFound
+	}
+
+	return nil
+}
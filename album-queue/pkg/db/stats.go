@@ -0,0 +1,312 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	statsTopGenres  = 10
+	statsTopArtists = 10
+	// StatsWeeks is how many weeks of additions the library stats cover
+	StatsWeeks = 12
+
+	week = int64(7 * 24 * 60 * 60)
+	// weekOffset moves the unix epoch, a Thursday, to the Monday before it
+	weekOffset = int64(4 * 24 * 60 * 60)
+)
+
+// LibraryStats extends Stats with the breakdown of the library and the
+// outcome of finished download requests
+type LibraryStats struct {
+	Stats
+
+	// Genres are simplified through genre_mappings, an empty name stands for
+	// files without a genre
+	Genres     []StatsCount `json:"genres"`
+	TopArtists []StatsCount `json:"top_artists"`
+	// Formats are the lowercase file extensions
+	Formats []StatsCount `json:"formats"`
+
+	// LibraryBytes only adds up the files the indexer recorded a size for
+	LibraryBytes     int64 `json:"library_bytes"`
+	FilesWithoutSize int64 `json:"files_without_size"`
+
+	// WeeklyAdditions has one entry per week, Monday 00:00 UTC, oldest first
+	WeeklyAdditions []WeeklyCount `json:"weekly_additions"`
+
+	Downloads DownloadStats `json:"downloads"`
+
+	GeneratedAt int64 `json:"generated_at"`
+}
+
+type StatsCount struct {
+	Name  string `json:"name" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+type WeeklyCount struct {
+	WeekStart int64 `json:"week_start" bson:"_id"`
+	Count     int64 `json:"count" bson:"count"`
+}
+
+// DownloadStats covers the download requests that are no longer active
+type DownloadStats struct {
+	Finished int64 `json:"finished"`
+	// Succeeded are the finished requests that didn't end in an error
+	Succeeded   int64   `json:"succeeded"`
+	SuccessRate float64 `json:"success_rate"`
+	// ExpectedTracks and FoundTracks add up the track counts of finished requests
+	ExpectedTracks int64 `json:"expected_tracks"`
+	FoundTracks    int64 `json:"found_tracks"`
+	// AvgSeconds is the average time from queueing a request to its last update
+	AvgSeconds float64 `json:"avg_seconds"`
+}
+
+// weekStart returns the start of the week of the unix time, Monday 00:00 UTC
+func weekStart(t int64) int64 {
+	return t - (t-weekOffset)%week
+}
+
+// GetLibraryStats computes the library stats, see StatsCache for a cached
+// version
+func (d *db) GetLibraryStats(ctx context.Context) (*LibraryStats, error) {
+	counters, err := d.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	stats := &LibraryStats{Stats: *counters, GeneratedAt: now}
+
+	if err := d.musicFilesStats(ctx, stats, weekStart(now)-(StatsWeeks-1)*week); err != nil {
+		return nil, err
+	}
+
+	if err := d.downloadStats(ctx, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// musicFilesStats runs the breakdowns of music-files in one $facet
+func (d *db) musicFilesStats(ctx context.Context, stats *LibraryStats, since int64) error {
+	top := func(limit int) bson.A {
+		return bson.A{
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": limit},
+		}
+	}
+
+	genres := bson.A{
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$toLower": bson.M{"$trim": bson.M{"input": bson.M{"$ifNull": bson.A{"$genre", ""}}}}},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$lookup": bson.M{
+			"from": "genre_mappings",
+			"let":  bson.M{"genre": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{bson.M{"$toLower": "$specific_genre"}, "$$genre"}}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "mapping",
+		}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$toLower": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$mapping.simplified_genre", 0}}, "$_id"}}},
+			"count": bson.M{"$sum": "$count"},
+		}},
+	}
+
+	artists := bson.A{
+		bson.M{"$match": bson.M{"artist": bson.M{"$nin": bson.A{"", nil}}}},
+		bson.M{"$group": bson.M{"_id": "$artist", "count": bson.M{"$sum": 1}}},
+	}
+
+	formats := bson.A{
+		bson.M{"$group": bson.M{
+			"_id": bson.M{"$toLower": bson.M{"$let": bson.M{
+				"vars": bson.M{"ext": bson.M{"$regexFind": bson.M{"input": "$path", "regex": `\.([^./]+)$`}}},
+				"in":   bson.M{"$arrayElemAt": bson.A{"$$ext.captures", 0}},
+			}}},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	size := bson.A{
+		bson.M{"$group": bson.M{
+			"_id":     nil,
+			"bytes":   bson.M{"$sum": "$size"},
+			"without": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$size", 0}}, 0, 1}}},
+		}},
+	}
+
+	weeks := bson.A{
+		bson.M{"$match": bson.M{"created_at": bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$subtract": bson.A{"$created_at", bson.M{"$mod": bson.A{bson.M{"$subtract": bson.A{"$created_at", weekOffset}}, week}}}},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := d.musicFilesCollection.Aggregate(ctx, bson.A{
+		bson.M{"$facet": bson.M{
+			"genres":  append(genres, top(statsTopGenres)...),
+			"artists": append(artists, top(statsTopArtists)...),
+			"formats": formats,
+			"size":    size,
+			"weeks":   weeks,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate music files stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Genres  []StatsCount `bson:"genres"`
+		Artists []StatsCount `bson:"artists"`
+		Formats []StatsCount `bson:"formats"`
+		Size    []struct {
+			Bytes   int64 `bson:"bytes"`
+			Without int64 `bson:"without"`
+		} `bson:"size"`
+		Weeks []WeeklyCount `bson:"weeks"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return fmt.Errorf("failed to decode music files stats: %w", err)
+	}
+	if len(facets) == 0 {
+		return nil
+	}
+
+	result := facets[0]
+	stats.Genres = result.Genres
+	stats.TopArtists = result.Artists
+	stats.Formats = result.Formats
+	if len(result.Size) > 0 {
+		stats.LibraryBytes = result.Size[0].Bytes
+		stats.FilesWithoutSize = result.Size[0].Without
+	}
+	stats.WeeklyAdditions = fillWeeks(result.Weeks, since)
+
+	return nil
+}
+
+// fillWeeks returns StatsWeeks weeks from since with the counts of the weeks
+// that had additions
+func fillWeeks(counts []WeeklyCount, since int64) []WeeklyCount {
+	byWeek := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		byWeek[c.WeekStart] = c.Count
+	}
+
+	weeks := make([]WeeklyCount, StatsWeeks)
+	for i := range weeks {
+		start := since + int64(i)*week
+		weeks[i] = WeeklyCount{WeekStart: start, Count: byWeek[start]}
+	}
+	return weeks
+}
+
+// requestComplete matches requests spotdl-wapper completed, every track that
+// wasn't skipped was found
+var requestComplete = bson.M{"$and": bson.A{
+	bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$track_metadata", bson.A{}}}}, 0}},
+	bson.M{"$allElementsTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$track_metadata", bson.A{}}},
+		"as":    "track",
+		"in":    bson.M{"$or": bson.A{"$$track.found", "$$track.skipped"}},
+	}}}},
+}}
+
+// downloadStats counts the requests the downloader finished, cancelled ones
+// are left out. Requests never updated after being queued have no duration.
+func (d *db) downloadStats(ctx context.Context, stats *LibraryStats) error {
+	cursor, err := d.downloadQueueRequestCollection.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"active": false, "cancelled": bson.M{"$ne": true}}},
+		bson.M{"$group": bson.M{
+			"_id":       nil,
+			"finished":  bson.M{"$sum": 1},
+			"succeeded": bson.M{"$sum": bson.M{"$cond": bson.A{requestComplete, 1, 0}}},
+			"expected":  bson.M{"$sum": "$expected_track_count"},
+			"found":     bson.M{"$sum": "$found_track_count"},
+			"seconds": bson.M{"$avg": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$updated_at", "$created_at"}},
+				bson.M{"$subtract": bson.A{"$updated_at", "$created_at"}},
+				nil,
+			}}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate download stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Finished  int64   `bson:"finished"`
+		Succeeded int64   `bson:"succeeded"`
+		Expected  int64   `bson:"expected"`
+		Found     int64   `bson:"found"`
+		Seconds   float64 `bson:"seconds"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return fmt.Errorf("failed to decode download stats: %w", err)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	r := results[0]
+	stats.Downloads = DownloadStats{
+		Finished:       r.Finished,
+		Succeeded:      r.Succeeded,
+		SuccessRate:    float64(r.Succeeded) / float64(r.Finished),
+		ExpectedTracks: r.Expected,
+		FoundTracks:    r.Found,
+		AvgSeconds:     r.Seconds,
+	}
+	return nil
+}
+
+// StatsCache keeps the library stats for a while, the aggregations go over
+// the whole library
+type StatsCache struct {
+	source func(ctx context.Context) (*LibraryStats, error)
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	stats     *LibraryStats
+	fetchedAt time.Time
+}
+
+func NewStatsCache(database Database, ttl time.Duration) *StatsCache {
+	return &StatsCache{source: database.GetLibraryStats, ttl: ttl, now: time.Now}
+}
+
+// Get returns the cached stats, computing them again once they are older
+// than the TTL. Concurrent callers wait for the same computation.
+func (c *StatsCache) Get(ctx context.Context) (*LibraryStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats != nil && c.now().Sub(c.fetchedAt) < c.ttl {
+		return c.stats, nil
+	}
+
+	stats, err := c.source(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.stats = stats
+	c.fetchedAt = c.now()
+	return stats, nil
+}
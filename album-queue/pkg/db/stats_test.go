@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	// Wednesday 2026-10-14 15:04 UTC
+	wednesday := time.Date(2026, 10, 14, 15, 4, 0, 0, time.UTC).Unix()
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC).Unix()
+
+	if got := weekStart(wednesday); got != monday {
+		t.Fatalf("expected %d, got %d", monday, got)
+	}
+	if got := weekStart(monday); got != monday {
+		t.Fatalf("expected Monday to start its own week, got %d", got)
+	}
+}
+
+func TestFillWeeks(t *testing.T) {
+	since := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC).Unix()
+
+	weeks := fillWeeks([]WeeklyCount{{WeekStart: since + week, Count: 5}}, since)
+
+	if len(weeks) != StatsWeeks || weeks[0].WeekStart != since || weeks[0].Count != 0 || weeks[1].Count != 5 {
+		t.Fatalf("unexpected weeks: %+v", weeks)
+	}
+	if last := weeks[StatsWeeks-1].WeekStart; last != since+(StatsWeeks-1)*week {
+		t.Fatalf("unexpected last week %d", last)
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	calls := 0
+	var sourceErr error
+	c := &StatsCache{
+		source: func(context.Context) (*LibraryStats, error) {
+			calls++
+			if sourceErr != nil {
+				return nil, sourceErr
+			}
+			return &LibraryStats{GeneratedAt: int64(calls)}, nil
+		},
+		ttl: time.Minute,
+		now: func() time.Time { return now },
+	}
+
+	first, _ := c.Get(context.Background())
+	now = now.Add(30 * time.Second)
+	cached, _ := c.Get(context.Background())
+	if calls != 1 || cached != first {
+		t.Fatalf("expected the stats to be cached, got %d calls", calls)
+	}
+
+	now = now.Add(time.Minute)
+	sourceErr = errors.New("mongo down")
+	if _, err := c.Get(context.Background()); err == nil {
+		t.Fatal("expected the error of the expired refresh")
+	}
+
+	sourceErr = nil
+	if fresh, _ := c.Get(context.Background()); calls != 3 || fresh.GeneratedAt != 3 {
+		t.Fatalf("expected the stats to be computed again, got %+v after %d calls", fresh, calls)
+	}
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSubscriptionChanges returns the latest syncs of the user's subscriptions
+// that found changes, newest first. The full track lists are left out.
+func (d *db) GetSubscriptionChanges(ctx context.Context, creatorID int64, limit int64) ([]models.SubscriptionSnapshot, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"tracks": 0})
+
+	cursor, err := d.snapshotsCollection.Find(ctx, bson.M{"creator_id": creatorID, "changed": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscription changes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []models.SubscriptionSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode subscription changes: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetUserLanguage returns the language the user picked with /lang, or an
+// empty string when they never did
+func (d *db) GetUserLanguage(ctx context.Context, userID int64) (string, error) {
+	var user models.User
+
+	err := d.usersCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user.Language, nil
+}
+
+func (d *db) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	now := time.Now().Unix()
+
+	_, err := d.usersCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$set":         bson.M{"language": language, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user language: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser returns the registered user, or mongo.ErrNoDocuments when the user was never added
+func (d *db) GetUser(ctx context.Context, userID int64) (models.User, error) {
+	var user models.User
+
+	err := d.usersCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.User{}, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers returns the users that have a role, ordered by when they were added
+func (d *db) ListUsers(ctx context.Context) ([]models.User, error) {
+	cursor, err := d.usersCollection.Find(
+		ctx,
+		bson.M{"role": bson.M{"$exists": true, "$ne": ""}},
+		options.Find().SetSort(bson.M{"created_at": 1}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetUserRole adds the user with the given role, or changes the role of an existing user
+func (d *db) SetUserRole(ctx context.Context, userID int64, role models.Role, addedBy int64) error {
+	now := time.Now().Unix()
+
+	_, err := d.usersCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$set":         bson.M{"role": role, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now, "added_by": addedBy},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user role: %w", err)
+	}
+
+	return nil
+}
+
+func (d *db) SetUserName(ctx context.Context, userID int64, name string) error {
+	_, err := d.usersCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"name": name, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user name: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes the user, returning mongo.ErrNoDocuments when there was none
+func (d *db) DeleteUser(ctx context.Context, userID int64) error {
+	result, err := d.usersCollection.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
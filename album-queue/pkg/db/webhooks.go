@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewWebhookSubscription adds an endpoint receiving the events, signed with a
+// newly generated secret
+func (d *db) NewWebhookSubscription(ctx context.Context, url string, events []webhook.Event, createdBy int64) (webhook.Subscription, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return webhook.Subscription{}, err
+	}
+
+	now := time.Now().Unix()
+	subscription := webhook.Subscription{
+		ID:        uuid.NewV4().String(),
+		URL:       url,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := d.webhookSubscriptionsCollection.InsertOne(ctx, subscription); err != nil {
+		return webhook.Subscription{}, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// GetWebhookSubscriptions returns the active subscriptions to the event
+func (d *db) GetWebhookSubscriptions(ctx context.Context, event webhook.Event) ([]webhook.Subscription, error) {
+	return d.findWebhookSubscriptions(ctx, bson.M{"active": true, "events": event})
+}
+
+func (d *db) ListWebhookSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	return d.findWebhookSubscriptions(ctx, bson.M{"active": true})
+}
+
+func (d *db) findWebhookSubscriptions(ctx context.Context, filter bson.M) ([]webhook.Subscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := d.webhookSubscriptionsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []webhook.Subscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription stops sending events to the subscription, its
+// delivery log is kept
+func (d *db) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	result, err := d.webhookSubscriptionsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "active": true},
+		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (d *db) SaveWebhookDelivery(ctx context.Context, delivery webhook.Delivery) error {
+	if _, err := d.webhookDeliveriesCollection.InsertOne(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries to the subscription
+func (d *db) GetWebhookDeliveries(ctx context.Context, subscriptionID string, limit int64) ([]webhook.Delivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+
+	cursor, err := d.webhookDeliveriesCollection.Find(ctx, bson.M{"subscription_id": subscriptionID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []webhook.Delivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const maxSuggestedAliasesShown = 20
+
+// commandArgs returns everything after the command itself, e.g. "a = b" for "/alias a = b".
+func commandArgs(text string) string {
+	_, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	return strings.TrimSpace(args)
+}
+
+func parseAliasArgs(args string) (alias, canonical string, ok bool) {
+	alias, canonical, found := strings.Cut(args, "=")
+	if !found {
+		return "", "", false
+	}
+
+	alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+	if alias == "" || canonical == "" || strings.EqualFold(alias, canonical) {
+		return "", "", false
+	}
+
+	return alias, canonical, true
+}
+
+func (h *handler) HandleAlias(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	alias, canonical, ok := parseAliasArgs(commandArgs(m.Text))
+	if !ok {
+		h.reply(m, i18n.T(lang, "alias_usage"))
+		return
+	}
+
+	if err := h.db.NewArtistAlias(context.Background(), alias, canonical, m.Sender.ID); err != nil {
+		h.log.Error("Failed to add artist alias", zap.Error(err))
+		h.reply(m, i18n.T(lang, "alias_add_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "alias_added", alias, canonical))
+}
+
+func (h *handler) HandleAliases(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+	ctx := context.Background()
+
+	aliases, err := h.db.GetArtistAliases(ctx)
+	if err != nil {
+		h.log.Error("Failed to get artist aliases", zap.Error(err))
+		h.reply(m, i18n.T(lang, "aliases_fetch_failed"))
+		return
+	}
+
+	// suggestions are collected in the background, see SuggestArtistAliases
+	suggestions, err := h.db.GetSuggestedArtistAliases(ctx)
+	if err != nil {
+		h.log.Error("Failed to get suggested artist aliases", zap.Error(err))
+		h.reply(m, i18n.T(lang, "aliases_suggest_failed"))
+		return
+	}
+
+	if len(aliases) == 0 && len(suggestions) == 0 {
+		h.reply(m, i18n.T(lang, "aliases_empty"))
+		return
+	}
+
+	var response strings.Builder
+	if len(aliases) > 0 {
+		keys := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			keys = append(keys, alias)
+		}
+		sort.Strings(keys)
+
+		response.WriteString(i18n.T(lang, "aliases_header"))
+		for _, alias := range keys {
+			response.WriteString(fmt.Sprintf("• %s → %s\n", alias, aliases[alias]))
+		}
+	}
+
+	if len(suggestions) > 0 {
+		if response.Len() > 0 {
+			response.WriteString("\n")
+		}
+		response.WriteString(i18n.T(lang, "aliases_suggestions_header"))
+		for i, suggestion := range suggestions {
+			if i == maxSuggestedAliasesShown {
+				response.WriteString(i18n.T(lang, "aliases_more", len(suggestions)-maxSuggestedAliasesShown))
+				break
+			}
+			response.WriteString(fmt.Sprintf("• %s → %s ?\n", suggestion.Alias, suggestion.Canonical))
+		}
+	}
+
+	h.reply(m, response.String())
+}
+
+func (h *handler) HandleAliasAccept(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	alias := commandArgs(m.Text)
+	if alias == "" {
+		h.reply(m, i18n.T(lang, "aliasaccept_usage"))
+		return
+	}
+
+	if err := h.db.ConfirmArtistAlias(context.Background(), alias, m.Sender.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			h.reply(m, i18n.T(lang, "alias_suggestion_not_found"))
+			return
+		}
+		h.log.Error("Failed to confirm artist alias", zap.Error(err))
+		h.reply(m, i18n.T(lang, "alias_confirm_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "alias_confirmed", alias))
+}
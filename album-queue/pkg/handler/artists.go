@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/utils"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// HandleFollow follows an artist so dynamic-playlists queues their new
+// albums and singles
+func (h *handler) HandleFollow(m *telebot.Message) {
+	h.log.Info("Received follow request", zap.Any("message", m.Text))
+
+	lang := h.lang(m.Sender)
+
+	msg := strings.Fields(m.Text)
+	if len(msg) != 2 {
+		h.reply(m, i18n.T(lang, "follow_usage"))
+		return
+	}
+
+	artistURL := msg[1]
+	if !utils.IsSpotifyArtistURL(artistURL) {
+		h.reply(m, i18n.T(lang, "follow_not_artist"))
+		return
+	}
+
+	ctx := context.Background()
+
+	exists, err := h.db.CheckArtistSubscriptionExists(ctx, artistURL, m.Sender.ID)
+	if err != nil {
+		h.log.Error("Failed to check artist subscription existence", zap.Error(err))
+		h.reply(m, i18n.T(lang, "follow_failed"))
+		return
+	}
+	if exists {
+		h.reply(m, i18n.T(lang, "already_following"))
+		return
+	}
+
+	artistName, err := h.spotifyService.GetObjectName(ctx, artistURL)
+	if err != nil {
+		h.log.Error("Failed to get artist name", zap.Error(err))
+		h.reply(m, i18n.T(lang, "artist_name_failed"))
+		return
+	}
+
+	if err := h.db.NewArtistSubscription(ctx, artistURL, m.Sender.ID, artistName); err != nil {
+		h.log.Error("Failed to follow artist", zap.Error(err))
+		h.reply(m, i18n.T(lang, "follow_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "followed", artistName))
+}
+
+func (h *handler) HandleUnfollow(m *telebot.Message) {
+	h.log.Info("Received unfollow request", zap.Any("message", m.Text))
+
+	lang := h.lang(m.Sender)
+
+	msg := strings.Fields(m.Text)
+	if len(msg) != 2 {
+		h.reply(m, i18n.T(lang, "unfollow_usage"))
+		return
+	}
+
+	artistURL := msg[1]
+	if !utils.IsSpotifyArtistURL(artistURL) {
+		h.reply(m, i18n.T(lang, "follow_not_artist"))
+		return
+	}
+
+	if err := h.db.DeleteArtistSubscription(context.Background(), artistURL, m.Sender.ID); err != nil {
+		h.log.Error("Failed to unfollow artist", zap.Error(err))
+		h.reply(m, i18n.T(lang, "unfollow_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "unfollowed"))
+}
+
+// HandleFollowing lists the artists the user follows
+func (h *handler) HandleFollowing(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	artists, err := h.db.GetArtistSubscriptions(context.Background(), m.Sender.ID)
+	if err != nil {
+		h.log.Error("Failed to get artist subscriptions", zap.Error(err))
+		h.reply(m, i18n.T(lang, "following_fetch_failed"))
+		return
+	}
+
+	if len(artists) == 0 {
+		h.reply(m, i18n.T(lang, "following_empty"))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "following_header"))
+	for i, artist := range artists {
+		lastCheckedText := i18n.T(lang, "following_not_checked")
+		if artist.LastChecked > 0 {
+			lastCheckedText = time.Unix(artist.LastChecked, 0).Format("02.01.2006 15:04")
+		}
+
+		response.WriteString(fmt.Sprintf("%d. 🎤 %s\n", i+1, artist.Name))
+		response.WriteString(fmt.Sprintf("   📎 %s\n", artist.SpotifyURL))
+		response.WriteString(i18n.T(lang, "following_last_checked", lastCheckedText))
+		response.WriteString("\n")
+	}
+
+	h.reply(m, response.String())
+}
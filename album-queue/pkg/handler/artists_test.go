@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+)
+
+const testArtistURL = "https://open.spotify.com/artist/ar1"
+
+func TestHandleFollowAndUnfollow(t *testing.T) {
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{testArtistURL: "Daft Punk"}}
+
+	h.HandleFollow(testMessage("/follow " + testArtistURL))
+	h.HandleFollow(testMessage("/follow " + testArtistURL))
+
+	if len(database.artists) != 1 || database.artists[0].Name != "Daft Punk" {
+		t.Fatalf("expected one followed artist, got %+v", database.artists)
+	}
+	if len(sinks.replies) != 2 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "followed", "Daft Punk") || sinks.replies[1] != i18n.T(i18n.Ukrainian, "already_following") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+
+	h.HandleFollowing(testMessage("/following"))
+	if !strings.Contains(sinks.replies[2], "Daft Punk") || !strings.Contains(sinks.replies[2], testArtistURL) {
+		t.Fatalf("expected the artist to be listed, got %q", sinks.replies[2])
+	}
+
+	h.HandleUnfollow(testMessage("/unfollow " + testArtistURL))
+	h.HandleFollowing(testMessage("/following"))
+
+	if sinks.replies[3] != i18n.T(i18n.Ukrainian, "unfollowed") || sinks.replies[4] != i18n.T(i18n.Ukrainian, "following_empty") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies[3:])
+	}
+}
+
+func TestHandleFollowRejectsOtherLinks(t *testing.T) {
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleFollow(testMessage("/follow https://open.spotify.com/album/a1"))
+
+	if len(database.artists) != 0 {
+		t.Fatalf("expected no followed artist, got %+v", database.artists)
+	}
+	if len(sinks.replies) != 1 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "follow_not_artist") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
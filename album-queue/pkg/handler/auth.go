@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/utils"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// commandRoles is the minimum role needed for each endpoint. Endpoints that
+// are not listed need an admin.
+var commandRoles = map[string]models.Role{
+	"/start":                          models.RoleReadOnly,
+	"/queue":                          models.RoleReadOnly,
+	"\f" + queueMissingCallbackUnique: models.RoleReadOnly,
+	"/failed":                         models.RoleReadOnly,
+	"\f" + failedPageCallbackUnique:   models.RoleReadOnly,
+	"/history":                        models.RoleReadOnly,
+	"\f" + historyPageCallbackUnique:  models.RoleReadOnly,
+	"/subscriptions":                  models.RoleReadOnly,
+	"/changes":                        models.RoleReadOnly,
+	"/following":                      models.RoleReadOnly,
+	"/radar":                          models.RoleReadOnly,
+	"/aliases":                        models.RoleReadOnly,
+	"/lang":                           models.RoleReadOnly,
+	"/quota":                          models.RoleReadOnly,
+	"/stats":                          models.RoleReadOnly,
+	"/getplaylist":                    models.RoleReadOnly,
+	"/search":                         models.RoleReadOnly,
+	"\f" + searchPageCallbackUnique:   models.RoleReadOnly,
+	"\f" + searchAlbumCallbackUnique:  models.RoleReadOnly,
+
+	telebot.OnText:     models.RoleMember,
+	telebot.OnDocument: models.RoleMember,
+	telebot.OnAudio:    models.RoleMember,
+
+	"\f" + previewConfirmCallbackUnique: models.RoleMember,
+	"\f" + previewCancelCallbackUnique:  models.RoleMember,
+	"/redownload":                       models.RoleMember,
+	"/deactivate":                       models.RoleMember,
+	"/p":                                models.RoleMember,
+	"/pnp":                              models.RoleMember,
+	"/subscribe":                        models.RoleMember,
+	"/unsubscribe":                      models.RoleMember,
+	"/follow":                           models.RoleMember,
+	"/unfollow":                         models.RoleMember,
+	"/alias":                            models.RoleMember,
+	"/aliasaccept":                      models.RoleMember,
+	"/link":                             models.RoleMember,
+	"/unlink":                           models.RoleMember,
+	"/send":                             models.RoleMember,
+
+	"\f" + searchQueueCallbackUnique: models.RoleMember,
+	"\f" + sendFileCallbackUnique:    models.RoleMember,
+	"\f" + queueCancelCallbackUnique: models.RoleMember,
+	"\f" + queueRetryCallbackUnique:  models.RoleMember,
+	"\f" + queueBumpCallbackUnique:   models.RoleMember,
+
+	"\f" + subscriptionIntervalCallbackUnique: models.RoleMember,
+	"\f" + subscriptionPullCallbackUnique:     models.RoleMember,
+	"\f" + subscriptionPauseCallbackUnique:    models.RoleMember,
+	"\f" + subscriptionSyncCallbackUnique:     models.RoleMember,
+	"\f" + subscriptionNotifyCallbackUnique:   models.RoleMember,
+
+	"\f" + radarAcceptCallbackUnique:  models.RoleMember,
+	"\f" + radarDismissCallbackUnique: models.RoleMember,
+
+	"/adduser":    models.RoleAdmin,
+	"/role":       models.RoleAdmin,
+	"/users":      models.RoleAdmin,
+	"/removeuser": models.RoleAdmin,
+}
+
+func requiredRole(endpoint string) models.Role {
+	if role, ok := commandRoles[endpoint]; ok {
+		return role
+	}
+	return models.RoleAdmin
+}
+
+// userRole returns the role of the user. Users from BOT_WHITELIST are always
+// admins so the bot can't lock everyone out. The second value is false for
+// users that were never added.
+func (h *handler) userRole(ctx context.Context, userID int64) (models.Role, bool) {
+	if utils.InWhiteList(userID, h.whiteList) {
+		return models.RoleAdmin, true
+	}
+
+	_, role, ok := h.lookupUser(ctx, userID)
+	return role, ok
+}
+
+// lookupUser is userRole that also returns the stored user, which is empty
+// for whitelisted users that were never added
+func (h *handler) lookupUser(ctx context.Context, userID int64) (models.User, models.Role, bool) {
+	user, err := h.db.GetUser(ctx, userID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		h.log.Error("Failed to get user", zap.Error(err), zap.Int64("user_id", userID))
+	}
+
+	if utils.InWhiteList(userID, h.whiteList) {
+		return user, models.RoleAdmin, true
+	}
+	if err != nil || !user.Role.Valid() {
+		return models.User{}, "", false
+	}
+	return user, user.Role, true
+}
+
+// authorize checks that the sender may use the endpoint. Unknown users are
+// ignored, users without the required role get the deny message.
+func (h *handler) authorize(endpoint string, sender *telebot.User, deny func(text string)) bool {
+	if sender == nil {
+		return false
+	}
+
+	ctx := context.Background()
+	user, role, ok := h.lookupUser(ctx, sender.ID)
+	if !ok {
+		h.log.Info("Unauthorized user", zap.Int64("user_id", sender.ID), zap.String("endpoint", endpoint))
+		if endpoint == "/start" {
+			deny(i18n.T(h.lang(sender), "not_registered", sender.ID))
+		}
+		return false
+	}
+
+	if !role.Allows(requiredRole(endpoint)) {
+		h.log.Info("Permission denied",
+			zap.Int64("user_id", sender.ID),
+			zap.String("role", string(role)),
+			zap.String("endpoint", endpoint))
+		deny(i18n.T(h.lang(sender), "permission_denied"))
+		return false
+	}
+
+	h.syncUserName(ctx, sender, user)
+	return true
+}
+
+// syncUserName keeps the stored name of the user in line with Telegram so
+// /users shows who is who
+func (h *handler) syncUserName(ctx context.Context, sender *telebot.User, user models.User) {
+	name := displayName(sender)
+	if name == "" || user.ID == 0 || user.Name == name {
+		return
+	}
+
+	if err := h.db.SetUserName(ctx, sender.ID, name); err != nil {
+		h.log.Error("Failed to save user name", zap.Error(err), zap.Int64("user_id", sender.ID))
+	}
+}
+
+// Authorize wraps a message handler with the permission check of the endpoint
+func (h *handler) Authorize(endpoint string, next func(m *telebot.Message)) func(m *telebot.Message) {
+	return func(m *telebot.Message) {
+		if !h.authorize(endpoint, m.Sender, h.replyTo(m)) {
+			return
+		}
+		next(m)
+	}
+}
+
+// AuthorizeCallback wraps a callback handler with the permission check of the endpoint
+func (h *handler) AuthorizeCallback(endpoint telebot.CallbackEndpoint, next func(c *telebot.Callback)) func(c *telebot.Callback) {
+	return func(c *telebot.Callback) {
+		deny := func(text string) {
+			_ = h.respondCallbackFn(c, text, true)
+		}
+		if !h.authorize(endpoint.CallbackUnique(), c.Sender, deny) {
+			return
+		}
+		next(c)
+	}
+}
+
+func displayName(user *telebot.User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/ingest"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/utils"
+	"github.com/supperdoggy/spot-models/playlistfile"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	// maxBulkURLs caps one message or file, the rest is reported as skipped
+	maxBulkURLs     = 50
+	maxBulkFileSize = 256 << 10
+
+	// maxMessageLength stays under the 4096 characters Telegram allows in a
+	// message, which counts emoji twice
+	maxMessageLength = 4000
+)
+
+// bulkFileExtensions are the link lists, a CSV is a link list when it isn't a
+// playlist with artist and title columns
+var bulkFileExtensions = []string{".txt"}
+
+// HandleDocument queues the links of an uploaded .txt or .csv file and
+// imports playlist files, audio sent as a file goes into the library
+func (h *handler) HandleDocument(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	doc := m.Document
+	if doc != nil && ingest.Supported(doc.FileName) {
+		h.ingestUpload(m, &doc.File, doc.FileName, ingest.TrackFromUpload("", "", doc.FileName))
+		return
+	}
+	if doc != nil && playlistfile.Supported(doc.FileName) {
+		h.importPlaylistFile(m, doc)
+		return
+	}
+	if doc == nil || !slices.Contains(bulkFileExtensions, strings.ToLower(filepath.Ext(doc.FileName))) {
+		h.reply(m, i18n.T(lang, "bulk_file_unsupported"))
+		return
+	}
+	if doc.FileSize > maxBulkFileSize {
+		h.reply(m, i18n.T(lang, "bulk_file_too_big", maxBulkFileSize>>10))
+		return
+	}
+
+	h.log.Info("Received URL file", zap.String("file_name", doc.FileName), zap.Int("size", doc.FileSize))
+
+	data, ok := h.readDocument(m, lang, doc, maxBulkFileSize)
+	if !ok {
+		return
+	}
+
+	h.queueFileURLs(m, lang, data)
+}
+
+// readDocument downloads up to limit bytes of the document, replying when
+// that fails
+func (h *handler) readDocument(m *telebot.Message, lang i18n.Lang, doc *telebot.Document, limit int64) ([]byte, bool) {
+	reader, err := h.getFileFn(&doc.File)
+	if err != nil {
+		h.log.Error("Failed to download file", zap.Error(err), zap.String("file_name", doc.FileName))
+		h.reply(m, i18n.T(lang, "bulk_file_failed"))
+		return nil, false
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, limit))
+	if err != nil {
+		h.log.Error("Failed to read file", zap.Error(err), zap.String("file_name", doc.FileName))
+		h.reply(m, i18n.T(lang, "bulk_file_failed"))
+		return nil, false
+	}
+
+	return data, true
+}
+
+// queueFileURLs queues the links found in an uploaded file
+func (h *handler) queueFileURLs(m *telebot.Message, lang i18n.Lang, data []byte) {
+	urls := utils.ExtractURLs(string(data))
+	if len(urls) == 0 {
+		h.reply(m, i18n.T(lang, "bulk_file_empty"))
+		return
+	}
+
+	h.queueURLs(m, lang, urls)
+}
+
+// queueURLs queues every link on its own and replies with the outcome of each
+// of them, split over as many messages as it takes
+func (h *handler) queueURLs(m *telebot.Message, lang i18n.Lang, urls []string) {
+	ctx := context.Background()
+
+	skipped := 0
+	if len(urls) > maxBulkURLs {
+		skipped = len(urls) - maxBulkURLs
+		urls = urls[:maxBulkURLs]
+	}
+
+	lines := make([]string, 0, len(urls))
+	queued := 0
+	for i, url := range urls {
+		var outcome string
+		if h.queueURL(ctx, m.Sender.ID, lang, url, func(text string) { outcome = text }, nil) {
+			queued++
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s\n   %s\n", i+1, url, outcome))
+	}
+
+	h.log.Info("Queued links in bulk",
+		zap.Int64("user_id", m.Sender.ID),
+		zap.Int("links", len(urls)),
+		zap.Int("queued", queued),
+		zap.Int("skipped", skipped))
+
+	if skipped > 0 {
+		lines = append(lines, i18n.T(lang, "bulk_skipped", skipped, maxBulkURLs))
+	}
+	for _, message := range splitMessages(i18n.T(lang, "bulk_header", queued, len(urls)), lines) {
+		h.reply(m, message)
+	}
+}
+
+// splitMessages joins the header and the lines into messages of up to
+// maxMessageLength characters, a line is never split
+func splitMessages(header string, lines []string) []string {
+	var messages []string
+	current := header
+	for _, line := range lines {
+		if current != "" && utf8.RuneCountInString(current)+utf8.RuneCountInString(line) > maxMessageLength {
+			messages = append(messages, current)
+			current = ""
+		}
+		current += line
+	}
+	if current != "" {
+		messages = append(messages, current)
+	}
+	return messages
+}
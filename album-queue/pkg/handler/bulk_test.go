@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/supperdoggy/spot-models/webhook"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func TestHandleTextQueuesEveryURL(t *testing.T) {
+	db := &fakeDatabase{activeByURL: map[string]bool{"https://open.spotify.com/album/queued": true}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{
+		"https://open.spotify.com/album/one": "One",
+		"https://open.spotify.com/album/two": "Two",
+	}}
+
+	h.HandleText(testMessage(strings.Join([]string{
+		"https://open.spotify.com/album/one",
+		"https://open.spotify.com/album/queued",
+		"https://example.com/nope",
+		"https://open.spotify.com/album/two",
+		"https://open.spotify.com/album/one",
+	}, "\n")))
+
+	if len(db.newRequests) != 2 || db.newRequests[0].name != "One" || db.newRequests[1].name != "Two" {
+		t.Fatalf("unexpected requests: %+v", db.newRequests)
+	}
+	if len(sinks.webhooks) != 2 {
+		t.Fatalf("expected one request.created per queued link, got %+v", sinks.webhooks)
+	}
+	if data := sinks.webhooks[1].Data.(webhook.RequestData); sinks.webhooks[1].Event != webhook.EventRequestCreated || data.ID != "request-2" || data.URL != "https://open.spotify.com/album/two" || data.Name != "Two" || data.CreatorID != 1 {
+		t.Fatalf("unexpected webhook: %+v", sinks.webhooks[1])
+	}
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected a single summary reply, got %#v", sinks.replies)
+	}
+
+	summary := sinks.replies[0]
+	for _, want := range []string{"2 з 4", "1. https://open.spotify.com/album/one", "вже є в черзі", "не посилання", "4. https://open.spotify.com/album/two"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("expected %q in summary: %q", want, summary)
+		}
+	}
+}
+
+func TestHandleTextSplitsLongSummaries(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{}}
+
+	urls := make([]string, maxBulkURLs+2)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://open.spotify.com/album/%s%d", strings.Repeat("a", 60), i)
+	}
+	h.HandleText(testMessage(strings.Join(urls, "\n")))
+
+	if len(db.newRequests) != maxBulkURLs {
+		t.Fatalf("expected %d requests, got %d", maxBulkURLs, len(db.newRequests))
+	}
+	if len(sinks.replies) < 2 {
+		t.Fatalf("expected the summary to be split, got %d replies", len(sinks.replies))
+	}
+
+	summary := strings.Join(sinks.replies, "")
+	for i, reply := range sinks.replies {
+		if utf8.RuneCountInString(reply) > maxMessageLength {
+			t.Fatalf("reply %d is %d characters long", i, utf8.RuneCountInString(reply))
+		}
+	}
+	for _, want := range []string{"50 з 50", "50. " + urls[49], "Ще 2 посилань пропущено"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("expected %q in summary: %q", want, summary)
+		}
+	}
+}
+
+func TestHandleDocumentQueuesURLsFromFile(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{}}
+	h.getFileFn = func(*telebot.File) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("name,url\na,https://open.spotify.com/album/a\nb,https://open.spotify.com/album/b\n")), nil
+	}
+
+	m := testMessage("")
+	m.Document = &telebot.Document{FileName: "albums.CSV", File: telebot.File{FileSize: 100}}
+	h.HandleDocument(m)
+
+	if len(db.newRequests) != 2 {
+		t.Fatalf("expected two requests, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "2 з 2") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleDocumentRejectsOtherFiles(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.getFileFn = func(*telebot.File) (io.ReadCloser, error) {
+		t.Fatal("file should not be downloaded")
+		return nil, nil
+	}
+
+	m := testMessage("")
+	m.Document = &telebot.Document{FileName: "cover.jpg"}
+	h.HandleDocument(m)
+
+	m.Document = &telebot.Document{FileName: "huge.txt", File: telebot.File{FileSize: maxBulkFileSize + 1}}
+	h.HandleDocument(m)
+
+	if len(sinks.replies) != 2 || len(db.newRequests) != 0 {
+		t.Fatalf("unexpected replies %#v requests %+v", sinks.replies, db.newRequests)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	// changesShown and changesTracksShown keep /changes within one message
+	changesShown       = 5
+	changesTracksShown = 3
+)
+
+// HandleChanges shows what the latest syncs of the user's subscriptions found
+// added, removed or newly in the library
+func (h *handler) HandleChanges(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	snapshots, err := h.db.GetSubscriptionChanges(context.Background(), m.Sender.ID, changesShown)
+	if err != nil {
+		h.log.Error("Failed to get subscription changes", zap.Error(err), zap.Int64("user_id", m.Sender.ID))
+		h.reply(m, i18n.T(lang, "changes_fetch_failed"))
+		return
+	}
+
+	if len(snapshots) == 0 {
+		h.reply(m, i18n.T(lang, "changes_empty"))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "changes_header"))
+	for _, snapshot := range snapshots {
+		response.WriteString(fmt.Sprintf("🎵 %s · %s\n", snapshot.Name, time.Unix(snapshot.CreatedAt, 0).Format("02.01.2006 15:04")))
+		writeChangedTracks(&response, lang, "changes_added", snapshot.Added)
+		writeChangedTracks(&response, lang, "changes_removed", snapshot.Removed)
+		writeChangedTracks(&response, lang, "changes_available", snapshot.Available)
+		response.WriteString("\n")
+	}
+
+	h.reply(m, response.String())
+}
+
+// writeChangedTracks writes one section of a change report, nothing when no
+// track changed that way
+func writeChangedTracks(b *strings.Builder, lang i18n.Lang, key string, tracks []models.SnapshotTrack) {
+	if len(tracks) == 0 {
+		return
+	}
+
+	b.WriteString(i18n.T(lang, key, len(tracks)))
+	for i, track := range tracks {
+		if i == changesTracksShown {
+			b.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", len(tracks)-changesTracksShown)))
+			break
+		}
+		b.WriteString(fmt.Sprintf("      • %s - %s\n", track.Artist, track.Title))
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	sendFileCallbackUnique = "send_file"
+	sendResultsShown       = 5
+
+	// maxUploadSize is the largest file the Bot API accepts from bots
+	maxUploadSize = 50 << 20
+)
+
+var sendFileCallbackEndpoint = &telebot.InlineButton{Unique: sendFileCallbackUnique}
+
+func SendFileCallbackEndpoint() telebot.CallbackEndpoint {
+	return sendFileCallbackEndpoint
+}
+
+// HandleGetPlaylist sends the M3U of one of the user's subscribed or imported
+// playlists or of a dynamic playlist, or lists the playlists when no name is
+// given
+func (h *handler) HandleGetPlaylist(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+	name := commandArgs(m.Text)
+
+	playlists, err := h.db.GetPlaylistFiles(context.Background(), m.Sender.ID)
+	if err != nil {
+		h.log.Error("Failed to get playlist files", zap.Error(err), zap.Int64("user_id", m.Sender.ID))
+		h.reply(m, i18n.T(lang, "getplaylist_failed"))
+		return
+	}
+
+	if name == "" {
+		if len(playlists) == 0 {
+			h.reply(m, i18n.T(lang, "getplaylist_empty"))
+			return
+		}
+
+		var response strings.Builder
+		response.WriteString(i18n.T(lang, "getplaylist_usage"))
+		for _, playlist := range playlists {
+			icon := "✨"
+			switch {
+			case playlist.Subscribed:
+				icon = "🎵"
+			case playlist.Imported:
+				icon = "📥"
+			}
+			response.WriteString(fmt.Sprintf("%s %s\n", icon, playlist.Name))
+		}
+		h.reply(m, response.String())
+		return
+	}
+
+	for _, playlist := range playlists {
+		if !strings.EqualFold(playlist.Name, name) {
+			continue
+		}
+
+		if playlist.Path == "" {
+			h.reply(m, i18n.T(lang, "getplaylist_not_generated", playlist.Name))
+			return
+		}
+		if reason := h.checkUpload(lang, playlist.Path); reason != "" {
+			h.reply(m, reason)
+			return
+		}
+
+		doc := &telebot.Document{File: telebot.FromDisk(playlist.Path), FileName: filepath.Base(playlist.Path), MIME: "audio/x-mpegurl"}
+		if err := h.sendDocumentFn(m, doc); err != nil {
+			h.log.Error("Failed to send playlist", zap.Error(err), zap.String("path", playlist.Path))
+			h.reply(m, i18n.T(lang, "upload_failed"))
+		}
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "getplaylist_not_found", name))
+}
+
+// HandleSend uploads the library file matching the search, or lists the
+// matches with a button each when there are several
+func (h *handler) HandleSend(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	query := commandArgs(m.Text)
+	if query == "" {
+		h.reply(m, i18n.T(lang, "send_usage"))
+		return
+	}
+
+	files, err := h.db.SearchMusicFiles(context.Background(), query, sendResultsShown+1)
+	if err != nil {
+		h.log.Error("Failed to search music files", zap.Error(err), zap.String("query", query))
+		h.reply(m, i18n.T(lang, "search_failed"))
+		return
+	}
+
+	switch len(files) {
+	case 0:
+		h.reply(m, i18n.T(lang, "search_empty", query))
+		return
+	case 1:
+		if reason := h.sendAudio(m, lang, files[0]); reason != "" {
+			h.reply(m, reason)
+		}
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "send_pick", query))
+
+	markup := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, sendResultsShown)
+	for i, file := range files {
+		if i == sendResultsShown {
+			response.WriteString(i18n.T(lang, "send_more"))
+			break
+		}
+		response.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, file.Artist, file.Title))
+		rows = append(rows, markup.Row(markup.Data(i18n.T(lang, "send_button", i+1), sendFileCallbackUnique, file.ID)))
+	}
+	markup.Inline(rows...)
+
+	if err := h.sendFailedPageFn(m, response.String(), markup); err != nil {
+		h.log.Error("Failed to send search results", zap.Error(err))
+	}
+}
+
+// HandleSendFile uploads the file of a /send result button
+func (h *handler) HandleSendFile(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	file, ok := h.searchResultFile(context.Background(), c, lang)
+	if !ok {
+		return
+	}
+
+	if c.Message == nil {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "upload_failed"), true)
+		return
+	}
+
+	if reason := h.sendAudio(c.Message, lang, file); reason != "" {
+		_ = h.respondCallbackFn(c, reason, true)
+		return
+	}
+
+	_ = h.respondCallbackFn(c, "", false)
+}
+
+// sendAudio uploads a library file as an audio message in reply to m and
+// returns the reason for the user when it couldn't
+func (h *handler) sendAudio(m *telebot.Message, lang i18n.Lang, file models.MusicFile) string {
+	if reason := h.checkUpload(lang, file.Path); reason != "" {
+		return reason
+	}
+
+	audio := &telebot.Audio{
+		File:      telebot.FromDisk(file.Path),
+		FileName:  filepath.Base(file.Path),
+		Title:     file.Title,
+		Performer: file.Artist,
+	}
+	if err := h.sendAudioFn(m, audio); err != nil {
+		h.log.Error("Failed to send audio", zap.Error(err), zap.String("path", file.Path))
+		return i18n.T(lang, "upload_failed")
+	}
+
+	return ""
+}
+
+// checkUpload returns why the file at path can't be sent to Telegram, empty
+// when it can
+func (h *handler) checkUpload(lang i18n.Lang, path string) string {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return i18n.T(lang, "upload_missing")
+	}
+	if err != nil {
+		h.log.Error("Failed to stat file", zap.Error(err), zap.String("path", path))
+		return i18n.T(lang, "upload_failed")
+	}
+
+	if info.Size() > maxUploadSize {
+		return i18n.T(lang, "upload_too_big", formatBytes(info.Size()), formatBytes(maxUploadSize))
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func writeTestFile(t *testing.T, name string, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHandleGetPlaylistSendsM3U(t *testing.T) {
+	path := writeTestFile(t, "Mix.m3u", 64)
+	database := &fakeDatabase{playlistFiles: []db.PlaylistFile{
+		{Name: "Mix", Path: path, Subscribed: true},
+		{Name: "Fresh Rock"},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleGetPlaylist(testMessage("/getplaylist mix"))
+
+	if len(sinks.documents) != 1 || sinks.documents[0].FileName != "Mix.m3u" || sinks.documents[0].FileLocal != path {
+		t.Fatalf("expected the M3U to be sent, got %+v", sinks.documents)
+	}
+
+	h.HandleGetPlaylist(testMessage("/getplaylist Fresh Rock"))
+	h.HandleGetPlaylist(testMessage("/getplaylist Nope"))
+
+	if len(sinks.replies) != 2 ||
+		sinks.replies[0] != i18n.T(i18n.Ukrainian, "getplaylist_not_generated", "Fresh Rock") ||
+		sinks.replies[1] != i18n.T(i18n.Ukrainian, "getplaylist_not_found", "Nope") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleGetPlaylistListsPlaylists(t *testing.T) {
+	database := &fakeDatabase{playlistFiles: []db.PlaylistFile{
+		{Name: "Mix", Subscribed: true},
+		{Name: "Fresh Rock"},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleGetPlaylist(testMessage("/getplaylist"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "🎵 Mix") || !strings.Contains(sinks.replies[0], "✨ Fresh Rock") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleSendUploadsSingleMatch(t *testing.T) {
+	path := writeTestFile(t, "song.mp3", 1024)
+	database := &fakeDatabase{musicFiles: []models.MusicFile{{ID: "f1", Artist: "Artist", Title: "Song", Path: path}}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleSend(testMessage("/send song"))
+
+	if len(sinks.audios) != 1 {
+		t.Fatalf("expected one audio, got %+v (replies %#v)", sinks.audios, sinks.replies)
+	}
+	if audio := sinks.audios[0]; audio.Title != "Song" || audio.Performer != "Artist" || audio.FileName != "song.mp3" {
+		t.Fatalf("unexpected audio: %+v", audio)
+	}
+}
+
+func TestHandleSendRejectsLargeAndMissingFiles(t *testing.T) {
+	big := writeTestFile(t, "long.flac", maxUploadSize+1)
+	database := &fakeDatabase{musicFiles: []models.MusicFile{
+		{ID: "f1", Artist: "Artist", Title: "Long", Path: big},
+		{ID: "f2", Artist: "Artist", Title: "Gone", Path: filepath.Join(t.TempDir(), "gone.mp3")},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleSend(testMessage("/send long"))
+	h.HandleSend(testMessage("/send gone"))
+
+	if len(sinks.audios) != 0 {
+		t.Fatalf("expected nothing to be uploaded, got %+v", sinks.audios)
+	}
+	if len(sinks.replies) != 2 ||
+		sinks.replies[0] != i18n.T(i18n.Ukrainian, "upload_too_big", "50.0 MiB", "50.0 MiB") ||
+		sinks.replies[1] != i18n.T(i18n.Ukrainian, "upload_missing") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleSendPicksFromSeveralMatches(t *testing.T) {
+	path := writeTestFile(t, "two.mp3", 1024)
+	database := &fakeDatabase{musicFiles: []models.MusicFile{
+		{ID: "f1", Artist: "Artist", Title: "One", Path: writeTestFile(t, "one.mp3", 1024)},
+		{ID: "f2", Artist: "Artist", Title: "Two", Path: path},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleSend(testMessage("/send artist"))
+
+	if len(sinks.sentPages) != 1 || len(sinks.audios) != 0 {
+		t.Fatalf("expected a list to pick from, got %#v", sinks.sentPages)
+	}
+	rows := sinks.sentPages[0].markup.InlineKeyboard
+	if len(rows) != 2 || rows[1][0].Unique != sendFileCallbackUnique || rows[1][0].Data != "f2" {
+		t.Fatalf("unexpected buttons: %+v", rows)
+	}
+
+	h.HandleSendFile(&telebot.Callback{Sender: &telebot.User{ID: 1}, Message: &telebot.Message{}, Data: "f2"})
+
+	if len(sinks.audios) != 1 || sinks.audios[0].FileLocal != path {
+		t.Fatalf("expected the picked file to be sent, got %+v", sinks.audios)
+	}
+	if len(sinks.callbackAcks) != 1 || sinks.callbackAcks[0].showAlert {
+		t.Fatalf("unexpected callback acks: %#v", sinks.callbackAcks)
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/ingest"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/utils"
 	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 	"gopkg.in/tucnak/telebot.v2"
@@ -20,9 +26,18 @@ import (
 type Handler interface {
 	Start(m *telebot.Message)
 	HandleText(m *telebot.Message)
+	HandleDocument(m *telebot.Message)
+	HandlePreviewConfirm(c *telebot.Callback)
+	HandlePreviewCancel(c *telebot.Callback)
 	HandleQueue(m *telebot.Message)
+	HandleQueueCancel(c *telebot.Callback)
+	HandleQueueRetry(c *telebot.Callback)
+	HandleQueueBump(c *telebot.Callback)
+	HandleQueueMissing(c *telebot.Callback)
 	HandleFailed(m *telebot.Message)
 	HandleFailedPage(c *telebot.Callback)
+	HandleHistory(m *telebot.Message)
+	HandleHistoryPage(c *telebot.Callback)
 	HandleRedownload(m *telebot.Message)
 	HandleDeactivate(m *telebot.Message)
 	HandlePlaylist(m *telebot.Message)
@@ -30,6 +45,44 @@ type Handler interface {
 	HandleSubscribe(m *telebot.Message)
 	HandleUnsubscribe(m *telebot.Message)
 	HandleListSubscriptions(m *telebot.Message)
+	HandleSubscriptionInterval(c *telebot.Callback)
+	HandleSubscriptionPull(c *telebot.Callback)
+	HandleSubscriptionPause(c *telebot.Callback)
+	HandleSubscriptionSync(c *telebot.Callback)
+	HandleSubscriptionNotify(c *telebot.Callback)
+	HandleChanges(m *telebot.Message)
+	HandleFollow(m *telebot.Message)
+	HandleUnfollow(m *telebot.Message)
+	HandleFollowing(m *telebot.Message)
+	HandleRadar(m *telebot.Message)
+	HandleRadarAccept(c *telebot.Callback)
+	HandleRadarDismiss(c *telebot.Callback)
+	HandleAlias(m *telebot.Message)
+	HandleAliases(m *telebot.Message)
+	HandleAliasAccept(m *telebot.Message)
+	HandleLink(m *telebot.Message)
+	HandleUnlink(m *telebot.Message)
+	HandleLang(m *telebot.Message)
+	HandleQuota(m *telebot.Message)
+	HandleStats(m *telebot.Message)
+	HandleGetPlaylist(m *telebot.Message)
+	HandleSend(m *telebot.Message)
+	HandleSendFile(c *telebot.Callback)
+	HandleAudio(m *telebot.Message)
+	HandleSearch(m *telebot.Message)
+	HandleSearchPage(c *telebot.Callback)
+	HandleSearchAlbum(c *telebot.Callback)
+	HandleSearchQueue(c *telebot.Callback)
+	HandleAddUser(m *telebot.Message)
+	HandleRole(m *telebot.Message)
+	HandleUsers(m *telebot.Message)
+	HandleRemoveUser(m *telebot.Message)
+	HandleWebhookAdd(m *telebot.Message)
+	HandleWebhooks(m *telebot.Message)
+	HandleWebhookDelete(m *telebot.Message)
+	Authorize(endpoint string, next func(m *telebot.Message)) func(m *telebot.Message)
+	AuthorizeCallback(endpoint telebot.CallbackEndpoint, next func(c *telebot.Callback)) func(c *telebot.Callback)
+	SpotifyCallback(w http.ResponseWriter, r *http.Request)
 }
 
 const (
@@ -46,32 +99,53 @@ func FailedPageCallbackEndpoint() telebot.CallbackEndpoint {
 type handler struct {
 	db                db.Database
 	spotifyService    spotify.SpotifyService
+	spotifyAuth       *spotify.Authenticator
+	userSpotify       *spotify.UserServices
+	sourceProvider    source.Provider
+	limits            quota.Limits
+	ingester          *ingest.Ingester
 	whiteList         []int64
 	bot               *telebot.Bot
 	log               *zap.Logger
-	doneWebhook       string
 	replyFunc         func(m *telebot.Message, text string) error
-	sendWebhookFn     func() error
+	dispatchFn        func(event webhook.Event, data any)
+	libraryStatsFn    func(ctx context.Context) (*db.LibraryStats, error)
 	sendFailedPageFn  func(m *telebot.Message, text string, markup *telebot.ReplyMarkup) error
 	editFailedPageFn  func(m *telebot.Message, text string, markup *telebot.ReplyMarkup) error
 	respondCallbackFn func(c *telebot.Callback, text string, showAlert bool) error
+	sendMessageFn     func(userID int64, text string) error
+	sendDocumentFn    func(m *telebot.Message, doc *telebot.Document) error
+	sendAudioFn       func(m *telebot.Message, audio *telebot.Audio) error
+	getFileFn         func(file *telebot.File) (io.ReadCloser, error)
+	previews          previewStore
 }
 
-func NewHandler(db db.Database, spotifyService spotify.SpotifyService, log *zap.Logger, bot *telebot.Bot, doneWebhook string, whiteList []int64) Handler {
+// NewHandler creates the bot handler. spotifyAuth may be nil, in which case
+// account linking is disabled and all Spotify calls use the app credentials.
+// sourceProvider resolves links of the other sources and may be nil to accept Spotify only.
+// limits apply to every user except admins.
+// webhooks sends request.created to the webhook subscriptions.
+// ingester takes audio uploads into the library and may be nil to refuse them.
+func NewHandler(db db.Database, spotifyService spotify.SpotifyService, spotifyAuth *spotify.Authenticator, sourceProvider source.Provider, limits quota.Limits, log *zap.Logger, bot *telebot.Bot, webhooks webhook.Dispatcher, stats *db.StatsCache, ingester *ingest.Ingester, whiteList []int64) Handler {
 	return &handler{
 		db:             db,
 		spotifyService: spotifyService,
+		spotifyAuth:    spotifyAuth,
+		userSpotify:    spotify.NewUserServices(spotifyAuth, db, spotifyService, log),
+		sourceProvider: sourceProvider,
+		limits:         limits,
+		ingester:       ingester,
 		log:            log,
 		bot:            bot,
 		whiteList:      whiteList,
-		doneWebhook:    doneWebhook,
 		replyFunc: func(m *telebot.Message, text string) error {
 			_, err := bot.Reply(m, text)
 			return err
 		},
-		sendWebhookFn: func() error {
-			return utils.SendDoneWebhook(doneWebhook)
+		dispatchFn: func(event webhook.Event, data any) {
+			webhooks.Dispatch(context.Background(), event, data)
 		},
+		libraryStatsFn: stats.Get,
 		sendFailedPageFn: func(m *telebot.Message, text string, markup *telebot.ReplyMarkup) error {
 			if markup != nil {
 				_, err := bot.Reply(m, text, markup)
@@ -95,6 +169,21 @@ func NewHandler(db db.Database, spotifyService spotify.SpotifyService, log *zap.
 			}
 			return bot.Respond(c, resp)
 		},
+		sendMessageFn: func(userID int64, text string) error {
+			_, err := bot.Send(&telebot.User{ID: userID}, text)
+			return err
+		},
+		sendDocumentFn: func(m *telebot.Message, doc *telebot.Document) error {
+			_, err := bot.Reply(m, doc)
+			return err
+		},
+		sendAudioFn: func(m *telebot.Message, audio *telebot.Audio) error {
+			_, err := bot.Reply(m, audio)
+			return err
+		},
+		getFileFn: func(file *telebot.File) (io.ReadCloser, error) {
+			return bot.GetFile(file)
+		},
 	}
 }
 
@@ -104,238 +193,157 @@ func (h *handler) reply(m *telebot.Message, text string) {
 	}
 }
 
-func (h *handler) sendWebhook() {
-	if err := h.sendWebhookFn(); err != nil {
-		h.log.Error("Failed to send webhook", zap.Error(err))
-	}
+// replyTo returns a function replying to m, for helpers shared by messages and callbacks
+func (h *handler) replyTo(m *telebot.Message) func(text string) {
+	return func(text string) { h.reply(m, text) }
 }
 
-func (h *handler) Start(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
+// requestCreated tells the webhook subscriptions something was queued
+func (h *handler) requestCreated(data webhook.RequestData) {
+	h.dispatchFn(webhook.EventRequestCreated, data)
+}
 
-	h.reply(m, "Привіііііііііт, я бот який кочає музіку на сєрвер, скинь мені урлу на спотік і я додам в чергу на скачування ❤️")
+func (h *handler) Start(m *telebot.Message) {
+	h.reply(m, i18n.T(h.lang(m.Sender), "start"))
 }
 
 func (h *handler) HandleText(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
-
 	h.log.Info("Received message", zap.Any("message", m.Text))
 
-	// Check if the message is a valid Spotify URL
-	if !utils.IsValidSpotifyURL(m.Text) {
-		h.reply(m, "о ніііііі, це не посилання на спотіфай.... 💔😭")
-		return
+	lang := h.lang(m.Sender)
+
+	urls := utils.ExtractURLs(m.Text)
+	switch len(urls) {
+	case 0:
+		h.reply(m, i18n.T(lang, "unsupported_url"))
+	case 1:
+		ctx := context.Background()
+		preview := func(name string, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) bool {
+			return h.previewCoverage(ctx, m, lang, urls[0], name, objectType, tracks)
+		}
+		h.queueURL(ctx, m.Sender.ID, lang, urls[0], h.replyTo(m), preview)
+	default:
+		h.queueURLs(m, lang, urls)
 	}
+}
 
-	ctx := context.Background()
+// queueURL resolves a link and adds it to the download queue, telling the
+// user how it went through reply. Spotify albums and playlists are handed to
+// preview first when it's set, and aren't queued when it takes them over.
+// It reports whether a request was added.
+func (h *handler) queueURL(ctx context.Context, userID int64, lang i18n.Lang, rawURL string, reply func(text string), preview func(name string, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) bool) bool {
+	// Check if the message is a link to a supported source
+	src, ok := source.Detect(rawURL)
+	resolver := h.resolverFor(src)
+	if !ok || resolver == nil {
+		reply(i18n.T(lang, "unsupported_url"))
+		return false
+	}
 
-	// Get object type, name and track count from Spotify API
-	objectType, err := h.spotifyService.GetObjectType(ctx, m.Text)
+	active, err := h.db.HasActiveRequestByURL(ctx, rawURL)
 	if err != nil {
-		h.log.Error("Failed to get object type from Spotify", zap.Error(err))
-		h.reply(m, "не получилось отримати тип об'єкта зі спотіфай, спробуй ще раз...")
-		return
+		h.log.Error("Failed to check active request", zap.Error(err))
+		reply(i18n.T(lang, "queue_add_failed"))
+		return false
+	}
+	if active {
+		reply(i18n.T(lang, "already_queued"))
+		return false
 	}
 
-	name, err := h.spotifyService.GetObjectName(ctx, m.Text)
+	// Get object type, name and track count from the source
+	objectType, err := resolver.GetObjectType(ctx, rawURL)
 	if err != nil {
-		h.log.Error("Failed to get object name from Spotify", zap.Error(err))
-		h.reply(m, "не получилось отримати інформацію зі спотіфай, спробуй ще раз...")
-		return
+		h.log.Error("Failed to get object type", zap.Error(err), zap.String("source", string(src)))
+		reply(i18n.T(lang, "object_type_failed", src))
+		return false
 	}
 
-	trackCount, trackMetadata, err := h.spotifyService.GetTrackCount(ctx, m.Text)
+	name, err := resolver.GetObjectName(ctx, rawURL)
 	if err != nil {
-		h.log.Error("Failed to get track count from Spotify", zap.Error(err))
-		h.reply(m, "не получилось отримати кількість треків, але додав в чергу...")
+		h.log.Error("Failed to get object name", zap.Error(err), zap.String("source", string(src)))
+		reply(i18n.T(lang, "object_info_failed", src))
+		return false
+	}
+
+	trackCount, trackMetadata, countErr := resolver.GetTrackCount(ctx, rawURL)
+	if countErr != nil {
+		h.log.Error("Failed to get track count", zap.Error(countErr), zap.String("source", string(src)))
+		if !h.allowUnknownTrackCount(ctx, userID, lang, reply) {
+			return false
+		}
 		// Continue with empty track data
 		trackCount = 0
 		trackMetadata = nil
 	}
 
-	// Add the download request to the database
-	err = h.db.NewDownloadRequest(ctx, m.Text, name, m.Sender.ID, objectType, trackCount, trackMetadata)
-	if err != nil {
-		h.log.Error("Failed to add download request to database", zap.Error(err))
-		h.reply(m, "не получилось додати в чергу, скажи максиму шо шось не так...")
-		return
-	}
-
-	h.sendWebhook()
-
-	h.reply(m, fmt.Sprintf("Ураураура успішно додали %s в чергу! (Треків: %d) ❤️", name, trackCount))
-}
-
-func (h *handler) HandleQueue(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
+	previewable := objectType == spotify.SpotifyObjectTypeAlbum || objectType == spotify.SpotifyObjectTypePlaylist
+	if preview != nil && src.IsSpotify() && previewable && len(trackMetadata) > 0 && preview(name, objectType, trackMetadata) {
+		return false
 	}
 
-	ctx := context.Background()
-	requests, err := h.db.GetActiveRequests(ctx)
-	if err != nil {
-		h.log.Error("Failed to get active download requests", zap.Error(err))
-		h.reply(m, "не получилося дістати чергу... 💔😭")
-		return
+	if !h.checkQuota(ctx, userID, lang, reply, func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, trackCount)
+	}) {
+		return false
 	}
 
-	playlists, err := h.db.GetActivePlaylists(ctx)
+	// Add the download request to the database
+	id, err := h.db.NewDownloadRequest(ctx, rawURL, name, userID, objectType, src, trackCount, trackMetadata)
 	if err != nil {
-		h.log.Error("Failed to get active playlist requests", zap.Error(err))
-		// Continue anyway, just log the error
-	}
-
-	if len(requests) == 0 && len(playlists) == 0 {
-		h.reply(m, "немає активних запитів на скачування...")
-		return
+		h.log.Error("Failed to add download request to database", zap.Error(err))
+		reply(i18n.T(lang, "queue_add_failed"))
+		return false
 	}
 
-	// Update found track count for each request and save to database
-	for i := range requests {
-		if requests[i].ExpectedTrackCount > 0 && len(requests[i].TrackMetadata) > 0 {
-			foundCount, err := h.compareTracks(ctx, requests[i])
-			if err != nil {
-				h.log.Error("Failed to compare tracks", zap.Error(err), zap.String("request_id", requests[i].ID))
-				continue
-			}
-			// Always update the count when /queue is called
-			requests[i].FoundTrackCount = foundCount
-			requests[i].UpdatedAt = time.Now().Unix()
-
-			// Mark as completed if all tracks are found
-			if foundCount == requests[i].ExpectedTrackCount && requests[i].Active {
-				requests[i].Active = false
-				h.log.Info("Marking request as completed",
-					zap.String("request_id", requests[i].ID),
-					zap.String("name", requests[i].Name),
-					zap.Int("found", foundCount),
-					zap.Int("expected", requests[i].ExpectedTrackCount))
-			}
-
-			if err := h.db.UpdateDownloadRequest(ctx, requests[i]); err != nil {
-				h.log.Error("Failed to update found track count", zap.Error(err))
-			}
-		}
+	h.requestCreated(webhook.RequestData{ID: id, URL: rawURL, Name: name, CreatorID: userID, ObjectType: string(objectType), Tracks: trackCount})
+	if countErr != nil {
+		reply(i18n.T(lang, "track_count_failed", name))
+	} else {
+		reply(i18n.T(lang, "queued", name, i18n.N(lang, "tracks", trackCount)))
 	}
+	return true
+}
 
-	response := "Активні запити на скачування:\n\n"
-	for _, r := range requests {
-		response += fmt.Sprintf("📀 %s\n", r.Name)
-		if r.ExpectedTrackCount > 0 {
-			downloaded := r.FoundTrackCount
-			percentage := float64(downloaded) / float64(r.ExpectedTrackCount) * 100
-
-			response += fmt.Sprintf("   ✅ Завантажено: %d/%d (%.0f%%)\n", downloaded, r.ExpectedTrackCount, percentage)
-
-			// Calculate missing tracks (not found and not skipped)
-			missingTracks := []spotify.TrackMetadata{}
-			skippedCount := 0
-			if len(r.TrackMetadata) > 0 {
-				for _, track := range r.TrackMetadata {
-					if !track.Found && !track.Skipped {
-						missingTracks = append(missingTracks, track)
-					} else if track.Skipped {
-						skippedCount++
-					}
-				}
-			}
-
-			remaining := len(missingTracks)
-
-			if remaining > 0 {
-				response += fmt.Sprintf("   ⏳ Залишилось: %d треків\n", remaining)
-
-				// Show first 5 tracks that need to be downloaded
-				displayCount := remaining
-				if displayCount > 5 {
-					displayCount = 5
-				}
-
-				response += "   📋 Треки для завантаження:\n"
-				for i := 0; i < displayCount; i++ {
-					track := missingTracks[i]
-					response += fmt.Sprintf("      • %s - %s\n", track.Artist, track.Title)
-				}
-				if remaining > 5 {
-					response += fmt.Sprintf("      ... та ще %d треків\n", remaining-5)
-				}
-			} else {
-				response += "   🎉 Всі треки завантажені!\n"
-			}
-
-			if skippedCount > 0 {
-				response += fmt.Sprintf("   ⚠️ Пропущено: %d треків\n", skippedCount)
-			}
-		} else {
-			response += "   ⏳ Очікування завантаження...\n"
-		}
-
-		if r.Errored {
-			response += fmt.Sprintf("   ⚠️ Помилки: %d\n", r.RetryCount)
-		}
-		response += "\n"
+// allowUnknownTrackCount decides on a link whose track count couldn't be
+// resolved: users with a quota are refused since their track limit can't be
+// checked, everyone else queues it without a count
+func (h *handler) allowUnknownTrackCount(ctx context.Context, userID int64, lang i18n.Lang, reply func(text string)) bool {
+	if h.hasQuota(ctx, userID) {
+		reply(i18n.T(lang, "track_count_unknown"))
+		return false
 	}
+	return true
+}
 
-	// Add playlist requests
-	if len(playlists) > 0 {
-		if len(requests) > 0 {
-			response += "---\n\n"
-		}
-		response += "Активні запити на плейлисти:\n\n"
-		for _, p := range playlists {
-			// Try to get playlist name
-			playlistName, err := h.spotifyService.GetObjectName(ctx, p.SpotifyURL)
-			if err != nil {
-				h.log.Error("Failed to get playlist name", zap.Error(err))
-				playlistName = p.SpotifyURL
-			}
-
-			response += fmt.Sprintf("🎵 %s\n", playlistName)
-			response += fmt.Sprintf("   📎 URL: %s\n", p.SpotifyURL)
-			if p.NoPull {
-				response += "   ⚠️ NoPull: true (не завантажувати відсутні треки)\n"
-			} else {
-				response += "   ✅ Завантажувати відсутні треки\n"
-			}
-			if p.Errored {
-				response += fmt.Sprintf("   ⚠️ Помилки: %d\n", p.RetryCount)
-			}
-			response += "\n"
-		}
+// resolverFor returns the resolver for links of the given source, or nil when
+// the source is not enabled
+func (h *handler) resolverFor(src source.Source) source.Resolver {
+	if src.IsSpotify() {
+		return h.spotifyService
 	}
-
-	h.reply(m, response)
+	return h.sourceProvider
 }
 
 func (h *handler) HandleFailed(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
+	lang := h.lang(m.Sender)
 
 	failedTracks, err := h.db.GetUnresolvedFailedTracks(context.Background())
 	if err != nil {
 		h.log.Error("Failed to get unresolved failed tracks", zap.Error(err))
-		h.reply(m, "не получилось дістати список фейлів на скачування...")
+		h.reply(m, i18n.T(lang, "failed_fetch_failed"))
 		return
 	}
 
 	if len(failedTracks) == 0 {
-		h.reply(m, "нема невирішених фейлів на скачування.")
+		h.reply(m, i18n.T(lang, "failed_empty"))
 		return
 	}
 
-	text, markup, err := renderFailedTracksPage(failedTracks, 0)
+	text, markup, err := renderFailedTracksPage(lang, failedTracks, 0)
 	if err != nil {
 		h.log.Error("Failed to render failed tracks page", zap.Error(err))
-		h.reply(m, "не получилось зібрати сторінку фейлів...")
+		h.reply(m, i18n.T(lang, "failed_render_failed"))
 		return
 	}
 
@@ -345,29 +353,24 @@ func (h *handler) HandleFailed(m *telebot.Message) {
 }
 
 func (h *handler) HandleFailedPage(c *telebot.Callback) {
-	if c == nil || c.Sender == nil || !utils.InWhiteList(c.Sender.ID, h.whiteList) {
-		if c != nil {
-			_ = h.respondCallbackFn(c, "доступ заборонено", true)
-		}
-		return
-	}
+	lang := h.lang(c.Sender)
 
 	page, err := strconv.Atoi(strings.TrimSpace(c.Data))
 	if err != nil {
-		_ = h.respondCallbackFn(c, "невірний номер сторінки", true)
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
 		return
 	}
 
 	failedTracks, err := h.db.GetUnresolvedFailedTracks(context.Background())
 	if err != nil {
 		h.log.Error("Failed to get unresolved failed tracks", zap.Error(err))
-		_ = h.respondCallbackFn(c, "не получилось оновити список фейлів", true)
+		_ = h.respondCallbackFn(c, i18n.T(lang, "failed_refresh_failed"), true)
 		return
 	}
 
 	if len(failedTracks) == 0 {
 		if c.Message != nil {
-			if err := h.editFailedPageFn(c.Message, "нема невирішених фейлів на скачування.", nil); err != nil {
+			if err := h.editFailedPageFn(c.Message, i18n.T(lang, "failed_empty"), nil); err != nil {
 				h.log.Error("Failed to edit failed page to empty state", zap.Error(err))
 			}
 		}
@@ -375,9 +378,9 @@ func (h *handler) HandleFailedPage(c *telebot.Callback) {
 		return
 	}
 
-	text, markup, err := renderFailedTracksPage(failedTracks, page)
+	text, markup, err := renderFailedTracksPage(lang, failedTracks, page)
 	if err != nil {
-		_ = h.respondCallbackFn(c, "невірний номер сторінки", true)
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
 		return
 	}
 
@@ -391,20 +394,18 @@ func (h *handler) HandleFailedPage(c *telebot.Callback) {
 }
 
 func (h *handler) HandleRedownload(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
+	lang := h.lang(m.Sender)
 
 	msg := strings.Fields(m.Text)
 	if len(msg) != 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /redownload <spotify_track_url>.")
+		h.reply(m, i18n.T(lang, "redownload_usage"))
 		return
 	}
 
 	trackURL := strings.TrimSpace(msg[1])
-	if !isValidSpotifyTrackURL(trackURL) {
-		h.reply(m, "це має бути лінк на трек Spotify у форматі https://open.spotify.com/track/...")
+	src, ok := source.Detect(trackURL)
+	if !ok || (src.IsSpotify() && !isValidSpotifyTrackURL(trackURL)) {
+		h.reply(m, i18n.T(lang, "redownload_not_track"))
 		return
 	}
 
@@ -413,23 +414,23 @@ func (h *handler) HandleRedownload(m *telebot.Message) {
 	hasActive, err := h.db.HasActiveRequestByURL(ctx, trackURL)
 	if err != nil {
 		h.log.Error("Failed to check existing active request", zap.Error(err), zap.String("track_url", trackURL))
-		h.reply(m, "не получилось перевірити активні запити, спробуй ще раз...")
+		h.reply(m, i18n.T(lang, "active_check_failed"))
 		return
 	}
 	if hasActive {
-		h.reply(m, "цей трек вже є в активній черзі, редовнлоад пропущено.")
+		h.reply(m, i18n.T(lang, "redownload_already_active"))
 		return
 	}
 
 	failedTrack, err := h.db.GetUnresolvedFailedTrackByURL(ctx, trackURL)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			h.reply(m, "цей трек не знайдений серед невирішених фейлів.")
+			h.reply(m, i18n.T(lang, "redownload_not_failed"))
 			return
 		}
 
 		h.log.Error("Failed to get unresolved failed track by url", zap.Error(err), zap.String("track_url", trackURL))
-		h.reply(m, "не получилось перевірити фейл цього треку, спробуй ще раз...")
+		h.reply(m, i18n.T(lang, "redownload_check_failed"))
 		return
 	}
 
@@ -438,6 +439,11 @@ func (h *handler) HandleRedownload(m *telebot.Message) {
 		requestName = failedTrack.SpotifyURL
 	}
 
+	// Requests queued before sources existed are Spotify requests
+	if failedTrack.Source != "" {
+		src = failedTrack.Source
+	}
+
 	trackMetadata := []spotify.TrackMetadata{
 		{
 			SpotifyURL:     failedTrack.SpotifyURL,
@@ -449,31 +455,32 @@ func (h *handler) HandleRedownload(m *telebot.Message) {
 		},
 	}
 
-	err = h.db.NewDownloadRequest(
+	id, err := h.db.NewDownloadRequest(
 		ctx,
 		failedTrack.SpotifyURL,
 		requestName,
 		m.Sender.ID,
 		spotify.SpotifyObjectTypeTrack,
+		src,
 		1,
 		trackMetadata,
 	)
 	if err != nil {
 		h.log.Error("Failed to create redownload request", zap.Error(err), zap.String("track_url", trackURL))
-		h.reply(m, "не получилось створити редовнлоад запит, спробуй ще раз...")
+		h.reply(m, i18n.T(lang, "redownload_create_failed"))
 		return
 	}
 
-	h.sendWebhook()
+	h.requestCreated(webhook.RequestData{ID: id, URL: failedTrack.SpotifyURL, Name: requestName, CreatorID: m.Sender.ID, ObjectType: string(spotify.SpotifyObjectTypeTrack), Tracks: 1})
 
-	h.reply(m, fmt.Sprintf("редовнлоад створено ✅\n%s\n%s - %s", failedTrack.SpotifyURL, failedTrack.Artist, failedTrack.Title))
+	h.reply(m, i18n.T(lang, "redownload_created", failedTrack.SpotifyURL, failedTrack.Artist, failedTrack.Title))
 }
 
 func isValidSpotifyTrackURL(url string) bool {
 	return utils.IsValidSpotifyURL(url) && strings.HasPrefix(url, "https://open.spotify.com/track/")
 }
 
-func renderFailedTracksPage(failedTracks []db.FailedTrack, page int) (string, *telebot.ReplyMarkup, error) {
+func renderFailedTracksPage(lang i18n.Lang, failedTracks []db.FailedTrack, page int) (string, *telebot.ReplyMarkup, error) {
 	if len(failedTracks) == 0 {
 		return "", nil, errors.New("failed tracks list is empty")
 	}
@@ -490,25 +497,25 @@ func renderFailedTracksPage(failedTracks []db.FailedTrack, page int) (string, *t
 	}
 
 	var response strings.Builder
-	response.WriteString(fmt.Sprintf("Невирішені фейли на скачування: %d\n", len(failedTracks)))
-	response.WriteString(fmt.Sprintf("Сторінка %d/%d\n\n", page+1, totalPages))
+	response.WriteString(i18n.T(lang, "failed_header", len(failedTracks)))
+	response.WriteString(i18n.T(lang, "page", page+1, totalPages))
 
 	for i := start; i < end; i++ {
 		track := failedTracks[i]
 
 		artist := strings.TrimSpace(track.Artist)
 		if artist == "" {
-			artist = "невідомий артист"
+			artist = i18n.T(lang, "unknown_artist")
 		}
 
 		title := strings.TrimSpace(track.Title)
 		if title == "" {
-			title = "невідома назва"
+			title = i18n.T(lang, "unknown_title")
 		}
 
 		response.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, artist, title))
 		response.WriteString(fmt.Sprintf("   🔗 %s\n", track.SpotifyURL))
-		response.WriteString(fmt.Sprintf("   ⚠️ Спроб: %d | Джерел: %d\n\n", track.FailedAttempts, track.SourceCount))
+		response.WriteString(i18n.T(lang, "failed_attempts", track.FailedAttempts, track.SourceCount))
 	}
 
 	if totalPages == 1 {
@@ -519,10 +526,10 @@ func renderFailedTracksPage(failedTracks []db.FailedTrack, page int) (string, *t
 	row := make([]telebot.Btn, 0, 2)
 
 	if page > 0 {
-		row = append(row, markup.Data("⬅️ Назад", failedPageCallbackUnique, strconv.Itoa(page-1)))
+		row = append(row, markup.Data(i18n.T(lang, "page_prev"), failedPageCallbackUnique, strconv.Itoa(page-1)))
 	}
 	if page < totalPages-1 {
-		row = append(row, markup.Data("Вперед ➡️", failedPageCallbackUnique, strconv.Itoa(page+1)))
+		row = append(row, markup.Data(i18n.T(lang, "page_next"), failedPageCallbackUnique, strconv.Itoa(page+1)))
 	}
 
 	if len(row) > 0 {
@@ -532,161 +539,174 @@ func renderFailedTracksPage(failedTracks []db.FailedTrack, page int) (string, *t
 	return response.String(), markup, nil
 }
 
-// compareTracks compares expected tracks with indexed files and returns the count of found tracks
-func (h *handler) compareTracks(ctx context.Context, request models.DownloadQueueRequest) (int, error) {
-	if len(request.TrackMetadata) == 0 {
-		return 0, nil
-	}
-
-	// Extract artists and titles from track metadata
-	artists := make([]string, 0, len(request.TrackMetadata))
-	titles := make([]string, 0, len(request.TrackMetadata))
-	for _, track := range request.TrackMetadata {
-		artists = append(artists, track.Artist)
-		titles = append(titles, track.Title)
-	}
-
-	// Find matching music files in the database
-	foundMusic, err := h.db.FindMusicFiles(ctx, artists, titles)
-	if err != nil {
-		return 0, err
-	}
-
-	// Create a map for quick lookup (case-insensitive)
-	foundMap := make(map[string]bool)
-	for _, music := range foundMusic {
-		key := strings.ToLower(music.Artist) + " " + strings.ToLower(music.Title)
-		foundMap[key] = true
-	}
-
-	// Count how many expected tracks were found (excluding skipped tracks)
-	foundCount := 0
-	for _, track := range request.TrackMetadata {
-		// Skip counting skipped tracks
-		if track.Skipped {
-			continue
-		}
-		key := strings.ToLower(track.Artist) + " " + strings.ToLower(track.Title)
-		if foundMap[key] {
-			foundCount++
-		}
-	}
-
-	return foundCount, nil
-}
-
 func (h *handler) HandleDeactivate(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
+	lang := h.lang(m.Sender)
 
 	s := strings.Split(m.Text, " ")
 	if len(s) != 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /deactivate <request_id>.")
+		h.reply(m, i18n.T(lang, "deactivate_usage"))
 		return
 	}
 
 	id := s[1]
 	h.log.Info("Deactivating request", zap.String("id", id))
 
-	err := h.db.DeactivateRequest(context.Background(), id)
+	ctx := context.Background()
+
+	// members may only cancel their own requests, admins any
+	if role, _ := h.userRole(ctx, m.Sender.ID); !role.Allows(models.RoleAdmin) {
+		request, err := h.db.GetDownloadRequest(ctx, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			h.reply(m, i18n.T(lang, "deactivate_not_found"))
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to get download request", zap.Error(err))
+			h.reply(m, i18n.T(lang, "deactivate_failed"))
+			return
+		}
+		if request.CreatorID != m.Sender.ID {
+			h.reply(m, i18n.T(lang, "deactivate_not_owner"))
+			return
+		}
+	}
+
+	err := h.db.DeactivateRequest(ctx, id)
 	if err != nil {
 		h.log.Error("Failed to deactivate request", zap.Error(err))
-		h.reply(m, "не получилося деактивувати запит. Пліз спробуй ще раз пізніше.")
+		h.reply(m, i18n.T(lang, "deactivate_failed"))
 		return
 	}
 
-	h.reply(m, "Запит деактивовано, всьо капец.")
+	h.reply(m, i18n.T(lang, "deactivated"))
 }
 
 func (h *handler) HandlePlaylist(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
-
 	h.log.Info("Received playlist request", zap.Any("message", m.Text))
 
+	lang := h.lang(m.Sender)
+
 	msg := strings.Split(m.Text, " ")
 	if len(msg) != 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /playlist <playlist_id>.")
+		h.reply(m, i18n.T(lang, "playlist_usage"))
 		return
 	}
 
 	playlistURL := msg[1]
 
 	if !utils.IsValidSpotifyURL(playlistURL) {
-		h.reply(m, "о ніііііі, це не посилання на спотіфай.... 💔😭")
+		h.reply(m, i18n.T(lang, "not_spotify_url"))
 		return
 	}
 
-	if err := h.db.NewPlaylistRequest(context.Background(), playlistURL, m.Sender.ID, false); err != nil {
-		h.log.Error("Failed to add playlist request to database", zap.Error(err))
-		h.reply(m, "не получилось додати в чергу, скажи максиму шо шось не так...")
+	ctx := context.Background()
+	trackCount, countErr := h.playlistTrackCount(ctx, playlistURL)
+	if countErr != nil && !h.allowUnknownTrackCount(ctx, m.Sender.ID, lang, h.replyTo(m)) {
+		return
+	}
+	if !h.checkQuota(ctx, m.Sender.ID, lang, h.replyTo(m), func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, trackCount)
+	}) {
 		return
 	}
 
-	h.sendWebhook()
+	id, err := h.db.NewPlaylistRequest(ctx, playlistURL, m.Sender.ID, false, trackCount)
+	if err != nil {
+		h.log.Error("Failed to add playlist request to database", zap.Error(err))
+		h.reply(m, i18n.T(lang, "queue_add_failed"))
+		return
+	}
 
-	h.reply(m, "Ураураура успішно додали плейлист в чергу!!!!")
-}
+	h.requestCreated(webhook.RequestData{ID: id, URL: playlistURL, CreatorID: m.Sender.ID, ObjectType: string(spotify.SpotifyObjectTypePlaylist), Tracks: trackCount})
 
-func (h *handler) HandlePlaylistNoPull(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
+	if countErr != nil {
+		h.reply(m, i18n.T(lang, "track_count_failed", playlistURL))
 		return
 	}
+	h.reply(m, i18n.T(lang, "playlist_queued"))
+}
 
+func (h *handler) HandlePlaylistNoPull(m *telebot.Message) {
 	h.log.Info("Received playlist request", zap.Any("message", m.Text))
 
+	lang := h.lang(m.Sender)
+
 	msg := strings.Split(m.Text, " ")
 	if len(msg) != 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /playlist <playlist_id>.")
+		h.reply(m, i18n.T(lang, "playlist_usage"))
 		return
 	}
 
 	playlistURL := msg[1]
 
 	if !utils.IsValidSpotifyURL(playlistURL) {
-		h.reply(m, "о ніііііі, це не посилання на спотіфай.... 💔😭")
+		h.reply(m, i18n.T(lang, "not_spotify_url"))
 		return
 	}
 
-	if err := h.db.NewPlaylistRequest(context.Background(), playlistURL, m.Sender.ID, true); err != nil {
+	ctx := context.Background()
+	trackCount, countErr := h.playlistTrackCount(ctx, playlistURL)
+	if countErr != nil && !h.allowUnknownTrackCount(ctx, m.Sender.ID, lang, h.replyTo(m)) {
+		return
+	}
+	if !h.checkQuota(ctx, m.Sender.ID, lang, h.replyTo(m), func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, trackCount)
+	}) {
+		return
+	}
+
+	id, err := h.db.NewPlaylistRequest(ctx, playlistURL, m.Sender.ID, true, trackCount)
+	if err != nil {
 		h.log.Error("Failed to add playlist request to database", zap.Error(err))
-		h.reply(m, "не получилось додати в чергу, скажи максиму шо шось не так...")
+		h.reply(m, i18n.T(lang, "queue_add_failed"))
 		return
 	}
 
-	h.sendWebhook()
+	h.requestCreated(webhook.RequestData{ID: id, URL: playlistURL, CreatorID: m.Sender.ID, ObjectType: string(spotify.SpotifyObjectTypePlaylist), Tracks: trackCount})
 
-	h.reply(m, "Ураураура успішно додали плейлист в чергу!!!!")
+	if countErr != nil {
+		h.reply(m, i18n.T(lang, "track_count_failed", playlistURL))
+		return
+	}
+	h.reply(m, i18n.T(lang, "playlist_queued"))
 }
 
-func (h *handler) HandleSubscribe(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
+// playlistTrackCount returns how many tracks the playlist has, see
+// allowUnknownTrackCount for when Spotify can't tell
+func (h *handler) playlistTrackCount(ctx context.Context, playlistURL string) (int, error) {
+	trackCount, _, err := h.spotifyService.GetTrackCount(ctx, playlistURL)
+	if err != nil {
+		h.log.Error("Failed to get playlist track count", zap.Error(err), zap.String("playlist_url", playlistURL))
+		return 0, err
 	}
+	return trackCount, nil
+}
 
+func (h *handler) HandleSubscribe(m *telebot.Message) {
 	h.log.Info("Received subscribe request", zap.Any("message", m.Text))
 
+	lang := h.lang(m.Sender)
+
 	msg := strings.Split(m.Text, " ")
 	if len(msg) < 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /subscribe <playlist_url> [weekly|nopull].")
+		h.reply(m, i18n.T(lang, "subscribe_usage"))
 		return
 	}
 
 	playlistURL := msg[1]
+	if strings.EqualFold(playlistURL, "liked") {
+		playlistURL = spotify.LikedSongsURL
+	}
 	if !utils.IsValidSpotifyURL(playlistURL) {
-		h.reply(m, "о ніііііі, це не посилання на спотіфай.... 💔😭")
+		h.reply(m, i18n.T(lang, "not_spotify_url"))
 		return
 	}
 
 	// Parse optional parameters
 	refreshInterval := "daily"
 	noPull := false
+	notifyChanges := false
+	removalPolicy := models.RemovalMirror
 	if len(msg) > 2 {
 		for _, param := range msg[2:] {
 			switch strings.ToLower(param) {
@@ -696,6 +716,12 @@ func (h *handler) HandleSubscribe(m *telebot.Message) {
 				refreshInterval = "hourly"
 			case "nopull":
 				noPull = true
+			case "notify":
+				notifyChanges = true
+			case "keep":
+				removalPolicy = models.RemovalKeep
+			case "archive":
+				removalPolicy = models.RemovalArchive
 			}
 		}
 	}
@@ -706,61 +732,69 @@ func (h *handler) HandleSubscribe(m *telebot.Message) {
 	exists, err := h.db.CheckSubscriptionExists(ctx, playlistURL, m.Sender.ID)
 	if err != nil {
 		h.log.Error("Failed to check subscription existence", zap.Error(err))
-		h.reply(m, "не получилось перевірити підписку, спробуй ще раз...")
+		h.reply(m, i18n.T(lang, "subscription_check_failed"))
 		return
 	}
 	if exists {
-		h.reply(m, "ти вже підписаний на цей плейлист! 🎵")
+		h.reply(m, i18n.T(lang, "already_subscribed"))
 		return
 	}
 
-	// Get playlist name from Spotify
-	playlistName, err := h.spotifyService.GetObjectName(ctx, playlistURL)
+	if !h.checkQuota(ctx, m.Sender.ID, lang, h.replyTo(m), h.limits.CheckSubscription) {
+		return
+	}
+
+	// Get playlist name from Spotify, as the user when their account is linked
+	playlistName, err := h.spotifyFor(ctx, m.Sender.ID).GetObjectName(ctx, playlistURL)
+	if errors.Is(err, spotify.ErrUserAuthRequired) {
+		h.reply(m, i18n.T(lang, "liked_requires_link"))
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to get playlist name", zap.Error(err))
-		h.reply(m, "не получилось отримати назву плейлиста зі спотіфай, спробуй ще раз...")
+		h.reply(m, i18n.T(lang, "playlist_name_failed"))
 		return
 	}
 
 	// Create subscription
-	if err := h.db.NewSubscribedPlaylist(ctx, playlistURL, m.Sender.ID, playlistName, refreshInterval, noPull); err != nil {
+	if err := h.db.NewSubscribedPlaylist(ctx, playlistURL, m.Sender.ID, playlistName, refreshInterval, noPull, notifyChanges, removalPolicy); err != nil {
 		h.log.Error("Failed to create subscription", zap.Error(err))
-		h.reply(m, "не получилось створити підписку, скажи максиму шо шось не так...")
+		h.reply(m, i18n.T(lang, "subscription_create_failed"))
 		return
 	}
 
-	intervalText := "щодня"
-	if refreshInterval == "weekly" {
-		intervalText = "щотижня"
-	} else if refreshInterval == "hourly" {
-		intervalText = "щогодини"
-	}
-
-	pullText := "з завантаженням відсутніх треків"
+	pullText := i18n.T(lang, "subscribe_pull")
 	if noPull {
-		pullText = "без завантаження відсутніх треків"
+		pullText = i18n.T(lang, "subscribe_nopull")
 	}
 
-	h.reply(m, fmt.Sprintf("Ураураура успішно підписались на плейлист '%s'! Оновлення %s, %s 🎵❤️", playlistName, intervalText, pullText))
+	response := i18n.T(lang, "subscribed", playlistName, intervalText(lang, refreshInterval), pullText)
+	if notifyChanges {
+		response += i18n.T(lang, "subscribe_notify")
+	}
+	if removalPolicy.TracksRemoved() {
+		response += i18n.T(lang, "subscribe_removal", removalPolicyText(lang, removalPolicy))
+	}
+	h.reply(m, response)
 }
 
 func (h *handler) HandleUnsubscribe(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
-
 	h.log.Info("Received unsubscribe request", zap.Any("message", m.Text))
 
+	lang := h.lang(m.Sender)
+
 	msg := strings.Split(m.Text, " ")
 	if len(msg) != 2 {
-		h.reply(m, "не розумію цю команду. Пліз юзай /unsubscribe <playlist_url>.")
+		h.reply(m, i18n.T(lang, "unsubscribe_usage"))
 		return
 	}
 
 	playlistURL := msg[1]
+	if strings.EqualFold(playlistURL, "liked") {
+		playlistURL = spotify.LikedSongsURL
+	}
 	if !utils.IsValidSpotifyURL(playlistURL) {
-		h.reply(m, "о ніііііі, це не посилання на спотіфай.... 💔😭")
+		h.reply(m, i18n.T(lang, "not_spotify_url"))
 		return
 	}
 
@@ -768,62 +802,9 @@ func (h *handler) HandleUnsubscribe(m *telebot.Message) {
 
 	if err := h.db.DeleteSubscribedPlaylist(ctx, playlistURL, m.Sender.ID); err != nil {
 		h.log.Error("Failed to unsubscribe", zap.Error(err))
-		h.reply(m, "не получилось відписатись, можливо ти не підписаний на цей плейлист?")
-		return
-	}
-
-	h.reply(m, "Успішно відписались від плейлиста! 👋")
-}
-
-func (h *handler) HandleListSubscriptions(m *telebot.Message) {
-	if !utils.InWhiteList(m.Sender.ID, h.whiteList) {
-		h.log.Info("Unauthorized user", zap.Int64("user_id", m.Sender.ID))
-		return
-	}
-
-	ctx := context.Background()
-	subscriptions, err := h.db.GetSubscribedPlaylists(ctx, m.Sender.ID)
-	if err != nil {
-		h.log.Error("Failed to get subscriptions", zap.Error(err))
-		h.reply(m, "не получилось отримати список підписок... 💔😭")
-		return
-	}
-
-	if len(subscriptions) == 0 {
-		h.reply(m, "немає активних підписок на плейлисти...")
+		h.reply(m, i18n.T(lang, "unsubscribe_failed"))
 		return
 	}
 
-	response := "Твої активні підписки:\n\n"
-	for _, sub := range subscriptions {
-		intervalText := "щодня"
-		if sub.RefreshInterval == "weekly" {
-			intervalText = "щотижня"
-		} else if sub.RefreshInterval == "hourly" {
-			intervalText = "щогодини"
-		}
-
-		pullText := "з завантаженням"
-		if sub.NoPull {
-			pullText = "без завантаження"
-		}
-
-		lastSyncedText := "ще не синхронізовано"
-		if sub.LastSynced > 0 {
-			lastSynced := time.Unix(sub.LastSynced, 0)
-			lastSyncedText = lastSynced.Format("02.01.2006 15:04")
-		}
-
-		response += fmt.Sprintf("🎵 %s\n", sub.Name)
-		response += fmt.Sprintf("   📎 %s\n", sub.SpotifyURL)
-		response += fmt.Sprintf("   ⏰ Оновлення: %s\n", intervalText)
-		response += fmt.Sprintf("   📥 %s\n", pullText)
-		response += fmt.Sprintf("   🕐 Остання синхронізація: %s\n", lastSyncedText)
-		if sub.LastTrackCount > 0 {
-			response += fmt.Sprintf("   🎶 Треків: %d\n", sub.LastTrackCount)
-		}
-		response += "\n"
-	}
-
-	h.reply(m, response)
+	h.reply(m, i18n.T(lang, "unsubscribed"))
 }
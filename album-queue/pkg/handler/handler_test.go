@@ -2,12 +2,18 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
 	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 	"gopkg.in/tucnak/telebot.v2"
@@ -18,8 +24,10 @@ type newRequestCall struct {
 	name               string
 	creatorID          int64
 	objectType         spotify.SpotifyObjectType
+	source             source.Source
 	expectedTrackCount int
 	trackMetadata      []spotify.TrackMetadata
+	tracksOnly         bool
 }
 
 type fakeDatabase struct {
@@ -28,22 +36,95 @@ type fakeDatabase struct {
 
 	newRequestErr error
 	newRequests   []newRequestCall
+
+	aliases          models.ArtistAliases
+	suggestedAliases []models.ArtistAlias
+	newAliases       []models.ArtistAlias
+	confirmedAliases []string
+	suggestCalls     int
+	getUserCalls     int
+
+	spotifyTokens map[int64]spotify.UserToken
+
+	subscriptions       []subscriptionCall
+	subscribedPlaylists []models.SubscribedPlaylist
+	snapshots           []models.SubscriptionSnapshot
+	artists             []models.ArtistSubscription
+	releaseSuggestions  []models.ReleaseSuggestion
+
+	webhooks   []webhook.Subscription
+	deliveries []webhook.Delivery
+
+	languages map[int64]string
+
+	users       map[int64]models.User
+	requests    map[string]models.DownloadQueueRequest
+	deactivated []string
+	retried     []string
+	bumped      []string
+
+	playlistRequests []models.PlaylistRequest
+
+	usage quota.Usage
+
+	musicFiles []models.MusicFile
+
+	libraryStats *db.LibraryStats
+
+	playlistFiles []db.PlaylistFile
+
+	importedPlaylists []models.ImportedPlaylist
 }
 
-func (f *fakeDatabase) NewDownloadRequest(_ context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) error {
+type subscriptionCall struct {
+	url           string
+	creatorID     int64
+	name          string
+	notifyChanges bool
+	removalPolicy models.RemovalPolicy
+}
+
+func (f *fakeDatabase) NewDownloadRequest(_ context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, src source.Source, expectedTrackCount int, trackMetadata []spotify.TrackMetadata) (string, error) {
 	f.newRequests = append(f.newRequests, newRequestCall{
 		url:                url,
 		name:               name,
 		creatorID:          creatorID,
 		objectType:         objectType,
+		source:             src,
 		expectedTrackCount: expectedTrackCount,
 		trackMetadata:      trackMetadata,
 	})
-	return f.newRequestErr
+	return f.requestID(), f.newRequestErr
+}
+
+func (f *fakeDatabase) NewTracksRequest(_ context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) (string, error) {
+	f.newRequests = append(f.newRequests, newRequestCall{
+		url:                url,
+		name:               name,
+		creatorID:          creatorID,
+		objectType:         objectType,
+		source:             source.Spotify,
+		expectedTrackCount: len(tracks),
+		trackMetadata:      tracks,
+		tracksOnly:         true,
+	})
+	return f.requestID(), f.newRequestErr
+}
+
+// requestID is the id of the last queued request
+func (f *fakeDatabase) requestID() string {
+	return fmt.Sprintf("request-%d", len(f.newRequests))
 }
 
 func (f *fakeDatabase) GetActiveRequests(context.Context) ([]models.DownloadQueueRequest, error) {
-	return nil, nil
+	var active []models.DownloadQueueRequest
+	for _, request := range f.requests {
+		if request.Active {
+			active = append(active, request)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	return active, nil
 }
 
 func (f *fakeDatabase) GetUnresolvedFailedTracks(context.Context) ([]db.FailedTrack, error) {
@@ -64,32 +145,146 @@ func (f *fakeDatabase) HasActiveRequestByURL(_ context.Context, trackURL string)
 	return f.activeByURL[trackURL], nil
 }
 
-func (f *fakeDatabase) DeactivateRequest(context.Context, string) error {
+func (f *fakeDatabase) GetDownloadRequest(_ context.Context, id string) (models.DownloadQueueRequest, error) {
+	request, ok := f.requests[id]
+	if !ok {
+		return models.DownloadQueueRequest{}, mongo.ErrNoDocuments
+	}
+	return request, nil
+}
+
+func (f *fakeDatabase) DeactivateRequest(_ context.Context, id string) error {
+	f.deactivated = append(f.deactivated, id)
 	return nil
 }
 
-func (f *fakeDatabase) NewPlaylistRequest(context.Context, string, int64, bool) error {
+func (f *fakeDatabase) GetUserHistory(_ context.Context, creatorID int64, offset, limit int64) ([]db.HistoryEntry, int64, error) {
+	var entries []db.HistoryEntry
+	for _, request := range f.requests {
+		if request.CreatorID == creatorID {
+			entries = append(entries, db.HistoryEntry{Download: &request})
+		}
+	}
+	for _, request := range f.playlistRequests {
+		if request.CreatorID == creatorID {
+			entries = append(entries, db.HistoryEntry{Playlist: &request})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt() > entries[j].CreatedAt() })
+	return fakePage(entries, offset, limit), int64(len(entries)), nil
+}
+
+func fakePage[T any](items []T, offset, limit int64) []T {
+	start := min(int(offset), len(items))
+	end := min(start+int(limit), len(items))
+	return items[start:end]
+}
+
+func (f *fakeDatabase) RetryRequest(_ context.Context, id string) error {
+	f.retried = append(f.retried, id)
 	return nil
 }
 
+func (f *fakeDatabase) BumpRequestPriority(_ context.Context, id string) (int, error) {
+	f.bumped = append(f.bumped, id)
+	return len(f.bumped), nil
+}
+
+func (f *fakeDatabase) NewPlaylistRequest(_ context.Context, url string, creatorID int64, _ bool, trackCount int) (string, error) {
+	f.newRequests = append(f.newRequests, newRequestCall{
+		url:                url,
+		creatorID:          creatorID,
+		objectType:         spotify.SpotifyObjectTypePlaylist,
+		source:             source.Spotify,
+		expectedTrackCount: trackCount,
+	})
+	return f.requestID(), nil
+}
+
 func (f *fakeDatabase) GetActivePlaylists(context.Context) ([]models.PlaylistRequest, error) {
 	return nil, nil
 }
 
-func (f *fakeDatabase) FindMusicFiles(context.Context, []string, []string) ([]models.MusicFile, error) {
-	return nil, nil
+// FindMusicFiles matches like the database does: any known spelling of the
+// artist and the exact title, ignoring case
+func (f *fakeDatabase) FindMusicFiles(_ context.Context, artists, titles []string) ([]models.MusicFile, error) {
+	var files []models.MusicFile
+	for _, file := range f.musicFiles {
+		for i := range artists {
+			matchesArtist := slices.ContainsFunc(f.aliases.Variants(artists[i]), func(variant string) bool {
+				return strings.EqualFold(variant, file.Artist)
+			})
+			if matchesArtist && strings.EqualFold(titles[i], file.Title) {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+	return files, nil
+}
+
+func (f *fakeDatabase) SearchMusicFiles(_ context.Context, query string, limit int) ([]models.MusicFile, error) {
+	var result []models.MusicFile
+	for _, file := range f.musicFiles {
+		text := strings.ToLower(file.Artist + " " + file.Title + " " + file.Album)
+		if strings.Contains(text, strings.ToLower(query)) && len(result) < limit {
+			result = append(result, file)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeDatabase) IndexMusicFile(_ context.Context, file models.MusicFile) (models.MusicFile, error) {
+	file.ID = fmt.Sprintf("file-%d", len(f.musicFiles)+1)
+	f.musicFiles = append(f.musicFiles, file)
+	return file, nil
+}
+
+func (f *fakeDatabase) GetMusicFile(_ context.Context, id string) (models.MusicFile, error) {
+	for _, file := range f.musicFiles {
+		if file.ID == id {
+			return file, nil
+		}
+	}
+	return models.MusicFile{}, mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) GetAlbumFiles(_ context.Context, artist, album string) ([]models.MusicFile, error) {
+	var result []models.MusicFile
+	for _, file := range f.musicFiles {
+		if file.Artist == artist && file.Album == album {
+			result = append(result, file)
+		}
+	}
+	return result, nil
 }
 
 func (f *fakeDatabase) UpdateDownloadRequest(context.Context, models.DownloadQueueRequest) error {
 	return nil
 }
 
-func (f *fakeDatabase) NewSubscribedPlaylist(context.Context, string, int64, string, string, bool) error {
+func (f *fakeDatabase) NewSubscribedPlaylist(_ context.Context, url string, creatorID int64, name string, _ string, _, notifyChanges bool, removalPolicy models.RemovalPolicy) error {
+	f.subscriptions = append(f.subscriptions, subscriptionCall{url: url, creatorID: creatorID, name: name, notifyChanges: notifyChanges, removalPolicy: removalPolicy})
 	return nil
 }
 
-func (f *fakeDatabase) GetSubscribedPlaylists(context.Context, int64) ([]models.SubscribedPlaylist, error) {
-	return nil, nil
+func (f *fakeDatabase) GetSubscribedPlaylists(_ context.Context, creatorID int64) ([]models.SubscribedPlaylist, error) {
+	var playlists []models.SubscribedPlaylist
+	for _, p := range f.subscribedPlaylists {
+		if p.CreatorID == creatorID && p.Active {
+			playlists = append(playlists, p)
+		}
+	}
+	return playlists, nil
+}
+
+func (f *fakeDatabase) GetPlaylistFiles(context.Context, int64) ([]db.PlaylistFile, error) {
+	return f.playlistFiles, nil
+}
+
+func (f *fakeDatabase) SaveImportedPlaylist(_ context.Context, playlist models.ImportedPlaylist) error {
+	f.importedPlaylists = append(f.importedPlaylists, playlist)
+	return nil
 }
 
 func (f *fakeDatabase) DeleteSubscribedPlaylist(context.Context, string, int64) error {
@@ -100,6 +295,171 @@ func (f *fakeDatabase) CheckSubscriptionExists(context.Context, string, int64) (
 	return false, nil
 }
 
+func (f *fakeDatabase) UpdateSubscribedPlaylist(_ context.Context, id string, creatorID int64, update db.SubscriptionUpdate) (models.SubscribedPlaylist, error) {
+	for i, p := range f.subscribedPlaylists {
+		if p.ID != id || p.CreatorID != creatorID || !p.Active {
+			continue
+		}
+		if update.RefreshInterval != nil {
+			p.RefreshInterval = *update.RefreshInterval
+		}
+		if update.NoPull != nil {
+			p.NoPull = *update.NoPull
+		}
+		if update.Paused != nil {
+			p.Paused = *update.Paused
+		}
+		if update.NotifyChanges != nil {
+			p.NotifyChanges = *update.NotifyChanges
+		}
+		if update.SyncNow {
+			p.LastSynced = 0
+		}
+		f.subscribedPlaylists[i] = p
+		return p, nil
+	}
+	return models.SubscribedPlaylist{}, mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) GetSubscriptionChanges(_ context.Context, creatorID int64, limit int64) ([]models.SubscriptionSnapshot, error) {
+	var snapshots []models.SubscriptionSnapshot
+	for _, s := range f.snapshots {
+		if s.CreatorID == creatorID && s.Changed && int64(len(snapshots)) < limit {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func (f *fakeDatabase) NewArtistSubscription(_ context.Context, url string, creatorID int64, name string) error {
+	f.artists = append(f.artists, models.ArtistSubscription{
+		ID:         url,
+		CreatorID:  creatorID,
+		SpotifyURL: url,
+		Name:       name,
+		Active:     true,
+	})
+	return nil
+}
+
+func (f *fakeDatabase) GetArtistSubscriptions(_ context.Context, creatorID int64) ([]models.ArtistSubscription, error) {
+	var artists []models.ArtistSubscription
+	for _, artist := range f.artists {
+		if artist.CreatorID == creatorID && artist.Active {
+			artists = append(artists, artist)
+		}
+	}
+	return artists, nil
+}
+
+func (f *fakeDatabase) DeleteArtistSubscription(_ context.Context, url string, creatorID int64) error {
+	for i, artist := range f.artists {
+		if artist.SpotifyURL == url && artist.CreatorID == creatorID && artist.Active {
+			f.artists[i].Active = false
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) CheckArtistSubscriptionExists(ctx context.Context, url string, creatorID int64) (bool, error) {
+	artists, _ := f.GetArtistSubscriptions(ctx, creatorID)
+	for _, artist := range artists {
+		if artist.SpotifyURL == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeDatabase) GetPendingReleaseSuggestions(_ context.Context, limit int64) ([]models.ReleaseSuggestion, error) {
+	var suggestions []models.ReleaseSuggestion
+	for _, s := range f.releaseSuggestions {
+		if s.Status == models.ReleaseSuggestionPending && int64(len(suggestions)) < limit {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, nil
+}
+
+func (f *fakeDatabase) GetReleaseSuggestion(_ context.Context, id string) (models.ReleaseSuggestion, error) {
+	for _, s := range f.releaseSuggestions {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return models.ReleaseSuggestion{}, mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) DecideReleaseSuggestion(_ context.Context, id string, status models.ReleaseSuggestionStatus, userID int64) error {
+	for i, s := range f.releaseSuggestions {
+		if s.ID == id && s.Status == models.ReleaseSuggestionPending {
+			f.releaseSuggestions[i].Status = status
+			f.releaseSuggestions[i].DecidedBy = userID
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) NewWebhookSubscription(_ context.Context, url string, events []webhook.Event, createdBy int64) (webhook.Subscription, error) {
+	subscription := webhook.Subscription{
+		ID:        fmt.Sprintf("hook-%d", len(f.webhooks)+1),
+		URL:       url,
+		Secret:    "secret",
+		Events:    events,
+		Active:    true,
+		CreatedBy: createdBy,
+	}
+	f.webhooks = append(f.webhooks, subscription)
+	return subscription, nil
+}
+
+func (f *fakeDatabase) GetWebhookSubscriptions(_ context.Context, event webhook.Event) ([]webhook.Subscription, error) {
+	var subscriptions []webhook.Subscription
+	for _, s := range f.webhooks {
+		if s.Wants(event) {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (f *fakeDatabase) ListWebhookSubscriptions(context.Context) ([]webhook.Subscription, error) {
+	var subscriptions []webhook.Subscription
+	for _, s := range f.webhooks {
+		if s.Active {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (f *fakeDatabase) DeleteWebhookSubscription(_ context.Context, id string) error {
+	for i, s := range f.webhooks {
+		if s.ID == id && s.Active {
+			f.webhooks[i].Active = false
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) SaveWebhookDelivery(_ context.Context, delivery webhook.Delivery) error {
+	f.deliveries = append(f.deliveries, delivery)
+	return nil
+}
+
+func (f *fakeDatabase) GetWebhookDeliveries(_ context.Context, subscriptionID string, limit int64) ([]webhook.Delivery, error) {
+	var deliveries []webhook.Delivery
+	for i := len(f.deliveries) - 1; i >= 0 && int64(len(deliveries)) < limit; i-- {
+		if f.deliveries[i].SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, f.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
 func (f *fakeDatabase) Close(context.Context) error {
 	return nil
 }
@@ -112,6 +472,142 @@ func (f *fakeDatabase) GetStats(context.Context) (*db.Stats, error) {
 	return &db.Stats{}, nil
 }
 
+func (f *fakeDatabase) BackfillMusicFileSizes(context.Context) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeDatabase) GetLibraryStats(context.Context) (*db.LibraryStats, error) {
+	if f.libraryStats == nil {
+		return &db.LibraryStats{}, nil
+	}
+	return f.libraryStats, nil
+}
+
+func (f *fakeDatabase) GetArtistAliases(context.Context) (models.ArtistAliases, error) {
+	return f.aliases, nil
+}
+
+func (f *fakeDatabase) NewArtistAlias(_ context.Context, alias, canonical string, creatorID int64) error {
+	f.newAliases = append(f.newAliases, models.ArtistAlias{Alias: alias, Canonical: canonical, CreatorID: creatorID})
+	return nil
+}
+
+func (f *fakeDatabase) ConfirmArtistAlias(_ context.Context, alias string, _ int64) error {
+	for _, suggestion := range f.suggestedAliases {
+		if suggestion.Alias == alias {
+			f.confirmedAliases = append(f.confirmedAliases, alias)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (f *fakeDatabase) GetSuggestedArtistAliases(context.Context) ([]models.ArtistAlias, error) {
+	return f.suggestedAliases, nil
+}
+
+func (f *fakeDatabase) SuggestArtistAliases(context.Context) ([]models.ArtistAlias, error) {
+	f.suggestCalls++
+	return f.suggestedAliases, nil
+}
+
+func (f *fakeDatabase) GetSpotifyToken(_ context.Context, userID int64) (spotify.UserToken, error) {
+	token, ok := f.spotifyTokens[userID]
+	if !ok {
+		return spotify.UserToken{}, spotify.ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (f *fakeDatabase) SaveSpotifyToken(_ context.Context, token spotify.UserToken) error {
+	if f.spotifyTokens == nil {
+		f.spotifyTokens = make(map[int64]spotify.UserToken)
+	}
+	f.spotifyTokens[token.UserID] = token
+	return nil
+}
+
+func (f *fakeDatabase) DeleteSpotifyToken(_ context.Context, userID int64) error {
+	if _, ok := f.spotifyTokens[userID]; !ok {
+		return spotify.ErrTokenNotFound
+	}
+	delete(f.spotifyTokens, userID)
+	return nil
+}
+
+func (f *fakeDatabase) GetUserLanguage(_ context.Context, userID int64) (string, error) {
+	return f.languages[userID], nil
+}
+
+func (f *fakeDatabase) SetUserLanguage(_ context.Context, userID int64, language string) error {
+	if f.languages == nil {
+		f.languages = make(map[int64]string)
+	}
+	f.languages[userID] = language
+	return nil
+}
+
+func (f *fakeDatabase) GetUser(_ context.Context, userID int64) (models.User, error) {
+	f.getUserCalls++
+	user, ok := f.users[userID]
+	if !ok {
+		return models.User{}, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (f *fakeDatabase) ListUsers(context.Context) ([]models.User, error) {
+	users := make([]models.User, 0, len(f.users))
+	for _, user := range f.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (f *fakeDatabase) SetUserRole(_ context.Context, userID int64, role models.Role, addedBy int64) error {
+	if f.users == nil {
+		f.users = make(map[int64]models.User)
+	}
+	user, ok := f.users[userID]
+	if !ok {
+		user = models.User{ID: userID, AddedBy: addedBy}
+	}
+	user.Role = role
+	f.users[userID] = user
+	return nil
+}
+
+func (f *fakeDatabase) SetUserName(_ context.Context, userID int64, name string) error {
+	user := f.users[userID]
+	user.Name = name
+	f.users[userID] = user
+	return nil
+}
+
+func (f *fakeDatabase) GetUsage(context.Context, int64, int64) (quota.Usage, error) {
+	return f.usage, nil
+}
+
+func (f *fakeDatabase) GetPendingNotifications(context.Context, int) ([]models.Notification, error) {
+	return nil, nil
+}
+
+func (f *fakeDatabase) MarkNotificationSent(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeDatabase) MarkNotificationFailed(context.Context, string, bool) error {
+	return nil
+}
+
+func (f *fakeDatabase) DeleteUser(_ context.Context, userID int64) error {
+	if _, ok := f.users[userID]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(f.users, userID)
+	return nil
+}
+
 type pageEvent struct {
 	text   string
 	markup *telebot.ReplyMarkup
@@ -128,6 +624,10 @@ type testSinks struct {
 	editedPages  []pageEvent
 	callbackAcks []callbackEvent
 	webhookCalls int
+	webhooks     []webhook.Payload
+	sentMessages map[int64][]string
+	documents    []*telebot.Document
+	audios       []*telebot.Audio
 }
 
 func createTestHandler(database *fakeDatabase, sinks *testSinks) *handler {
@@ -139,10 +639,11 @@ func createTestHandler(database *fakeDatabase, sinks *testSinks) *handler {
 			sinks.replies = append(sinks.replies, text)
 			return nil
 		},
-		sendWebhookFn: func() error {
+		dispatchFn: func(event webhook.Event, data any) {
 			sinks.webhookCalls++
-			return nil
+			sinks.webhooks = append(sinks.webhooks, webhook.Payload{Event: event, Data: data})
 		},
+		libraryStatsFn: database.GetLibraryStats,
 		sendFailedPageFn: func(_ *telebot.Message, text string, markup *telebot.ReplyMarkup) error {
 			sinks.sentPages = append(sinks.sentPages, pageEvent{text: text, markup: markup})
 			return nil
@@ -155,6 +656,21 @@ func createTestHandler(database *fakeDatabase, sinks *testSinks) *handler {
 			sinks.callbackAcks = append(sinks.callbackAcks, callbackEvent{text: text, showAlert: showAlert})
 			return nil
 		},
+		sendMessageFn: func(userID int64, text string) error {
+			if sinks.sentMessages == nil {
+				sinks.sentMessages = make(map[int64][]string)
+			}
+			sinks.sentMessages[userID] = append(sinks.sentMessages[userID], text)
+			return nil
+		},
+		sendDocumentFn: func(_ *telebot.Message, doc *telebot.Document) error {
+			sinks.documents = append(sinks.documents, doc)
+			return nil
+		},
+		sendAudioFn: func(_ *telebot.Message, audio *telebot.Audio) error {
+			sinks.audios = append(sinks.audios, audio)
+			return nil
+		},
 	}
 }
 
@@ -298,7 +814,7 @@ func TestHandleRedownloadRejectsBadSyntax(t *testing.T) {
 	if len(sinks.replies) != 1 {
 		t.Fatalf("expected one reply, got %d", len(sinks.replies))
 	}
-	if !strings.Contains(sinks.replies[0], "/redownload <spotify_track_url>") {
+	if !strings.Contains(sinks.replies[0], "/redownload <track_url>") {
 		t.Fatalf("unexpected reply: %q", sinks.replies[0])
 	}
 }
@@ -395,3 +911,174 @@ func TestHandleRedownloadCreatesTrackRequestWithCallerID(t *testing.T) {
 		t.Fatalf("unexpected success reply: %#v", sinks.replies)
 	}
 }
+
+func TestHandleRedownloadKeepsFailedTrackSource(t *testing.T) {
+	trackURL := "https://artist.bandcamp.com/track/retry"
+	db := &fakeDatabase{
+		activeByURL: map[string]bool{},
+		unresolvedTracks: []db.FailedTrack{
+			{SpotifyURL: trackURL, Artist: "artist", Title: "title", FailedAttempts: 3, SourceCount: 1, Source: source.Bandcamp},
+		},
+	}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleRedownload(testMessage("/redownload " + trackURL))
+
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected one inserted request, got %d", len(db.newRequests))
+	}
+	if inserted := db.newRequests[0]; inserted.url != trackURL || inserted.source != source.Bandcamp {
+		t.Fatalf("expected a bandcamp request for %q, got %+v", trackURL, inserted)
+	}
+}
+
+func TestHandleRedownloadRejectsUnsupportedURL(t *testing.T) {
+	db := &fakeDatabase{activeByURL: map[string]bool{}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleRedownload(testMessage("/redownload https://example.com/track/1"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "Bandcamp") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected no new request insertion, got %d", len(db.newRequests))
+	}
+}
+
+func TestHandleAliasRejectsBadSyntax(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleAlias(testMessage("/alias weeknd"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "/alias <") {
+		t.Fatalf("unexpected reply: %#v", sinks.replies)
+	}
+	if len(db.newAliases) != 0 {
+		t.Fatalf("expected no alias insertion, got %d", len(db.newAliases))
+	}
+}
+
+func TestHandleAliasStoresMultiWordArtists(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleAlias(testMessage("/alias Weeknd =  The Weeknd "))
+
+	if len(db.newAliases) != 1 {
+		t.Fatalf("expected one alias insertion, got %d", len(db.newAliases))
+	}
+	got := db.newAliases[0]
+	if got.Alias != "Weeknd" || got.Canonical != "The Weeknd" || got.CreatorID != 1 {
+		t.Fatalf("unexpected alias: %+v", got)
+	}
+}
+
+func TestHandleAliasesListsAliasesAndSuggestions(t *testing.T) {
+	db := &fakeDatabase{
+		aliases:          models.ArtistAliases{"weeknd": "the weeknd"},
+		suggestedAliases: []models.ArtistAlias{{Alias: "beatles", Canonical: "the beatles", Suggested: true}},
+	}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleAliases(testMessage("/aliases"))
+
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected one reply, got %d", len(sinks.replies))
+	}
+	for _, expected := range []string{"weeknd → the weeknd", "beatles → the beatles ?", "/aliasaccept"} {
+		if !strings.Contains(sinks.replies[0], expected) {
+			t.Fatalf("expected %q in reply: %q", expected, sinks.replies[0])
+		}
+	}
+	if db.suggestCalls != 0 {
+		t.Fatalf("expected /aliases to only read the stored suggestions, got %d suggest runs", db.suggestCalls)
+	}
+}
+
+func TestHandleAliasAcceptUnknownSuggestion(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleAliasAccept(testMessage("/aliasaccept nobody"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "такої підказки нема") {
+		t.Fatalf("unexpected reply: %#v", sinks.replies)
+	}
+}
+
+type fakeSourceProvider struct {
+	name     string
+	tracks   []spotify.TrackMetadata
+	countErr error
+}
+
+func (f *fakeSourceProvider) GetObjectName(context.Context, string) (string, error) {
+	return f.name, nil
+}
+
+func (f *fakeSourceProvider) GetObjectType(context.Context, string) (spotify.SpotifyObjectType, error) {
+	return spotify.SpotifyObjectTypeAlbum, nil
+}
+
+func (f *fakeSourceProvider) GetTrackCount(context.Context, string) (int, []spotify.TrackMetadata, error) {
+	if f.countErr != nil {
+		return 0, nil, f.countErr
+	}
+	return len(f.tracks), f.tracks, nil
+}
+
+func (f *fakeSourceProvider) Download(context.Context, string, string) error {
+	return nil
+}
+
+func TestHandleTextQueuesNonSpotifySource(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.sourceProvider = &fakeSourceProvider{
+		name:   "Record",
+		tracks: []spotify.TrackMetadata{{SpotifyURL: "https://artist.bandcamp.com/track/song", Artist: "artist", Title: "song"}},
+	}
+
+	h.HandleText(testMessage("https://artist.bandcamp.com/album/record"))
+
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected one inserted request, got %d", len(db.newRequests))
+	}
+	inserted := db.newRequests[0]
+	if inserted.source != source.Bandcamp || inserted.objectType != spotify.SpotifyObjectTypeAlbum || inserted.expectedTrackCount != 1 {
+		t.Fatalf("unexpected request: %+v", inserted)
+	}
+	if sinks.webhookCalls != 1 {
+		t.Fatalf("expected webhook call, got %d", sinks.webhookCalls)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "Record") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleTextRejectsUnsupportedURL(t *testing.T) {
+	for _, text := range []string{"https://google.com", "https://soundcloud.com/artist/song"} {
+		db := &fakeDatabase{}
+		sinks := &testSinks{}
+		// no source provider, so only Spotify links are accepted
+		h := createTestHandler(db, sinks)
+
+		h.HandleText(testMessage(text))
+
+		if len(db.newRequests) != 0 {
+			t.Fatalf("expected no request for %q, got %d", text, len(db.newRequests))
+		}
+		if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "не посилання") {
+			t.Fatalf("unexpected replies for %q: %#v", text, sinks.replies)
+		}
+	}
+}
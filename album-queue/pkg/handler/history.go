@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	historyPageCallbackUnique = "history_page"
+	historyPageSize           = 5
+	historyDateFormat         = "02.01.2006"
+)
+
+var historyPageCallbackEndpoint = &telebot.InlineButton{Unique: historyPageCallbackUnique}
+
+func HistoryPageCallbackEndpoint() telebot.CallbackEndpoint {
+	return historyPageCallbackEndpoint
+}
+
+func (h *handler) HandleHistory(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+	ctx := context.Background()
+
+	entries, total, err := h.history(ctx, m.Sender.ID, 0)
+	if err != nil {
+		h.log.Error("Failed to get request history", zap.Error(err), zap.Int64("user_id", m.Sender.ID))
+		h.reply(m, i18n.T(lang, "history_fetch_failed"))
+		return
+	}
+
+	if total == 0 {
+		h.reply(m, i18n.T(lang, "history_empty"))
+		return
+	}
+
+	text, markup, err := h.renderHistoryPage(ctx, lang, entries, total, 0)
+	if err != nil {
+		h.log.Error("Failed to render history page", zap.Error(err))
+		h.reply(m, i18n.T(lang, "history_fetch_failed"))
+		return
+	}
+
+	if err := h.sendFailedPageFn(m, text, markup); err != nil {
+		h.log.Error("Failed to send history", zap.Error(err))
+	}
+}
+
+func (h *handler) HandleHistoryPage(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	page, err := strconv.Atoi(strings.TrimSpace(c.Data))
+	if err != nil || page < 0 {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
+		return
+	}
+
+	entries, total, err := h.history(ctx, c.Sender.ID, page)
+	if err != nil {
+		h.log.Error("Failed to get request history", zap.Error(err), zap.Int64("user_id", c.Sender.ID))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "history_fetch_failed"), true)
+		return
+	}
+
+	text, markup, err := h.renderHistoryPage(ctx, lang, entries, total, page)
+	if err != nil {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
+		return
+	}
+
+	if c.Message != nil {
+		if err := h.editFailedPageFn(c.Message, text, markup); err != nil {
+			h.log.Error("Failed to edit history page", zap.Error(err))
+		}
+	}
+
+	_ = h.respondCallbackFn(c, "", false)
+}
+
+// history returns one page of the user's download and playlist requests,
+// newest first, and how many there are in total
+func (h *handler) history(ctx context.Context, userID int64, page int) ([]db.HistoryEntry, int, error) {
+	entries, total, err := h.db.GetUserHistory(ctx, userID, int64(page*historyPageSize), historyPageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, int(total), nil
+}
+
+func (h *handler) renderHistoryPage(ctx context.Context, lang i18n.Lang, entries []db.HistoryEntry, total, page int) (string, *telebot.ReplyMarkup, error) {
+	totalPages := (total + historyPageSize - 1) / historyPageSize
+	if len(entries) == 0 || page >= totalPages {
+		return "", nil, errors.New("page out of range")
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "history_header", total))
+	response.WriteString(i18n.T(lang, "page", page+1, totalPages))
+
+	for i, entry := range entries {
+		n := page*historyPageSize + i + 1
+
+		if r := entry.Download; r != nil {
+			response.WriteString(fmt.Sprintf("%d. 📀 %s\n", n, r.Name))
+			response.WriteString(historyStatus(lang, r.Active, r.Errored, r.RetryCount))
+			if r.ExpectedTrackCount > 0 {
+				response.WriteString(i18n.T(lang, "history_tracks", r.FoundTrackCount, r.ExpectedTrackCount))
+			}
+		} else {
+			r := entry.Playlist
+			name, err := h.spotifyService.GetObjectName(ctx, r.SpotifyURL)
+			if err != nil {
+				h.log.Warn("Failed to get playlist name", zap.Error(err))
+				name = r.SpotifyURL
+			}
+			response.WriteString(fmt.Sprintf("%d. 🎵 %s\n", n, name))
+			response.WriteString(historyStatus(lang, r.Active, r.Errored, r.RetryCount))
+		}
+
+		response.WriteString(i18n.T(lang, "history_created", time.Unix(entry.CreatedAt(), 0).Format(historyDateFormat)))
+		if finishedAt := entry.FinishedAt(); finishedAt > 0 {
+			response.WriteString(i18n.T(lang, "history_finished", time.Unix(finishedAt, 0).Format(historyDateFormat)))
+		}
+		response.WriteString("\n")
+	}
+
+	if totalPages == 1 {
+		return response.String(), nil, nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	row := make([]telebot.Btn, 0, 2)
+
+	if page > 0 {
+		row = append(row, markup.Data(i18n.T(lang, "page_prev"), historyPageCallbackUnique, strconv.Itoa(page-1)))
+	}
+	if page < totalPages-1 {
+		row = append(row, markup.Data(i18n.T(lang, "page_next"), historyPageCallbackUnique, strconv.Itoa(page+1)))
+	}
+
+	markup.Inline(markup.Row(row...))
+	return response.String(), markup, nil
+}
+
+func historyStatus(lang i18n.Lang, active, errored bool, retryCount int) string {
+	switch {
+	case active:
+		return i18n.T(lang, "history_active")
+	case errored:
+		return i18n.T(lang, "history_errored", retryCount)
+	default:
+		return i18n.T(lang, "history_done")
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func historyTestDatabase() *fakeDatabase {
+	db := &fakeDatabase{
+		users:    map[int64]models.User{2: {ID: 2, Role: models.RoleReadOnly}},
+		requests: map[string]models.DownloadQueueRequest{},
+	}
+	for i := 0; i < historyPageSize; i++ {
+		id := fmt.Sprintf("album-%d", i)
+		db.requests[id] = models.DownloadQueueRequest{
+			ID:                 id,
+			CreatorID:          2,
+			Name:               fmt.Sprintf("Album %d", i),
+			CreatedAt:          int64(100 + i*10),
+			UpdatedAt:          int64(129600 + i*10),
+			ExpectedTrackCount: 10,
+			FoundTrackCount:    i,
+		}
+	}
+	db.requests["active"] = models.DownloadQueueRequest{ID: "active", CreatorID: 2, Name: "Active", Active: true, CreatedAt: 1000}
+	db.requests["other"] = models.DownloadQueueRequest{ID: "other", CreatorID: 3, Name: "Other", CreatedAt: 2000}
+	db.playlistRequests = []models.PlaylistRequest{
+		{ID: "playlist", CreatorID: 2, SpotifyURL: "https://open.spotify.com/playlist/p", Errored: true, RetryCount: 5, CreatedAt: 105},
+	}
+	return db
+}
+
+func TestHandleHistoryShowsNewestFirst(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(historyTestDatabase(), sinks)
+	h.spotifyService = &fakeSpotifyService{}
+
+	h.HandleHistory(userMessage(2, "/history"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected one history page, got %d (replies %#v)", len(sinks.sentPages), sinks.replies)
+	}
+
+	text := sinks.sentPages[0].text
+	if !strings.Contains(text, "1. 📀 Active") || !strings.Contains(text, "⏳") {
+		t.Fatalf("expected newest active request first: %q", text)
+	}
+	if !strings.Contains(text, "Знайдено: 4/10") {
+		t.Fatalf("expected found counts: %q", text)
+	}
+	if strings.Count(text, "Завершено: 02.01.1970") != historyPageSize-1 {
+		t.Fatalf("expected the finished requests to show when they were done: %q", text)
+	}
+	if strings.Contains(text, "Other") {
+		t.Fatalf("expected only the sender's requests: %q", text)
+	}
+
+	rows := sinks.sentPages[0].markup.InlineKeyboard
+	if len(rows) != 1 || len(rows[0]) != 1 || rows[0][0].Unique != historyPageCallbackUnique || rows[0][0].Data != "1" {
+		t.Fatalf("unexpected pagination: %+v", rows)
+	}
+}
+
+func TestHandleHistoryPageMergesPlaylists(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(historyTestDatabase(), sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{"https://open.spotify.com/playlist/p": "Road Trip"}}
+
+	h.HandleHistoryPage(&telebot.Callback{Sender: &telebot.User{ID: 2}, Message: &telebot.Message{}, Data: "1"})
+
+	if len(sinks.editedPages) != 1 {
+		t.Fatalf("expected one edit, got %d (acks %#v)", len(sinks.editedPages), sinks.callbackAcks)
+	}
+
+	text := sinks.editedPages[0].text
+	if !strings.Contains(text, "6. 🎵 Road Trip") || !strings.Contains(text, "спроб: 5") {
+		t.Fatalf("expected failed playlist between albums: %q", text)
+	}
+	if !strings.Contains(text, "7. 📀 Album 0") {
+		t.Fatalf("expected oldest album last: %q", text)
+	}
+}
+
+func TestHandleHistoryEmpty(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{}, sinks)
+
+	h.HandleHistory(testMessage("/history"))
+
+	if len(sinks.replies) != 1 || len(sinks.sentPages) != 0 {
+		t.Fatalf("expected empty state reply, got replies %#v pages %d", sinks.replies, len(sinks.sentPages))
+	}
+}
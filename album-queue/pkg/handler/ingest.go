@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/ingest"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// HandleAudio files an uploaded or forwarded audio message into the library
+func (h *handler) HandleAudio(m *telebot.Message) {
+	audio := m.Audio
+	if audio == nil {
+		return
+	}
+
+	fileName := audio.FileName
+	if fileName == "" {
+		// Telegram leaves the name out of some forwarded audio, the MIME type
+		// still says what it is
+		fileName = "audio" + audioExtension(audio.MIME)
+	}
+
+	h.ingestUpload(m, &audio.File, fileName, ingest.TrackFromUpload(audio.Performer, audio.Title, fileName))
+}
+
+// ingestUpload downloads an upload into the library and replies with where it
+// landed
+func (h *handler) ingestUpload(m *telebot.Message, file *telebot.File, fileName string, track ingest.Track) {
+	lang := h.lang(m.Sender)
+
+	if h.ingester == nil {
+		h.reply(m, i18n.T(lang, "ingest_disabled"))
+		return
+	}
+	if !ingest.Supported(fileName) {
+		h.reply(m, i18n.T(lang, "ingest_unsupported"))
+		return
+	}
+	if file.FileSize > ingest.MaxDownloadSize {
+		h.reply(m, i18n.T(lang, "ingest_too_big", formatBytes(int64(file.FileSize)), formatBytes(ingest.MaxDownloadSize)))
+		return
+	}
+
+	h.log.Info("Received audio upload", zap.String("file_name", fileName), zap.Int("size", file.FileSize), zap.Int64("user_id", m.Sender.ID))
+
+	reader, err := h.getFileFn(file)
+	if err != nil {
+		h.log.Error("Failed to download audio", zap.Error(err), zap.String("file_name", fileName))
+		h.reply(m, i18n.T(lang, "ingest_failed"))
+		return
+	}
+	defer reader.Close()
+
+	indexed, err := h.ingester.Ingest(context.Background(), reader, fileName, track)
+	switch {
+	case errors.Is(err, ingest.ErrNoArtist):
+		h.reply(m, i18n.T(lang, "ingest_no_artist"))
+		return
+	case errors.Is(err, ingest.ErrExists):
+		h.reply(m, i18n.T(lang, "ingest_exists", track.Artist, track.Title))
+		return
+	case err != nil:
+		h.log.Error("Failed to ingest audio", zap.Error(err), zap.String("file_name", fileName))
+		h.reply(m, i18n.T(lang, "ingest_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "ingest_done", indexed.Artist, indexed.Title, h.ingester.Rel(indexed.Path)))
+}
+
+func audioExtension(mime string) string {
+	switch mime {
+	case "audio/flac", "audio/x-flac":
+		return ".flac"
+	case "audio/mp4", "audio/x-m4a", "audio/m4a":
+		return ".m4a"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/opus":
+		return ".opus"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	default:
+		return ".mp3"
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/ingest"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func createIngestTestHandler(t *testing.T) (*handler, *fakeDatabase, *testSinks) {
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.ingester = ingest.NewIngester(database, t.TempDir(), t.TempDir(), zap.NewNop())
+	h.getFileFn = func(*telebot.File) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("audio")), nil
+	}
+	return h, database, sinks
+}
+
+func TestHandleAudioAddsToLibrary(t *testing.T) {
+	h, database, sinks := createIngestTestHandler(t)
+
+	m := testMessage("")
+	m.Audio = &telebot.Audio{Performer: "Artist", Title: "Song", FileName: "track01.ogg", MIME: "audio/ogg"}
+	h.HandleAudio(m)
+
+	if len(database.musicFiles) != 1 || database.musicFiles[0].Artist != "Artist" || database.musicFiles[0].Title != "Song" {
+		t.Fatalf("expected the upload to be indexed, got %+v", database.musicFiles)
+	}
+	want := i18n.T(i18n.Ukrainian, "ingest_done", "Artist", "Song", filepath.Join("Artist", "Artist - Song.ogg"))
+	if len(sinks.replies) != 1 || sinks.replies[0] != want {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleDocumentTakesAudioFiles(t *testing.T) {
+	h, database, sinks := createIngestTestHandler(t)
+
+	m := testMessage("")
+	m.Document = &telebot.Document{FileName: "Artist - Song.flac"}
+	h.HandleDocument(m)
+	h.HandleDocument(m)
+
+	if len(database.musicFiles) != 1 {
+		t.Fatalf("expected one indexed file, got %+v", database.musicFiles)
+	}
+	if len(sinks.replies) != 2 || sinks.replies[1] != i18n.T(i18n.Ukrainian, "ingest_exists", "Artist", "Song") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleAudioRefusals(t *testing.T) {
+	h, database, sinks := createIngestTestHandler(t)
+	h.getFileFn = func(*telebot.File) (io.ReadCloser, error) {
+		t.Fatal("file should not be downloaded")
+		return nil, nil
+	}
+
+	big := testMessage("")
+	big.Audio = &telebot.Audio{Performer: "Artist", Title: "Long", FileName: "long.mp3", File: telebot.File{FileSize: ingest.MaxDownloadSize + 1}}
+	h.HandleAudio(big)
+
+	h.ingester = nil
+	m := testMessage("")
+	m.Audio = &telebot.Audio{Performer: "Artist", Title: "Song", FileName: "song.mp3"}
+	h.HandleAudio(m)
+
+	if len(database.musicFiles) != 0 {
+		t.Fatalf("expected nothing to be indexed, got %+v", database.musicFiles)
+	}
+	if len(sinks.replies) != 2 ||
+		!strings.Contains(sinks.replies[0], "20.0 MiB") ||
+		sinks.replies[1] != i18n.T(i18n.Ukrainian, "ingest_disabled") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
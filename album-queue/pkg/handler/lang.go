@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// userLang returns the language picked with /lang, otherwise the language of
+// the user's Telegram client, otherwise the default one
+func (h *handler) userLang(ctx context.Context, userID int64, languageCode string) i18n.Lang {
+	saved, err := h.db.GetUserLanguage(ctx, userID)
+	if err != nil {
+		h.log.Error("Failed to get user language", zap.Error(err), zap.Int64("user_id", userID))
+	}
+	if lang, ok := i18n.Parse(saved); ok {
+		return lang
+	}
+	if lang, ok := i18n.Parse(languageCode); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+func (h *handler) lang(user *telebot.User) i18n.Lang {
+	if user == nil {
+		return i18n.Default
+	}
+	return h.userLang(context.Background(), user.ID, user.LanguageCode)
+}
+
+func (h *handler) HandleLang(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	args := commandArgs(m.Text)
+	if args == "" {
+		h.reply(m, i18n.T(lang, "lang_current", lang.Name()))
+		return
+	}
+
+	picked, ok := i18n.Parse(args)
+	if !ok {
+		h.reply(m, i18n.T(lang, "lang_unknown"))
+		return
+	}
+
+	if err := h.db.SetUserLanguage(context.Background(), m.Sender.ID, string(picked)); err != nil {
+		h.log.Error("Failed to save user language", zap.Error(err))
+		h.reply(m, i18n.T(lang, "lang_save_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(picked, "lang_saved"))
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func TestHandleLangSavesLanguage(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleLang(testMessage("/lang en"))
+
+	if db.languages[1] != "en" {
+		t.Fatalf("expected English to be saved, got %q", db.languages[1])
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "Speaking English") {
+		t.Fatalf("expected reply in English, got %#v", sinks.replies)
+	}
+
+	h.HandleUnlink(testMessage("/unlink"))
+	if len(sinks.replies) != 2 || sinks.replies[1] != "Spotify isn't linked anyway." {
+		t.Fatalf("expected later replies in English, got %#v", sinks.replies)
+	}
+}
+
+func TestHandleLangRejectsUnknownLanguage(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleLang(testMessage("/lang de"))
+
+	if len(db.languages) != 0 {
+		t.Fatalf("expected nothing saved, got %v", db.languages)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "не знаю такої мови") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleLangShowsCurrentLanguage(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleLang(testMessage("/lang"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "українська") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestTelegramLanguageUsedUntilLanguagePicked(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	m := &telebot.Message{Text: "/redownload", Sender: &telebot.User{ID: 1, LanguageCode: "en-GB"}}
+	h.HandleRedownload(m)
+
+	db.languages = map[int64]string{1: "uk"}
+	h.HandleRedownload(m)
+
+	if len(sinks.replies) != 2 {
+		t.Fatalf("expected two replies, got %d", len(sinks.replies))
+	}
+	if !strings.HasPrefix(sinks.replies[0], "I don't get this command") {
+		t.Fatalf("expected English from the Telegram client, got %q", sinks.replies[0])
+	}
+	if !strings.HasPrefix(sinks.replies[1], "не розумію цю команду") {
+		t.Fatalf("expected the picked language to win, got %q", sinks.replies[1])
+	}
+}
+
+func TestHandleTextPluralisesTrackCount(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.sourceProvider = &fakeSourceProvider{name: "Record"}
+
+	h.HandleText(testMessage("https://artist.bandcamp.com/album/record"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "(0 треків)") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/playlistfile"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	// maxPlaylistFileSize leaves room for the playlists of a whole Spotify
+	// account export
+	maxPlaylistFileSize = 10 << 20
+
+	// importPlaylistsShown and importMissingShown keep the reply within one
+	// message, missing tracks are only listed for a single playlist
+	importPlaylistsShown = 20
+	importMissingShown   = 5
+)
+
+// importPlaylistFile imports the playlists of an uploaded M3U, CSV or Spotify
+// export file and replies with how much of each is in the library. The M3Us
+// are written by dynamic-playlists, which also queues the missing tracks when
+// the caption says "queue". A CSV without artist and title columns is a list
+// of links and is queued like a .txt file.
+func (h *handler) importPlaylistFile(m *telebot.Message, doc *telebot.Document) {
+	lang := h.lang(m.Sender)
+
+	if doc.FileSize > maxPlaylistFileSize {
+		h.reply(m, i18n.T(lang, "bulk_file_too_big", maxPlaylistFileSize>>10))
+		return
+	}
+
+	h.log.Info("Received playlist file", zap.String("file_name", doc.FileName), zap.Int("size", doc.FileSize))
+
+	data, ok := h.readDocument(m, lang, doc, maxPlaylistFileSize)
+	if !ok {
+		return
+	}
+
+	playlists, err := playlistfile.Parse(doc.FileName, bytes.NewReader(data))
+	switch {
+	case errors.Is(err, playlistfile.ErrNoColumns):
+		h.queueFileURLs(m, lang, data)
+		return
+	case errors.Is(err, playlistfile.ErrNoTracks):
+		h.reply(m, i18n.T(lang, "import_empty"))
+		return
+	case err != nil:
+		h.log.Error("Failed to parse playlist file", zap.Error(err), zap.String("file_name", doc.FileName))
+		h.reply(m, i18n.T(lang, "import_unreadable"))
+		return
+	}
+
+	ctx := context.Background()
+	queueMissing := slices.Contains(strings.Fields(strings.ToLower(m.Caption)), "queue")
+
+	aliases, err := h.db.GetArtistAliases(ctx)
+	if err != nil {
+		h.log.Warn("Failed to load artist aliases", zap.Error(err))
+	}
+
+	found := make([]int, len(playlists))
+	missing := 0
+	for i, parsed := range playlists {
+		found[i], err = h.matchImportedTracks(ctx, parsed.Tracks, aliases)
+		if err != nil {
+			h.log.Error("Failed to match imported tracks", zap.Error(err), zap.String("name", parsed.Name))
+			h.reply(m, i18n.T(lang, "import_failed"))
+			return
+		}
+		missing += len(parsed.Tracks) - found[i]
+	}
+
+	// the missing tracks are queued by dynamic-playlists, count them now
+	if queueMissing && missing > 0 && !h.checkQuota(ctx, m.Sender.ID, lang, h.replyTo(m), func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, missing)
+	}) {
+		return
+	}
+
+	var response strings.Builder
+	for i, parsed := range playlists {
+		err = h.db.SaveImportedPlaylist(ctx, models.ImportedPlaylist{
+			CreatorID:    m.Sender.ID,
+			Name:         parsed.Name,
+			FileName:     filepath.Base(doc.FileName),
+			Tracks:       parsed.Tracks,
+			QueueMissing: queueMissing,
+		})
+		if err != nil {
+			h.log.Error("Failed to save imported playlist", zap.Error(err), zap.String("name", parsed.Name))
+			h.reply(m, i18n.T(lang, "import_failed"))
+			return
+		}
+
+		switch {
+		case i < importPlaylistsShown:
+			response.WriteString(i18n.T(lang, "import_playlist", parsed.Name, found[i], len(parsed.Tracks)))
+		case i == importPlaylistsShown:
+			response.WriteString(i18n.T(lang, "import_more", len(playlists)-importPlaylistsShown))
+		}
+		if len(playlists) == 1 {
+			writeMissingTracks(&response, lang, parsed.Tracks)
+		}
+	}
+
+	h.log.Info("Imported playlist file",
+		zap.Int64("user_id", m.Sender.ID),
+		zap.String("file_name", doc.FileName),
+		zap.Int("playlists", len(playlists)),
+		zap.Int("missing", missing),
+		zap.Bool("queue_missing", queueMissing))
+
+	response.WriteString(i18n.T(lang, "import_written"))
+	switch {
+	case missing > 0 && queueMissing:
+		response.WriteString(i18n.T(lang, "import_queue_missing", i18n.N(lang, "tracks", missing)))
+	case missing > 0:
+		response.WriteString(i18n.T(lang, "import_queue_hint"))
+	}
+
+	h.reply(m, response.String())
+}
+
+// matchImportedTracks sets InLibrary on the tracks found in the library and
+// returns how many there are
+func (h *handler) matchImportedTracks(ctx context.Context, tracks []models.ImportedTrack, aliases models.ArtistAliases) (int, error) {
+	artists := make([]string, 0, len(tracks))
+	titles := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		artists = append(artists, strings.ToLower(track.Artist))
+		titles = append(titles, strings.ToLower(track.Title))
+	}
+
+	files, err := h.db.FindMusicFiles(ctx, artists, titles)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(models.MatchImportedTracks(tracks, files, aliases)), nil
+}
+
+// writeMissingTracks lists the first tracks of the playlist that aren't in
+// the library
+func writeMissingTracks(b *strings.Builder, lang i18n.Lang, tracks []models.ImportedTrack) {
+	shown, total := 0, 0
+	for _, track := range tracks {
+		if track.InLibrary {
+			continue
+		}
+		total++
+		if shown < importMissingShown {
+			b.WriteString(fmt.Sprintf("      • %s - %s\n", track.Artist, track.Title))
+			shown++
+		}
+	}
+	if total > shown {
+		b.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", total-shown)))
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	models "github.com/supperdoggy/spot-models"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func playlistDocument(h *handler, fileName, content, caption string) *telebot.Message {
+	h.getFileFn = func(*telebot.File) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+
+	m := testMessage("")
+	m.Caption = caption
+	m.Document = &telebot.Document{FileName: fileName, File: telebot.File{FileSize: len(content)}}
+	return m
+}
+
+func TestHandleDocumentImportsPlaylist(t *testing.T) {
+	db := &fakeDatabase{musicFiles: []models.MusicFile{{Artist: "Daft Punk", Title: "Get Lucky", Path: "/music/lucky.mp3"}}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleDocument(playlistDocument(h, "Road Trip.m3u8",
+		"#EXTM3U\n#EXTINF:-1,Daft Punk - Get Lucky\nlucky.mp3\n#EXTINF:-1,Radiohead - Creep\ncreep.mp3\n", ""))
+
+	if len(db.importedPlaylists) != 1 {
+		t.Fatalf("expected one imported playlist, got %+v", db.importedPlaylists)
+	}
+	playlist := db.importedPlaylists[0]
+	if playlist.Name != "Road Trip" || playlist.CreatorID != 1 || playlist.QueueMissing || len(playlist.Tracks) != 2 {
+		t.Fatalf("unexpected playlist: %+v", playlist)
+	}
+	if !playlist.Tracks[0].InLibrary || playlist.Tracks[1].InLibrary {
+		t.Fatalf("expected only the first track in the library, got %+v", playlist.Tracks)
+	}
+
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected one reply, got %#v", sinks.replies)
+	}
+	reply := sinks.replies[0]
+	for _, want := range []string{"Road Trip: 1 з 2", "Radiohead - Creep", i18n.T(i18n.Ukrainian, "import_queue_hint")} {
+		if !strings.Contains(reply, want) {
+			t.Fatalf("expected %q in reply: %q", want, reply)
+		}
+	}
+}
+
+func TestHandleDocumentImportsExportWithQueueCaption(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleDocument(playlistDocument(h, "Playlist1.json", `{"playlists":[
+		{"name":"Gym","items":[{"track":{"trackName":"Get Lucky","artistName":"Daft Punk","trackUri":"spotify:track:t1"}}]},
+		{"name":"Chill","items":[{"track":{"trackName":"Teardrop","artistName":"Massive Attack","trackUri":"spotify:track:t2"}}]}
+	]}`, "Queue please"))
+
+	if len(db.importedPlaylists) != 2 || !db.importedPlaylists[0].QueueMissing || !db.importedPlaylists[1].QueueMissing {
+		t.Fatalf("expected both playlists to queue their missing tracks, got %+v", db.importedPlaylists)
+	}
+	if url := db.importedPlaylists[1].Tracks[0].SpotifyURL; url != "https://open.spotify.com/track/t2" {
+		t.Fatalf("unexpected track url: %q", url)
+	}
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected the downloads to be left to dynamic-playlists, got %+v", db.newRequests)
+	}
+
+	reply := sinks.replies[0]
+	if !strings.Contains(reply, "Gym: 0 з 1") || !strings.Contains(reply, "Chill: 0 з 1") || !strings.Contains(reply, "2 треки") {
+		t.Fatalf("unexpected reply: %q", reply)
+	}
+	if strings.Contains(reply, "Massive Attack - Teardrop") {
+		t.Fatalf("expected missing tracks to be listed only for a single playlist: %q", reply)
+	}
+}
+
+func TestHandleDocumentQueueCaptionEnforcesQuota(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{TracksToday: 9}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	m := playlistDocument(h, "mix.m3u", "#EXTM3U\n#EXTINF:-1,Daft Punk - Get Lucky\nlucky.mp3\n#EXTINF:-1,Radiohead - Creep\ncreep.mp3\n", "queue")
+	m.Sender.ID = 2
+	h.HandleDocument(m)
+
+	if len(db.importedPlaylists) != 0 {
+		t.Fatalf("expected nothing to be imported over the quota, got %+v", db.importedPlaylists)
+	}
+	if len(sinks.replies) != 1 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "quota_tracks_per_day", 10, 1) {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+
+	m = playlistDocument(h, "mix.m3u", "#EXTM3U\n#EXTINF:-1,Radiohead - Creep\ncreep.mp3\n", "queue")
+	m.Sender.ID = 2
+	h.HandleDocument(m)
+	if len(db.importedPlaylists) != 1 || !db.importedPlaylists[0].QueueMissing {
+		t.Fatalf("expected a playlist within the quota to be imported, got %+v", db.importedPlaylists)
+	}
+}
+
+func TestHandleDocumentUnreadablePlaylist(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleDocument(playlistDocument(h, "broken.json", "{", ""))
+	h.HandleDocument(playlistDocument(h, "empty.m3u", "#EXTM3U\n", ""))
+
+	if len(db.importedPlaylists) != 0 {
+		t.Fatalf("expected nothing to be imported, got %+v", db.importedPlaylists)
+	}
+	if len(sinks.replies) != 2 ||
+		sinks.replies[0] != i18n.T(i18n.Ukrainian, "import_unreadable") ||
+		sinks.replies[1] != i18n.T(i18n.Ukrainian, "import_empty") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	previewConfirmCallbackUnique = "preview_confirm"
+	previewCancelCallbackUnique  = "preview_cancel"
+
+	// previewTTL is how long the confirm button of a coverage preview works
+	previewTTL          = time.Hour
+	previewMissingShown = 10
+)
+
+var (
+	previewConfirmCallbackEndpoint = &telebot.InlineButton{Unique: previewConfirmCallbackUnique}
+	previewCancelCallbackEndpoint  = &telebot.InlineButton{Unique: previewCancelCallbackUnique}
+)
+
+func PreviewConfirmCallbackEndpoint() telebot.CallbackEndpoint {
+	return previewConfirmCallbackEndpoint
+}
+
+func PreviewCancelCallbackEndpoint() telebot.CallbackEndpoint {
+	return previewCancelCallbackEndpoint
+}
+
+// pendingQueue is an album or playlist waiting for the user to confirm
+// queueing its missing tracks
+type pendingQueue struct {
+	userID     int64
+	url        string
+	name       string
+	objectType spotify.SpotifyObjectType
+	missing    []spotify.TrackMetadata
+	createdAt  time.Time
+}
+
+// previewStore keeps pending previews in memory, a restart only means the
+// user has to send the link again. The zero value is ready to use.
+type previewStore struct {
+	mu      sync.Mutex
+	pending map[string]pendingQueue
+}
+
+func (s *previewStore) add(p pendingQueue) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == nil {
+		s.pending = make(map[string]pendingQueue)
+	}
+	for id, old := range s.pending {
+		if time.Since(old.createdAt) > previewTTL {
+			delete(s.pending, id)
+		}
+	}
+
+	id := uuid.NewV4().String()
+	s.pending[id] = p
+	return id
+}
+
+// get returns the pending preview of the user, it stays pending until it is
+// removed so a failed confirm can be tried again
+func (s *previewStore) get(id string, userID int64) (pendingQueue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pending[id]
+	if !ok || p.userID != userID {
+		return pendingQueue{}, false
+	}
+	if time.Since(p.createdAt) > previewTTL {
+		delete(s.pending, id)
+		return pendingQueue{}, false
+	}
+	return p, true
+}
+
+func (s *previewStore) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, id)
+}
+
+// previewCoverage shows how much of the album or playlist is already in the
+// library and asks before queueing the rest. It reports false when there is
+// nothing to preview because none of the tracks are in the library yet.
+func (h *handler) previewCoverage(ctx context.Context, m *telebot.Message, lang i18n.Lang, rawURL, name string, objectType spotify.SpotifyObjectType, tracks []spotify.TrackMetadata) bool {
+	missingCount, err := h.markFoundTracks(ctx, tracks)
+	if err != nil {
+		h.log.Error("Failed to compare with library", zap.Error(err))
+		return false
+	}
+	if missingCount == len(tracks) {
+		return false
+	}
+	if missingCount == 0 {
+		h.reply(m, i18n.T(lang, "preview_complete", name, len(tracks), len(tracks)))
+		return true
+	}
+
+	missing := unfoundTracks(tracks)
+	id := h.previews.add(pendingQueue{
+		userID:     m.Sender.ID,
+		url:        rawURL,
+		name:       name,
+		objectType: objectType,
+		missing:    missing,
+		createdAt:  time.Now(),
+	})
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "preview_header", name, len(tracks)-missingCount, len(tracks)))
+	for i, track := range missing {
+		if i == previewMissingShown {
+			response.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", missingCount-previewMissingShown)))
+			break
+		}
+		response.WriteString(fmt.Sprintf("      • %s - %s\n", track.Artist, track.Title))
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data(i18n.T(lang, "preview_confirm_button", missingCount), previewConfirmCallbackUnique, id),
+		markup.Data(i18n.T(lang, "preview_cancel_button"), previewCancelCallbackUnique, id),
+	))
+
+	if err := h.sendFailedPageFn(m, response.String(), markup); err != nil {
+		h.log.Error("Failed to send coverage preview", zap.Error(err))
+	}
+	return true
+}
+
+// unfoundTracks returns the tracks markFoundTracks didn't find in the library
+func unfoundTracks(tracks []spotify.TrackMetadata) []spotify.TrackMetadata {
+	missing := make([]spotify.TrackMetadata, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.Found {
+			missing = append(missing, track)
+		}
+	}
+	return missing
+}
+
+// HandlePreviewConfirm queues the missing tracks of a previewed album or playlist
+func (h *handler) HandlePreviewConfirm(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	id := strings.TrimSpace(c.Data)
+	pending, ok := h.previews.get(id, c.Sender.ID)
+	if !ok {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "preview_expired"), true)
+		return
+	}
+
+	active, err := h.db.HasActiveRequestByURL(ctx, pending.url)
+	if err != nil {
+		h.log.Error("Failed to check active request", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_add_failed"), true)
+		return
+	}
+	if active {
+		h.previews.remove(id)
+		h.closePreview(c, i18n.T(lang, "already_queued"))
+		return
+	}
+
+	deny := func(text string) { _ = h.respondCallbackFn(c, text, true) }
+	if !h.checkQuota(ctx, c.Sender.ID, lang, deny, func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, len(pending.missing))
+	}) {
+		return
+	}
+
+	requestID, err := h.db.NewTracksRequest(ctx, pending.url, pending.name, c.Sender.ID, pending.objectType, pending.missing)
+	if err != nil {
+		h.log.Error("Failed to add tracks request to database", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_add_failed"), true)
+		return
+	}
+	h.previews.remove(id)
+
+	h.requestCreated(webhook.RequestData{ID: requestID, URL: pending.url, Name: pending.name, CreatorID: c.Sender.ID, ObjectType: string(pending.objectType), Tracks: len(pending.missing)})
+	h.closePreview(c, i18n.T(lang, "queued", pending.name, i18n.N(lang, "tracks", len(pending.missing))))
+}
+
+func (h *handler) HandlePreviewCancel(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id := strings.TrimSpace(c.Data)
+	if _, ok := h.previews.get(id, c.Sender.ID); !ok {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "preview_expired"), true)
+		return
+	}
+	h.previews.remove(id)
+
+	h.closePreview(c, i18n.T(lang, "preview_cancelled"))
+}
+
+// closePreview replaces the preview with the outcome so the buttons go away
+func (h *handler) closePreview(c *telebot.Callback, text string) {
+	if c.Message != nil {
+		if err := h.editFailedPageFn(c.Message, text, nil); err != nil {
+			h.log.Error("Failed to edit coverage preview", zap.Error(err))
+		}
+	}
+	_ = h.respondCallbackFn(c, "", false)
+}
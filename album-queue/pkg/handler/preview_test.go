@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const previewTestURL = "https://open.spotify.com/playlist/mix"
+
+func createPreviewTestHandler(library []models.MusicFile) (*handler, *fakeDatabase, *testSinks) {
+	db := &fakeDatabase{
+		musicFiles: library,
+		users:      map[int64]models.User{2: {ID: 2, Role: models.RoleMember}},
+	}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+	h.spotifyService = &fakeSpotifyService{
+		names: map[string]string{previewTestURL: "Mix"},
+		tracks: map[string][]spotify.TrackMetadata{previewTestURL: {
+			{SpotifyURL: "https://open.spotify.com/track/1", Artist: "Artist", Title: "One"},
+			{SpotifyURL: "https://open.spotify.com/track/2", Artist: "Artist", Title: "Two"},
+			{SpotifyURL: "https://open.spotify.com/track/3", Artist: "Artist", Title: "Three"},
+		}},
+	}
+	return h, db, sinks
+}
+
+func previewCallback(userID int64, sinks *testSinks, unique string) *telebot.Callback {
+	for _, row := range sinks.sentPages[len(sinks.sentPages)-1].markup.InlineKeyboard {
+		for _, btn := range row {
+			if btn.Unique == unique {
+				return &telebot.Callback{Sender: &telebot.User{ID: userID}, Message: &telebot.Message{}, Data: btn.Data}
+			}
+		}
+	}
+	return nil
+}
+
+func TestHandleTextPreviewsPartialCoverage(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler([]models.MusicFile{{Artist: "Artist", Title: "One"}})
+
+	h.HandleText(userMessage(2, previewTestURL))
+
+	if len(db.newRequests) != 0 || sinks.webhookCalls != 0 {
+		t.Fatalf("expected nothing queued before confirming, got %+v", db.newRequests)
+	}
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected a preview, got pages %d replies %#v", len(sinks.sentPages), sinks.replies)
+	}
+	text := sinks.sentPages[0].text
+	if !strings.Contains(text, "1/3") || !strings.Contains(text, "Artist - Two") || strings.Contains(text, "Artist - One") {
+		t.Fatalf("unexpected preview: %q", text)
+	}
+
+	if c := previewCallback(3, sinks, previewConfirmCallbackUnique); c != nil {
+		h.HandlePreviewConfirm(c)
+	}
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected another user not to confirm the preview, got %+v", db.newRequests)
+	}
+
+	h.HandlePreviewConfirm(previewCallback(2, sinks, previewConfirmCallbackUnique))
+
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected one request, got %+v", db.newRequests)
+	}
+	req := db.newRequests[0]
+	if !req.tracksOnly || req.url != previewTestURL || req.expectedTrackCount != 2 || req.creatorID != 2 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	for _, track := range req.trackMetadata {
+		if track.Title == "One" {
+			t.Fatalf("expected only missing tracks, got %+v", req.trackMetadata)
+		}
+	}
+	if sinks.webhookCalls != 1 || len(sinks.editedPages) != 1 || sinks.editedPages[0].markup != nil {
+		t.Fatalf("expected webhook and closed preview, got webhook %d edits %+v", sinks.webhookCalls, sinks.editedPages)
+	}
+	if data := sinks.webhooks[0].Data.(webhook.RequestData); data.ID != "request-1" {
+		t.Fatalf("expected the request id in the webhook, got %+v", data)
+	}
+
+	h.HandlePreviewConfirm(previewCallback(2, sinks, previewConfirmCallbackUnique))
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected the preview to be used up, got %+v", db.newRequests)
+	}
+}
+
+func TestHandlePreviewConfirmKeepsPreviewOnFailure(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler([]models.MusicFile{{Artist: "Artist", Title: "One"}})
+	db.newRequestErr = errors.New("insert failed")
+
+	h.HandleText(userMessage(2, previewTestURL))
+	h.HandlePreviewConfirm(previewCallback(2, sinks, previewConfirmCallbackUnique))
+
+	if last := sinks.callbackAcks[len(sinks.callbackAcks)-1]; !last.showAlert || sinks.webhookCalls != 0 {
+		t.Fatalf("expected the failed confirm to be reported, got %+v webhook %d", last, sinks.webhookCalls)
+	}
+
+	db.newRequestErr = nil
+	h.HandlePreviewConfirm(previewCallback(2, sinks, previewConfirmCallbackUnique))
+
+	if len(db.newRequests) != 2 || sinks.webhookCalls != 1 || len(sinks.editedPages) != 1 {
+		t.Fatalf("expected the retry to queue the preview, got %+v webhook %d", db.newRequests, sinks.webhookCalls)
+	}
+}
+
+func TestHandleTextPreviewIgnoresLibraryCase(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler([]models.MusicFile{
+		{Artist: "ARTIST", Title: "one"},
+		{Artist: "artist", Title: "TwO"},
+		{Artist: "Other Artist", Title: "Three"},
+	})
+
+	h.HandleText(userMessage(2, previewTestURL))
+
+	if len(db.newRequests) != 0 || len(sinks.sentPages) != 1 {
+		t.Fatalf("expected a preview, got requests %+v replies %#v", db.newRequests, sinks.replies)
+	}
+	text := sinks.sentPages[0].text
+	if !strings.Contains(text, "2/3") || !strings.Contains(text, "Artist - Three") || strings.Contains(text, "Artist - Two") {
+		t.Fatalf("unexpected preview: %q", text)
+	}
+}
+
+func TestHandleTextPreviewCancel(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler([]models.MusicFile{{Artist: "Artist", Title: "One"}})
+
+	h.HandleText(userMessage(2, previewTestURL))
+	h.HandlePreviewCancel(previewCallback(2, sinks, previewCancelCallbackUnique))
+	h.HandlePreviewConfirm(previewCallback(2, sinks, previewConfirmCallbackUnique))
+
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected nothing queued after cancel, got %+v", db.newRequests)
+	}
+	if last := sinks.callbackAcks[len(sinks.callbackAcks)-1]; !last.showAlert {
+		t.Fatalf("expected confirm after cancel to be rejected, got %+v", last)
+	}
+}
+
+func TestHandleTextSkipsCompleteAlbums(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler([]models.MusicFile{
+		{Artist: "Artist", Title: "One"},
+		{Artist: "Artist", Title: "Two"},
+		{Artist: "Artist", Title: "Three"},
+	})
+
+	h.HandleText(userMessage(2, previewTestURL))
+
+	if len(db.newRequests) != 0 || len(sinks.sentPages) != 0 {
+		t.Fatalf("expected nothing queued or previewed, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "3/3") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleTextQueuesUncoveredAlbumsDirectly(t *testing.T) {
+	h, db, sinks := createPreviewTestHandler(nil)
+
+	h.HandleText(userMessage(2, previewTestURL))
+
+	if len(db.newRequests) != 1 || db.newRequests[0].tracksOnly || db.newRequests[0].expectedTrackCount != 3 {
+		t.Fatalf("expected the whole playlist to be queued, got %+v", db.newRequests)
+	}
+	if len(sinks.sentPages) != 0 || sinks.webhookCalls != 1 {
+		t.Fatalf("expected no preview, got %d pages", len(sinks.sentPages))
+	}
+}
@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	queueCancelCallbackUnique  = "queue_cancel"
+	queueRetryCallbackUnique   = "queue_retry"
+	queueBumpCallbackUnique    = "queue_bump"
+	queueMissingCallbackUnique = "queue_missing"
+
+	// Telegram allows 100 buttons per message, four go to each request
+	maxQueueButtonRows = 20
+	queuePreviewTracks = 5
+	maxMissingTracks   = 50
+)
+
+var (
+	queueCancelCallbackEndpoint  = &telebot.InlineButton{Unique: queueCancelCallbackUnique}
+	queueRetryCallbackEndpoint   = &telebot.InlineButton{Unique: queueRetryCallbackUnique}
+	queueBumpCallbackEndpoint    = &telebot.InlineButton{Unique: queueBumpCallbackUnique}
+	queueMissingCallbackEndpoint = &telebot.InlineButton{Unique: queueMissingCallbackUnique}
+)
+
+func QueueCancelCallbackEndpoint() telebot.CallbackEndpoint {
+	return queueCancelCallbackEndpoint
+}
+
+func QueueRetryCallbackEndpoint() telebot.CallbackEndpoint {
+	return queueRetryCallbackEndpoint
+}
+
+func QueueBumpCallbackEndpoint() telebot.CallbackEndpoint {
+	return queueBumpCallbackEndpoint
+}
+
+func QueueMissingCallbackEndpoint() telebot.CallbackEndpoint {
+	return queueMissingCallbackEndpoint
+}
+
+func (h *handler) HandleQueue(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	text, markup, err := h.renderQueue(context.Background(), lang)
+	if err != nil {
+		h.log.Error("Failed to get active download requests", zap.Error(err))
+		h.reply(m, i18n.T(lang, "queue_fetch_failed"))
+		return
+	}
+
+	if err := h.sendFailedPageFn(m, text, markup); err != nil {
+		h.log.Error("Failed to send queue", zap.Error(err))
+	}
+}
+
+func (h *handler) HandleQueueCancel(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	request, ok := h.queueRequestFor(ctx, c, lang, true)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeactivateRequest(ctx, request.ID); err != nil {
+		h.log.Error("Failed to deactivate request", zap.Error(err), zap.String("id", request.ID))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_action_failed"), true)
+		return
+	}
+
+	h.log.Info("Request cancelled", zap.String("id", request.ID), zap.Int64("user_id", c.Sender.ID))
+	h.refreshQueue(ctx, c, lang)
+	_ = h.respondCallbackFn(c, i18n.T(lang, "queue_cancelled"), false)
+}
+
+func (h *handler) HandleQueueRetry(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	request, ok := h.queueRequestFor(ctx, c, lang, true)
+	if !ok {
+		return
+	}
+
+	if err := h.db.RetryRequest(ctx, request.ID); err != nil {
+		h.log.Error("Failed to retry request", zap.Error(err), zap.String("id", request.ID))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_action_failed"), true)
+		return
+	}
+
+	h.log.Info("Request retried", zap.String("id", request.ID), zap.Int64("user_id", c.Sender.ID))
+	h.requestCreated(webhook.NewRequestData(request))
+	h.refreshQueue(ctx, c, lang)
+	_ = h.respondCallbackFn(c, i18n.T(lang, "queue_retried"), false)
+}
+
+func (h *handler) HandleQueueBump(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	request, ok := h.queueRequestFor(ctx, c, lang, true)
+	if !ok {
+		return
+	}
+
+	priority, err := h.db.BumpRequestPriority(ctx, request.ID)
+	if err != nil {
+		h.log.Error("Failed to bump request priority", zap.Error(err), zap.String("id", request.ID))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_action_failed"), true)
+		return
+	}
+
+	h.log.Info("Request priority bumped", zap.String("id", request.ID), zap.Int("priority", priority), zap.Int64("user_id", c.Sender.ID))
+	h.refreshQueue(ctx, c, lang)
+	_ = h.respondCallbackFn(c, i18n.T(lang, "queue_bumped"), false)
+}
+
+// HandleQueueMissing sends the full list of tracks a request is still missing
+func (h *handler) HandleQueueMissing(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	request, ok := h.queueRequestFor(context.Background(), c, lang, false)
+	if !ok {
+		return
+	}
+
+	missing, _ := missingTracks(request)
+	if len(missing) == 0 {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_no_missing"), true)
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "queue_missing_header", request.Name, i18n.N(lang, "tracks", len(missing))))
+	for i, track := range missing {
+		if i == maxMissingTracks {
+			response.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", len(missing)-maxMissingTracks)))
+			break
+		}
+		response.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, track.Artist, track.Title))
+	}
+
+	if c.Message != nil {
+		if err := h.sendFailedPageFn(c.Message, response.String(), nil); err != nil {
+			h.log.Error("Failed to send missing tracks", zap.Error(err))
+		}
+	}
+
+	_ = h.respondCallbackFn(c, "", false)
+}
+
+// queueRequestFor loads the active request a queue button points at. With
+// manage set members may only touch their own requests, admins any.
+func (h *handler) queueRequestFor(ctx context.Context, c *telebot.Callback, lang i18n.Lang, manage bool) (models.DownloadQueueRequest, bool) {
+	request, err := h.db.GetDownloadRequest(ctx, strings.TrimSpace(c.Data))
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && !request.Active) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_request_gone"), true)
+		return models.DownloadQueueRequest{}, false
+	}
+	if err != nil {
+		h.log.Error("Failed to get download request", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_action_failed"), true)
+		return models.DownloadQueueRequest{}, false
+	}
+
+	if manage && request.CreatorID != c.Sender.ID {
+		if role, _ := h.userRole(ctx, c.Sender.ID); !role.Allows(models.RoleAdmin) {
+			_ = h.respondCallbackFn(c, i18n.T(lang, "queue_not_owner"), true)
+			return models.DownloadQueueRequest{}, false
+		}
+	}
+
+	return request, true
+}
+
+// refreshQueue redraws the /queue message a button was pressed on
+func (h *handler) refreshQueue(ctx context.Context, c *telebot.Callback, lang i18n.Lang) {
+	if c.Message == nil {
+		return
+	}
+
+	text, markup, err := h.renderQueue(ctx, lang)
+	if err != nil {
+		h.log.Error("Failed to render queue", zap.Error(err))
+		return
+	}
+
+	if err := h.editFailedPageFn(c.Message, text, markup); err != nil {
+		h.log.Error("Failed to edit queue", zap.Error(err))
+	}
+}
+
+// missingTracks returns the tracks of the request that are neither found nor
+// skipped, and how many were skipped
+func missingTracks(r models.DownloadQueueRequest) ([]spotify.TrackMetadata, int) {
+	missing := []spotify.TrackMetadata{}
+	skipped := 0
+	for _, track := range r.TrackMetadata {
+		if track.Skipped {
+			skipped++
+		} else if !track.Found {
+			missing = append(missing, track)
+		}
+	}
+	return missing, skipped
+}
+
+func (h *handler) renderQueue(ctx context.Context, lang i18n.Lang) (string, *telebot.ReplyMarkup, error) {
+	requests, err := h.db.GetActiveRequests(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	playlists, err := h.db.GetActivePlaylists(ctx)
+	if err != nil {
+		h.log.Error("Failed to get active playlist requests", zap.Error(err))
+		// Continue anyway, just log the error
+	}
+
+	if len(requests) == 0 && len(playlists) == 0 {
+		return i18n.T(lang, "queue_empty"), nil, nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, min(len(requests), maxQueueButtonRows))
+
+	response := i18n.T(lang, "queue_header")
+	if len(requests) > 0 {
+		response += i18n.T(lang, "queue_actions")
+	}
+	for i, r := range requests {
+		response += fmt.Sprintf("%d. 📀 %s\n", i+1, r.Name)
+		if !r.Source.IsSpotify() {
+			response += i18n.T(lang, "queue_source", r.Source)
+		}
+		if r.ExpectedTrackCount > 0 {
+			downloaded := r.FoundTrackCount
+			percentage := float64(downloaded) / float64(r.ExpectedTrackCount) * 100
+
+			response += i18n.T(lang, "queue_downloaded", downloaded, r.ExpectedTrackCount, percentage)
+
+			missing, skippedCount := missingTracks(r)
+			remaining := len(missing)
+
+			if remaining > 0 {
+				response += i18n.T(lang, "queue_remaining", i18n.N(lang, "tracks", remaining))
+
+				// Show the first tracks that need to be downloaded, the rest are behind the button
+				response += i18n.T(lang, "queue_to_download")
+				for _, track := range missing[:min(remaining, queuePreviewTracks)] {
+					response += fmt.Sprintf("      • %s - %s\n", track.Artist, track.Title)
+				}
+				if remaining > queuePreviewTracks {
+					response += i18n.T(lang, "queue_more", i18n.N(lang, "tracks", remaining-queuePreviewTracks))
+				}
+			} else {
+				response += i18n.T(lang, "queue_all_downloaded")
+			}
+
+			if skippedCount > 0 {
+				response += i18n.T(lang, "queue_skipped", i18n.N(lang, "tracks", skippedCount))
+			}
+		} else {
+			response += i18n.T(lang, "queue_waiting")
+		}
+
+		if r.Errored {
+			response += i18n.T(lang, "queue_errors", r.RetryCount)
+		}
+		response += "\n"
+
+		if i < maxQueueButtonRows {
+			rows = append(rows, markup.Row(
+				markup.Data(i18n.T(lang, "queue_cancel_button", i+1), queueCancelCallbackUnique, r.ID),
+				markup.Data(i18n.T(lang, "queue_retry_button", i+1), queueRetryCallbackUnique, r.ID),
+				markup.Data(i18n.T(lang, "queue_bump_button", i+1), queueBumpCallbackUnique, r.ID),
+				markup.Data(i18n.T(lang, "queue_missing_button", i+1), queueMissingCallbackUnique, r.ID),
+			))
+		}
+	}
+
+	// Add playlist requests
+	if len(playlists) > 0 {
+		if len(requests) > 0 {
+			response += "---\n\n"
+		}
+		response += i18n.T(lang, "queue_playlists_header")
+		for _, p := range playlists {
+			// Try to get playlist name
+			playlistName, err := h.spotifyService.GetObjectName(ctx, p.SpotifyURL)
+			if err != nil {
+				h.log.Error("Failed to get playlist name", zap.Error(err))
+				playlistName = p.SpotifyURL
+			}
+
+			response += fmt.Sprintf("🎵 %s\n", playlistName)
+			response += fmt.Sprintf("   📎 URL: %s\n", p.SpotifyURL)
+			if p.NoPull {
+				response += i18n.T(lang, "queue_playlist_nopull")
+			} else {
+				response += i18n.T(lang, "queue_playlist_pull")
+			}
+			if p.Errored {
+				response += i18n.T(lang, "queue_errors", p.RetryCount)
+			}
+			response += "\n"
+		}
+	}
+
+	if len(rows) == 0 {
+		return response, nil, nil
+	}
+
+	markup.Inline(rows...)
+	return response, markup, nil
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func queueTestDatabase() *fakeDatabase {
+	return &fakeDatabase{
+		users: map[int64]models.User{2: {ID: 2, Role: models.RoleMember}},
+		requests: map[string]models.DownloadQueueRequest{
+			"mine": {ID: "mine", CreatorID: 2, Name: "Mine", Active: true, ExpectedTrackCount: 2, TrackMetadata: []spotify.TrackMetadata{
+				{Artist: "Artist", Title: "Found", Found: true},
+				{Artist: "Artist", Title: "Missing"},
+			}},
+			"theirs": {ID: "theirs", CreatorID: 3, Name: "Theirs", Active: true},
+			"done":   {ID: "done", CreatorID: 2, Name: "Done"},
+		},
+	}
+}
+
+func queueCallback(userID int64, data string) *telebot.Callback {
+	return &telebot.Callback{Sender: &telebot.User{ID: userID}, Message: &telebot.Message{}, Data: data}
+}
+
+func TestHandleQueueRendersButtonsPerRequest(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(queueTestDatabase(), sinks)
+
+	h.HandleQueue(testMessage("/queue"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected one queue message, got %d", len(sinks.sentPages))
+	}
+
+	page := sinks.sentPages[0]
+	if !strings.Contains(page.text, "1. 📀 Mine") || !strings.Contains(page.text, "2. 📀 Theirs") || strings.Contains(page.text, "Done") {
+		t.Fatalf("unexpected queue text: %q", page.text)
+	}
+
+	rows := page.markup.InlineKeyboard
+	if len(rows) != 2 || len(rows[0]) != 4 {
+		t.Fatalf("unexpected keyboard: %+v", rows)
+	}
+
+	uniques := []string{queueCancelCallbackUnique, queueRetryCallbackUnique, queueBumpCallbackUnique, queueMissingCallbackUnique}
+	for i, btn := range rows[0] {
+		if btn.Unique != uniques[i] || btn.Data != "mine" {
+			t.Fatalf("unexpected button %d: %+v", i, btn)
+		}
+	}
+}
+
+func TestHandleQueueCancelChecksOwnership(t *testing.T) {
+	db := queueTestDatabase()
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleQueueCancel(queueCallback(2, "theirs"))
+	h.HandleQueueCancel(queueCallback(2, "mine"))
+	h.HandleQueueCancel(queueCallback(1, "theirs"))
+
+	if len(db.deactivated) != 2 || db.deactivated[0] != "mine" || db.deactivated[1] != "theirs" {
+		t.Fatalf("unexpected deactivated requests: %v", db.deactivated)
+	}
+	if !sinks.callbackAcks[0].showAlert || !strings.Contains(sinks.callbackAcks[0].text, "це не твій запит") {
+		t.Fatalf("unexpected callback ack: %+v", sinks.callbackAcks[0])
+	}
+	if len(sinks.editedPages) != 2 {
+		t.Fatalf("expected queue to be redrawn twice, got %d", len(sinks.editedPages))
+	}
+}
+
+func TestHandleQueueButtonsIgnoreFinishedRequests(t *testing.T) {
+	db := queueTestDatabase()
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleQueueBump(queueCallback(2, "done"))
+	h.HandleQueueRetry(queueCallback(2, "unknown"))
+
+	if len(db.bumped) != 0 || len(db.retried) != 0 {
+		t.Fatalf("expected no changes, bumped=%v retried=%v", db.bumped, db.retried)
+	}
+	for _, ack := range sinks.callbackAcks {
+		if !ack.showAlert || !strings.Contains(ack.text, "/queue") {
+			t.Fatalf("unexpected callback ack: %+v", ack)
+		}
+	}
+}
+
+func TestHandleQueueRetryAndBump(t *testing.T) {
+	db := queueTestDatabase()
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleQueueRetry(queueCallback(2, "mine"))
+	h.HandleQueueBump(queueCallback(2, "mine"))
+
+	if len(db.retried) != 1 || db.retried[0] != "mine" {
+		t.Fatalf("unexpected retried requests: %v", db.retried)
+	}
+	if len(db.bumped) != 1 || db.bumped[0] != "mine" {
+		t.Fatalf("unexpected bumped requests: %v", db.bumped)
+	}
+	if sinks.webhookCalls != 1 {
+		t.Fatalf("expected retry to trigger the webhook, got %d calls", sinks.webhookCalls)
+	}
+}
+
+func TestHandleQueueMissingListsTracks(t *testing.T) {
+	db := queueTestDatabase()
+	db.users[4] = models.User{ID: 4, Role: models.RoleReadOnly}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleQueueMissing(queueCallback(4, "mine"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected missing tracks message, got %d", len(sinks.sentPages))
+	}
+	text := sinks.sentPages[0].text
+	if !strings.Contains(text, "Artist - Missing") || strings.Contains(text, "Artist - Found") {
+		t.Fatalf("unexpected missing tracks: %q", text)
+	}
+}
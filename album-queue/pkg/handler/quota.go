@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// hasQuota reports whether limits apply to the user, admins have none
+func (h *handler) hasQuota(ctx context.Context, userID int64) bool {
+	if h.limits.Unlimited() {
+		return false
+	}
+	role, _ := h.userRole(ctx, userID)
+	return !role.Allows(models.RoleAdmin)
+}
+
+// checkQuota tells the user why and returns false when check rejects their current usage
+func (h *handler) checkQuota(ctx context.Context, userID int64, lang i18n.Lang, reply func(text string), check func(usage quota.Usage) error) bool {
+	if !h.hasQuota(ctx, userID) {
+		return true
+	}
+
+	usage, err := h.db.GetUsage(ctx, userID, time.Now().Add(-quota.Window).Unix())
+	if err != nil {
+		h.log.Error("Failed to get quota usage", zap.Error(err), zap.Int64("user_id", userID))
+		reply(i18n.T(lang, "quota_check_failed"))
+		return false
+	}
+
+	err = check(usage)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, quota.ErrActiveRequests):
+		reply(i18n.T(lang, "quota_active_requests", h.limits.ActiveRequests))
+	case errors.Is(err, quota.ErrTracksPerDay):
+		left, _ := quota.Remaining(h.limits.TracksPerDay, usage.TracksToday)
+		reply(i18n.T(lang, "quota_tracks_per_day", h.limits.TracksPerDay, left))
+	case errors.Is(err, quota.ErrSubscriptions):
+		reply(i18n.T(lang, "quota_subscriptions", h.limits.Subscriptions))
+	default:
+		reply(i18n.T(lang, "quota_check_failed"))
+	}
+
+	h.log.Info("Quota exceeded", zap.Int64("user_id", userID), zap.Error(err))
+	return false
+}
+
+func (h *handler) HandleQuota(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+	ctx := context.Background()
+
+	if !h.hasQuota(ctx, m.Sender.ID) {
+		h.reply(m, i18n.T(lang, "quota_unlimited"))
+		return
+	}
+
+	usage, err := h.db.GetUsage(ctx, m.Sender.ID, time.Now().Add(-quota.Window).Unix())
+	if err != nil {
+		h.log.Error("Failed to get quota usage", zap.Error(err), zap.Int64("user_id", m.Sender.ID))
+		h.reply(m, i18n.T(lang, "quota_check_failed"))
+		return
+	}
+
+	lines := []string{
+		i18n.T(lang, "quota_header"),
+		quotaLine(lang, "quota_active_label", h.limits.ActiveRequests, usage.ActiveRequests),
+		quotaLine(lang, "quota_tracks_label", h.limits.TracksPerDay, usage.TracksToday),
+		quotaLine(lang, "quota_subscriptions_label", h.limits.Subscriptions, usage.Subscriptions),
+	}
+
+	h.reply(m, strings.Join(lines, "\n"))
+}
+
+func quotaLine(lang i18n.Lang, labelKey string, limit, used int) string {
+	label := i18n.T(lang, labelKey)
+	left, limited := quota.Remaining(limit, used)
+	if !limited {
+		return i18n.T(lang, "quota_line_unlimited", label, used)
+	}
+	return i18n.T(lang, "quota_line", label, used, limit, left)
+}
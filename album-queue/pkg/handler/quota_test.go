@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+)
+
+func createQuotaTestHandler(db *fakeDatabase, sinks *testSinks) *handler {
+	if db.users == nil {
+		db.users = map[int64]models.User{}
+	}
+	db.users[2] = models.User{ID: 2, Role: models.RoleMember}
+
+	h := createTestHandler(db, sinks)
+	h.limits = quota.Limits{ActiveRequests: 3, TracksPerDay: 10, Subscriptions: 1}
+	h.sourceProvider = &fakeSourceProvider{
+		name:   "Record",
+		tracks: make([]spotify.TrackMetadata, 4),
+	}
+	h.spotifyService = &fakeSpotifyService{tracks: map[string][]spotify.TrackMetadata{
+		"https://open.spotify.com/playlist/abc": make([]spotify.TrackMetadata, 4),
+	}}
+	return h
+}
+
+func TestHandleTextEnforcesQuota(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{ActiveRequests: 1, TracksToday: 8}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	h.HandleText(userMessage(2, "https://artist.bandcamp.com/album/record"))
+
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected request over the daily limit to be rejected, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "залишилось 2") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+
+	db.usage.TracksToday = 6
+	h.HandleText(userMessage(2, "https://artist.bandcamp.com/album/record"))
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected request within the limit to be queued, got %d", len(db.newRequests))
+	}
+}
+
+func TestHandleTextRefusesUnknownTrackCount(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+	h.sourceProvider = &fakeSourceProvider{name: "Record", countErr: errors.New("yt-dlp failed")}
+
+	h.HandleText(userMessage(2, "https://artist.bandcamp.com/album/record"))
+
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected a request without a track count to be refused, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 1 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "track_count_unknown") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+
+	// without a quota there's nothing to check, the note comes once it's queued
+	h.HandleText(testMessage("https://artist.bandcamp.com/album/record"))
+
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected the admin request to be queued, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 2 || sinks.replies[1] != i18n.T(i18n.Ukrainian, "track_count_failed", "Record") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestQuotaDoesNotApplyToAdmins(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{ActiveRequests: 100, TracksToday: 100}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	h.HandleText(testMessage("https://artist.bandcamp.com/album/record"))
+
+	if len(db.newRequests) != 1 {
+		t.Fatalf("expected admin request to be queued, got %d", len(db.newRequests))
+	}
+}
+
+func TestHandlePlaylistEnforcesActiveRequests(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{ActiveRequests: 3}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	h.HandlePlaylist(userMessage(2, "/p https://open.spotify.com/playlist/abc"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "вже 3 активних") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+	if sinks.webhookCalls != 0 {
+		t.Fatalf("expected no webhook call, got %d", sinks.webhookCalls)
+	}
+}
+
+func TestHandlePlaylistEnforcesTracksPerDay(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{TracksToday: 8}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	h.HandlePlaylistNoPull(userMessage(2, "/pnp https://open.spotify.com/playlist/abc"))
+
+	if len(db.newRequests) != 0 {
+		t.Fatalf("expected playlist over the daily limit to be rejected, got %+v", db.newRequests)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "залишилось 2") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+
+	db.usage.TracksToday = 6
+	h.HandlePlaylist(userMessage(2, "/p https://open.spotify.com/playlist/abc"))
+	if len(db.newRequests) != 1 || db.newRequests[0].expectedTrackCount != 4 {
+		t.Fatalf("expected playlist within the limit to be queued with its track count, got %+v", db.newRequests)
+	}
+}
+
+func TestHandleSubscribeEnforcesQuota(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{Subscriptions: 1}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+
+	h.HandleSubscribe(userMessage(2, "/subscribe https://open.spotify.com/playlist/abc"))
+
+	if len(db.subscriptions) != 0 {
+		t.Fatalf("expected subscription to be rejected, got %+v", db.subscriptions)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "1 підписок") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleQuota(t *testing.T) {
+	db := &fakeDatabase{usage: quota.Usage{ActiveRequests: 1, TracksToday: 4}}
+	sinks := &testSinks{}
+	h := createQuotaTestHandler(db, sinks)
+	h.limits.Subscriptions = 0
+
+	h.HandleQuota(userMessage(2, "/quota"))
+
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected one reply, got %#v", sinks.replies)
+	}
+	for _, expected := range []string{"Активні запити: 1 з 3, залишилось 2", "Треки за добу: 4 з 10, залишилось 6", "Підписки: 0, без ліміту"} {
+		if !strings.Contains(sinks.replies[0], expected) {
+			t.Errorf("expected %q in %q", expected, sinks.replies[0])
+		}
+	}
+
+	h.HandleQuota(testMessage("/quota"))
+	if !strings.Contains(sinks.replies[1], "лімітів нема") {
+		t.Fatalf("expected admins to have no limits, got %q", sinks.replies[1])
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	radarAcceptCallbackUnique  = "radar_accept"
+	radarDismissCallbackUnique = "radar_dismiss"
+
+	// radarShown keeps /radar within one message
+	radarShown = 10
+)
+
+var (
+	radarAcceptCallbackEndpoint  = &telebot.InlineButton{Unique: radarAcceptCallbackUnique}
+	radarDismissCallbackEndpoint = &telebot.InlineButton{Unique: radarDismissCallbackUnique}
+)
+
+func RadarAcceptCallbackEndpoint() telebot.CallbackEndpoint {
+	return radarAcceptCallbackEndpoint
+}
+
+func RadarDismissCallbackEndpoint() telebot.CallbackEndpoint {
+	return radarDismissCallbackEndpoint
+}
+
+// HandleRadar lists the release radar suggestions, releases of library
+// artists newer than their newest album in the library
+func (h *handler) HandleRadar(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	text, markup, err := h.renderRadar(context.Background(), lang)
+	if err != nil {
+		h.log.Error("Failed to get release suggestions", zap.Error(err))
+		h.reply(m, i18n.T(lang, "radar_fetch_failed"))
+		return
+	}
+
+	if err := h.sendFailedPageFn(m, text, markup); err != nil {
+		h.log.Error("Failed to send release suggestions", zap.Error(err))
+	}
+}
+
+// HandleRadarAccept queues the suggested release
+func (h *handler) HandleRadarAccept(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	suggestion, ok := h.pendingSuggestion(ctx, c, lang)
+	if !ok {
+		return
+	}
+
+	reply := func(text string) { _ = h.respondCallbackFn(c, text, true) }
+	if !h.queueURL(ctx, c.Sender.ID, lang, suggestion.SpotifyURL, reply, nil) {
+		return
+	}
+
+	h.decideSuggestion(ctx, c, lang, suggestion, models.ReleaseSuggestionAccepted)
+}
+
+// HandleRadarDismiss drops the suggestion, it isn't suggested again
+func (h *handler) HandleRadarDismiss(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	suggestion, ok := h.pendingSuggestion(ctx, c, lang)
+	if !ok {
+		return
+	}
+
+	h.decideSuggestion(ctx, c, lang, suggestion, models.ReleaseSuggestionDismissed)
+	_ = h.respondCallbackFn(c, i18n.T(lang, "radar_dismissed", suggestion.Name), false)
+}
+
+// pendingSuggestion loads the suggestion a button points at, answering the
+// callback when it's gone or was decided on already
+func (h *handler) pendingSuggestion(ctx context.Context, c *telebot.Callback, lang i18n.Lang) (models.ReleaseSuggestion, bool) {
+	suggestion, err := h.db.GetReleaseSuggestion(ctx, strings.TrimSpace(c.Data))
+	if err == nil && suggestion.Status != models.ReleaseSuggestionPending {
+		err = mongo.ErrNoDocuments
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "radar_gone"), true)
+		h.refreshRadar(ctx, c, lang)
+		return models.ReleaseSuggestion{}, false
+	}
+	if err != nil {
+		h.log.Error("Failed to get release suggestion", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "radar_fetch_failed"), true)
+		return models.ReleaseSuggestion{}, false
+	}
+	return suggestion, true
+}
+
+// decideSuggestion stores what the user decided and redraws the /radar message
+func (h *handler) decideSuggestion(ctx context.Context, c *telebot.Callback, lang i18n.Lang, suggestion models.ReleaseSuggestion, status models.ReleaseSuggestionStatus) {
+	if err := h.db.DecideReleaseSuggestion(ctx, suggestion.ID, status, c.Sender.ID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		h.log.Error("Failed to update release suggestion", zap.Error(err), zap.String("id", suggestion.ID))
+	}
+
+	h.log.Info("Release suggestion decided", zap.String("id", suggestion.ID), zap.String("status", string(status)), zap.Int64("user_id", c.Sender.ID))
+	h.refreshRadar(ctx, c, lang)
+}
+
+// refreshRadar redraws the /radar message a button was pressed on
+func (h *handler) refreshRadar(ctx context.Context, c *telebot.Callback, lang i18n.Lang) {
+	if c.Message == nil {
+		return
+	}
+
+	text, markup, err := h.renderRadar(ctx, lang)
+	if err != nil {
+		h.log.Error("Failed to render release suggestions", zap.Error(err))
+		return
+	}
+
+	if err := h.editFailedPageFn(c.Message, text, markup); err != nil {
+		h.log.Error("Failed to edit release suggestions", zap.Error(err))
+	}
+}
+
+func (h *handler) renderRadar(ctx context.Context, lang i18n.Lang) (string, *telebot.ReplyMarkup, error) {
+	suggestions, err := h.db.GetPendingReleaseSuggestions(ctx, radarShown)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(suggestions) == 0 {
+		return i18n.T(lang, "radar_empty"), nil, nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(suggestions))
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "radar_header"))
+	for i, suggestion := range suggestions {
+		response.WriteString(fmt.Sprintf("%d. 💿 %s - %s (%s, %s)\n", i+1, suggestion.Artist, suggestion.Name, releaseTypeText(lang, suggestion.Type), time.Unix(suggestion.ReleaseDate, 0).UTC().Format("02.01.2006")))
+		response.WriteString(i18n.T(lang, "radar_latest_local", suggestion.LatestLocal))
+		response.WriteString(fmt.Sprintf("   📎 %s\n\n", suggestion.SpotifyURL))
+
+		rows = append(rows, markup.Row(
+			markup.Data(i18n.T(lang, "radar_accept_button", i+1), radarAcceptCallbackUnique, suggestion.ID),
+			markup.Data(i18n.T(lang, "radar_dismiss_button", i+1), radarDismissCallbackUnique, suggestion.ID),
+		))
+	}
+
+	markup.Inline(rows...)
+	return response.String(), markup, nil
+}
+
+// releaseTypeText returns the kind of release in the user's language
+func releaseTypeText(lang i18n.Lang, releaseType string) string {
+	if releaseType == "single" {
+		return i18n.T(lang, "release_single")
+	}
+	return i18n.T(lang, "release_album")
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const testReleaseURL = "https://open.spotify.com/album/new"
+
+func createRadarTestHandler() (*handler, *fakeDatabase, *testSinks) {
+	database := &fakeDatabase{releaseSuggestions: []models.ReleaseSuggestion{
+		{ID: "s-1", Artist: "Daft Punk", Name: "New", Type: "album", SpotifyURL: testReleaseURL, LatestLocal: "Discovery", Status: models.ReleaseSuggestionPending},
+		{ID: "s-2", Artist: "Daft Punk", Name: "Old", Type: "single", Status: models.ReleaseSuggestionDismissed},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{
+		names:  map[string]string{testReleaseURL: "New"},
+		tracks: map[string][]spotify.TrackMetadata{testReleaseURL: {{Artist: "daft punk", Title: "one"}}},
+	}
+	return h, database, sinks
+}
+
+func TestHandleRadarListsPendingSuggestions(t *testing.T) {
+	h, _, sinks := createRadarTestHandler()
+
+	h.HandleRadar(testMessage("/radar"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected one message, got %d", len(sinks.sentPages))
+	}
+	page := sinks.sentPages[0]
+	if !strings.Contains(page.text, "Daft Punk - New") || !strings.Contains(page.text, "Discovery") || strings.Contains(page.text, "Old") {
+		t.Fatalf("expected only the pending suggestion, got %q", page.text)
+	}
+	if rows := page.markup.InlineKeyboard; len(rows) != 1 || rows[0][0].Data != "s-1" || rows[0][1].Data != "s-1" {
+		t.Fatalf("unexpected buttons: %+v", rows)
+	}
+}
+
+func TestHandleRadarAcceptQueuesRelease(t *testing.T) {
+	h, database, sinks := createRadarTestHandler()
+
+	h.HandleRadarAccept(&telebot.Callback{Sender: &telebot.User{ID: 1}, Message: &telebot.Message{}, Data: "s-1"})
+
+	if len(database.newRequests) != 1 || database.newRequests[0].url != testReleaseURL || database.newRequests[0].creatorID != 1 {
+		t.Fatalf("expected the release to be queued, got %+v", database.newRequests)
+	}
+	if s := database.releaseSuggestions[0]; s.Status != models.ReleaseSuggestionAccepted || s.DecidedBy != 1 {
+		t.Fatalf("expected the suggestion to be accepted, got %+v", s)
+	}
+	if sinks.webhookCalls != 1 {
+		t.Fatalf("expected the webhook to be called, got %d", sinks.webhookCalls)
+	}
+	if len(sinks.editedPages) != 1 || sinks.editedPages[0].text != i18n.T(i18n.Ukrainian, "radar_empty") {
+		t.Fatalf("expected the list to be redrawn, got %+v", sinks.editedPages)
+	}
+}
+
+func TestHandleRadarDismissAndGone(t *testing.T) {
+	h, database, sinks := createRadarTestHandler()
+	callback := &telebot.Callback{Sender: &telebot.User{ID: 1}, Message: &telebot.Message{}, Data: "s-1"}
+
+	h.HandleRadarDismiss(callback)
+	h.HandleRadarAccept(callback)
+
+	if database.releaseSuggestions[0].Status != models.ReleaseSuggestionDismissed {
+		t.Fatalf("expected the suggestion to be dismissed, got %+v", database.releaseSuggestions[0])
+	}
+	if len(database.newRequests) != 0 {
+		t.Fatalf("expected nothing to be queued, got %+v", database.newRequests)
+	}
+	if len(sinks.callbackAcks) != 2 || sinks.callbackAcks[1].text != i18n.T(i18n.Ukrainian, "radar_gone") {
+		t.Fatalf("unexpected callback acks: %#v", sinks.callbackAcks)
+	}
+}
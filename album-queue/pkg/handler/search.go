@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/quota"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	searchPageCallbackUnique  = "search_page"
+	searchAlbumCallbackUnique = "search_album"
+	searchQueueCallbackUnique = "search_queue"
+	searchPageSize            = 5
+	maxSearchResults          = 100
+
+	// Telegram limits callback data to 64 bytes, the query travels in the
+	// page buttons and the file id in the per-result buttons
+	maxCallbackData    = 64
+	maxSearchQueryLen  = maxCallbackData - len("\f"+searchPageCallbackUnique+"|99|")
+	maxAlbumTracksShow = 30
+)
+
+var (
+	searchPageCallbackEndpoint  = &telebot.InlineButton{Unique: searchPageCallbackUnique}
+	searchAlbumCallbackEndpoint = &telebot.InlineButton{Unique: searchAlbumCallbackUnique}
+	searchQueueCallbackEndpoint = &telebot.InlineButton{Unique: searchQueueCallbackUnique}
+)
+
+func SearchPageCallbackEndpoint() telebot.CallbackEndpoint {
+	return searchPageCallbackEndpoint
+}
+
+func SearchAlbumCallbackEndpoint() telebot.CallbackEndpoint {
+	return searchAlbumCallbackEndpoint
+}
+
+func SearchQueueCallbackEndpoint() telebot.CallbackEndpoint {
+	return searchQueueCallbackEndpoint
+}
+
+func (h *handler) HandleSearch(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	query := commandArgs(m.Text)
+	if query == "" {
+		h.reply(m, i18n.T(lang, "search_usage"))
+		return
+	}
+	if len(query) > maxSearchQueryLen {
+		h.reply(m, i18n.T(lang, "search_too_long"))
+		return
+	}
+
+	files, err := h.db.SearchMusicFiles(context.Background(), query, maxSearchResults)
+	if err != nil {
+		h.log.Error("Failed to search music files", zap.Error(err), zap.String("query", query))
+		h.reply(m, i18n.T(lang, "search_failed"))
+		return
+	}
+
+	if len(files) == 0 {
+		h.reply(m, i18n.T(lang, "search_empty", query))
+		return
+	}
+
+	text, markup, err := renderSearchPage(lang, query, files, 0)
+	if err != nil {
+		h.log.Error("Failed to render search page", zap.Error(err))
+		h.reply(m, i18n.T(lang, "search_failed"))
+		return
+	}
+
+	if err := h.sendFailedPageFn(m, text, markup); err != nil {
+		h.log.Error("Failed to send search results", zap.Error(err))
+	}
+}
+
+func (h *handler) HandleSearchPage(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	pageData, query, _ := strings.Cut(c.Data, "|")
+	page, err := strconv.Atoi(strings.TrimSpace(pageData))
+	if err != nil || query == "" {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
+		return
+	}
+
+	files, err := h.db.SearchMusicFiles(context.Background(), query, maxSearchResults)
+	if err != nil {
+		h.log.Error("Failed to search music files", zap.Error(err), zap.String("query", query))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return
+	}
+
+	if len(files) == 0 {
+		if c.Message != nil {
+			if err := h.editFailedPageFn(c.Message, i18n.T(lang, "search_empty", query), nil); err != nil {
+				h.log.Error("Failed to edit search page to empty state", zap.Error(err))
+			}
+		}
+		_ = h.respondCallbackFn(c, "", false)
+		return
+	}
+
+	text, markup, err := renderSearchPage(lang, query, files, page)
+	if err != nil {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "invalid_page"), true)
+		return
+	}
+
+	if c.Message != nil {
+		if err := h.editFailedPageFn(c.Message, text, markup); err != nil {
+			h.log.Error("Failed to edit search page", zap.Error(err))
+		}
+	}
+
+	_ = h.respondCallbackFn(c, "", false)
+}
+
+// HandleSearchAlbum shows the library tracks of the album of a search result
+func (h *handler) HandleSearchAlbum(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	file, ok := h.searchResultFile(ctx, c, lang)
+	if !ok {
+		return
+	}
+
+	if strings.TrimSpace(file.Album) == "" {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_no_album"), true)
+		return
+	}
+
+	tracks, err := h.db.GetAlbumFiles(ctx, file.Artist, file.Album)
+	if err != nil {
+		h.log.Error("Failed to get album files", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "search_album_header", file.Album, file.Artist, i18n.N(lang, "tracks", len(tracks))))
+	for i, track := range tracks {
+		if i == maxAlbumTracksShow {
+			response.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", len(tracks)-maxAlbumTracksShow)))
+			break
+		}
+		response.WriteString(fmt.Sprintf("%d. %s\n", i+1, track.Title))
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data(i18n.T(lang, "search_queue_button"), searchQueueCallbackUnique, file.ID)))
+
+	if c.Message != nil {
+		if err := h.sendFailedPageFn(c.Message, response.String(), markup); err != nil {
+			h.log.Error("Failed to send album details", zap.Error(err))
+		}
+	}
+
+	_ = h.respondCallbackFn(c, "", false)
+}
+
+// HandleSearchQueue queues the tracks of a search result's album that are not
+// in the library yet
+func (h *handler) HandleSearchQueue(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+	ctx := context.Background()
+
+	file, ok := h.searchResultFile(ctx, c, lang)
+	if !ok {
+		return
+	}
+
+	if strings.TrimSpace(file.Album) == "" {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_no_album"), true)
+		return
+	}
+
+	spotifyService := h.spotifyFor(ctx, c.Sender.ID)
+	albumURL, err := spotifyService.SearchAlbum(ctx, file.Artist, file.Album)
+	if errors.Is(err, spotify.ErrAlbumNotFound) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_album_not_found"), true)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to search album on spotify", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return
+	}
+
+	active, err := h.db.HasActiveRequestByURL(ctx, albumURL)
+	if err != nil {
+		h.log.Error("Failed to check active request", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return
+	}
+	if active {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_already_queued"), true)
+		return
+	}
+
+	_, trackMetadata, err := spotifyService.GetTrackCount(ctx, albumURL)
+	if err != nil {
+		h.log.Error("Failed to get album tracks", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "track_count_failed"), true)
+		return
+	}
+
+	missing, err := h.markFoundTracks(ctx, trackMetadata)
+	if err != nil {
+		h.log.Error("Failed to compare album with library", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return
+	}
+	if missing == 0 {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_album_complete"), true)
+		return
+	}
+
+	deny := func(text string) { _ = h.respondCallbackFn(c, text, true) }
+	if !h.checkQuota(ctx, c.Sender.ID, lang, deny, func(usage quota.Usage) error {
+		return h.limits.CheckQueue(usage, missing)
+	}) {
+		return
+	}
+
+	name := fmt.Sprintf("%s - %s", file.Artist, file.Album)
+	id, err := h.db.NewTracksRequest(ctx, albumURL, name, c.Sender.ID, spotify.SpotifyObjectTypeAlbum, unfoundTracks(trackMetadata))
+	if err != nil {
+		h.log.Error("Failed to add download request to database", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "queue_add_failed"), true)
+		return
+	}
+
+	h.requestCreated(webhook.RequestData{ID: id, URL: albumURL, Name: name, CreatorID: c.Sender.ID, ObjectType: string(spotify.SpotifyObjectTypeAlbum), Tracks: missing})
+
+	_ = h.respondCallbackFn(c, i18n.T(lang, "search_queued", i18n.N(lang, "tracks", missing)), true)
+}
+
+// searchResultFile loads the music file a result button points at, answering
+// the callback when it's gone
+func (h *handler) searchResultFile(ctx context.Context, c *telebot.Callback, lang i18n.Lang) (models.MusicFile, bool) {
+	file, err := h.db.GetMusicFile(ctx, strings.TrimSpace(c.Data))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_result_gone"), true)
+		return models.MusicFile{}, false
+	}
+	if err != nil {
+		h.log.Error("Failed to get music file", zap.Error(err))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "search_failed"), true)
+		return models.MusicFile{}, false
+	}
+	return file, true
+}
+
+// markFoundTracks marks the tracks that are already in the library as found
+// and returns how many are missing
+func (h *handler) markFoundTracks(ctx context.Context, tracks []spotify.TrackMetadata) (int, error) {
+	if len(tracks) == 0 {
+		return 0, nil
+	}
+
+	artists := make([]string, 0, len(tracks))
+	titles := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		artists = append(artists, track.Artist)
+		titles = append(titles, track.Title)
+	}
+
+	foundMusic, err := h.db.FindMusicFiles(ctx, artists, titles)
+	if err != nil {
+		return 0, err
+	}
+
+	aliases, err := h.db.GetArtistAliases(ctx)
+	if err != nil {
+		h.log.Warn("Failed to load artist aliases", zap.Error(err))
+	}
+
+	foundMap := make(map[string]bool)
+	for _, music := range foundMusic {
+		foundMap[aliases.Key(music.Artist, music.Title)] = true
+	}
+
+	missing := 0
+	for i := range tracks {
+		for _, key := range aliases.Keys(tracks[i].Artist, tracks[i].Title) {
+			if foundMap[key] {
+				tracks[i].Found = true
+				break
+			}
+		}
+		if !tracks[i].Found {
+			missing++
+		}
+	}
+
+	return missing, nil
+}
+
+func renderSearchPage(lang i18n.Lang, query string, files []models.MusicFile, page int) (string, *telebot.ReplyMarkup, error) {
+	if len(files) == 0 {
+		return "", nil, errors.New("search results are empty")
+	}
+
+	totalPages := (len(files) + searchPageSize - 1) / searchPageSize
+	if page < 0 || page >= totalPages {
+		return "", nil, errors.New("page out of range")
+	}
+
+	start := page * searchPageSize
+	end := min(start+searchPageSize, len(files))
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "search_header", query, len(files)))
+	if len(files) == maxSearchResults {
+		response.WriteString(i18n.T(lang, "search_truncated"))
+	}
+	response.WriteString(i18n.T(lang, "page", page+1, totalPages))
+
+	markup := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, end-start+1)
+
+	for i := start; i < end; i++ {
+		file := files[i]
+
+		artist := strings.TrimSpace(file.Artist)
+		if artist == "" {
+			artist = i18n.T(lang, "unknown_artist")
+		}
+
+		title := strings.TrimSpace(file.Title)
+		if title == "" {
+			title = i18n.T(lang, "unknown_title")
+		}
+
+		response.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, artist, title))
+		if album := strings.TrimSpace(file.Album); album != "" {
+			response.WriteString(fmt.Sprintf("   💿 %s\n", album))
+		}
+
+		if file.Album == "" || len("\f"+searchAlbumCallbackUnique+"|"+file.ID) > maxCallbackData {
+			continue
+		}
+		rows = append(rows, markup.Row(
+			markup.Data(i18n.T(lang, "search_album_button", i+1), searchAlbumCallbackUnique, file.ID),
+			markup.Data(i18n.T(lang, "search_queue_button_short", i+1), searchQueueCallbackUnique, file.ID),
+		))
+	}
+
+	nav := make([]telebot.Btn, 0, 2)
+	if page > 0 {
+		nav = append(nav, markup.Data(i18n.T(lang, "page_prev"), searchPageCallbackUnique, strconv.Itoa(page-1), query))
+	}
+	if page < totalPages-1 {
+		nav = append(nav, markup.Data(i18n.T(lang, "page_next"), searchPageCallbackUnique, strconv.Itoa(page+1), query))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, markup.Row(nav...))
+	}
+
+	if len(rows) == 0 {
+		return response.String(), nil, nil
+	}
+
+	markup.Inline(rows...)
+	return response.String(), markup, nil
+}
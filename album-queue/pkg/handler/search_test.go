@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func searchTestFiles(count int) []models.MusicFile {
+	files := make([]models.MusicFile, 0, count)
+	for i := 0; i < count; i++ {
+		files = append(files, models.MusicFile{
+			ID:     fmt.Sprintf("file-%d", i),
+			Artist: "Okean Elzy",
+			Title:  fmt.Sprintf("Song %d", i),
+			Album:  "Model",
+		})
+	}
+	return files
+}
+
+func TestHandleSearchRejectsEmptyQuery(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{}, sinks)
+
+	h.HandleSearch(testMessage("/search"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "/search") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleSearchReportsNoResults(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{musicFiles: searchTestFiles(3)}, sinks)
+
+	h.HandleSearch(testMessage("/search boombox"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "boombox") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+	if len(sinks.sentPages) != 0 {
+		t.Fatalf("expected no pages, got %d", len(sinks.sentPages))
+	}
+}
+
+func TestHandleSearchUsesInlineKeyboardPagination(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{musicFiles: searchTestFiles(searchPageSize + 2)}, sinks)
+
+	h.HandleSearch(testMessage("/search okean"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected one page, got %d", len(sinks.sentPages))
+	}
+
+	page := sinks.sentPages[0]
+	if !strings.Contains(page.text, "Song 0") || strings.Contains(page.text, fmt.Sprintf("Song %d", searchPageSize)) {
+		t.Fatalf("unexpected first page: %q", page.text)
+	}
+
+	rows := page.markup.InlineKeyboard
+	if len(rows) != searchPageSize+1 {
+		t.Fatalf("expected %d rows, got %d", searchPageSize+1, len(rows))
+	}
+	if rows[0][0].Unique != searchAlbumCallbackUnique || rows[0][0].Data != "file-0" {
+		t.Fatalf("unexpected album button: %+v", rows[0][0])
+	}
+	if rows[0][1].Unique != searchQueueCallbackUnique || rows[0][1].Data != "file-0" {
+		t.Fatalf("unexpected queue button: %+v", rows[0][1])
+	}
+
+	nav := rows[len(rows)-1]
+	if len(nav) != 1 || nav[0].Unique != searchPageCallbackUnique || nav[0].Data != "1|okean" {
+		t.Fatalf("unexpected nav row: %+v", nav)
+	}
+}
+
+func TestHandleSearchPageEditsMessage(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{musicFiles: searchTestFiles(searchPageSize + 2)}, sinks)
+
+	h.HandleSearchPage(&telebot.Callback{
+		Sender:  &telebot.User{ID: 1},
+		Message: &telebot.Message{},
+		Data:    "1|okean",
+	})
+
+	if len(sinks.editedPages) != 1 {
+		t.Fatalf("expected one edit, got %d", len(sinks.editedPages))
+	}
+	if !strings.Contains(sinks.editedPages[0].text, fmt.Sprintf("Song %d", searchPageSize)) {
+		t.Fatalf("unexpected second page: %q", sinks.editedPages[0].text)
+	}
+	if len(sinks.callbackAcks) != 1 || sinks.callbackAcks[0].showAlert {
+		t.Fatalf("unexpected callback acks: %#v", sinks.callbackAcks)
+	}
+}
+
+func TestHandleSearchQueueQueuesMissingTracks(t *testing.T) {
+	const albumURL = "https://open.spotify.com/album/model"
+
+	database := &fakeDatabase{musicFiles: searchTestFiles(1), activeByURL: map[string]bool{}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{
+		albums: map[string]string{"Okean Elzy - Model": albumURL},
+		tracks: map[string][]spotify.TrackMetadata{albumURL: {
+			{Artist: "Okean Elzy", Title: "Song 0"},
+			{Artist: "Okean Elzy", Title: "Song 1"},
+		}},
+	}
+
+	h.HandleSearchQueue(&telebot.Callback{Sender: &telebot.User{ID: 1}, Data: "file-0"})
+
+	if len(database.newRequests) != 1 {
+		t.Fatalf("expected one request, got %d", len(database.newRequests))
+	}
+	req := database.newRequests[0]
+	if req.url != albumURL || req.objectType != spotify.SpotifyObjectTypeAlbum || req.creatorID != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if !req.tracksOnly || len(req.trackMetadata) != 1 || req.trackMetadata[0].Title != "Song 1" {
+		t.Fatalf("expected only the missing track, got %+v", req)
+	}
+	if sinks.webhookCalls != 1 {
+		t.Fatalf("expected webhook call, got %d", sinks.webhookCalls)
+	}
+}
+
+func TestHandleSearchQueueSkipsCompleteAlbum(t *testing.T) {
+	const albumURL = "https://open.spotify.com/album/model"
+
+	database := &fakeDatabase{musicFiles: searchTestFiles(1), activeByURL: map[string]bool{}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{
+		albums: map[string]string{"Okean Elzy - Model": albumURL},
+		tracks: map[string][]spotify.TrackMetadata{albumURL: {{Artist: "Okean Elzy", Title: "Song 0"}}},
+	}
+
+	h.HandleSearchQueue(&telebot.Callback{Sender: &telebot.User{ID: 1}, Data: "file-0"})
+
+	if len(database.newRequests) != 0 {
+		t.Fatalf("expected no requests, got %+v", database.newRequests)
+	}
+	if len(sinks.callbackAcks) != 1 || !sinks.callbackAcks[0].showAlert {
+		t.Fatalf("unexpected callback acks: %#v", sinks.callbackAcks)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// spotifyFor returns the Spotify service to use for requests made by the user
+func (h *handler) spotifyFor(ctx context.Context, userID int64) spotify.SpotifyService {
+	if h.userSpotify == nil {
+		return h.spotifyService
+	}
+	return h.userSpotify.ForUser(ctx, userID)
+}
+
+func (h *handler) HandleLink(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	if h.spotifyAuth == nil {
+		h.reply(m, i18n.T(lang, "link_not_configured"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "link_prompt", h.spotifyAuth.AuthURL(m.Sender.ID)))
+}
+
+func (h *handler) HandleUnlink(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	if err := h.db.DeleteSpotifyToken(context.Background(), m.Sender.ID); err != nil {
+		if errors.Is(err, spotify.ErrTokenNotFound) {
+			h.reply(m, i18n.T(lang, "unlink_not_linked"))
+			return
+		}
+		h.log.Error("Failed to delete spotify token", zap.Error(err))
+		h.reply(m, i18n.T(lang, "unlink_failed"))
+		return
+	}
+
+	h.reply(m, i18n.T(lang, "unlinked"))
+}
+
+// SpotifyCallback finishes the OAuth flow started by /link
+func (h *handler) SpotifyCallback(w http.ResponseWriter, r *http.Request) {
+	if h.spotifyAuth == nil {
+		http.Error(w, "Spotify linking is not configured", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	if authErr := query.Get("error"); authErr != "" {
+		h.log.Info("Spotify authorization declined", zap.String("error", authErr))
+		http.Error(w, "Spotify authorization was declined", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.spotifyAuth.ParseState(query.Get("state"))
+	if err != nil {
+		h.log.Warn("Invalid spotify callback state", zap.Error(err))
+		http.Error(w, "Invalid or expired link, run /link again", http.StatusBadRequest)
+		return
+	}
+
+	if role, ok := h.userRole(r.Context(), userID); !ok || !role.Allows(models.RoleMember) {
+		h.log.Info("Unauthorized user", zap.Int64("user_id", userID))
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	token, err := h.spotifyAuth.Link(r.Context(), userID, query.Get("code"))
+	if err != nil {
+		h.log.Error("Failed to link spotify account", zap.Error(err), zap.Int64("user_id", userID))
+		http.Error(w, "Failed to link Spotify account", http.StatusBadGateway)
+		return
+	}
+
+	if err := h.db.SaveSpotifyToken(r.Context(), token); err != nil {
+		h.log.Error("Failed to save spotify token", zap.Error(err), zap.Int64("user_id", userID))
+		http.Error(w, "Failed to save Spotify account", http.StatusInternalServerError)
+		return
+	}
+
+	lang := h.userLang(r.Context(), userID, "")
+	if err := h.sendMessageFn(userID, i18n.T(lang, "linked")); err != nil {
+		h.log.Error("Failed to notify user about linked spotify", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("Spotify linked, you can go back to Telegram."))
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/spot-models/spotify"
+	spotifyapi "github.com/zmb3/spotify/v2"
+	"go.uber.org/zap"
+)
+
+type fakeSpotifyService struct {
+	names  map[string]string
+	albums map[string]string
+	tracks map[string][]spotify.TrackMetadata
+}
+
+func (f *fakeSpotifyService) GetObjectName(_ context.Context, url string) (string, error) {
+	if url == spotify.LikedSongsURL {
+		return "", spotify.ErrUserAuthRequired
+	}
+	return f.names[url], nil
+}
+
+func (f *fakeSpotifyService) GetObjectType(context.Context, string) (spotify.SpotifyObjectType, error) {
+	return spotify.SpotifyObjectTypePlaylist, nil
+}
+
+func (f *fakeSpotifyService) GetPlaylistTracks(context.Context, string) ([]spotifyapi.PlaylistItem, error) {
+	return nil, nil
+}
+
+func (f *fakeSpotifyService) GetPlaylistSnapshotID(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeSpotifyService) GetTrackCount(_ context.Context, url string) (int, []spotify.TrackMetadata, error) {
+	return len(f.tracks[url]), f.tracks[url], nil
+}
+
+func (f *fakeSpotifyService) SearchAlbum(_ context.Context, artist, album string) (string, error) {
+	url, ok := f.albums[artist+" - "+album]
+	if !ok {
+		return "", spotify.ErrAlbumNotFound
+	}
+	return url, nil
+}
+
+func (f *fakeSpotifyService) GetArtistReleases(context.Context, string) ([]spotify.ArtistRelease, error) {
+	return nil, nil
+}
+
+func (f *fakeSpotifyService) SearchArtist(context.Context, string) (string, error) {
+	return "", spotify.ErrArtistNotFound
+}
+
+func (f *fakeSpotifyService) SearchTrack(context.Context, string, string, string) (string, error) {
+	return "", spotify.ErrTrackNotFound
+}
+
+func newStubSpotifyAuth(t *testing.T) *spotify.Authenticator {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	})
+	mux.HandleFunc("/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"spotify-user"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return spotify.NewAuthenticator("id", "secret", "http://localhost/spotify/callback", "state-secret", zap.NewNop(), spotify.WithEndpoints(spotify.Endpoints{
+		AuthURL:    server.URL + "/authorize",
+		TokenURL:   server.URL + "/api/token",
+		APIBaseURL: server.URL + "/v1/",
+	}))
+}
+
+func stateFromAuthURL(t *testing.T, authURL string) string {
+	t.Helper()
+
+	start := strings.Index(authURL, "http")
+	parsed, err := url.Parse(strings.TrimSpace(authURL[start:]))
+	if err != nil {
+		t.Fatalf("failed to parse auth url: %v", err)
+	}
+	return parsed.Query().Get("state")
+}
+
+func TestHandleLinkWithoutConfiguration(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{}, sinks)
+
+	h.HandleLink(testMessage("/link"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "не налаштоване") {
+		t.Fatalf("unexpected reply: %#v", sinks.replies)
+	}
+}
+
+func TestSpotifyCallbackStoresTokenForStateUser(t *testing.T) {
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyAuth = newStubSpotifyAuth(t)
+
+	h.HandleLink(testMessage("/link"))
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected link reply, got %#v", sinks.replies)
+	}
+	state := stateFromAuthURL(t, sinks.replies[0])
+
+	rec := httptest.NewRecorder()
+	h.SpotifyCallback(rec, httptest.NewRequest(http.MethodGet, "/spotify/callback?code=good-code&state="+url.QueryEscape(state), nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	token, ok := database.spotifyTokens[1]
+	if !ok || token.SpotifyUserID != "spotify-user" || token.RefreshToken != "refresh" {
+		t.Fatalf("unexpected stored token: %+v", database.spotifyTokens)
+	}
+	if len(sinks.sentMessages[1]) != 1 {
+		t.Fatalf("expected user to be notified, got %#v", sinks.sentMessages)
+	}
+}
+
+func TestSpotifyCallbackRejectsForgedState(t *testing.T) {
+	database := &fakeDatabase{}
+	h := createTestHandler(database, &testSinks{})
+	h.spotifyAuth = newStubSpotifyAuth(t)
+
+	rec := httptest.NewRecorder()
+	h.SpotifyCallback(rec, httptest.NewRequest(http.MethodGet, "/spotify/callback?code=good-code&state=1.123.forged", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if len(database.spotifyTokens) != 0 {
+		t.Fatalf("expected no stored token, got %+v", database.spotifyTokens)
+	}
+}
+
+func TestHandleSubscribeLikedRequiresLinkedAccount(t *testing.T) {
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{}
+
+	h.HandleSubscribe(testMessage("/subscribe liked"))
+
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "/link") {
+		t.Fatalf("unexpected reply: %#v", sinks.replies)
+	}
+	if len(database.subscriptions) != 0 {
+		t.Fatalf("expected no subscription, got %+v", database.subscriptions)
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// HandleStats shows the library breakdown and how downloads went, the stats
+// are cached so they may be a little behind
+func (h *handler) HandleStats(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	stats, err := h.libraryStatsFn(context.Background())
+	if err != nil {
+		h.log.Error("Failed to get library stats", zap.Error(err))
+		h.reply(m, i18n.T(lang, "stats_fetch_failed"))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(i18n.T(lang, "stats_header"))
+	response.WriteString(i18n.T(lang, "stats_library", stats.TotalMusicFiles, formatBytes(stats.LibraryBytes)))
+	if stats.FilesWithoutSize > 0 {
+		response.WriteString(i18n.T(lang, "stats_without_size", i18n.N(lang, "files", int(stats.FilesWithoutSize))))
+	}
+	response.WriteString(i18n.T(lang, "stats_queue", stats.ActiveDownloadQueue, stats.TotalDownloadRequests, stats.ActivePlaylists))
+
+	writeStatsCounts(&response, lang, "stats_genres", stats.Genres)
+	writeStatsCounts(&response, lang, "stats_artists", stats.TopArtists)
+	writeStatsCounts(&response, lang, "stats_formats", stats.Formats)
+
+	response.WriteString(i18n.T(lang, "stats_weeks"))
+	for _, w := range stats.WeeklyAdditions {
+		response.WriteString(fmt.Sprintf("   %s: %d\n", time.Unix(w.WeekStart, 0).UTC().Format("02.01"), w.Count))
+	}
+
+	downloads := stats.Downloads
+	if downloads.Finished == 0 {
+		response.WriteString(i18n.T(lang, "stats_downloads_empty"))
+	} else {
+		avg := time.Duration(downloads.AvgSeconds * float64(time.Second)).Round(time.Second)
+		response.WriteString(i18n.T(lang, "stats_downloads",
+			downloads.SuccessRate*100, downloads.Succeeded, downloads.Finished,
+			downloads.FoundTracks, downloads.ExpectedTracks, avg))
+	}
+
+	h.reply(m, response.String())
+}
+
+// writeStatsCounts writes one ranked section of /stats on a single line,
+// nothing when it is empty
+func writeStatsCounts(b *strings.Builder, lang i18n.Lang, key string, counts []db.StatsCount) {
+	if len(counts) == 0 {
+		return
+	}
+
+	parts := make([]string, 0, len(counts))
+	for _, c := range counts {
+		name := c.Name
+		if name == "" {
+			name = i18n.T(lang, "stats_unknown")
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", name, c.Count))
+	}
+	b.WriteString(i18n.T(lang, key, strings.Join(parts, ", ")))
+}
+
+// formatBytes formats a size in binary units, e.g. 1.5 GiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
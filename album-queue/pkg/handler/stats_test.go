@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+)
+
+func TestHandleStats(t *testing.T) {
+	database := &fakeDatabase{libraryStats: &db.LibraryStats{
+		Stats:            db.Stats{TotalMusicFiles: 120},
+		Genres:           []db.StatsCount{{Name: "rock", Count: 80}, {Name: "", Count: 40}},
+		TopArtists:       []db.StatsCount{{Name: "Artist", Count: 12}},
+		Formats:          []db.StatsCount{{Name: "mp3", Count: 100}, {Name: "flac", Count: 20}},
+		LibraryBytes:     3 << 30,
+		FilesWithoutSize: 5,
+		WeeklyAdditions:  []db.WeeklyCount{{WeekStart: 1791763200, Count: 7}},
+		Downloads:        db.DownloadStats{Finished: 4, Succeeded: 3, SuccessRate: 0.75, ExpectedTracks: 40, FoundTracks: 36, AvgSeconds: 150},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+
+	h.HandleStats(testMessage("/stats"))
+
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected one reply, got %#v", sinks.replies)
+	}
+	text := sinks.replies[0]
+	for _, want := range []string{"3.0 GiB", "5 файлів", "rock 80, невідомо 40", "Artist 12", "mp3 100, flac 20", "12.10: 7", "75% (3/4)", "36/40", "2m30s"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+}
+
+func TestHandleStatsFailure(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{}, sinks)
+	h.libraryStatsFn = func(context.Context) (*db.LibraryStats, error) {
+		return nil, errors.New("mongo down")
+	}
+
+	h.HandleStats(testMessage("/stats"))
+
+	if len(sinks.replies) != 1 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "stats_fetch_failed") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
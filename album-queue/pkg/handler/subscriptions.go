@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	subscriptionIntervalCallbackUnique = "sub_interval"
+	subscriptionPullCallbackUnique     = "sub_pull"
+	subscriptionPauseCallbackUnique    = "sub_pause"
+	subscriptionSyncCallbackUnique     = "sub_sync"
+	subscriptionNotifyCallbackUnique   = "sub_notify"
+
+	// Telegram allows 100 buttons per message, five go to each subscription
+	maxSubscriptionButtonRows = 20
+)
+
+// subscriptionIntervals is the order the interval button cycles through
+var subscriptionIntervals = []string{"daily", "weekly", "hourly"}
+
+var (
+	subscriptionIntervalCallbackEndpoint = &telebot.InlineButton{Unique: subscriptionIntervalCallbackUnique}
+	subscriptionPullCallbackEndpoint     = &telebot.InlineButton{Unique: subscriptionPullCallbackUnique}
+	subscriptionPauseCallbackEndpoint    = &telebot.InlineButton{Unique: subscriptionPauseCallbackUnique}
+	subscriptionSyncCallbackEndpoint     = &telebot.InlineButton{Unique: subscriptionSyncCallbackUnique}
+	subscriptionNotifyCallbackEndpoint   = &telebot.InlineButton{Unique: subscriptionNotifyCallbackUnique}
+)
+
+func SubscriptionIntervalCallbackEndpoint() telebot.CallbackEndpoint {
+	return subscriptionIntervalCallbackEndpoint
+}
+
+func SubscriptionPullCallbackEndpoint() telebot.CallbackEndpoint {
+	return subscriptionPullCallbackEndpoint
+}
+
+func SubscriptionPauseCallbackEndpoint() telebot.CallbackEndpoint {
+	return subscriptionPauseCallbackEndpoint
+}
+
+func SubscriptionSyncCallbackEndpoint() telebot.CallbackEndpoint {
+	return subscriptionSyncCallbackEndpoint
+}
+
+func SubscriptionNotifyCallbackEndpoint() telebot.CallbackEndpoint {
+	return subscriptionNotifyCallbackEndpoint
+}
+
+func (h *handler) HandleListSubscriptions(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	text, markup, err := h.renderSubscriptions(context.Background(), m.Sender.ID, lang)
+	if err != nil {
+		h.log.Error("Failed to get subscriptions", zap.Error(err))
+		h.reply(m, i18n.T(lang, "subscriptions_fetch_failed"))
+		return
+	}
+
+	if err := h.sendFailedPageFn(m, text, markup); err != nil {
+		h.log.Error("Failed to send subscriptions", zap.Error(err))
+	}
+}
+
+// HandleSubscriptionInterval sets the refresh interval the button carries
+func (h *handler) HandleSubscriptionInterval(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id, interval := subscriptionCallbackData(c)
+	if !slices.Contains(subscriptionIntervals, interval) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "subscription_update_failed"), true)
+		return
+	}
+
+	h.updateSubscription(c, lang, id, db.SubscriptionUpdate{RefreshInterval: &interval}, func(models.SubscribedPlaylist) string {
+		return i18n.T(lang, "subscription_interval_changed", intervalText(lang, interval))
+	})
+}
+
+// HandleSubscriptionPull turns downloading of missing tracks on or off
+func (h *handler) HandleSubscriptionPull(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id, value := subscriptionCallbackData(c)
+	noPull := value == "nopull"
+
+	h.updateSubscription(c, lang, id, db.SubscriptionUpdate{NoPull: &noPull}, func(models.SubscribedPlaylist) string {
+		if noPull {
+			return i18n.T(lang, "subscribe_nopull")
+		}
+		return i18n.T(lang, "subscribe_pull")
+	})
+}
+
+func (h *handler) HandleSubscriptionPause(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id, value := subscriptionCallbackData(c)
+	paused := value == "pause"
+
+	h.updateSubscription(c, lang, id, db.SubscriptionUpdate{Paused: &paused}, func(models.SubscribedPlaylist) string {
+		if paused {
+			return i18n.T(lang, "subscription_paused")
+		}
+		return i18n.T(lang, "subscription_resumed")
+	})
+}
+
+// HandleSubscriptionSync makes the subscription due so the next
+// dynamic-playlists run syncs it whatever its interval
+func (h *handler) HandleSubscriptionSync(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id, _ := subscriptionCallbackData(c)
+
+	h.updateSubscription(c, lang, id, db.SubscriptionUpdate{SyncNow: true}, func(sub models.SubscribedPlaylist) string {
+		if sub.Paused {
+			return i18n.T(lang, "subscription_sync_paused", sub.Name)
+		}
+		return i18n.T(lang, "subscription_sync_queued", sub.Name)
+	})
+}
+
+// HandleSubscriptionNotify turns change reports after each sync on or off
+func (h *handler) HandleSubscriptionNotify(c *telebot.Callback) {
+	lang := h.lang(c.Sender)
+
+	id, value := subscriptionCallbackData(c)
+	notify := value == "on"
+
+	h.updateSubscription(c, lang, id, db.SubscriptionUpdate{NotifyChanges: &notify}, func(models.SubscribedPlaylist) string {
+		if notify {
+			return i18n.T(lang, "subscription_notify_on")
+		}
+		return i18n.T(lang, "subscription_notify_off")
+	})
+}
+
+// subscriptionCallbackData splits the button data into the subscription id
+// and the value the button sets
+func subscriptionCallbackData(c *telebot.Callback) (string, string) {
+	id, value, _ := strings.Cut(strings.TrimSpace(c.Data), "|")
+	return id, value
+}
+
+// updateSubscription applies the update to a subscription of the sender,
+// redraws the /subscriptions message and answers with the text done returns
+// for the updated subscription
+func (h *handler) updateSubscription(c *telebot.Callback, lang i18n.Lang, id string, update db.SubscriptionUpdate, done func(sub models.SubscribedPlaylist) string) {
+	ctx := context.Background()
+
+	sub, err := h.db.UpdateSubscribedPlaylist(ctx, id, c.Sender.ID, update)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_ = h.respondCallbackFn(c, i18n.T(lang, "subscription_gone"), true)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to update subscription", zap.Error(err), zap.String("id", id))
+		_ = h.respondCallbackFn(c, i18n.T(lang, "subscription_update_failed"), true)
+		return
+	}
+
+	h.log.Info("Subscription updated", zap.String("id", sub.ID), zap.Int64("user_id", c.Sender.ID))
+	h.refreshSubscriptions(ctx, c, lang)
+	_ = h.respondCallbackFn(c, done(sub), false)
+}
+
+// refreshSubscriptions redraws the /subscriptions message a button was pressed on
+func (h *handler) refreshSubscriptions(ctx context.Context, c *telebot.Callback, lang i18n.Lang) {
+	if c.Message == nil {
+		return
+	}
+
+	text, markup, err := h.renderSubscriptions(ctx, c.Sender.ID, lang)
+	if err != nil {
+		h.log.Error("Failed to render subscriptions", zap.Error(err))
+		return
+	}
+
+	if err := h.editFailedPageFn(c.Message, text, markup); err != nil {
+		h.log.Error("Failed to edit subscriptions", zap.Error(err))
+	}
+}
+
+func (h *handler) renderSubscriptions(ctx context.Context, userID int64, lang i18n.Lang) (string, *telebot.ReplyMarkup, error) {
+	subscriptions, err := h.db.GetSubscribedPlaylists(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(subscriptions) == 0 {
+		return i18n.T(lang, "subscriptions_empty"), nil, nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, min(len(subscriptions), maxSubscriptionButtonRows))
+
+	response := i18n.T(lang, "subscriptions_header") + i18n.T(lang, "subscriptions_actions")
+	for i, sub := range subscriptions {
+		pullText := i18n.T(lang, "subscription_pull")
+		if sub.NoPull {
+			pullText = i18n.T(lang, "subscription_nopull")
+		}
+
+		lastSyncedText := i18n.T(lang, "subscription_not_synced")
+		if sub.LastSynced > 0 {
+			lastSynced := time.Unix(sub.LastSynced, 0)
+			lastSyncedText = lastSynced.Format("02.01.2006 15:04")
+		}
+
+		response += fmt.Sprintf("%d. 🎵 %s\n", i+1, sub.Name)
+		response += fmt.Sprintf("   📎 %s\n", sub.SpotifyURL)
+		if sub.Paused {
+			response += i18n.T(lang, "subscription_paused_status")
+		}
+		if sub.NotifyChanges {
+			response += i18n.T(lang, "subscription_notify_status")
+		}
+		if sub.RemovalPolicy.TracksRemoved() {
+			response += i18n.T(lang, "subscription_removal_status", removalPolicyText(lang, sub.RemovalPolicy))
+		}
+		response += i18n.T(lang, "subscription_interval", intervalText(lang, sub.RefreshInterval))
+		response += fmt.Sprintf("   📥 %s\n", pullText)
+		response += i18n.T(lang, "subscription_last_synced", lastSyncedText)
+		if sub.LastTrackCount > 0 {
+			response += fmt.Sprintf("   🎶 %s\n", i18n.N(lang, "tracks", sub.LastTrackCount))
+		}
+		response += "\n"
+
+		if i < maxSubscriptionButtonRows {
+			rows = append(rows, subscriptionRow(markup, lang, i+1, sub))
+		}
+	}
+
+	markup.Inline(rows...)
+	return response, markup, nil
+}
+
+// subscriptionRow returns the buttons of one subscription, each carrying the
+// value it sets so pressing one on an outdated message does what it says
+func subscriptionRow(markup *telebot.ReplyMarkup, lang i18n.Lang, n int, sub models.SubscribedPlaylist) telebot.Row {
+	pull := "nopull"
+	if sub.NoPull {
+		pull = "pull"
+	}
+
+	pause, pauseKey := "pause", "subscription_pause_button"
+	if sub.Paused {
+		pause, pauseKey = "resume", "subscription_resume_button"
+	}
+
+	notify, notifyKey := "on", "subscription_notify_button"
+	if sub.NotifyChanges {
+		notify, notifyKey = "off", "subscription_mute_button"
+	}
+
+	return markup.Row(
+		markup.Data(i18n.T(lang, "subscription_interval_button", n), subscriptionIntervalCallbackUnique, sub.ID, nextInterval(sub.RefreshInterval)),
+		markup.Data(i18n.T(lang, "subscription_pull_button", n), subscriptionPullCallbackUnique, sub.ID, pull),
+		markup.Data(i18n.T(lang, pauseKey, n), subscriptionPauseCallbackUnique, sub.ID, pause),
+		markup.Data(i18n.T(lang, "subscription_sync_button", n), subscriptionSyncCallbackUnique, sub.ID),
+		markup.Data(i18n.T(lang, notifyKey, n), subscriptionNotifyCallbackUnique, sub.ID, notify),
+	)
+}
+
+// nextInterval returns the interval after the given one in subscriptionIntervals
+func nextInterval(interval string) string {
+	i := slices.Index(subscriptionIntervals, interval)
+	return subscriptionIntervals[(i+1)%len(subscriptionIntervals)]
+}
+
+// removalPolicyText returns what happens to tracks removed from a
+// subscription in the user's language
+func removalPolicyText(lang i18n.Lang, policy models.RemovalPolicy) string {
+	switch policy {
+	case models.RemovalKeep:
+		return i18n.T(lang, "removal_keep")
+	case models.RemovalArchive:
+		return i18n.T(lang, "removal_archive")
+	default:
+		return i18n.T(lang, "removal_mirror")
+	}
+}
+
+// intervalText returns the refresh interval of a subscription in the user's language
+func intervalText(lang i18n.Lang, refreshInterval string) string {
+	switch refreshInterval {
+	case "weekly":
+		return i18n.T(lang, "interval_weekly")
+	case "hourly":
+		return i18n.T(lang, "interval_hourly")
+	default:
+		return i18n.T(lang, "interval_daily")
+	}
+}
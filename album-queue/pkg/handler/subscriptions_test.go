@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func createSubscriptionsTestHandler() (*handler, *fakeDatabase, *testSinks) {
+	db := &fakeDatabase{subscribedPlaylists: []models.SubscribedPlaylist{
+		{ID: "sub-1", CreatorID: 1, Name: "Mix", Active: true, RefreshInterval: "daily", LastSynced: 1700000000},
+		{ID: "sub-2", CreatorID: 2, Name: "Other", Active: true, RefreshInterval: "daily"},
+	}}
+	sinks := &testSinks{}
+	return createTestHandler(db, sinks), db, sinks
+}
+
+func subscriptionButton(t *testing.T, markup *telebot.ReplyMarkup, unique string) *telebot.Callback {
+	t.Helper()
+	for _, row := range markup.InlineKeyboard {
+		for _, btn := range row {
+			if btn.Unique == unique {
+				return &telebot.Callback{Sender: &telebot.User{ID: 1}, Message: &telebot.Message{}, Data: btn.Data}
+			}
+		}
+	}
+	t.Fatalf("no %s button in %+v", unique, markup.InlineKeyboard)
+	return nil
+}
+
+func TestHandleListSubscriptionsShowsButtons(t *testing.T) {
+	h, _, sinks := createSubscriptionsTestHandler()
+
+	h.HandleListSubscriptions(testMessage("/subscriptions"))
+
+	if len(sinks.sentPages) != 1 {
+		t.Fatalf("expected one message, got %d", len(sinks.sentPages))
+	}
+	page := sinks.sentPages[0]
+	if !strings.Contains(page.text, "Mix") || strings.Contains(page.text, "Other") {
+		t.Fatalf("expected only the user's subscriptions, got %q", page.text)
+	}
+
+	rows := page.markup.InlineKeyboard
+	if len(rows) != 1 || len(rows[0]) != 5 {
+		t.Fatalf("unexpected buttons: %+v", rows)
+	}
+	if rows[0][0].Data != "sub-1|weekly" || rows[0][1].Data != "sub-1|nopull" || rows[0][2].Data != "sub-1|pause" || rows[0][3].Data != "sub-1" || rows[0][4].Data != "sub-1|on" {
+		t.Fatalf("unexpected button data: %+v", rows[0])
+	}
+}
+
+func TestSubscriptionButtonsUpdateSubscription(t *testing.T) {
+	h, db, sinks := createSubscriptionsTestHandler()
+
+	h.HandleListSubscriptions(testMessage("/subscriptions"))
+	markup := sinks.sentPages[0].markup
+
+	h.HandleSubscriptionInterval(subscriptionButton(t, markup, subscriptionIntervalCallbackUnique))
+	h.HandleSubscriptionPull(subscriptionButton(t, markup, subscriptionPullCallbackUnique))
+	h.HandleSubscriptionPause(subscriptionButton(t, markup, subscriptionPauseCallbackUnique))
+	h.HandleSubscriptionSync(subscriptionButton(t, markup, subscriptionSyncCallbackUnique))
+
+	sub := db.subscribedPlaylists[0]
+	if sub.RefreshInterval != "weekly" || !sub.NoPull || !sub.Paused || sub.LastSynced != 0 {
+		t.Fatalf("unexpected subscription: %+v", sub)
+	}
+
+	if len(sinks.editedPages) != 4 {
+		t.Fatalf("expected the list to be redrawn after each change, got %d", len(sinks.editedPages))
+	}
+	last := sinks.editedPages[3]
+	if !strings.Contains(last.text, i18n.T(i18n.Ukrainian, "subscription_paused_status")) {
+		t.Fatalf("expected the subscription to show as paused, got %q", last.text)
+	}
+	if btn := last.markup.InlineKeyboard[0][2]; btn.Data != "sub-1|resume" {
+		t.Fatalf("expected a resume button, got %+v", btn)
+	}
+	if ack := sinks.callbackAcks[3]; ack.showAlert || ack.text != i18n.T(i18n.Ukrainian, "subscription_sync_paused", "Mix") {
+		t.Fatalf("expected sync of a paused subscription to say so, got %+v", ack)
+	}
+}
+
+func TestSubscriptionButtonsAreScopedToCreator(t *testing.T) {
+	h, db, sinks := createSubscriptionsTestHandler()
+
+	h.HandleSubscriptionPause(&telebot.Callback{Sender: &telebot.User{ID: 1}, Data: "sub-2|pause"})
+
+	if db.subscribedPlaylists[1].Paused {
+		t.Fatal("expected another user's subscription to stay as it was")
+	}
+	if len(sinks.callbackAcks) != 1 || !sinks.callbackAcks[0].showAlert {
+		t.Fatalf("unexpected callback acks: %#v", sinks.callbackAcks)
+	}
+}
+
+func TestSubscriptionNotifyButtonTogglesReports(t *testing.T) {
+	h, db, sinks := createSubscriptionsTestHandler()
+
+	h.HandleListSubscriptions(testMessage("/subscriptions"))
+	h.HandleSubscriptionNotify(subscriptionButton(t, sinks.sentPages[0].markup, subscriptionNotifyCallbackUnique))
+
+	if !db.subscribedPlaylists[0].NotifyChanges {
+		t.Fatal("expected change reports to be turned on")
+	}
+
+	h.HandleSubscriptionNotify(subscriptionButton(t, sinks.editedPages[0].markup, subscriptionNotifyCallbackUnique))
+
+	if db.subscribedPlaylists[0].NotifyChanges {
+		t.Fatal("expected change reports to be turned off again")
+	}
+}
+
+func TestHandleChangesListsChangedTracks(t *testing.T) {
+	db := &fakeDatabase{snapshots: []models.SubscriptionSnapshot{
+		{
+			CreatorID: 1,
+			Name:      "Mix",
+			Changed:   true,
+			Added: []models.SnapshotTrack{
+				{Artist: "Artist", Title: "One"},
+				{Artist: "Artist", Title: "Two"},
+				{Artist: "Artist", Title: "Three"},
+				{Artist: "Artist", Title: "Four"},
+			},
+			Removed: []models.SnapshotTrack{{Artist: "Artist", Title: "Gone"}},
+		},
+		{CreatorID: 2, Name: "Other", Changed: true, Added: []models.SnapshotTrack{{Artist: "Artist", Title: "Else"}}},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleChanges(testMessage("/changes"))
+
+	if len(sinks.replies) != 1 {
+		t.Fatalf("expected one reply, got %#v", sinks.replies)
+	}
+	text := sinks.replies[0]
+	if !strings.Contains(text, "Mix") || !strings.Contains(text, "Artist - Gone") || strings.Contains(text, "Other") {
+		t.Fatalf("unexpected changes: %q", text)
+	}
+	if !strings.Contains(text, "Artist - Three") || strings.Contains(text, "Artist - Four") {
+		t.Fatalf("expected the added tracks to be capped at %d, got %q", changesTracksShown, text)
+	}
+}
+
+func TestHandleChangesEmpty(t *testing.T) {
+	sinks := &testSinks{}
+	h := createTestHandler(&fakeDatabase{}, sinks)
+
+	h.HandleChanges(testMessage("/changes"))
+
+	if len(sinks.replies) != 1 || sinks.replies[0] != i18n.T(i18n.Ukrainian, "changes_empty") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleSubscribeWithNotify(t *testing.T) {
+	const playlistURL = "https://open.spotify.com/playlist/mix"
+
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{playlistURL: "Mix"}}
+
+	h.HandleSubscribe(testMessage("/subscribe " + playlistURL + " notify"))
+
+	if len(database.subscriptions) != 1 || !database.subscriptions[0].notifyChanges {
+		t.Fatalf("expected a subscription with change reports, got %+v", database.subscriptions)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "🔔") {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
+
+func TestHandleSubscribeWithRemovalPolicy(t *testing.T) {
+	const playlistURL = "https://open.spotify.com/playlist/mix"
+
+	database := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(database, sinks)
+	h.spotifyService = &fakeSpotifyService{names: map[string]string{playlistURL: "Mix"}}
+
+	h.HandleSubscribe(testMessage("/subscribe " + playlistURL + " archive"))
+
+	if len(database.subscriptions) != 1 || database.subscriptions[0].removalPolicy != models.RemovalArchive {
+		t.Fatalf("expected a subscription archiving removed tracks, got %+v", database.subscriptions)
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], i18n.T(i18n.Ukrainian, "removal_archive")) {
+		t.Fatalf("unexpected replies: %#v", sinks.replies)
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/utils"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// parseUserArgs parses "<user_id> [role]" as used by /adduser and /role
+func parseUserArgs(args string) (userID int64, role models.Role, ok bool) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, "", false
+	}
+
+	userID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, "", false
+	}
+
+	if len(fields) == 2 {
+		role = models.Role(strings.ToLower(fields[1]))
+	}
+
+	return userID, role, true
+}
+
+// checkManageable replies and returns false when the target user can't be
+// managed from the bot: admins from BOT_WHITELIST live in the config and
+// admins can't change themselves so they don't lock themselves out.
+func (h *handler) checkManageable(m *telebot.Message, lang i18n.Lang, userID int64) bool {
+	if utils.InWhiteList(userID, h.whiteList) {
+		h.reply(m, i18n.T(lang, "user_whitelisted"))
+		return false
+	}
+	if userID == m.Sender.ID {
+		h.reply(m, i18n.T(lang, "user_self"))
+		return false
+	}
+	return true
+}
+
+func (h *handler) HandleAddUser(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	userID, role, ok := parseUserArgs(commandArgs(m.Text))
+	if !ok {
+		h.reply(m, i18n.T(lang, "adduser_usage"))
+		return
+	}
+	if role == "" {
+		role = models.RoleMember
+	}
+	if !role.Valid() {
+		h.reply(m, i18n.T(lang, "unknown_role", role))
+		return
+	}
+	if !h.checkManageable(m, lang, userID) {
+		return
+	}
+
+	ctx := context.Background()
+	if err := h.db.SetUserRole(ctx, userID, role, m.Sender.ID); err != nil {
+		h.log.Error("Failed to add user", zap.Error(err), zap.Int64("user_id", userID))
+		h.reply(m, i18n.T(lang, "user_save_failed"))
+		return
+	}
+
+	h.log.Info("User added", zap.Int64("user_id", userID), zap.String("role", string(role)), zap.Int64("added_by", m.Sender.ID))
+	h.reply(m, i18n.T(lang, "user_added", userID, role))
+
+	if err := h.sendMessageFn(userID, i18n.T(h.userLang(ctx, userID, ""), "user_added_notify", role)); err != nil {
+		h.log.Warn("Failed to notify added user", zap.Error(err), zap.Int64("user_id", userID))
+	}
+}
+
+func (h *handler) HandleRole(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	userID, role, ok := parseUserArgs(commandArgs(m.Text))
+	if !ok || role == "" {
+		h.reply(m, i18n.T(lang, "role_usage"))
+		return
+	}
+	if !role.Valid() {
+		h.reply(m, i18n.T(lang, "unknown_role", role))
+		return
+	}
+	if !h.checkManageable(m, lang, userID) {
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := h.db.GetUser(ctx, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			h.reply(m, i18n.T(lang, "user_not_found"))
+			return
+		}
+		h.log.Error("Failed to get user", zap.Error(err), zap.Int64("user_id", userID))
+		h.reply(m, i18n.T(lang, "user_save_failed"))
+		return
+	}
+
+	if err := h.db.SetUserRole(ctx, userID, role, m.Sender.ID); err != nil {
+		h.log.Error("Failed to change user role", zap.Error(err), zap.Int64("user_id", userID))
+		h.reply(m, i18n.T(lang, "user_save_failed"))
+		return
+	}
+
+	h.log.Info("User role changed", zap.Int64("user_id", userID), zap.String("role", string(role)), zap.Int64("changed_by", m.Sender.ID))
+	h.reply(m, i18n.T(lang, "user_role_changed", userID, role))
+}
+
+func (h *handler) HandleUsers(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	users, err := h.db.ListUsers(context.Background())
+	if err != nil {
+		h.log.Error("Failed to list users", zap.Error(err))
+		h.reply(m, i18n.T(lang, "users_fetch_failed"))
+		return
+	}
+
+	if len(users) == 0 && len(h.whiteList) == 0 {
+		h.reply(m, i18n.T(lang, "users_empty"))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(i18n.T(lang, "users_header"))
+	for _, id := range h.whiteList {
+		fmt.Fprintf(&b, "\n• %d — %s (BOT_WHITELIST)", id, models.RoleAdmin)
+	}
+	for _, user := range users {
+		if utils.InWhiteList(user.ID, h.whiteList) {
+			continue
+		}
+		fmt.Fprintf(&b, "\n• %d — %s", user.ID, user.Role)
+		if user.Name != "" {
+			fmt.Fprintf(&b, " (%s)", user.Name)
+		}
+	}
+
+	h.reply(m, b.String())
+}
+
+func (h *handler) HandleRemoveUser(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	userID, role, ok := parseUserArgs(commandArgs(m.Text))
+	if !ok || role != "" {
+		h.reply(m, i18n.T(lang, "removeuser_usage"))
+		return
+	}
+	if !h.checkManageable(m, lang, userID) {
+		return
+	}
+
+	if err := h.db.DeleteUser(context.Background(), userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			h.reply(m, i18n.T(lang, "user_not_found"))
+			return
+		}
+		h.log.Error("Failed to remove user", zap.Error(err), zap.Int64("user_id", userID))
+		h.reply(m, i18n.T(lang, "user_remove_failed"))
+		return
+	}
+
+	h.log.Info("User removed", zap.Int64("user_id", userID), zap.Int64("removed_by", m.Sender.ID))
+	h.reply(m, i18n.T(lang, "user_removed", userID))
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func userMessage(userID int64, text string) *telebot.Message {
+	return &telebot.Message{Text: text, Sender: &telebot.User{ID: userID}}
+}
+
+func TestAuthorizeIgnoresUnknownUsers(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	called := false
+	next := func(*telebot.Message) { called = true }
+
+	h.Authorize("/queue", next)(userMessage(42, "/queue"))
+	if called || len(sinks.replies) != 0 {
+		t.Fatalf("expected unknown user to be ignored, called=%v replies=%#v", called, sinks.replies)
+	}
+
+	h.Authorize("/start", next)(userMessage(42, "/start"))
+	if called {
+		t.Fatal("expected /start handler not to run for unknown user")
+	}
+	if len(sinks.replies) != 1 || !strings.Contains(sinks.replies[0], "/adduser 42") {
+		t.Fatalf("expected registration hint, got %#v", sinks.replies)
+	}
+}
+
+func TestAuthorizeChecksRole(t *testing.T) {
+	db := &fakeDatabase{users: map[int64]models.User{
+		2: {ID: 2, Role: models.RoleReadOnly},
+		3: {ID: 3, Role: models.RoleMember},
+	}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	var calls []int64
+	next := func(m *telebot.Message) { calls = append(calls, m.Sender.ID) }
+
+	h.Authorize("/queue", next)(userMessage(2, "/queue"))
+	h.Authorize("/subscribe", next)(userMessage(2, "/subscribe x"))
+	h.Authorize("/subscribe", next)(userMessage(3, "/subscribe x"))
+	h.Authorize("/adduser", next)(userMessage(3, "/adduser 5"))
+	h.Authorize("/adduser", next)(userMessage(1, "/adduser 5"))
+
+	if len(calls) != 3 || calls[0] != 2 || calls[1] != 3 || calls[2] != 1 {
+		t.Fatalf("unexpected handler calls: %v", calls)
+	}
+	if len(sinks.replies) != 2 {
+		t.Fatalf("expected 2 permission denied replies, got %#v", sinks.replies)
+	}
+	for _, reply := range sinks.replies {
+		if !strings.Contains(reply, "тобі сюди не можна") {
+			t.Fatalf("unexpected reply: %q", reply)
+		}
+	}
+}
+
+func TestAuthorizeSyncsUserName(t *testing.T) {
+	db := &fakeDatabase{users: map[int64]models.User{2: {ID: 2, Role: models.RoleMember}}}
+	h := createTestHandler(db, &testSinks{})
+
+	m := &telebot.Message{Text: "/queue", Sender: &telebot.User{ID: 2, Username: "max"}}
+	h.Authorize("/queue", func(*telebot.Message) {})(m)
+
+	if db.users[2].Name != "@max" {
+		t.Fatalf("expected name to be saved, got %q", db.users[2].Name)
+	}
+	if db.getUserCalls != 1 {
+		t.Fatalf("expected the user to be fetched once, got %d", db.getUserCalls)
+	}
+}
+
+func TestHandleAddUser(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleAddUser(testMessage("/adduser 42"))
+
+	user, ok := db.users[42]
+	if !ok || user.Role != models.RoleMember || user.AddedBy != 1 {
+		t.Fatalf("expected member added by 1, got %+v", user)
+	}
+	if len(sinks.sentMessages[42]) != 1 {
+		t.Fatalf("expected added user to be notified, got %#v", sinks.sentMessages)
+	}
+
+	h.HandleAddUser(testMessage("/adduser 43 boss"))
+	if _, ok := db.users[43]; ok {
+		t.Fatal("expected unknown role to be rejected")
+	}
+
+	h.HandleAddUser(testMessage("/adduser 1 read-only"))
+	if _, ok := db.users[1]; ok {
+		t.Fatal("expected whitelisted admin not to be changed")
+	}
+}
+
+func TestHandleRole(t *testing.T) {
+	db := &fakeDatabase{users: map[int64]models.User{42: {ID: 42, Role: models.RoleMember}}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleRole(testMessage("/role 42 read-only"))
+	if db.users[42].Role != models.RoleReadOnly {
+		t.Fatalf("expected role to change, got %q", db.users[42].Role)
+	}
+
+	h.HandleRole(testMessage("/role 7 admin"))
+	if _, ok := db.users[7]; ok {
+		t.Fatal("expected /role not to create users")
+	}
+	if last := sinks.replies[len(sinks.replies)-1]; !strings.Contains(last, "нема такого користувача") {
+		t.Fatalf("unexpected reply: %q", last)
+	}
+}
+
+func TestHandleRemoveUser(t *testing.T) {
+	db := &fakeDatabase{users: map[int64]models.User{42: {ID: 42, Role: models.RoleMember}}}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleRemoveUser(testMessage("/removeuser 42"))
+	if _, ok := db.users[42]; ok {
+		t.Fatal("expected user to be removed")
+	}
+
+	h.HandleRemoveUser(testMessage("/removeuser 42"))
+	if last := sinks.replies[len(sinks.replies)-1]; !strings.Contains(last, "нема такого користувача") {
+		t.Fatalf("unexpected reply: %q", last)
+	}
+}
+
+func TestHandleDeactivateChecksOwnership(t *testing.T) {
+	db := &fakeDatabase{
+		users: map[int64]models.User{2: {ID: 2, Role: models.RoleMember}},
+		requests: map[string]models.DownloadQueueRequest{
+			"mine":   {ID: "mine", CreatorID: 2},
+			"theirs": {ID: "theirs", CreatorID: 3},
+		},
+	}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleDeactivate(userMessage(2, "/deactivate theirs"))
+	h.HandleDeactivate(userMessage(2, "/deactivate mine"))
+	h.HandleDeactivate(testMessage("/deactivate theirs"))
+
+	if len(db.deactivated) != 2 || db.deactivated[0] != "mine" || db.deactivated[1] != "theirs" {
+		t.Fatalf("unexpected deactivated requests: %v", db.deactivated)
+	}
+	if !strings.Contains(sinks.replies[0], "це не твій запит") {
+		t.Fatalf("unexpected reply: %q", sinks.replies[0])
+	}
+}
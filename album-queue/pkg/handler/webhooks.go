@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// parseWebhookArgs parses "<url> <event,...|all>", the events may also be
+// separated by spaces
+func parseWebhookArgs(args string) (string, []webhook.Event, error) {
+	fields := strings.FieldsFunc(args, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) < 2 {
+		return "", nil, errors.New("missing url or events")
+	}
+
+	target, err := url.Parse(fields[0])
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", nil, fmt.Errorf("invalid url %q", fields[0])
+	}
+
+	var events []webhook.Event
+	for _, field := range fields[1:] {
+		if field == "all" {
+			return fields[0], webhook.Events, nil
+		}
+		event := webhook.Event(field)
+		if !slices.Contains(webhook.Events, event) {
+			return "", nil, fmt.Errorf("unknown event %q", field)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	return fields[0], events, nil
+}
+
+func eventList(events []webhook.Event) string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ", ")
+}
+
+// HandleWebhookAdd subscribes an endpoint to webhook events and replies with
+// the secret its payloads are signed with
+func (h *handler) HandleWebhookAdd(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	target, events, err := parseWebhookArgs(commandArgs(m.Text))
+	if err != nil {
+		h.reply(m, i18n.T(lang, "webhookadd_usage", eventList(webhook.Events)))
+		return
+	}
+
+	subscription, err := h.db.NewWebhookSubscription(context.Background(), target, events, m.Sender.ID)
+	if err != nil {
+		h.log.Error("Failed to add webhook subscription", zap.Error(err), zap.String("url", target))
+		h.reply(m, i18n.T(lang, "webhook_save_failed"))
+		return
+	}
+
+	h.log.Info("Webhook subscription added", zap.String("id", subscription.ID), zap.String("url", target), zap.Int64("user_id", m.Sender.ID))
+	h.reply(m, i18n.T(lang, "webhook_added", subscription.ID, eventList(events), subscription.Secret))
+}
+
+// HandleWebhooks lists the webhook subscriptions with their last delivery
+func (h *handler) HandleWebhooks(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+	ctx := context.Background()
+
+	subscriptions, err := h.db.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		h.log.Error("Failed to list webhook subscriptions", zap.Error(err))
+		h.reply(m, i18n.T(lang, "webhooks_fetch_failed"))
+		return
+	}
+
+	if len(subscriptions) == 0 {
+		h.reply(m, i18n.T(lang, "webhooks_empty"))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(i18n.T(lang, "webhooks_header"))
+	for _, subscription := range subscriptions {
+		fmt.Fprintf(&b, "\n\n🔗 %s\n   %s\n   %s", subscription.URL, subscription.ID, eventList(subscription.Events))
+
+		deliveries, err := h.db.GetWebhookDeliveries(ctx, subscription.ID, 1)
+		if err != nil {
+			h.log.Warn("Failed to get webhook deliveries", zap.Error(err), zap.String("id", subscription.ID))
+			continue
+		}
+		if len(deliveries) == 0 {
+			b.WriteString("\n   " + i18n.T(lang, "webhook_never_delivered"))
+			continue
+		}
+
+		last := deliveries[0]
+		when := time.Unix(last.FinishedAt, 0).Format("02.01.2006 15:04")
+		if last.Delivered {
+			b.WriteString("\n   " + i18n.T(lang, "webhook_last_delivered", last.Event, when))
+		} else {
+			b.WriteString("\n   " + i18n.T(lang, "webhook_last_failed", last.Event, when, last.Attempts, last.Error))
+		}
+	}
+
+	h.reply(m, b.String())
+}
+
+func (h *handler) HandleWebhookDelete(m *telebot.Message) {
+	lang := h.lang(m.Sender)
+
+	id := commandArgs(m.Text)
+	if id == "" || strings.Contains(id, " ") {
+		h.reply(m, i18n.T(lang, "webhookdel_usage"))
+		return
+	}
+
+	if err := h.db.DeleteWebhookSubscription(context.Background(), id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			h.reply(m, i18n.T(lang, "webhook_not_found"))
+			return
+		}
+		h.log.Error("Failed to delete webhook subscription", zap.Error(err), zap.String("id", id))
+		h.reply(m, i18n.T(lang, "webhook_delete_failed"))
+		return
+	}
+
+	h.log.Info("Webhook subscription deleted", zap.String("id", id), zap.Int64("user_id", m.Sender.ID))
+	h.reply(m, i18n.T(lang, "webhook_deleted", id))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	"github.com/supperdoggy/spot-models/webhook"
+)
+
+func TestParseWebhookArgs(t *testing.T) {
+	url, events, err := parseWebhookArgs("https://ha.local/api/webhook/music request.completed,request.failed request.completed")
+	if err != nil || url != "https://ha.local/api/webhook/music" {
+		t.Fatalf("unexpected result: %q %v", url, err)
+	}
+	if len(events) != 2 || events[0] != webhook.EventRequestCompleted || events[1] != webhook.EventRequestFailed {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	if _, events, _ := parseWebhookArgs("http://ha.local all"); len(events) != len(webhook.Events) {
+		t.Fatalf("expected all events, got %v", events)
+	}
+
+	for _, args := range []string{"", "https://ha.local", "ftp://ha.local all", "https://ha.local request.deleted"} {
+		if _, _, err := parseWebhookArgs(args); err == nil {
+			t.Errorf("expected %q to be rejected", args)
+		}
+	}
+}
+
+func TestWebhookCommands(t *testing.T) {
+	db := &fakeDatabase{}
+	sinks := &testSinks{}
+	h := createTestHandler(db, sinks)
+
+	h.HandleWebhookAdd(testMessage("/webhookadd https://ha.local/hook track.downloaded"))
+
+	if len(db.webhooks) != 1 || db.webhooks[0].CreatedBy != 1 || !db.webhooks[0].Wants(webhook.EventTrackDownloaded) {
+		t.Fatalf("unexpected webhook subscriptions: %+v", db.webhooks)
+	}
+	if want := i18n.T(i18n.Ukrainian, "webhook_added", "hook-1", "track.downloaded", "secret"); sinks.replies[0] != want {
+		t.Fatalf("expected the secret in the reply, got %q", sinks.replies[0])
+	}
+
+	db.deliveries = []webhook.Delivery{{SubscriptionID: "hook-1", Event: webhook.EventTrackDownloaded, Attempts: 5, Error: "unexpected status 502"}}
+	h.HandleWebhooks(testMessage("/webhooks"))
+
+	if list := sinks.replies[1]; !strings.Contains(list, "https://ha.local/hook") || !strings.Contains(list, "unexpected status 502") || strings.Contains(list, "secret") {
+		t.Fatalf("unexpected list: %q", list)
+	}
+
+	h.HandleWebhookDelete(testMessage("/webhookdel hook-1"))
+	h.HandleWebhookDelete(testMessage("/webhookdel hook-1"))
+
+	if db.webhooks[0].Active {
+		t.Fatal("expected the subscription to be deactivated")
+	}
+	if sinks.replies[3] != i18n.T(i18n.Ukrainian, "webhook_not_found") {
+		t.Fatalf("expected the second delete to find nothing, got %q", sinks.replies[3])
+	}
+}
@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	Ukrainian Lang = "uk"
+	English   Lang = "en"
+
+	Default = Ukrainian
+)
+
+// Supported lists the languages users can pick with /lang
+var Supported = []Lang{Ukrainian, English}
+
+// Name returns the language name in the language itself
+func (l Lang) Name() string {
+	switch l {
+	case Ukrainian:
+		return "українська"
+	case English:
+		return "English"
+	default:
+		return string(l)
+	}
+}
+
+// Parse maps a language code such as "en", "en-US" or "ua" to a supported language
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if base, _, found := strings.Cut(code, "-"); found {
+		code = base
+	}
+
+	switch code {
+	case "uk", "ua":
+		return Ukrainian, true
+	case "en":
+		return English, true
+	default:
+		return "", false
+	}
+}
+
+// T returns the message for key in the given language, falling back to the
+// default language and finally to the key itself. Args are applied with fmt.Sprintf.
+func T(lang Lang, key string, args ...any) string {
+	translations, ok := messages[key]
+	if !ok {
+		return key
+	}
+	text, ok := translations[lang]
+	if !ok {
+		text = translations[Default]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N returns the plural form of key matching n, formatted with n, e.g.
+// N(Ukrainian, "tracks", 5) is "5 треків"
+func N(lang Lang, key string, n int) string {
+	translations, ok := plurals[key]
+	if !ok {
+		return fmt.Sprintf("%d %s", n, key)
+	}
+	forms, ok := translations[lang]
+	if !ok {
+		lang = Default
+		forms = translations[Default]
+	}
+
+	index := pluralIndex(lang, n)
+	if index >= len(forms) {
+		index = len(forms) - 1
+	}
+	return fmt.Sprintf(forms[index], n)
+}
+
+// pluralIndex picks the CLDR plural category: Ukrainian has one/few/many,
+// English has one/other
+func pluralIndex(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case Ukrainian:
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return 0
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	default:
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogIsComplete(t *testing.T) {
+	for key, translations := range messages {
+		for _, lang := range Supported {
+			if strings.TrimSpace(translations[lang]) == "" {
+				t.Errorf("message %q has no %s translation", key, lang)
+			}
+		}
+		if strings.Count(translations[Ukrainian], "%") != strings.Count(translations[English], "%") {
+			t.Errorf("message %q has different format verbs across languages", key)
+		}
+	}
+
+	for key, translations := range plurals {
+		if len(translations[Ukrainian]) != 3 {
+			t.Errorf("plural %q needs 3 Ukrainian forms, got %d", key, len(translations[Ukrainian]))
+		}
+		if len(translations[English]) != 2 {
+			t.Errorf("plural %q needs 2 English forms, got %d", key, len(translations[English]))
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(English, "alias_confirmed", "weeknd"); got != "Alias 'weeknd' confirmed 👍" {
+		t.Errorf("unexpected English message: %q", got)
+	}
+	if got := T(Lang("de"), "unlinked"); got != "Спотіфай відключено 👋" {
+		t.Errorf("expected fallback to Ukrainian, got %q", got)
+	}
+	if got := T(English, "no_such_key"); got != "no_such_key" {
+		t.Errorf("expected key for unknown message, got %q", got)
+	}
+}
+
+func TestN(t *testing.T) {
+	tests := []struct {
+		lang     Lang
+		n        int
+		expected string
+	}{
+		{Ukrainian, 1, "1 трек"},
+		{Ukrainian, 3, "3 треки"},
+		{Ukrainian, 5, "5 треків"},
+		{Ukrainian, 11, "11 треків"},
+		{Ukrainian, 12, "12 треків"},
+		{Ukrainian, 21, "21 трек"},
+		{Ukrainian, 22, "22 треки"},
+		{Ukrainian, 0, "0 треків"},
+		{English, 1, "1 track"},
+		{English, 0, "0 tracks"},
+		{English, 21, "21 tracks"},
+		{Lang("de"), 2, "2 треки"},
+	}
+
+	for _, tt := range tests {
+		if got := N(tt.lang, "tracks", tt.n); got != tt.expected {
+			t.Errorf("N(%s, tracks, %d) = %q, want %q", tt.lang, tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected Lang
+		ok       bool
+	}{
+		{"uk", Ukrainian, true},
+		{"UA", Ukrainian, true},
+		{"en", English, true},
+		{"en-US", English, true},
+		{"de", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		lang, ok := Parse(tt.code)
+		if lang != tt.expected || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.code, lang, ok, tt.expected, tt.ok)
+		}
+	}
+}
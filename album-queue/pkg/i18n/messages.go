@@ -0,0 +1,1227 @@
+package i18n
+
+// plurals holds the plural forms of counted words, one/few/many for
+// Ukrainian and one/other for English
+var plurals = map[string]map[Lang][]string{
+	"tracks": {
+		Ukrainian: {"%d трек", "%d треки", "%d треків"},
+		English:   {"%d track", "%d tracks"},
+	},
+	"files": {
+		Ukrainian: {"%d файл", "%d файли", "%d файлів"},
+		English:   {"%d file", "%d files"},
+	},
+}
+
+var messages = map[string]map[Lang]string{
+	// common
+	"start": {
+		Ukrainian: "Привіііііііііт, я бот який кочає музіку на сєрвер, скинь мені урлу на спотік, ютуб мюзік, саундклауд чи бендкемп і я додам в чергу на скачування ❤️",
+		English:   "Hiiii, I'm the bot that downloads music to the server. Send me a Spotify, YouTube Music, SoundCloud or Bandcamp link and I'll queue it for download ❤️",
+	},
+	"unsupported_url": {
+		Ukrainian: "о ніііііі, це не посилання на спотіфай, ютуб мюзік, саундклауд чи бендкемп.... 💔😭",
+		English:   "oh nooo, that's not a Spotify, YouTube Music, SoundCloud or Bandcamp link.... 💔😭",
+	},
+	"not_spotify_url": {
+		Ukrainian: "о ніііііі, це не посилання на спотіфай.... 💔😭",
+		English:   "oh nooo, that's not a Spotify link.... 💔😭",
+	},
+	"queue_add_failed": {
+		Ukrainian: "не получилось додати в чергу, скажи максиму шо шось не так...",
+		English:   "couldn't add it to the queue, tell Maksym something's wrong...",
+	},
+	"permission_denied": {
+		Ukrainian: "тобі сюди не можна 🙅 спитай адміна",
+		English:   "you're not allowed to do that 🙅 ask an admin",
+	},
+	"not_registered": {
+		Ukrainian: "Привіт! Тебе ще нема в списку, попроси адміна додати тебе: /adduser %d",
+		English:   "Hi! You're not on the list yet, ask an admin to add you: /adduser %d",
+	},
+	"invalid_page": {
+		Ukrainian: "невірний номер сторінки",
+		English:   "invalid page number",
+	},
+	"page_prev": {
+		Ukrainian: "⬅️ Назад",
+		English:   "⬅️ Back",
+	},
+	"page_next": {
+		Ukrainian: "Вперед ➡️",
+		English:   "Next ➡️",
+	},
+
+	// queueing links
+	"object_type_failed": {
+		Ukrainian: "не получилось отримати тип об'єкта з %s, спробуй ще раз...",
+		English:   "couldn't get the object type from %s, try again...",
+	},
+	"object_info_failed": {
+		Ukrainian: "не получилось отримати інформацію з %s, спробуй ще раз...",
+		English:   "couldn't get the details from %s, try again...",
+	},
+	"track_count_failed": {
+		Ukrainian: "додав %s в чергу, але не получилось отримати кількість треків...",
+		English:   "queued %s, but couldn't get the track count...",
+	},
+	"track_count_unknown": {
+		Ukrainian: "не получилось отримати кількість треків, а без неї не перевірити твій ліміт. Спробуй пізніше...",
+		English:   "couldn't get the track count, and your track limit can't be checked without it. Try again later...",
+	},
+	"already_queued": {
+		Ukrainian: "це вже є в черзі ⏳",
+		English:   "this is already in the queue ⏳",
+	},
+	"queued": {
+		Ukrainian: "Ураураура успішно додали %s в чергу! (%s) ❤️",
+		English:   "Yaaay, %s is in the queue! (%s) ❤️",
+	},
+
+	// /queue
+	"queue_fetch_failed": {
+		Ukrainian: "не получилося дістати чергу... 💔😭",
+		English:   "couldn't fetch the queue... 💔😭",
+	},
+	"queue_empty": {
+		Ukrainian: "немає активних запитів на скачування...",
+		English:   "no active download requests...",
+	},
+	"queue_header": {
+		Ukrainian: "Активні запити на скачування:\n\n",
+		English:   "Active download requests:\n\n",
+	},
+	"queue_source": {
+		Ukrainian: "   🌐 Джерело: %s\n",
+		English:   "   🌐 Source: %s\n",
+	},
+	"queue_downloaded": {
+		Ukrainian: "   ✅ Завантажено: %d/%d (%.0f%%)\n",
+		English:   "   ✅ Downloaded: %d/%d (%.0f%%)\n",
+	},
+	"queue_remaining": {
+		Ukrainian: "   ⏳ Залишилось: %s\n",
+		English:   "   ⏳ Remaining: %s\n",
+	},
+	"queue_to_download": {
+		Ukrainian: "   📋 Треки для завантаження:\n",
+		English:   "   📋 Tracks to download:\n",
+	},
+	"queue_more": {
+		Ukrainian: "      ... та ще %s\n",
+		English:   "      ... and %s more\n",
+	},
+	"queue_all_downloaded": {
+		Ukrainian: "   🎉 Всі треки завантажені!\n",
+		English:   "   🎉 All tracks downloaded!\n",
+	},
+	"queue_skipped": {
+		Ukrainian: "   ⚠️ Пропущено: %s\n",
+		English:   "   ⚠️ Skipped: %s\n",
+	},
+	"queue_waiting": {
+		Ukrainian: "   ⏳ Очікування завантаження...\n",
+		English:   "   ⏳ Waiting for download...\n",
+	},
+	"queue_errors": {
+		Ukrainian: "   ⚠️ Помилки: %d\n",
+		English:   "   ⚠️ Errors: %d\n",
+	},
+	"queue_actions": {
+		Ukrainian: "❌ скасувати · 🔁 повторити зараз · ⬆️ підняти в черзі · 📋 відсутні треки\n\n",
+		English:   "❌ cancel · 🔁 retry now · ⬆️ bump priority · 📋 missing tracks\n\n",
+	},
+	"queue_cancel_button": {
+		Ukrainian: "❌ %d",
+		English:   "❌ %d",
+	},
+	"queue_retry_button": {
+		Ukrainian: "🔁 %d",
+		English:   "🔁 %d",
+	},
+	"queue_bump_button": {
+		Ukrainian: "⬆️ %d",
+		English:   "⬆️ %d",
+	},
+	"queue_missing_button": {
+		Ukrainian: "📋 %d",
+		English:   "📋 %d",
+	},
+	"queue_request_gone": {
+		Ukrainian: "цього запиту вже нема в черзі, відкрий /queue ще раз.",
+		English:   "this request is no longer in the queue, open /queue again.",
+	},
+	"queue_not_owner": {
+		Ukrainian: "це не твій запит, керувати ним може тільки той хто додав або адмін.",
+		English:   "that's not your request, only whoever added it or an admin can manage it.",
+	},
+	"queue_action_failed": {
+		Ukrainian: "не получилось, спробуй ще раз пізніше...",
+		English:   "that didn't work, try again later...",
+	},
+	"queue_cancelled": {
+		Ukrainian: "Запит скасовано",
+		English:   "Request cancelled",
+	},
+	"queue_retried": {
+		Ukrainian: "Спробую ще раз найближчим часом 🔁",
+		English:   "Will retry it shortly 🔁",
+	},
+	"queue_bumped": {
+		Ukrainian: "Підняв на початок черги ⬆️",
+		English:   "Moved to the front of the queue ⬆️",
+	},
+	"queue_missing_header": {
+		Ukrainian: "📀 %s\nНе вистачає: %s\n\n",
+		English:   "📀 %s\nMissing: %s\n\n",
+	},
+	"queue_no_missing": {
+		Ukrainian: "в цьому запиті вже нічого не бракує 🎉",
+		English:   "nothing is missing from this request 🎉",
+	},
+	"queue_playlists_header": {
+		Ukrainian: "Активні запити на плейлисти:\n\n",
+		English:   "Active playlist requests:\n\n",
+	},
+	"queue_playlist_nopull": {
+		Ukrainian: "   ⚠️ NoPull: true (не завантажувати відсутні треки)\n",
+		English:   "   ⚠️ NoPull: true (missing tracks are not downloaded)\n",
+	},
+	"queue_playlist_pull": {
+		Ukrainian: "   ✅ Завантажувати відсутні треки\n",
+		English:   "   ✅ Download missing tracks\n",
+	},
+
+	// /failed
+	"failed_fetch_failed": {
+		Ukrainian: "не получилось дістати список фейлів на скачування...",
+		English:   "couldn't fetch the failed downloads...",
+	},
+	"failed_empty": {
+		Ukrainian: "нема невирішених фейлів на скачування.",
+		English:   "no unresolved failed downloads.",
+	},
+	"failed_render_failed": {
+		Ukrainian: "не получилось зібрати сторінку фейлів...",
+		English:   "couldn't build the failures page...",
+	},
+	"failed_refresh_failed": {
+		Ukrainian: "не получилось оновити список фейлів",
+		English:   "couldn't refresh the failures",
+	},
+	"failed_header": {
+		Ukrainian: "Невирішені фейли на скачування: %d\n",
+		English:   "Unresolved failed downloads: %d\n",
+	},
+	"page": {
+		Ukrainian: "Сторінка %d/%d\n\n",
+		English:   "Page %d/%d\n\n",
+	},
+	"failed_attempts": {
+		Ukrainian: "   ⚠️ Спроб: %d | Джерел: %d\n\n",
+		English:   "   ⚠️ Attempts: %d | Sources: %d\n\n",
+	},
+	"unknown_artist": {
+		Ukrainian: "невідомий артист",
+		English:   "unknown artist",
+	},
+	"unknown_title": {
+		Ukrainian: "невідома назва",
+		English:   "unknown title",
+	},
+
+	// /redownload
+	"redownload_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /redownload <track_url>.",
+		English:   "I don't get this command. Please use /redownload <track_url>.",
+	},
+	"redownload_not_track": {
+		Ukrainian: "це має бути лінк на трек у форматі https://open.spotify.com/track/... або лінк YouTube Music, SoundCloud чи Bandcamp",
+		English:   "this has to be a track link like https://open.spotify.com/track/... or a YouTube Music, SoundCloud or Bandcamp link",
+	},
+	"active_check_failed": {
+		Ukrainian: "не получилось перевірити активні запити, спробуй ще раз...",
+		English:   "couldn't check the active requests, try again...",
+	},
+	"redownload_already_active": {
+		Ukrainian: "цей трек вже є в активній черзі, редовнлоад пропущено.",
+		English:   "this track is already in the active queue, skipping the redownload.",
+	},
+	"redownload_not_failed": {
+		Ukrainian: "цей трек не знайдений серед невирішених фейлів.",
+		English:   "this track is not among the unresolved failures.",
+	},
+	"redownload_check_failed": {
+		Ukrainian: "не получилось перевірити фейл цього треку, спробуй ще раз...",
+		English:   "couldn't check this track's failure, try again...",
+	},
+	"redownload_create_failed": {
+		Ukrainian: "не получилось створити редовнлоад запит, спробуй ще раз...",
+		English:   "couldn't create the redownload request, try again...",
+	},
+	"redownload_created": {
+		Ukrainian: "редовнлоад створено ✅\n%s\n%s - %s",
+		English:   "redownload created ✅\n%s\n%s - %s",
+	},
+
+	// /deactivate
+	"deactivate_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /deactivate <request_id>.",
+		English:   "I don't get this command. Please use /deactivate <request_id>.",
+	},
+	"deactivate_failed": {
+		Ukrainian: "не получилося деактивувати запит. Пліз спробуй ще раз пізніше.",
+		English:   "couldn't deactivate the request. Please try again later.",
+	},
+	"deactivate_not_found": {
+		Ukrainian: "нема такого запиту 🤷",
+		English:   "there's no such request 🤷",
+	},
+	"deactivate_not_owner": {
+		Ukrainian: "це не твій запит, деактивувати його може тільки той хто додав або адмін.",
+		English:   "that's not your request, only whoever added it or an admin can deactivate it.",
+	},
+	"deactivated": {
+		Ukrainian: "Запит деактивовано, всьо капец.",
+		English:   "Request deactivated, it's over.",
+	},
+
+	// /p and /pnp
+	"playlist_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /playlist <playlist_id>.",
+		English:   "I don't get this command. Please use /playlist <playlist_id>.",
+	},
+	"playlist_queued": {
+		Ukrainian: "Ураураура успішно додали плейлист в чергу!!!!",
+		English:   "Yaaay, the playlist is in the queue!!!!",
+	},
+
+	// subscriptions
+	"subscribe_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /subscribe <playlist_url|liked> [weekly|hourly|nopull|notify|keep|archive].",
+		English:   "I don't get this command. Please use /subscribe <playlist_url|liked> [weekly|hourly|nopull|notify|keep|archive].",
+	},
+	"subscription_check_failed": {
+		Ukrainian: "не получилось перевірити підписку, спробуй ще раз...",
+		English:   "couldn't check the subscription, try again...",
+	},
+	"already_subscribed": {
+		Ukrainian: "ти вже підписаний на цей плейлист! 🎵",
+		English:   "you're already subscribed to this playlist! 🎵",
+	},
+	"liked_requires_link": {
+		Ukrainian: "щоб підписатись на лайкнуті треки, спочатку підключи спотіфай через /link 🔗",
+		English:   "to subscribe to your Liked Songs, link Spotify with /link first 🔗",
+	},
+	"playlist_name_failed": {
+		Ukrainian: "не получилось отримати назву плейлиста зі спотіфай, спробуй ще раз...",
+		English:   "couldn't get the playlist name from Spotify, try again...",
+	},
+	"subscription_create_failed": {
+		Ukrainian: "не получилось створити підписку, скажи максиму шо шось не так...",
+		English:   "couldn't create the subscription, tell Maksym something's wrong...",
+	},
+	"interval_daily": {
+		Ukrainian: "щодня",
+		English:   "daily",
+	},
+	"interval_weekly": {
+		Ukrainian: "щотижня",
+		English:   "weekly",
+	},
+	"interval_hourly": {
+		Ukrainian: "щогодини",
+		English:   "hourly",
+	},
+	"subscribe_pull": {
+		Ukrainian: "з завантаженням відсутніх треків",
+		English:   "missing tracks are downloaded",
+	},
+	"subscribe_nopull": {
+		Ukrainian: "без завантаження відсутніх треків",
+		English:   "missing tracks are not downloaded",
+	},
+	"subscribed": {
+		Ukrainian: "Ураураура успішно підписались на плейлист '%s'! Оновлення %s, %s 🎵❤️",
+		English:   "Yaaay, subscribed to '%s'! Updated %s, %s 🎵❤️",
+	},
+	"subscribe_notify": {
+		Ukrainian: "\n🔔 Напишу коли в плейлисті щось зміниться",
+		English:   "\n🔔 I'll message you when the playlist changes",
+	},
+	"subscribe_removal": {
+		Ukrainian: "\n🗂 Видалені з плейлиста треки %s",
+		English:   "\n🗂 Tracks removed from the playlist %s",
+	},
+	"removal_mirror": {
+		Ukrainian: "прибираються",
+		English:   "are dropped",
+	},
+	"removal_keep": {
+		Ukrainian: "залишаються в плейлисті",
+		English:   "stay in the playlist",
+	},
+	"removal_archive": {
+		Ukrainian: "переносяться в плейлист \"(removed)\"",
+		English:   "move to a \"(removed)\" playlist",
+	},
+	"unsubscribe_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /unsubscribe <playlist_url|liked>.",
+		English:   "I don't get this command. Please use /unsubscribe <playlist_url|liked>.",
+	},
+	"unsubscribe_failed": {
+		Ukrainian: "не получилось відписатись, можливо ти не підписаний на цей плейлист?",
+		English:   "couldn't unsubscribe, maybe you're not subscribed to this playlist?",
+	},
+	"unsubscribed": {
+		Ukrainian: "Успішно відписались від плейлиста! 👋",
+		English:   "Unsubscribed from the playlist! 👋",
+	},
+	"subscriptions_fetch_failed": {
+		Ukrainian: "не получилось отримати список підписок... 💔😭",
+		English:   "couldn't fetch your subscriptions... 💔😭",
+	},
+	"subscriptions_empty": {
+		Ukrainian: "немає активних підписок на плейлисти...",
+		English:   "no active playlist subscriptions...",
+	},
+	"subscriptions_header": {
+		Ukrainian: "Твої активні підписки:\n\n",
+		English:   "Your active subscriptions:\n\n",
+	},
+	"subscription_interval": {
+		Ukrainian: "   ⏰ Оновлення: %s\n",
+		English:   "   ⏰ Updated: %s\n",
+	},
+	"subscription_pull": {
+		Ukrainian: "з завантаженням",
+		English:   "with downloads",
+	},
+	"subscription_nopull": {
+		Ukrainian: "без завантаження",
+		English:   "without downloads",
+	},
+	"subscription_last_synced": {
+		Ukrainian: "   🕐 Остання синхронізація: %s\n",
+		English:   "   🕐 Last synced: %s\n",
+	},
+	"subscription_not_synced": {
+		Ukrainian: "ще не синхронізовано",
+		English:   "not synced yet",
+	},
+	"subscriptions_actions": {
+		Ukrainian: "⏰ змінити частоту · 📥 завантаження вкл/викл · ⏸ пауза · 🔄 синхронізувати зараз · 🔔 повідомлення про зміни\n\n",
+		English:   "⏰ change interval · 📥 downloads on/off · ⏸ pause · 🔄 sync now · 🔔 change reports\n\n",
+	},
+	"subscription_paused_status": {
+		Ukrainian: "   ⏸ На паузі\n",
+		English:   "   ⏸ Paused\n",
+	},
+	"subscription_notify_status": {
+		Ukrainian: "   🔔 Повідомлення про зміни\n",
+		English:   "   🔔 Change reports on\n",
+	},
+	"subscription_removal_status": {
+		Ukrainian: "   🗂 Видалені треки %s\n",
+		English:   "   🗂 Removed tracks %s\n",
+	},
+	"subscription_interval_button": {
+		Ukrainian: "⏰ %d",
+		English:   "⏰ %d",
+	},
+	"subscription_pull_button": {
+		Ukrainian: "📥 %d",
+		English:   "📥 %d",
+	},
+	"subscription_pause_button": {
+		Ukrainian: "⏸ %d",
+		English:   "⏸ %d",
+	},
+	"subscription_resume_button": {
+		Ukrainian: "▶️ %d",
+		English:   "▶️ %d",
+	},
+	"subscription_sync_button": {
+		Ukrainian: "🔄 %d",
+		English:   "🔄 %d",
+	},
+	"subscription_notify_button": {
+		Ukrainian: "🔔 %d",
+		English:   "🔔 %d",
+	},
+	"subscription_mute_button": {
+		Ukrainian: "🔕 %d",
+		English:   "🔕 %d",
+	},
+	"subscription_gone": {
+		Ukrainian: "цієї підписки вже нема, відкрий /subscriptions ще раз.",
+		English:   "this subscription is gone, open /subscriptions again.",
+	},
+	"subscription_update_failed": {
+		Ukrainian: "не получилось змінити підписку, спробуй ще раз пізніше...",
+		English:   "couldn't change the subscription, try again later...",
+	},
+	"subscription_interval_changed": {
+		Ukrainian: "Тепер оновлення %s ⏰",
+		English:   "Now updated %s ⏰",
+	},
+	"subscription_paused": {
+		Ukrainian: "Підписку поставлено на паузу ⏸",
+		English:   "Subscription paused ⏸",
+	},
+	"subscription_resumed": {
+		Ukrainian: "Підписку відновлено ▶️",
+		English:   "Subscription resumed ▶️",
+	},
+	"subscription_notify_on": {
+		Ukrainian: "Напишу коли в плейлисті щось зміниться 🔔",
+		English:   "I'll message you when the playlist changes 🔔",
+	},
+	"subscription_notify_off": {
+		Ukrainian: "Більше не писатиму про зміни 🔕",
+		English:   "No more change reports 🔕",
+	},
+	"subscription_sync_queued": {
+		Ukrainian: "'%s' синхронізується при наступному запуску 🔄",
+		English:   "'%s' will sync on the next run 🔄",
+	},
+	"subscription_sync_paused": {
+		Ukrainian: "'%s' на паузі, синхронізується одразу як відновиш ▶️",
+		English:   "'%s' is paused, it will sync as soon as you resume it ▶️",
+	},
+
+	// /changes
+	"changes_fetch_failed": {
+		Ukrainian: "не получилось дістати зміни в підписках...",
+		English:   "couldn't fetch the subscription changes...",
+	},
+	"changes_empty": {
+		Ukrainian: "в твоїх підписках поки нічого не змінилось.",
+		English:   "nothing has changed in your subscriptions yet.",
+	},
+	"changes_header": {
+		Ukrainian: "Останні зміни в підписках:\n\n",
+		English:   "Latest changes in your subscriptions:\n\n",
+	},
+	"changes_added": {
+		Ukrainian: "   ➕ Додано (%d):\n",
+		English:   "   ➕ Added (%d):\n",
+	},
+	"changes_removed": {
+		Ukrainian: "   ➖ Прибрано (%d):\n",
+		English:   "   ➖ Removed (%d):\n",
+	},
+	"changes_available": {
+		Ukrainian: "   📥 Тепер в бібліотеці (%d):\n",
+		English:   "   📥 Now in the library (%d):\n",
+	},
+
+	// followed artists
+	"follow_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /follow <artist_url>.",
+		English:   "I don't get this command. Please use /follow <artist_url>.",
+	},
+	"unfollow_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /unfollow <artist_url>.",
+		English:   "I don't get this command. Please use /unfollow <artist_url>.",
+	},
+	"follow_not_artist": {
+		Ukrainian: "це не посилання на артиста в спотіфай 🎤",
+		English:   "that's not a Spotify artist link 🎤",
+	},
+	"already_following": {
+		Ukrainian: "ти вже слідкуєш за цим артистом! 🎤",
+		English:   "you already follow this artist! 🎤",
+	},
+	"artist_name_failed": {
+		Ukrainian: "не получилось отримати артиста зі спотіфай, спробуй ще раз...",
+		English:   "couldn't get the artist from Spotify, try again...",
+	},
+	"follow_failed": {
+		Ukrainian: "не получилось підписатись на артиста, скажи максиму шо шось не так...",
+		English:   "couldn't follow the artist, tell Maksym something's wrong...",
+	},
+	"followed": {
+		Ukrainian: "Ураураура слідкуємо за %s! Нові альбоми і сингли будуть качатись самі 🎤❤️",
+		English:   "Yaaay, following %s! New albums and singles will be downloaded automatically 🎤❤️",
+	},
+	"unfollow_failed": {
+		Ukrainian: "не получилось відписатись, можливо ти не слідкуєш за цим артистом?",
+		English:   "couldn't unfollow, maybe you don't follow this artist?",
+	},
+	"unfollowed": {
+		Ukrainian: "Більше не слідкуємо за артистом! 👋",
+		English:   "Unfollowed the artist! 👋",
+	},
+	"following_fetch_failed": {
+		Ukrainian: "не получилось отримати список артистів... 💔😭",
+		English:   "couldn't fetch the artists you follow... 💔😭",
+	},
+	"following_empty": {
+		Ukrainian: "ти поки ні за ким не слідкуєш, спробуй /follow <artist_url>.",
+		English:   "you don't follow anyone yet, try /follow <artist_url>.",
+	},
+	"following_header": {
+		Ukrainian: "Артисти, за якими ти слідкуєш:\n\n",
+		English:   "Artists you follow:\n\n",
+	},
+	"following_last_checked": {
+		Ukrainian: "   🕐 Остання перевірка релізів: %s\n",
+		English:   "   🕐 Releases last checked: %s\n",
+	},
+	"following_not_checked": {
+		Ukrainian: "ще не перевіряли",
+		English:   "not checked yet",
+	},
+
+	// release radar
+	"radar_fetch_failed": {
+		Ukrainian: "не получилось дістати нові релізи... 💔😭",
+		English:   "couldn't fetch the new releases... 💔😭",
+	},
+	"radar_empty": {
+		Ukrainian: "нових релізів від артистів з бібліотеки поки нема 📡",
+		English:   "no new releases from library artists yet 📡",
+	},
+	"radar_header": {
+		Ukrainian: "📡 Нові релізи артистів з бібліотеки:\n\n",
+		English:   "📡 New releases from library artists:\n\n",
+	},
+	"radar_latest_local": {
+		Ukrainian: "   📚 Останній в бібліотеці: %s\n",
+		English:   "   📚 Newest in the library: %s\n",
+	},
+	"radar_accept_button": {
+		Ukrainian: "✅ %d",
+		English:   "✅ %d",
+	},
+	"radar_dismiss_button": {
+		Ukrainian: "✖️ %d",
+		English:   "✖️ %d",
+	},
+	"radar_gone": {
+		Ukrainian: "цей реліз вже хтось розібрав, список оновлено.",
+		English:   "someone already took care of this release, the list is updated.",
+	},
+	"radar_dismissed": {
+		Ukrainian: "окей, %s більше не пропонуватиму",
+		English:   "okay, won't suggest %s again",
+	},
+	"release_album": {
+		Ukrainian: "альбом",
+		English:   "album",
+	},
+	"release_single": {
+		Ukrainian: "сингл",
+		English:   "single",
+	},
+
+	// aliases
+	"alias_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /alias <інше написання> = <правильний артист>.",
+		English:   "I don't get this command. Please use /alias <other spelling> = <correct artist>.",
+	},
+	"alias_add_failed": {
+		Ukrainian: "не получилось додати аліас, скажи максиму шо шось не так...",
+		English:   "couldn't add the alias, tell Maksym something's wrong...",
+	},
+	"alias_added": {
+		Ukrainian: "Записав: '%s' тепер це '%s' ✍️",
+		English:   "Got it: '%s' is now '%s' ✍️",
+	},
+	"aliases_fetch_failed": {
+		Ukrainian: "не получилось дістати аліаси артистів...",
+		English:   "couldn't fetch the artist aliases...",
+	},
+	"aliases_suggest_failed": {
+		Ukrainian: "не получилось дістати підказки для аліасів...",
+		English:   "couldn't fetch the alias suggestions...",
+	},
+	"aliases_empty": {
+		Ukrainian: "аліасів ще нема. Додай через /alias <інше написання> = <правильний артист>.",
+		English:   "no aliases yet. Add one with /alias <other spelling> = <correct artist>.",
+	},
+	"aliases_header": {
+		Ukrainian: "Аліаси артистів:\n",
+		English:   "Artist aliases:\n",
+	},
+	"aliases_suggestions_header": {
+		Ukrainian: "Підказки з фейлів (підтверди через /aliasaccept <інше написання>):\n",
+		English:   "Suggestions from failures (confirm with /aliasaccept <other spelling>):\n",
+	},
+	"aliases_more": {
+		Ukrainian: "... і ще %d\n",
+		English:   "... and %d more\n",
+	},
+	"aliasaccept_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /aliasaccept <інше написання>.",
+		English:   "I don't get this command. Please use /aliasaccept <other spelling>.",
+	},
+	"alias_suggestion_not_found": {
+		Ukrainian: "такої підказки нема, глянь /aliases.",
+		English:   "there's no such suggestion, check /aliases.",
+	},
+	"alias_confirm_failed": {
+		Ukrainian: "не получилось підтвердити аліас, спробуй ще раз...",
+		English:   "couldn't confirm the alias, try again...",
+	},
+	"alias_confirmed": {
+		Ukrainian: "Аліас '%s' підтверджено 👍",
+		English:   "Alias '%s' confirmed 👍",
+	},
+
+	// Spotify account linking
+	"link_not_configured": {
+		Ukrainian: "підключення спотіфаю не налаштоване, скажи максиму...",
+		English:   "Spotify linking isn't set up, tell Maksym...",
+	},
+	"link_prompt": {
+		Ukrainian: "Тицяй сюди щоб підключити свій спотіфай (посилання живе 15 хвилин) 🔗\n%s",
+		English:   "Tap here to link your Spotify (the link lives for 15 minutes) 🔗\n%s",
+	},
+	"unlink_not_linked": {
+		Ukrainian: "спотіфай і так не підключений.",
+		English:   "Spotify isn't linked anyway.",
+	},
+	"unlink_failed": {
+		Ukrainian: "не получилось відключити спотіфай, спробуй ще раз...",
+		English:   "couldn't unlink Spotify, try again...",
+	},
+	"unlinked": {
+		Ukrainian: "Спотіфай відключено 👋",
+		English:   "Spotify unlinked 👋",
+	},
+	"linked": {
+		Ukrainian: "Спотіфай підключено ✅ Тепер можна /subscribe liked і на приватні плейлисти",
+		English:   "Spotify linked ✅ You can now /subscribe liked and to private playlists",
+	},
+
+	// /lang
+	"lang_current": {
+		Ukrainian: "Мова: %s\nЗмінити: /lang uk або /lang en",
+		English:   "Language: %s\nChange it with /lang uk or /lang en",
+	},
+	"lang_unknown": {
+		Ukrainian: "не знаю такої мови. Пліз юзай /lang uk або /lang en.",
+		English:   "I don't know this language. Please use /lang uk or /lang en.",
+	},
+	"lang_save_failed": {
+		Ukrainian: "не получилось зберегти мову, спробуй ще раз...",
+		English:   "couldn't save the language, try again...",
+	},
+	"lang_saved": {
+		Ukrainian: "Тепер розмовляю українською 🇺🇦",
+		English:   "Speaking English now 🇬🇧",
+	},
+
+	// user management
+	"adduser_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /adduser <user_id> [admin|member|read-only].",
+		English:   "I don't get this command. Please use /adduser <user_id> [admin|member|read-only].",
+	},
+	"role_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /role <user_id> <admin|member|read-only>.",
+		English:   "I don't get this command. Please use /role <user_id> <admin|member|read-only>.",
+	},
+	"removeuser_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /removeuser <user_id>.",
+		English:   "I don't get this command. Please use /removeuser <user_id>.",
+	},
+	"unknown_role": {
+		Ukrainian: "не знаю ролі '%s', є admin, member і read-only.",
+		English:   "I don't know the role '%s', there are admin, member and read-only.",
+	},
+	"user_whitelisted": {
+		Ukrainian: "цей адмін з BOT_WHITELIST, його міняють в конфігу.",
+		English:   "this admin comes from BOT_WHITELIST, change it in the config.",
+	},
+	"user_self": {
+		Ukrainian: "сам собі роль не міняй, попроси іншого адміна 😅",
+		English:   "don't change your own role, ask another admin 😅",
+	},
+	"user_not_found": {
+		Ukrainian: "нема такого користувача 🤷",
+		English:   "there's no such user 🤷",
+	},
+	"user_save_failed": {
+		Ukrainian: "не получилось зберегти користувача, спробуй ще раз...",
+		English:   "couldn't save the user, try again...",
+	},
+	"user_added": {
+		Ukrainian: "Додав %d як %s ✅",
+		English:   "Added %d as %s ✅",
+	},
+	"user_added_notify": {
+		Ukrainian: "Тебе додали в бота як %s 🎉 Жми /start",
+		English:   "You've been added to the bot as %s 🎉 Hit /start",
+	},
+	"user_role_changed": {
+		Ukrainian: "Тепер %d — %s ✅",
+		English:   "%d is now %s ✅",
+	},
+	"users_fetch_failed": {
+		Ukrainian: "не получилось дістати користувачів, спробуй ще раз...",
+		English:   "couldn't fetch the users, try again...",
+	},
+	"users_header": {
+		Ukrainian: "👥 Користувачі:",
+		English:   "👥 Users:",
+	},
+	"users_empty": {
+		Ukrainian: "Користувачів нема 🤷",
+		English:   "No users yet 🤷",
+	},
+	"user_remove_failed": {
+		Ukrainian: "не получилось видалити користувача, спробуй ще раз...",
+		English:   "couldn't remove the user, try again...",
+	},
+	"user_removed": {
+		Ukrainian: "Користувача %d видалено 👋",
+		English:   "User %d removed 👋",
+	},
+
+	// webhooks
+	"webhookadd_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /webhookadd <url> <події через кому або all>.\nПодії: %s",
+		English:   "I don't get this command. Please use /webhookadd <url> <comma separated events or all>.\nEvents: %s",
+	},
+	"webhookdel_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /webhookdel <id>, id є в /webhooks.",
+		English:   "I don't get this command. Please use /webhookdel <id>, the ids are in /webhooks.",
+	},
+	"webhook_save_failed": {
+		Ukrainian: "не получилось зберегти вебхук, спробуй ще раз...",
+		English:   "couldn't save the webhook, try again...",
+	},
+	"webhook_added": {
+		Ukrainian: "Вебхук %s додано ✅\nПодії: %s\nСекрет: %s\nЗбережи його, більше не покажу 🤫",
+		English:   "Webhook %s added ✅\nEvents: %s\nSecret: %s\nSave it, I won't show it again 🤫",
+	},
+	"webhooks_fetch_failed": {
+		Ukrainian: "не получилось дістати вебхуки, спробуй ще раз...",
+		English:   "couldn't fetch the webhooks, try again...",
+	},
+	"webhooks_header": {
+		Ukrainian: "🪝 Вебхуки:",
+		English:   "🪝 Webhooks:",
+	},
+	"webhooks_empty": {
+		Ukrainian: "Вебхуків нема, додай через /webhookadd 🤷",
+		English:   "No webhooks yet, add one with /webhookadd 🤷",
+	},
+	"webhook_never_delivered": {
+		Ukrainian: "ще нічого не надсилав",
+		English:   "nothing sent yet",
+	},
+	"webhook_last_delivered": {
+		Ukrainian: "✅ %s · %s",
+		English:   "✅ %s · %s",
+	},
+	"webhook_last_failed": {
+		Ukrainian: "❌ %s · %s, %d спроб: %s",
+		English:   "❌ %s · %s, %d attempts: %s",
+	},
+	"webhook_not_found": {
+		Ukrainian: "нема такого вебхука 🤷",
+		English:   "there's no such webhook 🤷",
+	},
+	"webhook_delete_failed": {
+		Ukrainian: "не получилось видалити вебхук, спробуй ще раз...",
+		English:   "couldn't delete the webhook, try again...",
+	},
+	"webhook_deleted": {
+		Ukrainian: "Вебхук %s видалено 👋",
+		English:   "Webhook %s removed 👋",
+	},
+	// quotas
+	"quota_check_failed": {
+		Ukrainian: "не получилось перевірити твій ліміт, спробуй ще раз...",
+		English:   "couldn't check your quota, try again...",
+	},
+	"quota_active_requests": {
+		Ukrainian: "в тебе вже %d активних запитів, почекай поки шось докачається 🐢",
+		English:   "you already have %d active requests, wait until something finishes 🐢",
+	},
+	"quota_tracks_per_day": {
+		Ukrainian: "це більше ніж дозволено за добу (%d треків, залишилось %d), спробуй пізніше 🐢",
+		English:   "that's more than allowed per day (%d tracks, %d left), try again later 🐢",
+	},
+	"quota_subscriptions": {
+		Ukrainian: "в тебе вже %d підписок, спочатку відпишись від чогось (/unsubscribe).",
+		English:   "you already have %d subscriptions, unsubscribe from something first (/unsubscribe).",
+	},
+	"quota_unlimited": {
+		Ukrainian: "Для тебе лімітів нема 😎",
+		English:   "You have no limits 😎",
+	},
+	"quota_header": {
+		Ukrainian: "📊 Твої ліміти:",
+		English:   "📊 Your quota:",
+	},
+	"quota_active_label": {
+		Ukrainian: "Активні запити",
+		English:   "Active requests",
+	},
+	"quota_tracks_label": {
+		Ukrainian: "Треки за добу",
+		English:   "Tracks per day",
+	},
+	"quota_subscriptions_label": {
+		Ukrainian: "Підписки",
+		English:   "Subscriptions",
+	},
+	"quota_line": {
+		Ukrainian: "• %s: %d з %d, залишилось %d",
+		English:   "• %s: %d of %d, %d left",
+	},
+	"quota_line_unlimited": {
+		Ukrainian: "• %s: %d, без ліміту",
+		English:   "• %s: %d, unlimited",
+	},
+
+	// completion notifications
+	"notify_download_done": {
+		Ukrainian: "✅ Докачав %s\n",
+		English:   "✅ Finished downloading %s\n",
+	},
+	"notify_playlist_done": {
+		Ukrainian: "🎵 Плейлист %s готовий\n",
+		English:   "🎵 Playlist %s is ready\n",
+	},
+	"notify_counts": {
+		Ukrainian: "Знайдено: %d, не вистачає: %d, пропущено: %d",
+		English:   "Found: %d, missing: %d, skipped: %d",
+	},
+	"notify_subscription_changes": {
+		Ukrainian: "🔔 Зміни в плейлисті %s\n",
+		English:   "🔔 Playlist %s changed\n",
+	},
+	"notify_new_releases": {
+		Ukrainian: "🎤 Нові релізи %s в черзі (%d):\n",
+		English:   "🎤 New releases by %s queued (%d):\n",
+	},
+	"notify_playlist_link": {
+		Ukrainian: "\n📎 M3U: %s",
+		English:   "\n📎 M3U: %s",
+	},
+
+	// /search
+	"search_usage": {
+		Ukrainian: "не розумію цю команду. Пліз юзай /search <артист, трек чи альбом>.",
+		English:   "I don't get this command. Please use /search <artist, track or album>.",
+	},
+	"search_too_long": {
+		Ukrainian: "задовгий запит, скороти трохи 🙏",
+		English:   "the query is too long, please shorten it 🙏",
+	},
+	"search_failed": {
+		Ukrainian: "не получилось пошукати, спробуй ще раз...",
+		English:   "couldn't search, try again...",
+	},
+	"search_empty": {
+		Ukrainian: "по запиту «%s» в бібліотеці нічого нема 🤷",
+		English:   "nothing in the library for «%s» 🤷",
+	},
+	"search_header": {
+		Ukrainian: "🔎 «%s»: знайдено %d\n",
+		English:   "🔎 «%s»: %d found\n",
+	},
+	"search_truncated": {
+		Ukrainian: "(показую перші, уточни запит)\n",
+		English:   "(showing the first ones, narrow the query down)\n",
+	},
+	"search_album_button": {
+		Ukrainian: "💿 %d",
+		English:   "💿 %d",
+	},
+	"search_queue_button_short": {
+		Ukrainian: "⬇️ %d",
+		English:   "⬇️ %d",
+	},
+	"search_queue_button": {
+		Ukrainian: "⬇️ Докачати відсутні треки",
+		English:   "⬇️ Queue missing tracks",
+	},
+	"search_album_header": {
+		Ukrainian: "💿 %s — %s\nВ бібліотеці: %s\n\n",
+		English:   "💿 %s — %s\nIn the library: %s\n\n",
+	},
+	"search_no_album": {
+		Ukrainian: "в цього треку нема альбому 🤷",
+		English:   "this track has no album 🤷",
+	},
+	"search_result_gone": {
+		Ukrainian: "цього треку вже нема в бібліотеці, пошукай ще раз.",
+		English:   "this track is no longer in the library, search again.",
+	},
+	"search_album_not_found": {
+		Ukrainian: "не знайшов цей альбом на спотіфаї 😢",
+		English:   "couldn't find this album on Spotify 😢",
+	},
+	"search_already_queued": {
+		Ukrainian: "цей альбом вже в черзі ⏳",
+		English:   "this album is already in the queue ⏳",
+	},
+	"search_album_complete": {
+		Ukrainian: "весь альбом вже є в бібліотеці ✅",
+		English:   "the whole album is already in the library ✅",
+	},
+	"search_queued": {
+		Ukrainian: "Додав в чергу, не вистачало %s ✅",
+		English:   "Queued, %s were missing ✅",
+	},
+
+	// /history
+	"history_fetch_failed": {
+		Ukrainian: "не получилося дістати історію запитів... 💔",
+		English:   "couldn't fetch the request history... 💔",
+	},
+	"history_empty": {
+		Ukrainian: "ти ще нічого не просив скачати 🤷",
+		English:   "you haven't asked for anything yet 🤷",
+	},
+	"history_header": {
+		Ukrainian: "Твої запити: %d\n",
+		English:   "Your requests: %d\n",
+	},
+	"history_active": {
+		Ukrainian: "   ⏳ В процесі\n",
+		English:   "   ⏳ In progress\n",
+	},
+	"history_errored": {
+		Ukrainian: "   ⚠️ Завершено з помилками (спроб: %d)\n",
+		English:   "   ⚠️ Finished with errors (attempts: %d)\n",
+	},
+	"history_done": {
+		Ukrainian: "   ✅ Готово\n",
+		English:   "   ✅ Done\n",
+	},
+	"history_tracks": {
+		Ukrainian: "   🎵 Знайдено: %d/%d\n",
+		English:   "   🎵 Found: %d/%d\n",
+	},
+	"history_created": {
+		Ukrainian: "   📅 Створено: %s\n",
+		English:   "   📅 Created: %s\n",
+	},
+	"history_finished": {
+		Ukrainian: "   🏁 Завершено: %s\n",
+		English:   "   🏁 Finished: %s\n",
+	},
+
+	// bulk links
+	"bulk_header": {
+		Ukrainian: "Додав в чергу %d з %d посилань:\n\n",
+		English:   "Queued %d of %d links:\n\n",
+	},
+	"bulk_skipped": {
+		Ukrainian: "\nЩе %d посилань пропущено, за раз можна до %d.",
+		English:   "\n%d more links were skipped, up to %d at a time.",
+	},
+	"bulk_file_unsupported": {
+		Ukrainian: "я розумію тільки .txt або .csv файли з посиланнями, плейлисти (.m3u, .m3u8, .csv, .json з експорту спотіфаю) та аудіо 📄",
+		English:   "I only understand .txt or .csv files with links, playlists (.m3u, .m3u8, .csv, .json from a Spotify export) and audio 📄",
+	},
+	"bulk_file_too_big": {
+		Ukrainian: "файл завеликий, максимум %d КБ.",
+		English:   "the file is too big, %d KB at most.",
+	},
+	"bulk_file_failed": {
+		Ukrainian: "не получилось прочитати файл, спробуй ще раз...",
+		English:   "couldn't read the file, try again...",
+	},
+	"bulk_file_empty": {
+		Ukrainian: "в цьому файлі нема посилань 🤷",
+		English:   "there are no links in this file 🤷",
+	},
+
+	// coverage preview
+	"preview_header": {
+		Ukrainian: "📀 %s\nВ бібліотеці вже є %d/%d, не вистачає:\n",
+		English:   "📀 %s\nYou already have %d/%d, missing:\n",
+	},
+	"preview_complete": {
+		Ukrainian: "📀 %s\nВ бібліотеці вже є всі треки (%d/%d) ✅",
+		English:   "📀 %s\nAll tracks are already in the library (%d/%d) ✅",
+	},
+	"preview_confirm_button": {
+		Ukrainian: "✅ Докачати %d",
+		English:   "✅ Queue %d missing",
+	},
+	"preview_cancel_button": {
+		Ukrainian: "❌ Скасувати",
+		English:   "❌ Cancel",
+	},
+	"preview_cancelled": {
+		Ukrainian: "Окей, нічого не додаю.",
+		English:   "Okay, not queueing anything.",
+	},
+	"preview_expired": {
+		Ukrainian: "ця кнопка вже не працює, надішли посилання ще раз.",
+		English:   "this button no longer works, send the link again.",
+	},
+
+	// /stats
+	"stats_fetch_failed": {
+		Ukrainian: "не вдалося порахувати статистику 😔",
+		English:   "failed to compute the stats 😔",
+	},
+	"stats_header": {
+		Ukrainian: "📊 Статистика бібліотеки:\n\n",
+		English:   "📊 Library stats:\n\n",
+	},
+	"stats_library": {
+		Ukrainian: "🎵 Треків: %d · на диску %s\n",
+		English:   "🎵 Tracks: %d · %s on disk\n",
+	},
+	"stats_without_size": {
+		Ukrainian: "   (без розміру: %s)\n",
+		English:   "   (without a size: %s)\n",
+	},
+	"stats_queue": {
+		Ukrainian: "📥 В черзі: %d · всього запитів: %d · активних плейлистів: %d\n\n",
+		English:   "📥 Queued: %d · requests in total: %d · active playlists: %d\n\n",
+	},
+	"stats_genres": {
+		Ukrainian: "🎸 Жанри: %s\n",
+		English:   "🎸 Genres: %s\n",
+	},
+	"stats_artists": {
+		Ukrainian: "🎤 Топ артистів: %s\n",
+		English:   "🎤 Top artists: %s\n",
+	},
+	"stats_formats": {
+		Ukrainian: "💾 Формати: %s\n",
+		English:   "💾 Formats: %s\n",
+	},
+	"stats_unknown": {
+		Ukrainian: "невідомо",
+		English:   "unknown",
+	},
+	"stats_weeks": {
+		Ukrainian: "\n📅 Додано по тижнях:\n",
+		English:   "\n📅 Added per week:\n",
+	},
+	"stats_downloads": {
+		Ukrainian: "\n✅ Успішних запитів: %.0f%% (%d/%d)\nЗнайдено треків: %d/%d\nВ середньому на запит: %s",
+		English:   "\n✅ Successful requests: %.0f%% (%d/%d)\nTracks found: %d/%d\nAverage time per request: %s",
+	},
+	"stats_downloads_empty": {
+		Ukrainian: "\n✅ Завершених запитів ще нема",
+		English:   "\n✅ No finished requests yet",
+	},
+
+	// /getplaylist and /send
+	"getplaylist_failed": {
+		Ukrainian: "не вдалося отримати плейлисти 😔",
+		English:   "failed to get the playlists 😔",
+	},
+	"getplaylist_empty": {
+		Ukrainian: "Поки нема жодного плейлиста.",
+		English:   "There are no playlists yet.",
+	},
+	"getplaylist_usage": {
+		Ukrainian: "Використання: /getplaylist <назва>\n\n🎵 твої підписки, 📥 імпортовані, ✨ динамічні плейлисти:\n",
+		English:   "Usage: /getplaylist <name>\n\n🎵 your subscriptions, 📥 imported, ✨ dynamic playlists:\n",
+	},
+	"getplaylist_not_found": {
+		Ukrainian: "Нема плейлиста з назвою \"%s\", /getplaylist покаже всі.",
+		English:   "No playlist named \"%s\", /getplaylist lists them all.",
+	},
+	"getplaylist_not_generated": {
+		Ukrainian: "Плейлист \"%s\" ще не згенерований, спробуй після наступної синхронізації.",
+		English:   "Playlist \"%s\" hasn't been generated yet, try after the next sync.",
+	},
+	"send_usage": {
+		Ukrainian: "Використання: /send <пошук>",
+		English:   "Usage: /send <search>",
+	},
+	"send_pick": {
+		Ukrainian: "🔎 Кілька треків за \"%s\", який надіслати?\n\n",
+		English:   "🔎 Several tracks match \"%s\", which one should I send?\n\n",
+	},
+	"send_more": {
+		Ukrainian: "…уточни пошук, щоб побачити інші\n",
+		English:   "…narrow the search to see others\n",
+	},
+	"send_button": {
+		Ukrainian: "📤 %d",
+		English:   "📤 %d",
+	},
+	"upload_missing": {
+		Ukrainian: "файлу нема на диску 😔",
+		English:   "the file isn't on disk 😔",
+	},
+	"upload_too_big": {
+		Ukrainian: "файл завеликий для телеграму (%s, максимум %s) 😔",
+		English:   "the file is too big for Telegram (%s, at most %s) 😔",
+	},
+	"upload_failed": {
+		Ukrainian: "не вдалося надіслати файл 😔",
+		English:   "failed to send the file 😔",
+	},
+
+	// audio uploads
+	"ingest_disabled": {
+		Ukrainian: "завантаження аудіо в бібліотеку вимкнене 🙅",
+		English:   "adding audio to the library is turned off 🙅",
+	},
+	"ingest_unsupported": {
+		Ukrainian: "я беру в бібліотеку тільки mp3, flac, m4a, ogg, opus або wav 🎧",
+		English:   "I only take mp3, flac, m4a, ogg, opus or wav into the library 🎧",
+	},
+	"ingest_too_big": {
+		Ukrainian: "файл завеликий (%s), телеграм дає ботам скачувати до %s 😔",
+		English:   "the file is too big (%s), Telegram lets bots download up to %s 😔",
+	},
+	"ingest_failed": {
+		Ukrainian: "не вдалося додати файл в бібліотеку 😔",
+		English:   "failed to add the file to the library 😔",
+	},
+	"ingest_no_artist": {
+		Ukrainian: "не знаю, чий це трек 🤔 Надішли файл з назвою \"Артист - Назва.mp3\"",
+		English:   "I can't tell whose track this is 🤔 Send the file named \"Artist - Title.mp3\"",
+	},
+	"ingest_exists": {
+		Ukrainian: "%s - %s вже є в бібліотеці ✅",
+		English:   "%s - %s is already in the library ✅",
+	},
+	"ingest_done": {
+		Ukrainian: "📥 %s - %s додано в бібліотеку\n📁 %s",
+		English:   "📥 %s - %s added to the library\n📁 %s",
+	},
+
+	// playlist import
+	"import_empty": {
+		Ukrainian: "в цьому плейлисті нема треків з артистом і назвою 🤷",
+		English:   "there are no tracks with an artist and a title in this playlist 🤷",
+	},
+	"import_unreadable": {
+		Ukrainian: "не получилось прочитати плейлист, це точно M3U, CSV чи JSON з експорту спотіфаю? 🤔",
+		English:   "couldn't read the playlist, is it really an M3U, CSV or Spotify export JSON? 🤔",
+	},
+	"import_failed": {
+		Ukrainian: "не вдалося імпортувати плейлист, спробуй ще раз...",
+		English:   "failed to import the playlist, try again...",
+	},
+	"import_playlist": {
+		Ukrainian: "📥 %s: %d з %d треків є в бібліотеці\n",
+		English:   "📥 %s: %d of %d tracks are in the library\n",
+	},
+	"import_more": {
+		Ukrainian: "      ... та ще %d плейлистів\n",
+		English:   "      ... and %d more playlists\n",
+	},
+	"import_written": {
+		Ukrainian: "\nM3U з'явиться після наступного оновлення плейлистів, забрати його можна через /getplaylist\n",
+		English:   "\nThe M3U will be written on the next playlist run, get it with /getplaylist\n",
+	},
+	"import_queue_missing": {
+		Ukrainian: "⬇️ відсутні %s поставлю в чергу на скачування",
+		English:   "⬇️ the %s missing will be queued for download",
+	},
+	"import_queue_hint": {
+		Ukrainian: "щоб скачати відсутні треки, надішли файл ще раз з підписом queue",
+		English:   "to download the missing tracks, send the file again with the caption queue",
+	},
+}
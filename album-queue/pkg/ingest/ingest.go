@@ -0,0 +1,276 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bogem/id3v2"
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/libraryfile"
+	"go.uber.org/zap"
+)
+
+// MaxDownloadSize is the largest file the Bot API lets bots download
+const MaxDownloadSize = 20 << 20
+
+// Extensions are the audio formats taken into the library, tags are only
+// written to MP3 and FLAC files
+var Extensions = []string{".mp3", ".flac", ".m4a", ".ogg", ".opus", ".wav"}
+
+var (
+	ErrUnsupported = errors.New("unsupported audio format")
+	// ErrNoArtist is returned when neither the upload nor its file name say
+	// who the artist is
+	ErrNoArtist = errors.New("no artist for the upload")
+	ErrExists   = errors.New("track is already in the library")
+)
+
+// Store finds the tracks already in the library and indexes the files moved
+// into it
+type Store interface {
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error)
+}
+
+// Track is what an upload is tagged and filed as
+type Track struct {
+	Artist string
+	Title  string
+}
+
+// Supported reports whether the file name has one of Extensions
+func Supported(fileName string) bool {
+	return slices.Contains(Extensions, strings.ToLower(filepath.Ext(fileName)))
+}
+
+// TrackFromUpload takes the artist and title from the Telegram metadata of
+// the upload, an "Artist - Title.mp3" file name fills in what's missing
+func TrackFromUpload(performer, title, fileName string) Track {
+	track := Track{Artist: strings.TrimSpace(performer), Title: strings.TrimSpace(title)}
+
+	stem := strings.TrimSpace(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	nameArtist, nameTitle, found := strings.Cut(stem, " - ")
+	if !found {
+		nameArtist, nameTitle = "", stem
+	}
+
+	if track.Artist == "" {
+		track.Artist = strings.TrimSpace(nameArtist)
+	}
+	if track.Title == "" {
+		track.Title = strings.TrimSpace(nameTitle)
+	}
+	return track
+}
+
+// Ingester files audio uploads into the library: it downloads them to an
+// inbox folder, tags them, moves them to <library>/<artist>/<artist> - <title>
+// and indexes them
+type Ingester struct {
+	store   Store
+	inbox   string
+	library string
+	log     *zap.Logger
+}
+
+func NewIngester(store Store, inbox, library string, log *zap.Logger) *Ingester {
+	return &Ingester{store: store, inbox: inbox, library: library, log: log}
+}
+
+// Ingest reads an upload named fileName from r into the library as track and
+// returns the indexed file
+func (i *Ingester) Ingest(ctx context.Context, r io.Reader, fileName string, track Track) (models.MusicFile, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !Supported(fileName) {
+		return models.MusicFile{}, ErrUnsupported
+	}
+	if track.Artist == "" {
+		return models.MusicFile{}, ErrNoArtist
+	}
+	if track.Title == "" {
+		track.Title = "Untitled"
+	}
+
+	destination := filepath.Join(i.library, libraryfile.CleanName(track.Artist), libraryfile.CleanName(track.Artist+" - "+track.Title)+ext)
+	exists, err := i.inLibrary(ctx, track, destination)
+	if err != nil {
+		return models.MusicFile{}, err
+	}
+	if exists {
+		return models.MusicFile{}, ErrExists
+	}
+
+	uploaded, err := i.download(r, ext)
+	if err != nil {
+		return models.MusicFile{}, err
+	}
+	defer os.Remove(uploaded)
+
+	if err := writeTags(uploaded, ext, track); err != nil {
+		// the file is still worth keeping, the name says what it is
+		i.log.Warn("Failed to tag upload", zap.Error(err), zap.String("file_name", fileName))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to create artist folder: %w", err)
+	}
+	if err := libraryfile.Move(uploaded, destination); err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to move upload into the library: %w", err)
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to stat library file: %w", err)
+	}
+
+	file, err := i.store.IndexMusicFile(ctx, models.MusicFile{
+		Artist:   track.Artist,
+		Title:    track.Title,
+		Path:     destination,
+		Size:     info.Size(),
+		MetaData: map[string]any{"source": "telegram", "file_name": fileName},
+	})
+	if err != nil {
+		return models.MusicFile{}, err
+	}
+
+	i.log.Info("Ingested upload", zap.String("file_name", fileName), zap.String("path", destination))
+	return file, nil
+}
+
+// inLibrary reports whether the track is indexed under any spelling of its
+// artist, or a file is already at its place in the library
+func (i *Ingester) inLibrary(ctx context.Context, track Track, destination string) (bool, error) {
+	aliases, err := i.store.GetArtistAliases(ctx)
+	if err != nil {
+		i.log.Warn("Failed to load artist aliases", zap.Error(err))
+	}
+
+	files, err := i.store.FindMusicFiles(ctx, []string{track.Artist}, []string{track.Title})
+	if err != nil {
+		return false, fmt.Errorf("failed to find music files: %w", err)
+	}
+	keys := aliases.Keys(track.Artist, track.Title)
+	for _, file := range files {
+		if slices.Contains(keys, aliases.Key(file.Artist, file.Title)) {
+			return true, nil
+		}
+	}
+
+	_, err = os.Stat(destination)
+	return err == nil, nil
+}
+
+// Rel returns the path of a library file relative to the library root
+func (i *Ingester) Rel(path string) string {
+	rel, err := filepath.Rel(i.library, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// download saves the upload to a new file in the inbox
+func (i *Ingester) download(r io.Reader, ext string) (string, error) {
+	if err := os.MkdirAll(i.inbox, 0755); err != nil {
+		return "", fmt.Errorf("failed to create inbox: %w", err)
+	}
+
+	f, err := os.CreateTemp(i.inbox, "upload-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create inbox file: %w", err)
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download upload: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+func writeTags(path, ext string, track Track) error {
+	switch ext {
+	case ".mp3":
+		return writeMP3Tags(path, track)
+	case ".flac":
+		return writeFLACTags(path, track)
+	default:
+		return nil
+	}
+}
+
+func writeMP3Tags(path string, track Track) error {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return fmt.Errorf("failed to open MP3 file: %w", err)
+	}
+	defer tag.Close()
+
+	tag.SetDefaultEncoding(id3v2.EncodingUTF8)
+	tag.SetArtist(track.Artist)
+	tag.SetTitle(track.Title)
+
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("failed to save MP3 tags: %w", err)
+	}
+	return nil
+}
+
+func writeFLACTags(path string, track Track) error {
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	comments := flacvorbis.New()
+	index := -1
+	for i, meta := range f.Meta {
+		if meta.Type != flac.VorbisComment {
+			continue
+		}
+		if comments, err = flacvorbis.ParseFromMetaDataBlock(*meta); err != nil {
+			return fmt.Errorf("failed to parse vorbis comment: %w", err)
+		}
+		index = i
+		break
+	}
+
+	// Add appends, drop the old values first
+	comments.Comments = slices.DeleteFunc(comments.Comments, func(c string) bool {
+		field, _, _ := strings.Cut(c, "=")
+		return strings.EqualFold(field, flacvorbis.FIELD_ARTIST) || strings.EqualFold(field, flacvorbis.FIELD_TITLE)
+	})
+	if err := comments.Add(flacvorbis.FIELD_ARTIST, track.Artist); err != nil {
+		return err
+	}
+	if err := comments.Add(flacvorbis.FIELD_TITLE, track.Title); err != nil {
+		return err
+	}
+
+	block := comments.Marshal()
+	if index >= 0 {
+		f.Meta[index] = &block
+	} else {
+		f.Meta = append(f.Meta, &block)
+	}
+
+	if err := f.Save(path); err != nil {
+		return fmt.Errorf("failed to save FLAC file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bogem/id3v2"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+type fakeStore struct {
+	aliases models.ArtistAliases
+	files   []models.MusicFile
+}
+
+func (f *fakeStore) GetArtistAliases(context.Context) (models.ArtistAliases, error) {
+	return f.aliases, nil
+}
+
+// FindMusicFiles matches like the database does: any known spelling of the
+// artist and the exact title, ignoring case
+func (f *fakeStore) FindMusicFiles(_ context.Context, artists, titles []string) ([]models.MusicFile, error) {
+	var files []models.MusicFile
+	for _, file := range f.files {
+		for i := range artists {
+			matchesArtist := slices.ContainsFunc(f.aliases.Variants(artists[i]), func(variant string) bool {
+				return strings.EqualFold(variant, file.Artist)
+			})
+			if matchesArtist && strings.EqualFold(titles[i], file.Title) {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+	return files, nil
+}
+
+func (f *fakeStore) IndexMusicFile(_ context.Context, file models.MusicFile) (models.MusicFile, error) {
+	file.ID = "file-1"
+	f.files = append(f.files, file)
+	return file, nil
+}
+
+func TestTrackFromUpload(t *testing.T) {
+	tests := []struct {
+		performer, title, fileName string
+		want                       Track
+	}{
+		{"Artist", "Song", "whatever.mp3", Track{Artist: "Artist", Title: "Song"}},
+		{"", "", "Artist - Song.flac", Track{Artist: "Artist", Title: "Song"}},
+		{"Tagged", "", "Artist - Song.mp3", Track{Artist: "Tagged", Title: "Song"}},
+		{"", "", "Song.mp3", Track{Title: "Song"}},
+	}
+
+	for _, tt := range tests {
+		if got := TrackFromUpload(tt.performer, tt.title, tt.fileName); got != tt.want {
+			t.Errorf("TrackFromUpload(%q, %q, %q) = %+v, want %+v", tt.performer, tt.title, tt.fileName, got, tt.want)
+		}
+	}
+}
+
+func newTestIngester(t *testing.T) (*Ingester, *fakeStore, string) {
+	t.Helper()
+	store := &fakeStore{}
+	library := t.TempDir()
+	return NewIngester(store, filepath.Join(t.TempDir(), "inbox"), library, zap.NewNop()), store, library
+}
+
+func TestIngestTagsMovesAndIndexes(t *testing.T) {
+	i, store, library := newTestIngester(t)
+
+	file, err := i.Ingest(context.Background(), strings.NewReader(strings.Repeat("\x00", 512)), "upload.mp3", Track{Artist: "AC/DC", Title: "Song"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(library, "AC_DC", "AC_DC - Song.mp3")
+	if file.Path != want || file.Artist != "AC/DC" || file.Size == 0 {
+		t.Fatalf("unexpected file: %+v", file)
+	}
+	if len(store.files) != 1 {
+		t.Fatalf("expected the file to be indexed, got %+v", store.files)
+	}
+	if rel := i.Rel(file.Path); rel != filepath.Join("AC_DC", "AC_DC - Song.mp3") {
+		t.Fatalf("unexpected relative path %q", rel)
+	}
+
+	tag, err := id3v2.Open(want, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tag.Close()
+	if tag.Artist() != "AC/DC" || tag.Title() != "Song" {
+		t.Fatalf("unexpected tags: %q - %q", tag.Artist(), tag.Title())
+	}
+
+	inbox, _ := os.ReadDir(i.inbox)
+	if len(inbox) != 0 {
+		t.Fatalf("expected the inbox to be empty, got %v", inbox)
+	}
+}
+
+func TestIngestRejects(t *testing.T) {
+	i, store, _ := newTestIngester(t)
+	ctx := context.Background()
+
+	if _, err := i.Ingest(ctx, strings.NewReader("x"), "cover.jpg", Track{Artist: "A", Title: "B"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := i.Ingest(ctx, strings.NewReader("x"), "song.ogg", Track{Title: "B"}); !errors.Is(err, ErrNoArtist) {
+		t.Errorf("expected ErrNoArtist, got %v", err)
+	}
+
+	if _, err := i.Ingest(ctx, strings.NewReader("x"), "song.ogg", Track{Artist: "A", Title: "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Ingest(ctx, strings.NewReader("x"), "again.ogg", Track{Artist: "A", Title: "B"}); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+	if len(store.files) != 1 {
+		t.Errorf("expected one indexed file, got %+v", store.files)
+	}
+}
+
+func TestIngestRejectsIndexedTracks(t *testing.T) {
+	i, store, library := newTestIngester(t)
+	store.aliases = models.ArtistAliases{"weeknd": "the weeknd"}
+	store.files = []models.MusicFile{{Artist: "The Weeknd", Title: "Blinding Lights", Path: "/music/elsewhere/lights.flac"}}
+
+	_, err := i.Ingest(context.Background(), strings.NewReader("x"), "lights.mp3", Track{Artist: "weeknd", Title: "blinding lights"})
+	if !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if entries, _ := os.ReadDir(library); len(entries) != 0 || len(store.files) != 1 {
+		t.Fatalf("expected nothing to be added to the library, got %v and %+v", entries, store.files)
+	}
+}
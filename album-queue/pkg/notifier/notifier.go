@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+const (
+	batchSize   = 50
+	maxAttempts = 5
+	// maxChangedTracks caps each list of a subscription change report
+	maxChangedTracks = 10
+)
+
+// Store is the part of the database the notifier needs
+type Store interface {
+	GetPendingNotifications(ctx context.Context, limit int) ([]models.Notification, error)
+	MarkNotificationSent(ctx context.Context, id string) error
+	MarkNotificationFailed(ctx context.Context, id string, giveUp bool) error
+	GetUserLanguage(ctx context.Context, userID int64) (string, error)
+}
+
+// Notifier delivers the notifications the workers put in the outbox
+type Notifier interface {
+	// Run delivers pending notifications every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+	// Deliver sends the pending notifications once
+	Deliver(ctx context.Context) error
+}
+
+type notifier struct {
+	store           Store
+	send            func(userID int64, text string) error
+	log             *zap.Logger
+	playlistBaseURL string
+}
+
+// NewNotifier creates the notifier. When playlistBaseURL is set, M3U files are
+// linked as playlistBaseURL/<file name>, otherwise their path is shown.
+func NewNotifier(store Store, send func(userID int64, text string) error, log *zap.Logger, playlistBaseURL string) Notifier {
+	return &notifier{
+		store:           store,
+		send:            send,
+		log:             log,
+		playlistBaseURL: strings.TrimSuffix(playlistBaseURL, "/"),
+	}
+}
+
+func (n *notifier) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := n.Deliver(ctx); err != nil {
+			n.log.Error("Failed to deliver notifications", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *notifier) Deliver(ctx context.Context) error {
+	notifications, err := n.store.GetPendingNotifications(ctx, batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, notification := range notifications {
+		text := n.render(n.lang(ctx, notification.UserID), notification)
+
+		if err := n.send(notification.UserID, text); err != nil {
+			giveUp := notification.Attempts+1 >= maxAttempts
+			n.log.Warn("Failed to send notification",
+				zap.Error(err),
+				zap.String("id", notification.ID),
+				zap.Int64("user_id", notification.UserID),
+				zap.Bool("give_up", giveUp))
+			if err := n.store.MarkNotificationFailed(ctx, notification.ID, giveUp); err != nil {
+				n.log.Error("Failed to mark notification as failed", zap.Error(err), zap.String("id", notification.ID))
+			}
+			continue
+		}
+
+		if err := n.store.MarkNotificationSent(ctx, notification.ID); err != nil {
+			n.log.Error("Failed to mark notification as sent", zap.Error(err), zap.String("id", notification.ID))
+		}
+	}
+
+	return nil
+}
+
+func (n *notifier) lang(ctx context.Context, userID int64) i18n.Lang {
+	saved, err := n.store.GetUserLanguage(ctx, userID)
+	if err != nil {
+		n.log.Error("Failed to get user language", zap.Error(err), zap.Int64("user_id", userID))
+	}
+	if lang, ok := i18n.Parse(saved); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+func (n *notifier) render(lang i18n.Lang, notification models.Notification) string {
+	var b strings.Builder
+
+	switch notification.Kind {
+	case models.NotificationSubscriptionChanges:
+		b.WriteString(i18n.T(lang, "notify_subscription_changes", notification.Name))
+		writeTracks(&b, lang, "changes_added", notification.Added)
+		writeTracks(&b, lang, "changes_removed", notification.Removed)
+		writeTracks(&b, lang, "changes_available", notification.Available)
+	case models.NotificationNewReleases:
+		b.WriteString(i18n.T(lang, "notify_new_releases", notification.Name, len(notification.Releases)))
+		for _, release := range notification.Releases {
+			b.WriteString("      • " + release + "\n")
+		}
+	case models.NotificationPlaylistDone:
+		b.WriteString(i18n.T(lang, "notify_playlist_done", notification.Name))
+		b.WriteString(i18n.T(lang, "notify_counts", notification.Found, notification.Missing, notification.Skipped))
+	default:
+		b.WriteString(i18n.T(lang, "notify_download_done", notification.Name))
+		b.WriteString(i18n.T(lang, "notify_counts", notification.Found, notification.Missing, notification.Skipped))
+	}
+
+	if notification.PlaylistPath != "" {
+		b.WriteString(i18n.T(lang, "notify_playlist_link", n.playlistLink(notification.PlaylistPath)))
+	}
+
+	return b.String()
+}
+
+func writeTracks(b *strings.Builder, lang i18n.Lang, key string, tracks []string) {
+	if len(tracks) == 0 {
+		return
+	}
+
+	b.WriteString(i18n.T(lang, key, len(tracks)))
+	for i, track := range tracks {
+		if i == maxChangedTracks {
+			b.WriteString(i18n.T(lang, "queue_more", i18n.N(lang, "tracks", len(tracks)-maxChangedTracks)))
+			break
+		}
+		b.WriteString("      • " + track + "\n")
+	}
+}
+
+func (n *notifier) playlistLink(playlistPath string) string {
+	if n.playlistBaseURL == "" {
+		return playlistPath
+	}
+	return n.playlistBaseURL + "/" + url.PathEscape(path.Base(playlistPath))
+}
@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/album-queue/pkg/i18n"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+type fakeStore struct {
+	pending   []models.Notification
+	languages map[int64]string
+	sent      []string
+	failed    map[string]bool
+}
+
+func (f *fakeStore) GetPendingNotifications(context.Context, int) ([]models.Notification, error) {
+	return f.pending, nil
+}
+
+func (f *fakeStore) MarkNotificationSent(_ context.Context, id string) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeStore) MarkNotificationFailed(_ context.Context, id string, giveUp bool) error {
+	if f.failed == nil {
+		f.failed = make(map[string]bool)
+	}
+	f.failed[id] = giveUp
+	return nil
+}
+
+func (f *fakeStore) GetUserLanguage(_ context.Context, userID int64) (string, error) {
+	return f.languages[userID], nil
+}
+
+func TestDeliverSendsAndMarksNotifications(t *testing.T) {
+	store := &fakeStore{
+		pending: []models.Notification{
+			{ID: "a", UserID: 1, Kind: models.NotificationDownloadDone, Name: "Album", Found: 9, Missing: 1, Skipped: 2},
+			{ID: "b", UserID: 2, Kind: models.NotificationPlaylistDone, Name: "Mix", Found: 20, PlaylistPath: "/music/Playlists/My Mix.m3u"},
+		},
+		languages: map[int64]string{2: "en"},
+	}
+
+	messages := map[int64]string{}
+	send := func(userID int64, text string) error {
+		messages[userID] = text
+		return nil
+	}
+
+	n := NewNotifier(store, send, zap.NewNop(), "https://music.example.com/playlists/")
+	if err := n.Deliver(context.Background()); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	if len(store.sent) != 2 {
+		t.Fatalf("expected both notifications marked as sent, got %v", store.sent)
+	}
+	if !strings.Contains(messages[1], "Докачав Album") || !strings.Contains(messages[1], "Знайдено: 9, не вистачає: 1, пропущено: 2") {
+		t.Errorf("unexpected Ukrainian message: %q", messages[1])
+	}
+	if !strings.Contains(messages[2], "Playlist Mix is ready") || !strings.Contains(messages[2], "https://music.example.com/playlists/My%20Mix.m3u") {
+		t.Errorf("unexpected English message: %q", messages[2])
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	store := &fakeStore{
+		pending: []models.Notification{
+			{ID: "new", UserID: 1},
+			{ID: "old", UserID: 1, Attempts: maxAttempts - 1},
+		},
+	}
+
+	send := func(int64, string) error { return errors.New("bot was blocked by the user") }
+
+	n := NewNotifier(store, send, zap.NewNop(), "")
+	if err := n.Deliver(context.Background()); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	if len(store.sent) != 0 {
+		t.Fatalf("expected nothing marked as sent, got %v", store.sent)
+	}
+	if giveUp, ok := store.failed["new"]; !ok || giveUp {
+		t.Errorf("expected new notification to be retried, got %v", store.failed)
+	}
+	if !store.failed["old"] {
+		t.Errorf("expected old notification to be given up, got %v", store.failed)
+	}
+}
+
+func TestPlaylistLinkWithoutBaseURL(t *testing.T) {
+	n := &notifier{}
+	if got := n.playlistLink("/music/Playlists/Mix.m3u"); got != "/music/Playlists/Mix.m3u" {
+		t.Errorf("expected the path, got %q", got)
+	}
+}
+
+func TestRenderSubscriptionChanges(t *testing.T) {
+	added := make([]string, 0, maxChangedTracks+2)
+	for i := 0; i < maxChangedTracks+2; i++ {
+		added = append(added, fmt.Sprintf("Artist - Song %d", i))
+	}
+
+	n := &notifier{}
+	text := n.render(i18n.English, models.Notification{
+		Kind:    models.NotificationSubscriptionChanges,
+		Name:    "Mix",
+		Added:   added,
+		Removed: []string{"Artist - Gone"},
+	})
+
+	if !strings.Contains(text, "Playlist Mix changed") || !strings.Contains(text, "Added (12)") || !strings.Contains(text, "Artist - Gone") {
+		t.Errorf("unexpected message: %q", text)
+	}
+	if strings.Contains(text, fmt.Sprintf("Song %d", maxChangedTracks)) || !strings.Contains(text, "and 2 tracks more") {
+		t.Errorf("expected the added tracks to be capped, got %q", text)
+	}
+	if strings.Contains(text, "Found:") {
+		t.Errorf("expected no download counts, got %q", text)
+	}
+}
+
+func TestRenderNewReleases(t *testing.T) {
+	n := &notifier{}
+	text := n.render(i18n.English, models.Notification{
+		Kind:     models.NotificationNewReleases,
+		Name:     "Daft Punk",
+		Releases: []string{"Discovery", "Infinity Repeating"},
+	})
+
+	if !strings.Contains(text, "New releases by Daft Punk queued (2)") || !strings.Contains(text, "• Infinity Repeating") {
+		t.Errorf("unexpected message: %q", text)
+	}
+	if strings.Contains(text, "Found:") {
+		t.Errorf("expected no download counts, got %q", text)
+	}
+}
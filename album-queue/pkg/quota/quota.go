@@ -0,0 +1,68 @@
+package quota
+
+import (
+	"errors"
+	"time"
+)
+
+// Window is the period the daily track limit is counted over
+const Window = 24 * time.Hour
+
+var (
+	ErrActiveRequests = errors.New("too many active requests")
+	ErrTracksPerDay   = errors.New("daily track limit reached")
+	ErrSubscriptions  = errors.New("too many subscriptions")
+)
+
+// Limits are the per-user allowances. Zero means unlimited.
+type Limits struct {
+	ActiveRequests int
+	TracksPerDay   int
+	Subscriptions  int
+}
+
+// Usage is what a user currently takes up
+type Usage struct {
+	// ActiveRequests counts active download and playlist requests
+	ActiveRequests int
+	// TracksToday sums the expected tracks of requests queued within Window
+	TracksToday   int
+	Subscriptions int
+}
+
+// Unlimited reports whether no limit is configured at all
+func (l Limits) Unlimited() bool {
+	return l.ActiveRequests <= 0 && l.TracksPerDay <= 0 && l.Subscriptions <= 0
+}
+
+// CheckQueue returns an error when queueing one more request with the given
+// number of tracks would go over a limit
+func (l Limits) CheckQueue(u Usage, tracks int) error {
+	if l.ActiveRequests > 0 && u.ActiveRequests >= l.ActiveRequests {
+		return ErrActiveRequests
+	}
+	if l.TracksPerDay > 0 && u.TracksToday+tracks > l.TracksPerDay {
+		return ErrTracksPerDay
+	}
+	return nil
+}
+
+// CheckSubscription returns an error when one more subscription would go over the limit
+func (l Limits) CheckSubscription(u Usage) error {
+	if l.Subscriptions > 0 && u.Subscriptions >= l.Subscriptions {
+		return ErrSubscriptions
+	}
+	return nil
+}
+
+// Remaining returns what is left of limit after used, never below zero.
+// The second value is false when there is no limit.
+func Remaining(limit, used int) (int, bool) {
+	if limit <= 0 {
+		return 0, false
+	}
+	if used >= limit {
+		return 0, true
+	}
+	return limit - used, true
+}
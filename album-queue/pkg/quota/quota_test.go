@@ -0,0 +1,55 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckQueue(t *testing.T) {
+	limits := Limits{ActiveRequests: 2, TracksPerDay: 100}
+
+	tests := []struct {
+		name     string
+		usage    Usage
+		tracks   int
+		expected error
+	}{
+		{"within limits", Usage{ActiveRequests: 1, TracksToday: 50}, 50, nil},
+		{"too many active", Usage{ActiveRequests: 2}, 1, ErrActiveRequests},
+		{"too many tracks", Usage{ActiveRequests: 0, TracksToday: 90}, 11, ErrTracksPerDay},
+		{"unknown track count", Usage{TracksToday: 100}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		if err := limits.CheckQueue(tt.usage, tt.tracks); !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+
+	if err := (Limits{}).CheckQueue(Usage{ActiveRequests: 1000, TracksToday: 1000000}, 1000); err != nil {
+		t.Errorf("expected zero limits to be unlimited, got %v", err)
+	}
+}
+
+func TestCheckSubscription(t *testing.T) {
+	limits := Limits{Subscriptions: 3}
+
+	if err := limits.CheckSubscription(Usage{Subscriptions: 2}); err != nil {
+		t.Errorf("expected subscription to be allowed, got %v", err)
+	}
+	if err := limits.CheckSubscription(Usage{Subscriptions: 3}); !errors.Is(err, ErrSubscriptions) {
+		t.Errorf("expected ErrSubscriptions, got %v", err)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	if left, limited := Remaining(10, 4); left != 6 || !limited {
+		t.Errorf("Remaining(10, 4) = %d, %v", left, limited)
+	}
+	if left, limited := Remaining(10, 12); left != 0 || !limited {
+		t.Errorf("Remaining(10, 12) = %d, %v", left, limited)
+	}
+	if _, limited := Remaining(0, 12); limited {
+		t.Error("expected zero limit to be unlimited")
+	}
+}
@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const (
+	// UpdatesPath is the route of the bot's HTTP server Telegram posts updates to
+	UpdatesPath = "/telegram/updates"
+	// SecretTokenHeader carries the secret token given to setWebhook on every update
+	SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// registerRetryDelay is the first wait after setWebhook failed, it doubles
+	// up to registerRetryMaxDelay
+	registerRetryDelay    = 5 * time.Second
+	registerRetryMaxDelay = 5 * time.Minute
+)
+
+// secretTokenPattern is what Telegram accepts as a secret token
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Webhook is a telebot.Poller receiving updates over a webhook instead of
+// polling for them. It doesn't listen on its own, it is the handler of
+// UpdatesPath on the bot's HTTP server.
+type Webhook struct {
+	publicURL   string
+	secretToken string
+	log         *zap.Logger
+	retryDelay  time.Duration
+
+	updates chan telebot.Update
+}
+
+// NewWebhook creates the poller. publicURL is where Telegram reaches
+// UpdatesPath, usually through the reverse proxy, and secretToken is checked
+// on every update.
+func NewWebhook(publicURL, secretToken string, log *zap.Logger) (*Webhook, error) {
+	if publicURL == "" {
+		return nil, errors.New("webhook public url is empty")
+	}
+	if !secretTokenPattern.MatchString(secretToken) {
+		return nil, errors.New("webhook secret token must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+
+	return &Webhook{
+		publicURL:   publicURL,
+		secretToken: secretToken,
+		log:         log,
+		retryDelay:  registerRetryDelay,
+		updates:     make(chan telebot.Update),
+	}, nil
+}
+
+// Poll registers the webhook with Telegram and hands the received updates to
+// the bot until it stops
+func (w *Webhook) Poll(b *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	go w.registerUntilDone(b, stop)
+
+	for {
+		select {
+		case update := <-w.updates:
+			dest <- update
+		case <-stop:
+			return
+		}
+	}
+}
+
+// registerUntilDone retries setWebhook with backoff, without it Telegram
+// never sends an update and the bot would sit there silently
+func (w *Webhook) registerUntilDone(b *telebot.Bot, stop chan struct{}) {
+	delay := w.retryDelay
+	for {
+		err := w.register(b)
+		if err == nil {
+			w.log.Info("Telegram webhook registered", zap.String("url", w.publicURL))
+			return
+		}
+		w.log.Error("Failed to register telegram webhook, retrying", zap.Error(err), zap.String("url", w.publicURL), zap.Duration("retry_in", delay))
+
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return
+		}
+		delay = min(delay*2, registerRetryMaxDelay)
+	}
+}
+
+func (w *Webhook) register(b *telebot.Bot) error {
+	_, err := b.Raw("setWebhook", map[string]string{
+		"url":          w.publicURL,
+		"secret_token": w.secretToken,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// ServeHTTP accepts an update when it carries the secret token. It answers
+// once the bot took the update, so Telegram retries updates that arrive while
+// the bot isn't polling.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(w.secretToken)) != 1 {
+		w.log.Warn("Rejected telegram update with a wrong secret token", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update telebot.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		w.log.Warn("Failed to decode telegram update", zap.Error(err))
+		http.Error(rw, "invalid update", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case w.updates <- update:
+		rw.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+	}
+}
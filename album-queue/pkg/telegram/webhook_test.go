@@ -0,0 +1,158 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const testUpdate = `{"update_id":1,"message":{"message_id":7,"from":{"id":42,"first_name":"Test"},"chat":{"id":42,"type":"private"},"date":1700000000,"text":"/queue"}}`
+
+// startBot runs a bot on the webhook against a stub Bot API and returns the
+// parameters the webhook was registered with and the received messages
+func startBot(t *testing.T, w *Webhook) (chan map[string]string, chan *telebot.Message) {
+	t.Helper()
+	return startBotFailing(t, w, 0)
+}
+
+// startBotFailing is startBot with the first failures setWebhook calls failing
+func startBotFailing(t *testing.T, w *Webhook, failures int) (chan map[string]string, chan *telebot.Message) {
+	t.Helper()
+
+	registered := make(chan map[string]string, failures+1)
+	api := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/setWebhook") {
+			var params map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			registered <- params
+			if failures > 0 {
+				failures--
+				rw.WriteHeader(http.StatusBadGateway)
+				_, _ = rw.Write([]byte(`{"ok":false,"error_code":502,"description":"Bad Gateway"}`))
+				return
+			}
+		}
+		_, _ = rw.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(api.Close)
+
+	bot, err := telebot.NewBot(telebot.Settings{URL: api.URL, Token: "token", Poller: w, Offline: true})
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+
+	received := make(chan *telebot.Message, 1)
+	bot.Handle("/queue", func(m *telebot.Message) { received <- m })
+
+	go bot.Start()
+	t.Cleanup(bot.Stop)
+
+	return registered, received
+}
+
+func postUpdate(w *Webhook, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, UpdatesPath, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set(SecretTokenHeader, token)
+	}
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebhookDeliversUpdates(t *testing.T) {
+	w, err := NewWebhook("https://bot.example.com"+UpdatesPath, "s3cret", zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registered, received := startBot(t, w)
+
+	select {
+	case params := <-registered:
+		if params["url"] != "https://bot.example.com/telegram/updates" || params["secret_token"] != "s3cret" {
+			t.Fatalf("unexpected setWebhook params: %v", params)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the webhook to be registered")
+	}
+
+	if rec := postUpdate(w, "s3cret", testUpdate); rec.Code != http.StatusOK {
+		t.Fatalf("expected the update to be accepted, got %d", rec.Code)
+	}
+
+	select {
+	case m := <-received:
+		if m.Sender.ID != 42 || m.Text != "/queue" {
+			t.Fatalf("unexpected message: %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the bot to handle the update")
+	}
+}
+
+func TestWebhookRetriesRegistration(t *testing.T) {
+	w, err := NewWebhook("https://bot.example.com"+UpdatesPath, "s3cret", zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.retryDelay = time.Millisecond
+	registered, _ := startBotFailing(t, w, 2)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-registered:
+		case <-time.After(time.Second):
+			t.Fatalf("expected setWebhook attempt %d", i+1)
+		}
+	}
+
+	select {
+	case <-registered:
+		t.Fatal("expected no more attempts after the webhook was registered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebhookRejectsRequests(t *testing.T) {
+	w, err := NewWebhook("https://bot.example.com"+UpdatesPath, "s3cret", zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		token, body string
+		code        int
+	}{
+		"missing token": {"", testUpdate, http.StatusUnauthorized},
+		"wrong token":   {"guess", testUpdate, http.StatusUnauthorized},
+		"invalid body":  {"s3cret", "{", http.StatusBadRequest},
+	} {
+		if rec := postUpdate(w, tc.token, tc.body); rec.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d", name, tc.code, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, UpdatesPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestNewWebhookValidatesConfig(t *testing.T) {
+	for _, tc := range []struct{ url, token string }{
+		{"", "s3cret"},
+		{"https://bot.example.com", ""},
+		{"https://bot.example.com", "not allowed!"},
+	} {
+		if _, err := NewWebhook(tc.url, tc.token, zap.NewNop()); err == nil {
+			t.Errorf("expected %q/%q to be rejected", tc.url, tc.token)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package utils
 
 import (
-	"net/http"
 	"slices"
 	"strings"
+	"unicode"
 )
 
 func IsValidSpotifyURL(url string) bool {
@@ -11,17 +11,34 @@ func IsValidSpotifyURL(url string) bool {
 	return strings.HasPrefix(url, "https://open.spotify.com/")
 }
 
-func InWhiteList(url int64, whitelist []int64) bool {
-	return slices.Contains(whitelist, url)
+// IsSpotifyArtistURL reports whether the URL is a Spotify artist page
+func IsSpotifyArtistURL(url string) bool {
+	return strings.HasPrefix(url, "https://open.spotify.com/artist/")
 }
 
-func SendDoneWebhook(webhookURL string) error {
-	// This function would send a GET request to the webhook URL with the message
-	resp, err := http.Get(webhookURL)
-	if err != nil {
-		return err
+// ExtractURLs returns the http(s) links found in text, in order and without
+// duplicates. Links may be separated by whitespace, commas or semicolons so
+// pasted lists and CSV files work alike.
+func ExtractURLs(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';'
+	})
+
+	urls := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, `"'<>()[]`)
+		if !strings.HasPrefix(field, "https://") && !strings.HasPrefix(field, "http://") {
+			continue
+		}
+		if slices.Contains(urls, field) {
+			continue
+		}
+		urls = append(urls, field)
 	}
-	defer resp.Body.Close()
 
-	return nil
+	return urls
+}
+
+func InWhiteList(url int64, whitelist []int64) bool {
+	return slices.Contains(whitelist, url)
 }
@@ -55,6 +55,23 @@ func TestIsValidSpotifyURL(t *testing.T) {
 	}
 }
 
+func TestIsSpotifyArtistURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "https://open.spotify.com/artist/4tZwfgrHOc3mvqYlEYSvVi", expected: true},
+		{url: "https://open.spotify.com/album/4tZwfgrHOc3mvqYlEYSvVi", expected: false},
+		{url: "https://spotify.com/artist/4tZwfgrHOc3mvqYlEYSvVi", expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := IsSpotifyArtistURL(tt.url); result != tt.expected {
+			t.Errorf("IsSpotifyArtistURL(%q) = %v, want %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
 func TestInWhiteList(t *testing.T) {
 	whitelist := []int64{123, 456, 789}
 
@@ -96,3 +113,51 @@ func TestInWhiteList_EmptyList(t *testing.T) {
 		t.Error("InWhiteList should return false for empty whitelist")
 	}
 }
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "single URL",
+			text:     "https://open.spotify.com/album/1",
+			expected: []string{"https://open.spotify.com/album/1"},
+		},
+		{
+			name:     "one per line",
+			text:     "https://open.spotify.com/album/1\nhttps://soundcloud.com/a/b\r\n\nhttps://open.spotify.com/album/2",
+			expected: []string{"https://open.spotify.com/album/1", "https://soundcloud.com/a/b", "https://open.spotify.com/album/2"},
+		},
+		{
+			name:     "csv with header and quotes",
+			text:     "name,url\nFirst,\"https://open.spotify.com/album/1\"\nSecond;https://open.spotify.com/album/2",
+			expected: []string{"https://open.spotify.com/album/1", "https://open.spotify.com/album/2"},
+		},
+		{
+			name:     "duplicates dropped",
+			text:     "https://open.spotify.com/album/1 https://open.spotify.com/album/1",
+			expected: []string{"https://open.spotify.com/album/1"},
+		},
+		{
+			name:     "no links",
+			text:     "hello there",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractURLs(tt.text)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("ExtractURLs(%q) = %v, want %v", tt.text, result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("ExtractURLs(%q)[%d] = %q, want %q", tt.text, i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/dynamic-playlists/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/dynamic-playlists/pkg/service"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/playlistfile"
+)
+
+const importUsage = "usage: dynamic-playlists import [-queue] [-user <telegram id>] [-name <name>] <file>..."
+
+// runImport imports the playlist files given on the command line, writes
+// their M3Us right away and keeps them for the next runs to update
+func runImport(ctx context.Context, args []string, database db.Database, processor *service.ImportedPlaylistsProcessor) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	queue := flags.Bool("queue", false, "queue downloads of the tracks missing from the library")
+	creatorID := flags.Int64("user", 0, "Telegram ID of the user the playlists and their downloads belong to")
+	name := flags.String("name", "", "playlist name for a file with one playlist, the file's own name by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New(importUsage)
+	}
+
+	for _, path := range flags.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		playlists, err := playlistfile.Parse(filepath.Base(path), f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		for _, parsed := range playlists {
+			playlist := models.ImportedPlaylist{
+				CreatorID:    *creatorID,
+				Name:         parsed.Name,
+				FileName:     filepath.Base(path),
+				Tracks:       parsed.Tracks,
+				QueueMissing: *queue,
+			}
+			if *name != "" && len(playlists) == 1 {
+				playlist.Name = *name
+			}
+
+			playlist, err = database.SaveImportedPlaylist(ctx, playlist)
+			if err != nil {
+				return fmt.Errorf("failed to save playlist %s: %w", parsed.Name, err)
+			}
+
+			summary, err := processor.ImportPlaylist(ctx, playlist)
+			if err != nil {
+				return fmt.Errorf("failed to import playlist %s: %w", playlist.Name, err)
+			}
+
+			fmt.Printf("%s: %d of %d tracks in the library, %d queued, written to %s\n",
+				playlist.Name, summary.Found, len(playlist.Tracks), summary.Queued, summary.OutputPath)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"go.uber.org/zap"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/dynamic-playlists/pkg/service"
 	models "github.com/supperdoggy/spot-models"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 )
 
 func main() {
@@ -78,17 +80,54 @@ func main() {
 	svc := service.NewService(database, playlistGenerator, openAIClient, logger)
 
 	// Initialize Spotify service for subscribed playlists
-	spotifyService := spotify.NewSpotifyService(ctx, cfg.SpotifyClientID, cfg.SpotifyClientSecret, logger)
+	spotifyEndpoints := spotify.WithEndpoints(spotify.Endpoints{
+		TokenURL:   cfg.SpotifyTokenURL,
+		APIBaseURL: cfg.SpotifyAPIURL,
+	})
+	spotifyService := spotify.NewSpotifyService(ctx, cfg.SpotifyClientID, cfg.SpotifyClientSecret, logger, spotifyEndpoints)
+
+	// Subscriptions of users who linked their account are fetched with their own token
+	spotifyAuth := spotify.NewAuthenticator(cfg.SpotifyClientID, cfg.SpotifyClientSecret, "", "", logger, spotifyEndpoints)
+	userSpotify := spotify.NewUserServices(spotifyAuth, database, spotifyService, logger)
+
+	// Webhook subscriptions are managed from album-queue
+	webhooks := webhook.NewDispatcher(database, logger)
 
 	// Initialize subscribed playlists processor
 	subscribedPlaylistsProcessor := service.NewSubscribedPlaylistsProcessor(
 		database,
 		spotifyService,
+		userSpotify,
 		cfg.MusicLibraryPath,
 		cfg.PlaylistsOutputPath,
+		webhooks,
 		logger,
 	)
 
+	// Initialize artist releases processor
+	artistReleasesProcessor := service.NewArtistReleasesProcessor(database, spotifyService, logger)
+
+	// Initialize release radar for the top library artists
+	releaseRadarProcessor := service.NewReleaseRadarProcessor(database, spotifyService, cfg.ReleaseRadarArtists, logger)
+
+	// Initialize imported playlists processor
+	importedPlaylistsProcessor := service.NewImportedPlaylistsProcessor(database, spotifyService, playlistGenerator, logger)
+
+	// `dynamic-playlists import <file>...` imports playlist files instead of running the jobs
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err := runImport(ctx, os.Args[2:], database, importedPlaylistsProcessor)
+		if err != nil {
+			logger.Error("Failed to import playlists", zap.Error(err))
+		}
+		if closeErr := database.Close(ctx); closeErr != nil {
+			logger.Warn("Error closing database connection", zap.Error(closeErr))
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Process playlists
 	logger.Info("Processing dynamic playlists...")
 	if err := svc.ProcessPlaylists(ctx); err != nil {
@@ -101,8 +140,29 @@ func main() {
 		logger.Error("Failed to process subscribed playlists", zap.Error(err))
 	}
 
+	// Update the M3Us of imported playlists
+	logger.Info("Processing imported playlists...")
+	if err := importedPlaylistsProcessor.ProcessImportedPlaylists(ctx); err != nil {
+		logger.Error("Failed to process imported playlists", zap.Error(err))
+	}
+
+	// Queue new releases of followed artists
+	logger.Info("Processing followed artists...")
+	if err := artistReleasesProcessor.ProcessArtistReleases(ctx); err != nil {
+		logger.Error("Failed to process followed artists", zap.Error(err))
+	}
+
+	// Suggest new releases of artists in the library
+	logger.Info("Processing release radar...")
+	if err := releaseRadarProcessor.ProcessReleaseRadar(ctx); err != nil {
+		logger.Error("Failed to process release radar", zap.Error(err))
+	}
+
 	logger.Info("Dynamic playlists processing completed successfully")
 
+	// Let the webhooks finish their retries before the connection is closed
+	webhooks.Wait()
+
 	// Close database connection
 	if err := database.Close(ctx); err != nil {
 		logger.Warn("Error closing database connection", zap.Error(err))
@@ -11,6 +11,13 @@ type Config struct {
 	SpotifyClientID     string `envconfig:"SPOTIFY_CLIENT_ID" required:"true"`
 	SpotifyClientSecret string `envconfig:"SPOTIFY_CLIENT_SECRET" required:"true"`
 	DryRun              bool   `envconfig:"DRY_RUN" default:"false"`
+	// ReleaseRadarArtists is how many of the most represented library artists
+	// are checked for new releases, 0 turns the release radar off
+	ReleaseRadarArtists int `envconfig:"RELEASE_RADAR_ARTISTS" default:"20"`
+
+	// Endpoint overrides, used to point the job at a stub server
+	SpotifyTokenURL string `envconfig:"SPOTIFY_TOKEN_URL"`
+	SpotifyAPIURL   string `envconfig:"SPOTIFY_API_URL"`
 }
 
 func NewConfig() (*Config, error) {
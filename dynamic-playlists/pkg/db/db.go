@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	models "github.com/supperdoggy/spot-models"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -24,8 +26,26 @@ type Database interface {
 	GetActiveSubscribedPlaylists(ctx context.Context) ([]models.SubscribedPlaylist, error)
 	UpdateSubscribedPlaylist(ctx context.Context, playlist models.SubscribedPlaylist) error
 	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	GetSpotifyToken(ctx context.Context, userID int64) (spotify.UserToken, error)
+	SaveSpotifyToken(ctx context.Context, token spotify.UserToken) error
 	CheckIfRequestAlreadySynced(ctx context.Context, url string) (bool, error)
 	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType) error
+	NewImportedTrackRequest(ctx context.Context, url, name string, playlist models.ImportedPlaylist) error
+	GetLatestSubscriptionSnapshot(ctx context.Context, subscriptionID string) (*models.SubscriptionSnapshot, error)
+	NewSubscriptionSnapshot(ctx context.Context, snapshot models.SubscriptionSnapshot) error
+	NewNotification(ctx context.Context, notification models.Notification) error
+	GetActiveArtistSubscriptions(ctx context.Context) ([]models.ArtistSubscription, error)
+	UpdateArtistSubscription(ctx context.Context, artist models.ArtistSubscription) error
+	HasDownloadRequest(ctx context.Context, url string) (bool, error)
+	AlbumInLibrary(ctx context.Context, artist, album string) (bool, error)
+	GetTopArtists(ctx context.Context, limit int) ([]string, error)
+	HasReleaseSuggestion(ctx context.Context, url string) (bool, error)
+	NewReleaseSuggestion(ctx context.Context, suggestion models.ReleaseSuggestion) error
+	GetActiveImportedPlaylists(ctx context.Context) ([]models.ImportedPlaylist, error)
+	SaveImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) (models.ImportedPlaylist, error)
+	UpdateImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) error
+	webhook.Store
 	Close(ctx context.Context) error
 	Ping(ctx context.Context) error
 }
@@ -115,6 +135,96 @@ func (d *db) downloadQueueRequestCollection() *mongo.Collection {
 	return d.conn.Database(d.dbname).Collection("download-queue-requests")
 }
 
+func (d *db) artistAliasesCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("artist_aliases")
+}
+
+func (d *db) spotifyTokensCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("spotify_tokens")
+}
+
+func (d *db) subscriptionSnapshotsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("subscription_snapshots")
+}
+
+func (d *db) notificationsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("notifications")
+}
+
+func (d *db) artistSubscriptionsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("artist_subscriptions")
+}
+
+func (d *db) releaseSuggestionsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("release_suggestions")
+}
+
+func (d *db) webhookSubscriptionsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("webhook_subscriptions")
+}
+
+func (d *db) webhookDeliveriesCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("webhook_deliveries")
+}
+
+func (d *db) importedPlaylistsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+	return d.conn.Database(d.dbname).Collection("imported_playlists")
+}
+
 func (d *db) GetActiveDynamicPlaylists(ctx context.Context) ([]models.DynamicPlaylist, error) {
 	cur, err := d.dynamicPlaylistsCollection().Find(ctx, bson.M{"active": true})
 	if err != nil {
@@ -240,8 +350,10 @@ func (d *db) Ping(ctx context.Context) error {
 	return d.conn.Ping(ctx, nil)
 }
 
+// GetActiveSubscribedPlaylists returns the subscriptions to sync, paused ones
+// are left out until they are resumed from the bot
 func (d *db) GetActiveSubscribedPlaylists(ctx context.Context) ([]models.SubscribedPlaylist, error) {
-	cur, err := d.subscribedPlaylistsCollection().Find(ctx, bson.M{"active": true})
+	cur, err := d.subscribedPlaylistsCollection().Find(ctx, bson.M{"active": true, "paused": bson.M{"$ne": true}})
 	if err != nil {
 		return nil, err
 	}
@@ -263,11 +375,20 @@ func (d *db) GetActiveSubscribedPlaylists(ctx context.Context) ([]models.Subscri
 	return playlists, nil
 }
 
+// UpdateSubscribedPlaylist stores the outcome of a sync. Only the sync fields
+// are written so settings changed from the bot during the sync are kept.
 func (d *db) UpdateSubscribedPlaylist(ctx context.Context, playlist models.SubscribedPlaylist) error {
 	_, err := d.subscribedPlaylistsCollection().UpdateOne(
 		ctx,
 		bson.M{"_id": playlist.ID},
-		bson.M{"$set": playlist},
+		bson.M{"$set": bson.M{
+			"last_synced":      playlist.LastSynced,
+			"last_track_count": playlist.LastTrackCount,
+			"snapshot_id":      playlist.SnapshotID,
+			"removed_tracks":   playlist.RemovedTracks,
+			"output_path":      playlist.OutputPath,
+			"updated_at":       playlist.UpdatedAt,
+		}},
 	)
 	return err
 }
@@ -281,14 +402,24 @@ func (d *db) FindMusicFiles(ctx context.Context, artists, titles []string) ([]mo
 		return nil, fmt.Errorf("artists and titles must have the same length")
 	}
 
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		d.log.Warn("failed to load artist aliases, matching exact artists only", zap.Error(err))
+	}
+
 	orPairs := make([]bson.M, 0, len(artists))
 	for i := range artists {
-		// Use case-insensitive regex matching for both artist and title
-		escapedArtist := escapeRegex(artists[i])
+		// Use case-insensitive regex matching for both artist and title,
+		// accepting every known spelling of the artist
+		variants := aliases.Variants(artists[i])
+		escapedArtists := make([]string, 0, len(variants))
+		for _, variant := range variants {
+			escapedArtists = append(escapedArtists, escapeRegex(variant))
+		}
 		escapedTitle := escapeRegex(titles[i])
 		orPairs = append(orPairs, bson.M{
 			"$and": []bson.M{
-				{"artist": bson.M{"$regex": "^" + escapedArtist + "$", "$options": "i"}},
+				{"artist": bson.M{"$regex": "^(?:" + strings.Join(escapedArtists, "|") + ")$", "$options": "i"}},
 				{"title": bson.M{"$regex": "^" + escapedTitle + "$", "$options": "i"}},
 			},
 		})
@@ -317,6 +448,39 @@ func (d *db) FindMusicFiles(ctx context.Context, artists, titles []string) ([]mo
 	return files, nil
 }
 
+func (d *db) GetArtistAliases(ctx context.Context) (models.ArtistAliases, error) {
+	cur, err := d.artistAliasesCollection().Find(ctx, bson.M{"suggested": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var aliases []models.ArtistAlias
+	if err := cur.All(ctx, &aliases); err != nil {
+		return nil, err
+	}
+
+	return models.NewArtistAliases(aliases), nil
+}
+
+func (d *db) GetSpotifyToken(ctx context.Context, userID int64) (spotify.UserToken, error) {
+	var token spotify.UserToken
+	err := d.spotifyTokensCollection().FindOne(ctx, bson.M{"user_id": userID}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return spotify.UserToken{}, spotify.ErrTokenNotFound
+	}
+	if err != nil {
+		return spotify.UserToken{}, err
+	}
+	return token, nil
+}
+
+func (d *db) SaveSpotifyToken(ctx context.Context, token spotify.UserToken) error {
+	token.UpdatedAt = time.Now().Unix()
+	_, err := d.spotifyTokensCollection().ReplaceOne(ctx, bson.M{"user_id": token.UserID}, token, options.Replace().SetUpsert(true))
+	return err
+}
+
 func (d *db) CheckIfRequestAlreadySynced(ctx context.Context, url string) (bool, error) {
 	count, err := d.downloadQueueRequestCollection().CountDocuments(ctx, bson.M{"spotify_url": url, "active": false})
 	if err != nil && err != mongo.ErrNoDocuments {
@@ -349,3 +513,312 @@ func (d *db) NewDownloadRequest(ctx context.Context, url, name string, creatorID
 
 	return nil
 }
+
+// NewImportedTrackRequest queues a missing track of an imported playlist. It
+// counts one track against the quota of the playlist's creator and carries the
+// playlist id, so all tracks of the playlist count as one active request.
+func (d *db) NewImportedTrackRequest(ctx context.Context, url, name string, playlist models.ImportedPlaylist) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	request := models.DownloadQueueRequest{
+		ID:                 id.String(),
+		CreatorID:          playlist.CreatorID,
+		SpotifyURL:         url,
+		ObjectType:         spotify.SpotifyObjectTypeTrack,
+		Name:               name,
+		Active:             true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		ExpectedTrackCount: 1,
+		ImportedPlaylistID: playlist.ID,
+	}
+
+	_, err = d.downloadQueueRequestCollection().InsertOne(ctx, request)
+	return err
+}
+
+// GetLatestSubscriptionSnapshot returns the snapshot of the last sync of the
+// subscription, or nil when it was never synced with snapshots
+func (d *db) GetLatestSubscriptionSnapshot(ctx context.Context, subscriptionID string) (*models.SubscriptionSnapshot, error) {
+	var snapshot models.SubscriptionSnapshot
+	err := d.subscriptionSnapshotsCollection().FindOne(
+		ctx,
+		bson.M{"subscription_id": subscriptionID},
+		options.FindOne().SetSort(bson.M{"created_at": -1}),
+	).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// NewSubscriptionSnapshot stores the snapshot of a sync. Only the latest one
+// is needed to diff against, so older snapshots without changes are dropped
+// and the older change records lose their track lists.
+func (d *db) NewSubscriptionSnapshot(ctx context.Context, snapshot models.SubscriptionSnapshot) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = id.String()
+	snapshot.CreatedAt = time.Now().Unix()
+
+	if _, err := d.subscriptionSnapshotsCollection().InsertOne(ctx, snapshot); err != nil {
+		return err
+	}
+
+	_, err = d.subscriptionSnapshotsCollection().DeleteMany(ctx, bson.M{
+		"subscription_id": snapshot.SubscriptionID,
+		"changed":         false,
+		"_id":             bson.M{"$ne": snapshot.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = d.subscriptionSnapshotsCollection().UpdateMany(ctx, bson.M{
+		"subscription_id": snapshot.SubscriptionID,
+		"tracks":          bson.M{"$exists": true},
+		"_id":             bson.M{"$ne": snapshot.ID},
+	}, bson.M{"$unset": bson.M{"tracks": ""}})
+	return err
+}
+
+// NewNotification adds a notification to the outbox that album-queue delivers
+func (d *db) NewNotification(ctx context.Context, notification models.Notification) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	notification.ID = id.String()
+	notification.CreatedAt = time.Now().Unix()
+	notification.SentAt = 0
+
+	_, err = d.notificationsCollection().InsertOne(ctx, notification)
+	return err
+}
+
+func (d *db) GetActiveArtistSubscriptions(ctx context.Context) ([]models.ArtistSubscription, error) {
+	cur, err := d.artistSubscriptionsCollection().Find(ctx, bson.M{"active": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	artists := make([]models.ArtistSubscription, 0)
+	for cur.Next(ctx) {
+		var artist models.ArtistSubscription
+		if err := cur.Decode(&artist); err != nil {
+			d.log.Error("failed to decode artist subscription", zap.Error(err))
+			continue
+		}
+		artists = append(artists, artist)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return artists, nil
+}
+
+// UpdateArtistSubscription stores when the artist's releases were last checked
+func (d *db) UpdateArtistSubscription(ctx context.Context, artist models.ArtistSubscription) error {
+	_, err := d.artistSubscriptionsCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": artist.ID},
+		bson.M{"$set": bson.M{
+			"last_checked": artist.LastChecked,
+			"updated_at":   artist.UpdatedAt,
+		}},
+	)
+	return err
+}
+
+// HasDownloadRequest reports whether the URL was ever queued, whether or not
+// the request is done
+func (d *db) HasDownloadRequest(ctx context.Context, url string) (bool, error) {
+	count, err := d.downloadQueueRequestCollection().CountDocuments(ctx, bson.M{"spotify_url": url})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AlbumInLibrary reports whether the library has a file of the album by any
+// known spelling of the artist
+func (d *db) AlbumInLibrary(ctx context.Context, artist, album string) (bool, error) {
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		d.log.Warn("failed to load artist aliases, matching exact artist only", zap.Error(err))
+	}
+
+	variants := aliases.Variants(artist)
+	escapedArtists := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		escapedArtists = append(escapedArtists, escapeRegex(variant))
+	}
+
+	count, err := d.musicFilesCollection().CountDocuments(ctx, bson.M{
+		"artist": bson.M{"$regex": "^(?:" + strings.Join(escapedArtists, "|") + ")$", "$options": "i"},
+		"album":  bson.M{"$regex": "^" + escapeRegex(album) + "$", "$options": "i"},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetTopArtists returns the artists with the most files in the library, most
+// first
+func (d *db) GetTopArtists(ctx context.Context, limit int) ([]string, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"artist": bson.M{"$nin": []any{"", nil}}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$artist", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cur, err := d.musicFilesCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var results []struct {
+		Artist string `bson:"_id"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	artists := make([]string, 0, len(results))
+	for _, result := range results {
+		artists = append(artists, result.Artist)
+	}
+	return artists, nil
+}
+
+// HasReleaseSuggestion reports whether the release was ever suggested,
+// whatever was decided about it
+func (d *db) HasReleaseSuggestion(ctx context.Context, url string) (bool, error) {
+	count, err := d.releaseSuggestionsCollection().CountDocuments(ctx, bson.M{"spotify_url": url})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *db) NewReleaseSuggestion(ctx context.Context, suggestion models.ReleaseSuggestion) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	suggestion.ID = id.String()
+	suggestion.Status = models.ReleaseSuggestionPending
+	suggestion.CreatedAt = time.Now().Unix()
+	suggestion.UpdatedAt = suggestion.CreatedAt
+
+	_, err = d.releaseSuggestionsCollection().InsertOne(ctx, suggestion)
+	return err
+}
+
+// GetWebhookSubscriptions returns the active subscriptions to the event,
+// they are managed from album-queue
+func (d *db) GetWebhookSubscriptions(ctx context.Context, event webhook.Event) ([]webhook.Subscription, error) {
+	cur, err := d.webhookSubscriptionsCollection().Find(ctx, bson.M{"active": true, "events": event})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var subscriptions []webhook.Subscription
+	if err := cur.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (d *db) SaveWebhookDelivery(ctx context.Context, delivery webhook.Delivery) error {
+	_, err := d.webhookDeliveriesCollection().InsertOne(ctx, delivery)
+	return err
+}
+
+func (d *db) GetActiveImportedPlaylists(ctx context.Context) ([]models.ImportedPlaylist, error) {
+	cur, err := d.importedPlaylistsCollection().Find(ctx, bson.M{"active": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	playlists := make([]models.ImportedPlaylist, 0)
+	for cur.Next(ctx) {
+		var playlist models.ImportedPlaylist
+		if err := cur.Decode(&playlist); err != nil {
+			d.log.Error("failed to decode imported playlist", zap.Error(err))
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return playlists, nil
+}
+
+// SaveImportedPlaylist stores a playlist imported from the command line,
+// replacing the user's playlist of the same name, and returns it with its ID
+func (d *db) SaveImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) (models.ImportedPlaylist, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return models.ImportedPlaylist{}, err
+	}
+
+	playlist.ID = id.String()
+	playlist.Active = true
+	playlist.CreatedAt = time.Now().Unix()
+	playlist.UpdatedAt = playlist.CreatedAt
+
+	var existing models.ImportedPlaylist
+	err = d.importedPlaylistsCollection().FindOne(ctx, bson.M{"creator_id": playlist.CreatorID, "name": playlist.Name}).Decode(&existing)
+	switch {
+	case err == nil:
+		playlist.ID = existing.ID
+		playlist.CreatedAt = existing.CreatedAt
+	case err != mongo.ErrNoDocuments:
+		return models.ImportedPlaylist{}, err
+	}
+
+	_, err = d.importedPlaylistsCollection().ReplaceOne(ctx, bson.M{"_id": playlist.ID}, playlist, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.ImportedPlaylist{}, err
+	}
+	return playlist, nil
+}
+
+// UpdateImportedPlaylist stores the outcome of a run: which tracks are in the
+// library or were queued, and where the M3U was written
+func (d *db) UpdateImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) error {
+	_, err := d.importedPlaylistsCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": playlist.ID},
+		bson.M{"$set": bson.M{
+			"tracks":         playlist.Tracks,
+			"track_count":    playlist.TrackCount,
+			"output_path":    playlist.OutputPath,
+			"last_generated": playlist.LastGenerated,
+			"updated_at":     playlist.UpdatedAt,
+		}},
+	)
+	return err
+}
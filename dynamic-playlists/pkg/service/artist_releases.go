@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+)
+
+// artistCheckInterval is how often the releases of a followed artist are checked
+const artistCheckInterval = 24 * time.Hour
+
+type ArtistReleasesDB interface {
+	GetActiveArtistSubscriptions(ctx context.Context) ([]models.ArtistSubscription, error)
+	UpdateArtistSubscription(ctx context.Context, artist models.ArtistSubscription) error
+	HasDownloadRequest(ctx context.Context, url string) (bool, error)
+	AlbumInLibrary(ctx context.Context, artist, album string) (bool, error)
+	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType) error
+	NewNotification(ctx context.Context, notification models.Notification) error
+}
+
+type ArtistReleasesService interface {
+	GetArtistReleases(ctx context.Context, url string) ([]spotify.ArtistRelease, error)
+}
+
+// ArtistReleasesProcessor queues the new albums and singles of the artists
+// users follow from the bot
+type ArtistReleasesProcessor struct {
+	db             ArtistReleasesDB
+	spotifyService ArtistReleasesService
+	log            *zap.Logger
+}
+
+func NewArtistReleasesProcessor(db ArtistReleasesDB, spotifyService ArtistReleasesService, log *zap.Logger) *ArtistReleasesProcessor {
+	return &ArtistReleasesProcessor{
+		db:             db,
+		spotifyService: spotifyService,
+		log:            log,
+	}
+}
+
+func (p *ArtistReleasesProcessor) ProcessArtistReleases(ctx context.Context) error {
+	artists, err := p.db.GetActiveArtistSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.log.Info("Processing followed artists", zap.Int("count", len(artists)))
+
+	now := time.Now()
+	for _, artist := range artists {
+		if now.Sub(time.Unix(artist.LastChecked, 0)) < artistCheckInterval {
+			p.log.Info("Skipping artist - checked recently",
+				zap.String("artist_id", artist.ID),
+				zap.Int64("last_checked", artist.LastChecked))
+			continue
+		}
+
+		if err := p.processArtist(ctx, artist); err != nil {
+			p.log.Error("Failed to process artist", zap.Error(err), zap.String("artist_id", artist.ID))
+			continue
+		}
+	}
+
+	return nil
+}
+
+func (p *ArtistReleasesProcessor) processArtist(ctx context.Context, artist models.ArtistSubscription) error {
+	p.log.Info("Checking artist releases", zap.String("artist_id", artist.ID), zap.String("spotify_url", artist.SpotifyURL))
+
+	releases, err := p.spotifyService.GetArtistReleases(ctx, artist.SpotifyURL)
+	if err != nil {
+		return fmt.Errorf("failed to get artist releases: %w", err)
+	}
+
+	// Release dates are days at best, so anything out on the day the artist
+	// was followed counts as new
+	followedOn := time.Unix(artist.FollowedAt, 0).UTC().Truncate(24 * time.Hour)
+
+	var queued []string
+	for _, release := range releases {
+		// releases are newest first
+		if release.ReleaseDate.Before(followedOn) {
+			break
+		}
+
+		isNew, err := p.isNewRelease(ctx, release)
+		if err != nil {
+			p.log.Error("failed to check release", zap.Error(err), zap.String("url", release.URL))
+			continue
+		}
+		if !isNew {
+			continue
+		}
+
+		name := fmt.Sprintf("%s - %s", release.Artist, release.Name)
+		if err := p.db.NewDownloadRequest(ctx, release.URL, name, artist.CreatorID, spotify.SpotifyObjectTypeAlbum); err != nil {
+			p.log.Error("failed to add download request for release", zap.Error(err), zap.String("url", release.URL))
+			continue
+		}
+
+		p.log.Info("queued new release", zap.String("url", release.URL), zap.String("name", name), zap.String("type", release.Type))
+		queued = append(queued, release.Name)
+	}
+
+	artist.LastChecked = time.Now().Unix()
+	artist.UpdatedAt = time.Now().Unix()
+	if err := p.db.UpdateArtistSubscription(ctx, artist); err != nil {
+		return fmt.Errorf("failed to update artist subscription: %w", err)
+	}
+
+	if len(queued) == 0 {
+		return nil
+	}
+
+	if err := p.db.NewNotification(ctx, models.NewReleasesNotification(artist, queued)); err != nil {
+		p.log.Error("failed to add new releases notification", zap.Error(err), zap.String("artist_id", artist.ID))
+	}
+
+	return nil
+}
+
+// isNewRelease reports whether the release was neither requested before nor
+// is in the library already
+func (p *ArtistReleasesProcessor) isNewRelease(ctx context.Context, release spotify.ArtistRelease) (bool, error) {
+	requested, err := p.db.HasDownloadRequest(ctx, release.URL)
+	if err != nil {
+		return false, fmt.Errorf("failed to check download requests: %w", err)
+	}
+	if requested {
+		return false, nil
+	}
+
+	inLibrary, err := p.db.AlbumInLibrary(ctx, release.Artist, release.Name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check library: %w", err)
+	}
+	return !inLibrary, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+)
+
+type fakeArtistReleasesDB struct {
+	artists       []models.ArtistSubscription
+	requested     map[string]bool
+	library       map[string]bool
+	updated       []models.ArtistSubscription
+	requests      []string
+	notifications []models.Notification
+}
+
+func (f *fakeArtistReleasesDB) GetActiveArtistSubscriptions(context.Context) ([]models.ArtistSubscription, error) {
+	return f.artists, nil
+}
+
+func (f *fakeArtistReleasesDB) UpdateArtistSubscription(_ context.Context, artist models.ArtistSubscription) error {
+	f.updated = append(f.updated, artist)
+	return nil
+}
+
+func (f *fakeArtistReleasesDB) HasDownloadRequest(_ context.Context, url string) (bool, error) {
+	return f.requested[url], nil
+}
+
+func (f *fakeArtistReleasesDB) AlbumInLibrary(_ context.Context, artist, album string) (bool, error) {
+	return f.library[artist+" - "+album], nil
+}
+
+func (f *fakeArtistReleasesDB) NewDownloadRequest(_ context.Context, url, _ string, _ int64, _ spotify.SpotifyObjectType) error {
+	f.requests = append(f.requests, url)
+	return nil
+}
+
+func (f *fakeArtistReleasesDB) NewNotification(_ context.Context, notification models.Notification) error {
+	f.notifications = append(f.notifications, notification)
+	return nil
+}
+
+type fakeReleasesSpotify struct {
+	releases map[string][]spotify.ArtistRelease
+	fetched  []string
+}
+
+func (f *fakeReleasesSpotify) GetArtistReleases(_ context.Context, url string) ([]spotify.ArtistRelease, error) {
+	f.fetched = append(f.fetched, url)
+	return f.releases[url], nil
+}
+
+func release(name string, date time.Time) spotify.ArtistRelease {
+	return spotify.ArtistRelease{
+		URL:         "https://open.spotify.com/album/" + strings.ReplaceAll(strings.ToLower(name), " ", "-"),
+		Name:        name,
+		Artist:      "Artist",
+		Type:        "album",
+		ReleaseDate: date,
+	}
+}
+
+const followedArtistURL = "https://open.spotify.com/artist/artist"
+
+func TestProcessArtistQueuesReleasesSinceFollowing(t *testing.T) {
+	followedAt := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	db := &fakeArtistReleasesDB{
+		requested: map[string]bool{"https://open.spotify.com/album/requested": true},
+		library:   map[string]bool{"Artist - Owned": true},
+	}
+	spotifyService := &fakeReleasesSpotify{releases: map[string][]spotify.ArtistRelease{followedArtistURL: {
+		release("Newest", day(20)),
+		release("Requested", day(18)),
+		release("Owned", day(15)),
+		release("Same Day", day(10)),
+		release("Before", day(9)),
+		// out of order on purpose, the check stops at the first older release
+		release("Late Listing", day(12)),
+	}}}
+	processor := NewArtistReleasesProcessor(db, spotifyService, zap.NewNop())
+	artist := models.ArtistSubscription{ID: "artist", CreatorID: 2, SpotifyURL: followedArtistURL, Name: "Artist", FollowedAt: followedAt.Unix()}
+
+	if err := processor.processArtist(context.Background(), artist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"https://open.spotify.com/album/newest", "https://open.spotify.com/album/same-day"}
+	if strings.Join(db.requests, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected %v to be queued, got %v", expected, db.requests)
+	}
+	if len(db.updated) != 1 || db.updated[0].LastChecked == 0 {
+		t.Fatalf("expected the check time to be stored, got %+v", db.updated)
+	}
+	if len(db.notifications) != 1 {
+		t.Fatalf("expected one notification, got %+v", db.notifications)
+	}
+	notification := db.notifications[0]
+	if notification.UserID != 2 || notification.Kind != models.NotificationNewReleases || strings.Join(notification.Releases, ",") != "Newest,Same Day" {
+		t.Fatalf("unexpected notification: %+v", notification)
+	}
+}
+
+func TestProcessArtistWithoutNewReleasesSkipsNotification(t *testing.T) {
+	db := &fakeArtistReleasesDB{library: map[string]bool{"Artist - Owned": true}}
+	spotifyService := &fakeReleasesSpotify{releases: map[string][]spotify.ArtistRelease{followedArtistURL: {
+		release("Owned", time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)),
+		release("Old", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}}}
+	processor := NewArtistReleasesProcessor(db, spotifyService, zap.NewNop())
+	artist := models.ArtistSubscription{ID: "artist", SpotifyURL: followedArtistURL, FollowedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Unix()}
+
+	if err := processor.processArtist(context.Background(), artist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(db.requests) != 0 || len(db.notifications) != 0 {
+		t.Fatalf("expected nothing queued, got requests %v notifications %+v", db.requests, db.notifications)
+	}
+	if len(db.updated) != 1 {
+		t.Fatalf("expected the check time to be stored, got %+v", db.updated)
+	}
+}
+
+func TestProcessArtistReleasesSkipsRecentlyChecked(t *testing.T) {
+	db := &fakeArtistReleasesDB{artists: []models.ArtistSubscription{
+		{ID: "recent", SpotifyURL: "https://open.spotify.com/artist/recent", LastChecked: time.Now().Add(-time.Hour).Unix()},
+		{ID: "due", SpotifyURL: "https://open.spotify.com/artist/due", LastChecked: time.Now().Add(-2 * artistCheckInterval).Unix()},
+	}}
+	spotifyService := &fakeReleasesSpotify{}
+	processor := NewArtistReleasesProcessor(db, spotifyService, zap.NewNop())
+
+	if err := processor.ProcessArtistReleases(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(spotifyService.fetched, " ") != "https://open.spotify.com/artist/due" {
+		t.Fatalf("expected only the due artist to be checked, got %v", spotifyService.fetched)
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+)
+
+type ImportedPlaylistsDB interface {
+	GetActiveImportedPlaylists(ctx context.Context) ([]models.ImportedPlaylist, error)
+	UpdateImportedPlaylist(ctx context.Context, playlist models.ImportedPlaylist) error
+	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	HasDownloadRequest(ctx context.Context, url string) (bool, error)
+	NewImportedTrackRequest(ctx context.Context, url, name string, playlist models.ImportedPlaylist) error
+}
+
+// TrackSearcher finds the Spotify URL of a missing track the playlist file
+// didn't name one for
+type TrackSearcher interface {
+	SearchTrack(ctx context.Context, artist, title, isrc string) (string, error)
+}
+
+// ImportedPlaylistsProcessor writes the M3Us of playlists imported from files
+// and queues their missing tracks
+type ImportedPlaylistsProcessor struct {
+	db                ImportedPlaylistsDB
+	spotifyService    TrackSearcher
+	playlistGenerator *PlaylistGenerator
+	log               *zap.Logger
+}
+
+// ImportSummary is the outcome of one run over an imported playlist
+type ImportSummary struct {
+	Found   int
+	Missing int
+	// Queued is how many missing tracks were queued for download on this run
+	Queued     int
+	OutputPath string
+}
+
+func NewImportedPlaylistsProcessor(db ImportedPlaylistsDB, spotifyService TrackSearcher, playlistGenerator *PlaylistGenerator, log *zap.Logger) *ImportedPlaylistsProcessor {
+	return &ImportedPlaylistsProcessor{
+		db:                db,
+		spotifyService:    spotifyService,
+		playlistGenerator: playlistGenerator,
+		log:               log,
+	}
+}
+
+// ProcessImportedPlaylists rewrites the M3U of every imported playlist so the
+// tracks that reached the library since the last run are added
+func (s *ImportedPlaylistsProcessor) ProcessImportedPlaylists(ctx context.Context) error {
+	playlists, err := s.db.GetActiveImportedPlaylists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get imported playlists: %w", err)
+	}
+
+	s.log.Info("processing imported playlists", zap.Int("count", len(playlists)))
+
+	for _, playlist := range playlists {
+		if _, err := s.ImportPlaylist(ctx, playlist); err != nil {
+			s.log.Error("failed to process imported playlist", zap.Error(err), zap.String("playlist_id", playlist.ID))
+		}
+	}
+
+	return nil
+}
+
+// ImportPlaylist matches the playlist against the library, writes the found
+// tracks to its M3U through the playlist generator and queues the missing
+// ones when the playlist asks for it
+func (s *ImportedPlaylistsProcessor) ImportPlaylist(ctx context.Context, playlist models.ImportedPlaylist) (ImportSummary, error) {
+	files, err := s.findInLibrary(ctx, playlist.Tracks)
+	if err != nil {
+		return ImportSummary{}, err
+	}
+
+	outputPath, err := s.playlistGenerator.WriteM3U(files, importedFolder(playlist.CreatorID), playlist.Name)
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("failed to write m3u: %w", err)
+	}
+
+	summary := ImportSummary{
+		Found:      len(files),
+		Missing:    len(playlist.Tracks) - len(files),
+		OutputPath: outputPath,
+	}
+	if playlist.QueueMissing {
+		summary.Queued = s.requestMissingTracks(ctx, playlist)
+	}
+
+	playlist.TrackCount = len(files)
+	playlist.OutputPath = outputPath
+	playlist.LastGenerated = time.Now().Unix()
+	playlist.UpdatedAt = time.Now().Unix()
+
+	if err := s.db.UpdateImportedPlaylist(ctx, playlist); err != nil {
+		return summary, fmt.Errorf("failed to update playlist: %w", err)
+	}
+
+	s.log.Info("imported playlist written",
+		zap.String("playlist_id", playlist.ID),
+		zap.String("name", playlist.Name),
+		zap.Int("found", summary.Found),
+		zap.Int("missing", summary.Missing),
+		zap.Int("queued", summary.Queued))
+
+	return summary, nil
+}
+
+// importedFolder keeps the M3Us of imported playlists apart from the dynamic
+// and subscribed ones and from those other users imported under the same name
+func importedFolder(creatorID int64) string {
+	return filepath.Join("imported", strconv.FormatInt(creatorID, 10))
+}
+
+// findInLibrary sets InLibrary on each track and returns the files of the
+// found ones in playlist order
+func (s *ImportedPlaylistsProcessor) findInLibrary(ctx context.Context, tracks []models.ImportedTrack) ([]models.MusicFile, error) {
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+
+	artists := make([]string, 0, len(tracks))
+	titles := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		artists = append(artists, strings.ToLower(track.Artist))
+		titles = append(titles, strings.ToLower(track.Title))
+	}
+
+	foundMusic, err := s.db.FindMusicFiles(ctx, artists, titles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find music files: %w", err)
+	}
+
+	aliases, err := s.db.GetArtistAliases(ctx)
+	if err != nil {
+		s.log.Warn("failed to load artist aliases", zap.Error(err))
+	}
+
+	return models.MatchImportedTracks(tracks, foundMusic, aliases), nil
+}
+
+// requestMissingTracks queues a download of each missing track that wasn't
+// queued before and returns how many were queued. Tracks without a Spotify
+// URL are searched for, those Spotify doesn't have are not searched again.
+func (s *ImportedPlaylistsProcessor) requestMissingTracks(ctx context.Context, playlist models.ImportedPlaylist) int {
+	createdCount := 0
+	for i := range playlist.Tracks {
+		track := &playlist.Tracks[i]
+		if track.InLibrary || track.Requested {
+			continue
+		}
+
+		trackURL := track.SpotifyURL
+		if trackURL == "" {
+			var err error
+			trackURL, err = s.spotifyService.SearchTrack(ctx, track.Artist, track.Title, track.ISRC)
+			if errors.Is(err, spotify.ErrTrackNotFound) {
+				s.log.Info("missing track not found on spotify", zap.String("artist", track.Artist), zap.String("title", track.Title))
+				track.Requested = true
+				continue
+			}
+			if err != nil {
+				s.log.Error("failed to search track", zap.Error(err), zap.String("artist", track.Artist), zap.String("title", track.Title))
+				continue
+			}
+		}
+
+		queued, err := s.db.HasDownloadRequest(ctx, trackURL)
+		if err != nil {
+			s.log.Error("failed to check download request", zap.Error(err), zap.String("track_url", trackURL))
+			continue
+		}
+		if queued {
+			track.Requested = true
+			continue
+		}
+
+		trackName := fmt.Sprintf("%s - %s", track.Artist, track.Title)
+		if err := s.db.NewImportedTrackRequest(ctx, trackURL, trackName, playlist); err != nil {
+			s.log.Error("failed to add download request for track", zap.Error(err), zap.String("track_url", trackURL))
+			continue
+		}
+
+		track.Requested = true
+		createdCount++
+	}
+
+	return createdCount
+}
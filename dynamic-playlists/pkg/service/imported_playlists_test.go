@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+type fakeImportedDB struct {
+	library  []models.MusicFile
+	requests []models.ImportedPlaylist
+	updated  []models.ImportedPlaylist
+}
+
+func (f *fakeImportedDB) GetActiveImportedPlaylists(context.Context) ([]models.ImportedPlaylist, error) {
+	return nil, nil
+}
+
+func (f *fakeImportedDB) UpdateImportedPlaylist(_ context.Context, playlist models.ImportedPlaylist) error {
+	f.updated = append(f.updated, playlist)
+	return nil
+}
+
+func (f *fakeImportedDB) FindMusicFiles(context.Context, []string, []string) ([]models.MusicFile, error) {
+	return f.library, nil
+}
+
+func (f *fakeImportedDB) GetArtistAliases(context.Context) (models.ArtistAliases, error) {
+	return nil, nil
+}
+
+func (f *fakeImportedDB) HasDownloadRequest(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeImportedDB) NewImportedTrackRequest(_ context.Context, _, _ string, playlist models.ImportedPlaylist) error {
+	f.requests = append(f.requests, playlist)
+	return nil
+}
+
+func TestImportPlaylistWritesToUserFolder(t *testing.T) {
+	db := &fakeImportedDB{library: []models.MusicFile{{Artist: "Artist", Title: "One", Path: "/music/one.mp3"}}}
+	outputPath := t.TempDir()
+	processor := NewImportedPlaylistsProcessor(db, nil, NewPlaylistGenerator(nil, nil, "/music", outputPath, zap.NewNop()), zap.NewNop())
+
+	// a subscription of the same name keeps its m3u
+	subscribed := filepath.Join(outputPath, "Mix.m3u")
+	if err := os.WriteFile(subscribed, []byte("#EXTM3U\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	playlist := models.ImportedPlaylist{ID: "imported", CreatorID: 2, Name: "Mix", QueueMissing: true, Tracks: []models.ImportedTrack{
+		{Artist: "Artist", Title: "One"},
+		{Artist: "Artist", Title: "Two", SpotifyURL: "https://open.spotify.com/track/two"},
+	}}
+	summary, err := processor.ImportPlaylist(context.Background(), playlist)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := filepath.Join(outputPath, "imported", "2", "Mix.m3u"); summary.OutputPath != expected {
+		t.Fatalf("expected the m3u at %s, got %s", expected, summary.OutputPath)
+	}
+	if data, err := os.ReadFile(subscribed); err != nil || string(data) != "#EXTM3U\n" {
+		t.Fatalf("expected the subscribed m3u to be kept, got %q %v", data, err)
+	}
+	if summary.Queued != 1 || len(db.requests) != 1 || db.requests[0].ID != "imported" {
+		t.Fatalf("expected the missing track to be queued for the playlist, got %+v", db.requests)
+	}
+}
@@ -266,6 +266,16 @@ func (pg *PlaylistGenerator) matchesSimplifiedGenreFilter(query bson.M, simplifi
 	return true
 }
 
+// WriteM3U writes the files to <output>/<folder>/<name>.m3u and returns its
+// path. Slashes in the name would point into subfolders and are replaced.
+func (pg *PlaylistGenerator) WriteM3U(files []models.MusicFile, folder, name string) (string, error) {
+	m3uPath := filepath.Join(pg.outputPath, folder, strings.ReplaceAll(name, "/", "-")+".m3u")
+	if err := pg.createM3UPlaylist(files, m3uPath); err != nil {
+		return "", err
+	}
+	return m3uPath, nil
+}
+
 func (pg *PlaylistGenerator) createM3UPlaylist(files []models.MusicFile, outputPath string) error {
 	// Ensure output directory exists
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+type ReleaseRadarDB interface {
+	GetTopArtists(ctx context.Context, limit int) ([]string, error)
+	FindMusicFilesByQuery(ctx context.Context, query bson.M, sortBy string, limit int) ([]models.MusicFile, error)
+	HasReleaseSuggestion(ctx context.Context, url string) (bool, error)
+	NewReleaseSuggestion(ctx context.Context, suggestion models.ReleaseSuggestion) error
+	HasDownloadRequest(ctx context.Context, url string) (bool, error)
+	AlbumInLibrary(ctx context.Context, artist, album string) (bool, error)
+}
+
+type ReleaseRadarService interface {
+	SearchArtist(ctx context.Context, name string) (string, error)
+	GetArtistReleases(ctx context.Context, url string) ([]spotify.ArtistRelease, error)
+}
+
+// ReleaseRadarProcessor suggests the releases of the most represented library
+// artists that came out after their newest album in the library
+type ReleaseRadarProcessor struct {
+	db             ReleaseRadarDB
+	spotifyService ReleaseRadarService
+	topArtists     int
+	log            *zap.Logger
+}
+
+func NewReleaseRadarProcessor(db ReleaseRadarDB, spotifyService ReleaseRadarService, topArtists int, log *zap.Logger) *ReleaseRadarProcessor {
+	return &ReleaseRadarProcessor{
+		db:             db,
+		spotifyService: spotifyService,
+		topArtists:     topArtists,
+		log:            log,
+	}
+}
+
+func (p *ReleaseRadarProcessor) ProcessReleaseRadar(ctx context.Context) error {
+	if p.topArtists <= 0 {
+		p.log.Info("Release radar disabled")
+		return nil
+	}
+
+	artists, err := p.db.GetTopArtists(ctx, p.topArtists)
+	if err != nil {
+		return fmt.Errorf("failed to get top artists: %w", err)
+	}
+
+	p.log.Info("Processing release radar", zap.Int("count", len(artists)))
+
+	for _, artist := range artists {
+		if err := p.processArtist(ctx, artist); err != nil {
+			p.log.Error("Failed to check artist for new releases", zap.Error(err), zap.String("artist", artist))
+			continue
+		}
+	}
+
+	return nil
+}
+
+func (p *ReleaseRadarProcessor) processArtist(ctx context.Context, artist string) error {
+	files, err := p.db.FindMusicFilesByQuery(ctx, bson.M{
+		"artist": bson.M{"$regex": "^" + regexp.QuoteMeta(artist) + "$", "$options": "i"},
+	}, "", 0)
+	if err != nil {
+		return fmt.Errorf("failed to get artist files: %w", err)
+	}
+
+	localAlbums := make(map[string]bool)
+	for _, file := range files {
+		if album := normalizeAlbum(file.Album); album != "" {
+			localAlbums[album] = true
+		}
+	}
+	if len(localAlbums) == 0 {
+		p.log.Debug("no albums of artist in library", zap.String("artist", artist))
+		return nil
+	}
+
+	artistURL, err := p.spotifyService.SearchArtist(ctx, artist)
+	if errors.Is(err, spotify.ErrArtistNotFound) {
+		p.log.Debug("artist not found on spotify", zap.String("artist", artist))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to search artist: %w", err)
+	}
+
+	releases, err := p.spotifyService.GetArtistReleases(ctx, artistURL)
+	if err != nil {
+		return fmt.Errorf("failed to get artist releases: %w", err)
+	}
+
+	// releases are newest first, everything before the newest local album is newer
+	latest := -1
+	for i, release := range releases {
+		if localAlbums[normalizeAlbum(release.Name)] {
+			latest = i
+			break
+		}
+	}
+	if latest == -1 {
+		p.log.Debug("no library album of artist found on spotify", zap.String("artist", artist))
+		return nil
+	}
+
+	latestLocal := releases[latest]
+	for _, release := range releases[:latest] {
+		if !release.ReleaseDate.After(latestLocal.ReleaseDate) {
+			continue
+		}
+
+		isNew, err := p.isNewSuggestion(ctx, release)
+		if err != nil {
+			p.log.Error("failed to check release", zap.Error(err), zap.String("url", release.URL))
+			continue
+		}
+		if !isNew {
+			continue
+		}
+
+		suggestion := models.ReleaseSuggestion{
+			Artist:      artist,
+			ArtistURL:   artistURL,
+			SpotifyURL:  release.URL,
+			Name:        release.Name,
+			Type:        release.Type,
+			ReleaseDate: release.ReleaseDate.Unix(),
+			LatestLocal: latestLocal.Name,
+		}
+		if err := p.db.NewReleaseSuggestion(ctx, suggestion); err != nil {
+			p.log.Error("failed to add release suggestion", zap.Error(err), zap.String("url", release.URL))
+			continue
+		}
+
+		p.log.Info("suggested new release", zap.String("artist", artist), zap.String("name", release.Name), zap.String("url", release.URL))
+	}
+
+	return nil
+}
+
+// isNewSuggestion reports whether the release was never suggested or
+// requested and isn't in the library
+func (p *ReleaseRadarProcessor) isNewSuggestion(ctx context.Context, release spotify.ArtistRelease) (bool, error) {
+	suggested, err := p.db.HasReleaseSuggestion(ctx, release.URL)
+	if err != nil {
+		return false, fmt.Errorf("failed to check release suggestions: %w", err)
+	}
+	if suggested {
+		return false, nil
+	}
+
+	requested, err := p.db.HasDownloadRequest(ctx, release.URL)
+	if err != nil {
+		return false, fmt.Errorf("failed to check download requests: %w", err)
+	}
+	if requested {
+		return false, nil
+	}
+
+	inLibrary, err := p.db.AlbumInLibrary(ctx, release.Artist, release.Name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check library: %w", err)
+	}
+	return !inLibrary, nil
+}
+
+// normalizeAlbum makes album names from tags and from Spotify comparable
+func normalizeAlbum(album string) string {
+	return strings.Join(strings.Fields(strings.ToLower(album)), " ")
+}
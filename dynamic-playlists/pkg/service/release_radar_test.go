@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// fakeReleaseRadarDB remembers the suggestions it saved, like the collection
+// the processor checks before suggesting
+type fakeReleaseRadarDB struct {
+	topArtists  []string
+	files       []models.MusicFile
+	requested   map[string]bool
+	library     map[string]bool
+	suggestions []models.ReleaseSuggestion
+}
+
+func (f *fakeReleaseRadarDB) GetTopArtists(context.Context, int) ([]string, error) {
+	return f.topArtists, nil
+}
+
+func (f *fakeReleaseRadarDB) FindMusicFilesByQuery(context.Context, bson.M, string, int) ([]models.MusicFile, error) {
+	return f.files, nil
+}
+
+func (f *fakeReleaseRadarDB) HasReleaseSuggestion(_ context.Context, url string) (bool, error) {
+	for _, suggestion := range f.suggestions {
+		if suggestion.SpotifyURL == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeReleaseRadarDB) NewReleaseSuggestion(_ context.Context, suggestion models.ReleaseSuggestion) error {
+	f.suggestions = append(f.suggestions, suggestion)
+	return nil
+}
+
+func (f *fakeReleaseRadarDB) HasDownloadRequest(_ context.Context, url string) (bool, error) {
+	return f.requested[url], nil
+}
+
+func (f *fakeReleaseRadarDB) AlbumInLibrary(_ context.Context, artist, album string) (bool, error) {
+	return f.library[artist+" - "+album], nil
+}
+
+type fakeRadarSpotify struct {
+	fakeReleasesSpotify
+	artists map[string]string
+}
+
+func (f *fakeRadarSpotify) SearchArtist(_ context.Context, name string) (string, error) {
+	url, ok := f.artists[name]
+	if !ok {
+		return "", spotify.ErrArtistNotFound
+	}
+	return url, nil
+}
+
+func newRadarTest(files []models.MusicFile, releases []spotify.ArtistRelease) (*ReleaseRadarProcessor, *fakeReleaseRadarDB) {
+	db := &fakeReleaseRadarDB{
+		topArtists: []string{"Artist", "Unknown"},
+		files:      files,
+		requested:  map[string]bool{"https://open.spotify.com/album/requested": true},
+		library:    map[string]bool{"Artist - Owned Single": true},
+	}
+	spotifyService := &fakeRadarSpotify{
+		fakeReleasesSpotify: fakeReleasesSpotify{releases: map[string][]spotify.ArtistRelease{followedArtistURL: releases}},
+		artists:             map[string]string{"Artist": followedArtistURL},
+	}
+	return NewReleaseRadarProcessor(db, spotifyService, 10, zap.NewNop()), db
+}
+
+func suggestedNames(suggestions []models.ReleaseSuggestion) string {
+	names := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		names = append(names, suggestion.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestProcessReleaseRadarSuggestsReleasesAfterNewestLocalAlbum(t *testing.T) {
+	day := func(year int) time.Time { return time.Date(year, 3, 1, 0, 0, 0, 0, time.UTC) }
+	processor, db := newRadarTest([]models.MusicFile{
+		{Artist: "Artist", Album: "First"},
+		{Artist: "artist", Album: "  second  ALBUM"},
+		{Artist: "Artist", Album: ""},
+	}, []spotify.ArtistRelease{
+		release("Fourth", day(2024)),
+		release("Requested", day(2023)),
+		release("Owned Single", day(2023)),
+		release("Third", day(2022)),
+		// a reissue listed before the album it shares the date with
+		release("Second Album Deluxe", day(2020)),
+		release("Second Album", day(2020)),
+		release("Between", day(2019)),
+		release("First", day(2018)),
+	})
+
+	if err := processor.ProcessReleaseRadar(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if names := suggestedNames(db.suggestions); names != "Fourth,Third" {
+		t.Fatalf("expected Fourth and Third to be suggested, got %q", names)
+	}
+	for _, suggestion := range db.suggestions {
+		if suggestion.LatestLocal != "Second Album" || suggestion.ArtistURL != followedArtistURL || suggestion.Artist != "Artist" {
+			t.Fatalf("unexpected suggestion: %+v", suggestion)
+		}
+	}
+	if db.suggestions[0].ReleaseDate != day(2024).Unix() {
+		t.Fatalf("expected the release date to be stored, got %+v", db.suggestions[0])
+	}
+}
+
+func TestProcessReleaseRadarNeverSuggestsTwice(t *testing.T) {
+	processor, db := newRadarTest([]models.MusicFile{{Artist: "Artist", Album: "First"}}, []spotify.ArtistRelease{
+		release("Second", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		release("First", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := processor.ProcessReleaseRadar(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if names := suggestedNames(db.suggestions); names != "Second" {
+		t.Fatalf("expected one suggestion, got %q", names)
+	}
+}
+
+func TestProcessReleaseRadarSkipsArtistsWithoutKnownAlbums(t *testing.T) {
+	processor, db := newRadarTest([]models.MusicFile{{Artist: "Artist", Album: "Bootleg"}}, []spotify.ArtistRelease{
+		release("Second", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		release("First", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+
+	if err := processor.ProcessReleaseRadar(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(db.suggestions) != 0 {
+		t.Fatalf("expected no suggestions without a library album on spotify, got %+v", db.suggestions)
+	}
+}
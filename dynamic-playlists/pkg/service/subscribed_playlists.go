@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	models "github.com/supperdoggy/spot-models"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	spotifyapi "github.com/zmb3/spotify/v2"
 	"go.uber.org/zap"
 )
@@ -18,29 +20,44 @@ type SubscribedPlaylistsDB interface {
 	GetActiveSubscribedPlaylists(ctx context.Context) ([]models.SubscribedPlaylist, error)
 	UpdateSubscribedPlaylist(ctx context.Context, playlist models.SubscribedPlaylist) error
 	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
 	CheckIfRequestAlreadySynced(ctx context.Context, url string) (bool, error)
 	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType) error
+	GetLatestSubscriptionSnapshot(ctx context.Context, subscriptionID string) (*models.SubscriptionSnapshot, error)
+	NewSubscriptionSnapshot(ctx context.Context, snapshot models.SubscriptionSnapshot) error
+	NewNotification(ctx context.Context, notification models.Notification) error
 }
 
 type SpotifyService interface {
 	GetPlaylistTracks(ctx context.Context, url string) ([]spotifyapi.PlaylistItem, error)
+	GetPlaylistSnapshotID(ctx context.Context, url string) (string, error)
 	GetObjectName(ctx context.Context, url string) (string, error)
 }
 
+// UserSpotifyServices returns a Spotify service authorised as the given user,
+// needed for private playlists and Liked Songs
+type UserSpotifyServices interface {
+	ForUser(ctx context.Context, userID int64) spotify.SpotifyService
+}
+
 type SubscribedPlaylistsProcessor struct {
 	db             SubscribedPlaylistsDB
 	spotifyService SpotifyService
+	userSpotify    UserSpotifyServices
 	musicRoot      string
 	outputPath     string
+	webhooks       webhook.Dispatcher
 	log            *zap.Logger
 }
 
-func NewSubscribedPlaylistsProcessor(db SubscribedPlaylistsDB, spotifyService SpotifyService, musicRoot, outputPath string, log *zap.Logger) *SubscribedPlaylistsProcessor {
+func NewSubscribedPlaylistsProcessor(db SubscribedPlaylistsDB, spotifyService SpotifyService, userSpotify UserSpotifyServices, musicRoot, outputPath string, webhooks webhook.Dispatcher, log *zap.Logger) *SubscribedPlaylistsProcessor {
 	return &SubscribedPlaylistsProcessor{
 		db:             db,
 		spotifyService: spotifyService,
+		userSpotify:    userSpotify,
 		musicRoot:      musicRoot,
 		outputPath:     outputPath,
+		webhooks:       webhooks,
 		log:            log,
 	}
 }
@@ -98,143 +115,105 @@ func (s *SubscribedPlaylistsProcessor) shouldRefreshSubscription(playlist models
 func (s *SubscribedPlaylistsProcessor) processSubscription(ctx context.Context, playlist models.SubscribedPlaylist) error {
 	s.log.Info("Processing subscription", zap.String("playlist_id", playlist.ID), zap.String("spotify_url", playlist.SpotifyURL))
 
-	// Fetch tracks from Spotify
-	songList, err := s.spotifyService.GetPlaylistTracks(ctx, playlist.SpotifyURL)
+	// Fetch tracks from Spotify, as the subscriber when their account is linked
+	spotifyService := s.spotifyFor(ctx, playlist.CreatorID)
+
+	snapshotID, err := spotifyService.GetPlaylistSnapshotID(ctx, playlist.SpotifyURL)
 	if err != nil {
-		return fmt.Errorf("failed to get playlist tracks: %w", err)
+		s.log.Warn("failed to get playlist snapshot id, fetching all tracks", zap.Error(err), zap.String("playlist_id", playlist.ID))
 	}
 
-	// Extract artists and titles
-	artists := []string{}
-	titles := []string{}
-	for _, item := range songList {
-		if item.Track.Track == nil {
-			s.log.Error("skipping empty track", zap.Any("item", item))
-			continue
+	previous, err := s.db.GetLatestSubscriptionSnapshot(ctx, playlist.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get previous snapshot: %w", err)
+	}
+
+	var tracks []models.SnapshotTrack
+	pull := !playlist.NoPull
+	if snapshotID != "" && snapshotID == playlist.SnapshotID && previous != nil {
+		gained, err := s.libraryGainedTracks(ctx, slices.Concat(previous.Tracks, playlist.RemovedTracks))
+		if err != nil {
+			return fmt.Errorf("failed to check library for missing tracks: %w", err)
 		}
-		artist := []string{}
-		for _, artistItem := range item.Track.Track.Artists {
-			artist = append(artist, strings.ToLower(artistItem.Name))
+		if !gained {
+			s.log.Info("playlist unchanged, skipping", zap.String("playlist_id", playlist.ID), zap.String("snapshot_id", snapshotID))
+			playlist.LastSynced = time.Now().Unix()
+			playlist.UpdatedAt = time.Now().Unix()
+			if err := s.db.UpdateSubscribedPlaylist(ctx, playlist); err != nil {
+				return fmt.Errorf("failed to update playlist: %w", err)
+			}
+			return nil
 		}
-		artists = append(artists, strings.Join(artist, ", "))
-		titles = append(titles, strings.ToLower(item.Track.Track.Name))
+
+		// The track list is the same, only the M3U needs the new tracks.
+		// Missing ones were already requested on the sync that saw them.
+		s.log.Info("playlist unchanged but library gained tracks, regenerating m3u", zap.String("playlist_id", playlist.ID))
+		tracks = slices.Clone(previous.Tracks)
+		pull = false
 	}
 
-	// Find matching music files
-	foundMusic, err := s.db.FindMusicFiles(ctx, artists, titles)
+	if tracks == nil {
+		songList, err := spotifyService.GetPlaylistTracks(ctx, playlist.SpotifyURL)
+		if err != nil {
+			return fmt.Errorf("failed to get playlist tracks: %w", err)
+		}
+		tracks = s.snapshotTracks(songList)
+	}
+	playlist.SnapshotID = snapshotID
+
+	indexedFiles, err := s.findInLibrary(ctx, tracks)
 	if err != nil {
-		return fmt.Errorf("failed to find music files: %w", err)
+		return err
 	}
 
-	if len(foundMusic) == 0 {
+	if len(indexedFiles) == 0 {
 		s.log.Warn("no indexed paths found for playlist", zap.String("playlist_name", playlist.Name))
 		// Continue anyway - we'll create download requests for all tracks
 	}
 
-	// Create map for quick lookup
-	foundMusicMap := make(map[string]models.MusicFile)
-	for _, music := range foundMusic {
-		normalizedArtist := strings.ToLower(music.Artist)
-		normalizedTitle := strings.ToLower(music.Title)
-		key := normalizedArtist + " " + normalizedTitle
-		foundMusicMap[key] = music
-	}
-
-	// Track missing songs and indexed paths with metadata
-	missingMusicFiles := []spotifyapi.PlaylistItem{}
-	indexedPaths := make([]string, 0)
-	indexedFiles := make([]models.MusicFile, 0)
-	for _, song := range songList {
-		if song.Track.Track == nil {
-			s.log.Error("skipping empty track", zap.Any("item", song))
-			continue
-		}
-		songArtists := []string{}
-		for _, artistItem := range song.Track.Track.Artists {
-			songArtists = append(songArtists, strings.ToLower(artistItem.Name))
-		}
-
-		artist := strings.Join(songArtists, ", ")
-		songName := strings.ToLower(song.Track.Track.Name)
-		key := artist + " " + songName
-
-		// Try single artist format (first artist) in case database stores it that way
-		var singleArtistKey string
-		if len(songArtists) > 0 {
-			singleArtistKey = songArtists[0] + " " + songName
+	missingTracks := []models.SnapshotTrack{}
+	for _, track := range tracks {
+		if !track.InLibrary {
+			missingTracks = append(missingTracks, track)
 		}
-
-		var foundFile models.MusicFile
-		var found bool
-
-		// Try comma-separated artist format first
-		if file, ok := foundMusicMap[key]; ok {
-			foundFile = file
-			found = true
-		} else if singleArtistKey != "" {
-			// Try single artist format
-			if file, ok := foundMusicMap[singleArtistKey]; ok {
-				foundFile = file
-				found = true
-			}
-		}
-
-		if !found {
-			s.log.Debug("song not found in indexed paths", zap.String("artist", artist), zap.String("songName", songName))
-			missingMusicFiles = append(missingMusicFiles, song)
-			continue
-		}
-
-		indexedPaths = append(indexedPaths, foundFile.Path)
-		indexedFiles = append(indexedFiles, foundFile)
 	}
 
 	// Create download requests for missing songs if NoPull is false
-	if len(missingMusicFiles) > 0 && !playlist.NoPull {
-		s.log.Info("creating download requests for missing tracks", zap.Int("count", len(missingMusicFiles)))
-
-		createdCount := 0
-		for _, missingItem := range missingMusicFiles {
-			if missingItem.Track.Track == nil {
-				continue
-			}
+	if len(missingTracks) > 0 && pull {
+		s.requestMissingTracks(ctx, missingTracks)
+	}
 
-			// Build track URL
-			trackURL := fmt.Sprintf("https://open.spotify.com/track/%s", missingItem.Track.Track.ID)
+	snapshot := models.NewSubscriptionSnapshot(playlist, tracks, previous)
 
-			// Check if this specific track is already being downloaded or was downloaded
-			alreadySynced, err := s.db.CheckIfRequestAlreadySynced(ctx, trackURL)
-			if err != nil {
-				s.log.Error("failed to check if track request already synced", zap.Error(err), zap.String("track_url", trackURL))
-				continue
-			}
+	// Tracks removed from the playlist stay in it or go to the companion
+	// playlist, depending on the removal policy
+	var removedFiles []models.MusicFile
+	if playlist.RemovalPolicy.TracksRemoved() {
+		playlist.RemovedTracks = models.MergeRemovedTracks(playlist.RemovedTracks, snapshot)
+		removedFiles, err = s.findInLibrary(ctx, playlist.RemovedTracks)
+		if err != nil {
+			return err
+		}
+	}
 
-			if alreadySynced {
-				s.log.Debug("skipping already synced track", zap.String("track_url", trackURL))
-				continue
-			}
+	playlistPathName := strings.ReplaceAll(playlist.Name, "/", `-`)
 
-			// Build track name
-			trackArtists := []string{}
-			for _, artist := range missingItem.Track.Track.Artists {
-				trackArtists = append(trackArtists, artist.Name)
-			}
-			trackName := fmt.Sprintf("%s - %s", strings.Join(trackArtists, ", "), missingItem.Track.Track.Name)
-
-			// Create download request for individual track
-			objectType := spotify.SpotifyObjectTypeTrack
-			if err := s.db.NewDownloadRequest(ctx, trackURL, trackName, 0, objectType); err != nil {
-				s.log.Error("failed to add download request for track", zap.Error(err), zap.String("track_url", trackURL))
-			} else {
-				createdCount++
-				s.log.Info("created download request for missing track", zap.String("track_url", trackURL), zap.String("track_name", trackName))
+	switch playlist.RemovalPolicy {
+	case models.RemovalKeep:
+		indexedFiles = append(indexedFiles, removedFiles...)
+	case models.RemovalArchive:
+		archivePath := filepath.Join(s.outputPath, playlistPathName+" (removed).m3u")
+		if len(removedFiles) == 0 {
+			// nothing left to archive, drop the file of an earlier sync
+			if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove removed tracks m3u playlist: %w", err)
 			}
+			break
 		}
-
-		if createdCount > 0 {
-			s.log.Info("created download requests for missing tracks", zap.Int("count", createdCount), zap.Int("total_missing", len(missingMusicFiles)))
-			// Don't return error - we'll still generate the M3U with available tracks
+		if err := s.createM3UPlaylist(removedFiles, archivePath); err != nil {
+			return fmt.Errorf("failed to create removed tracks m3u playlist: %w", err)
 		}
+		s.log.Info("created removed tracks m3u playlist", zap.String("outputPath", archivePath))
 	}
 
 	// Generate M3U file
@@ -247,10 +226,10 @@ func (s *SubscribedPlaylistsProcessor) processSubscription(ctx context.Context,
 		if err := s.db.UpdateSubscribedPlaylist(ctx, playlist); err != nil {
 			return fmt.Errorf("failed to update playlist: %w", err)
 		}
+		s.recordChanges(ctx, playlist, snapshot)
 		return nil
 	}
 
-	playlistPathName := strings.ReplaceAll(playlist.Name, "/", `-`)
 	outputPath := filepath.Join(s.outputPath, playlistPathName+".m3u")
 
 	if err := s.createM3UPlaylist(indexedFiles, outputPath); err != nil {
@@ -269,9 +248,195 @@ func (s *SubscribedPlaylistsProcessor) processSubscription(ctx context.Context,
 		return fmt.Errorf("failed to update playlist: %w", err)
 	}
 
+	s.recordChanges(ctx, playlist, snapshot)
 	return nil
 }
 
+// findInLibrary looks the tracks up in the library, sets InLibrary on each of
+// them and returns the files of the found ones in playlist order
+func (s *SubscribedPlaylistsProcessor) findInLibrary(ctx context.Context, tracks []models.SnapshotTrack) ([]models.MusicFile, error) {
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+
+	// Extract artists and titles
+	artists := make([]string, 0, len(tracks))
+	titles := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		artists = append(artists, strings.ToLower(track.Artist))
+		titles = append(titles, strings.ToLower(track.Title))
+	}
+
+	// Find matching music files
+	foundMusic, err := s.db.FindMusicFiles(ctx, artists, titles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find music files: %w", err)
+	}
+
+	aliases, err := s.db.GetArtistAliases(ctx)
+	if err != nil {
+		s.log.Warn("failed to load artist aliases", zap.Error(err))
+	}
+
+	// Create map for quick lookup, keyed by canonical artist
+	foundMusicMap := make(map[string]models.MusicFile)
+	for _, music := range foundMusic {
+		foundMusicMap[aliases.Key(music.Artist, music.Title)] = music
+	}
+
+	indexedFiles := make([]models.MusicFile, 0)
+	for i, track := range tracks {
+		artist := strings.ToLower(track.Artist)
+		songName := strings.ToLower(track.Title)
+
+		tracks[i].InLibrary = false
+
+		// Try comma-separated artist format first, then the first artist only
+		for _, key := range aliases.Keys(artist, songName) {
+			if file, ok := foundMusicMap[key]; ok {
+				indexedFiles = append(indexedFiles, file)
+				tracks[i].InLibrary = true
+				break
+			}
+		}
+
+		if !tracks[i].InLibrary {
+			s.log.Debug("song not found in indexed paths", zap.String("artist", artist), zap.String("songName", songName))
+		}
+	}
+
+	return indexedFiles, nil
+}
+
+// requestMissingTracks queues a download of each missing track that wasn't
+// requested before
+func (s *SubscribedPlaylistsProcessor) requestMissingTracks(ctx context.Context, missingTracks []models.SnapshotTrack) {
+	s.log.Info("creating download requests for missing tracks", zap.Int("count", len(missingTracks)))
+
+	createdCount := 0
+	for _, missing := range missingTracks {
+		if missing.ID == "" {
+			continue
+		}
+
+		// Build track URL
+		trackURL := fmt.Sprintf("https://open.spotify.com/track/%s", missing.ID)
+
+		// Check if this specific track is already being downloaded or was downloaded
+		alreadySynced, err := s.db.CheckIfRequestAlreadySynced(ctx, trackURL)
+		if err != nil {
+			s.log.Error("failed to check if track request already synced", zap.Error(err), zap.String("track_url", trackURL))
+			continue
+		}
+
+		if alreadySynced {
+			s.log.Debug("skipping already synced track", zap.String("track_url", trackURL))
+			continue
+		}
+
+		trackName := fmt.Sprintf("%s - %s", missing.Artist, missing.Title)
+
+		// Create download request for individual track
+		objectType := spotify.SpotifyObjectTypeTrack
+		if err := s.db.NewDownloadRequest(ctx, trackURL, trackName, 0, objectType); err != nil {
+			s.log.Error("failed to add download request for track", zap.Error(err), zap.String("track_url", trackURL))
+		} else {
+			createdCount++
+			s.log.Info("created download request for missing track", zap.String("track_url", trackURL), zap.String("track_name", trackName))
+		}
+	}
+
+	if createdCount > 0 {
+		s.log.Info("created download requests for missing tracks", zap.Int("count", createdCount), zap.Int("total_missing", len(missingTracks)))
+	}
+}
+
+// snapshotTracks turns the Spotify playlist items into the tracks a sync
+// works with, artists joined the way the library stores them
+func (s *SubscribedPlaylistsProcessor) snapshotTracks(songList []spotifyapi.PlaylistItem) []models.SnapshotTrack {
+	tracks := make([]models.SnapshotTrack, 0, len(songList))
+	for _, item := range songList {
+		if item.Track.Track == nil {
+			s.log.Error("skipping empty track", zap.Any("item", item))
+			continue
+		}
+
+		artists := make([]string, 0, len(item.Track.Track.Artists))
+		for _, artist := range item.Track.Track.Artists {
+			artists = append(artists, artist.Name)
+		}
+
+		tracks = append(tracks, models.SnapshotTrack{
+			ID:     string(item.Track.Track.ID),
+			Artist: strings.Join(artists, ", "),
+			Title:  item.Track.Track.Name,
+		})
+	}
+	return tracks
+}
+
+// libraryGainedTracks reports whether any track missing on the last sync is in
+// the library now, matched the way the sync matches them
+func (s *SubscribedPlaylistsProcessor) libraryGainedTracks(ctx context.Context, tracks []models.SnapshotTrack) (bool, error) {
+	missing := make([]models.SnapshotTrack, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.InLibrary {
+			missing = append(missing, track)
+		}
+	}
+
+	if _, err := s.findInLibrary(ctx, missing); err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(missing, func(track models.SnapshotTrack) bool { return track.InLibrary }), nil
+}
+
+// recordChanges stores the snapshot of this sync, sends subscription.synced
+// and tells the subscriber about the changes since the previous one when they
+// asked for it. Failures are only logged, the playlist itself is already synced.
+func (s *SubscribedPlaylistsProcessor) recordChanges(ctx context.Context, playlist models.SubscribedPlaylist, snapshot models.SubscriptionSnapshot) {
+	if err := s.db.NewSubscriptionSnapshot(ctx, snapshot); err != nil {
+		s.log.Error("failed to save snapshot", zap.Error(err), zap.String("playlist_id", playlist.ID))
+		return
+	}
+
+	s.webhooks.Dispatch(ctx, webhook.EventSubscriptionSynced, webhook.SubscriptionData{
+		ID:        playlist.ID,
+		Name:      playlist.Name,
+		URL:       playlist.SpotifyURL,
+		CreatorID: playlist.CreatorID,
+		Tracks:    len(snapshot.Tracks),
+		Added:     len(snapshot.Added),
+		Removed:   len(snapshot.Removed),
+		Available: len(snapshot.Available),
+	})
+
+	if !snapshot.Changed {
+		return
+	}
+
+	s.log.Info("subscription changed",
+		zap.String("playlist_id", playlist.ID),
+		zap.Int("added", len(snapshot.Added)),
+		zap.Int("removed", len(snapshot.Removed)),
+		zap.Int("available", len(snapshot.Available)))
+
+	if !playlist.NotifyChanges {
+		return
+	}
+
+	if err := s.db.NewNotification(ctx, models.NewSubscriptionChangesNotification(playlist, snapshot)); err != nil {
+		s.log.Error("failed to add change notification", zap.Error(err), zap.String("playlist_id", playlist.ID))
+	}
+}
+
+func (s *SubscribedPlaylistsProcessor) spotifyFor(ctx context.Context, userID int64) SpotifyService {
+	if s.userSpotify == nil {
+		return s.spotifyService
+	}
+	return s.userSpotify.ForUser(ctx, userID)
+}
+
 func (s *SubscribedPlaylistsProcessor) createM3UPlaylist(files []models.MusicFile, outputPath string) error {
 	// Ensure output directory exists
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
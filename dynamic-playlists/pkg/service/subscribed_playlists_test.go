@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	spotifyapi "github.com/zmb3/spotify/v2"
+	"go.uber.org/zap"
+)
+
+// fakeSubscriptionsDB returns the whole library from FindMusicFiles, like a
+// query that matches more loosely than the processor does
+type fakeSubscriptionsDB struct {
+	library   []models.MusicFile
+	previous  *models.SubscriptionSnapshot
+	updated   []models.SubscribedPlaylist
+	snapshots []models.SubscriptionSnapshot
+	requests  []string
+}
+
+func (f *fakeSubscriptionsDB) GetActiveSubscribedPlaylists(context.Context) ([]models.SubscribedPlaylist, error) {
+	return nil, nil
+}
+
+func (f *fakeSubscriptionsDB) UpdateSubscribedPlaylist(_ context.Context, playlist models.SubscribedPlaylist) error {
+	f.updated = append(f.updated, playlist)
+	return nil
+}
+
+func (f *fakeSubscriptionsDB) FindMusicFiles(context.Context, []string, []string) ([]models.MusicFile, error) {
+	return f.library, nil
+}
+
+func (f *fakeSubscriptionsDB) GetArtistAliases(context.Context) (models.ArtistAliases, error) {
+	return nil, nil
+}
+
+func (f *fakeSubscriptionsDB) CheckIfRequestAlreadySynced(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeSubscriptionsDB) NewDownloadRequest(_ context.Context, url, _ string, _ int64, _ spotify.SpotifyObjectType) error {
+	f.requests = append(f.requests, url)
+	return nil
+}
+
+func (f *fakeSubscriptionsDB) GetLatestSubscriptionSnapshot(context.Context, string) (*models.SubscriptionSnapshot, error) {
+	return f.previous, nil
+}
+
+func (f *fakeSubscriptionsDB) NewSubscriptionSnapshot(_ context.Context, snapshot models.SubscriptionSnapshot) error {
+	f.snapshots = append(f.snapshots, snapshot)
+	return nil
+}
+
+func (f *fakeSubscriptionsDB) NewNotification(context.Context, models.Notification) error {
+	return nil
+}
+
+type fakePlaylistSpotify struct {
+	snapshotID string
+	items      []spotifyapi.PlaylistItem
+	fetched    int
+}
+
+func (f *fakePlaylistSpotify) GetPlaylistTracks(context.Context, string) ([]spotifyapi.PlaylistItem, error) {
+	f.fetched++
+	return f.items, nil
+}
+
+func (f *fakePlaylistSpotify) GetPlaylistSnapshotID(context.Context, string) (string, error) {
+	return f.snapshotID, nil
+}
+
+func (f *fakePlaylistSpotify) GetObjectName(context.Context, string) (string, error) {
+	return "Mix", nil
+}
+
+type nopDispatcher struct{}
+
+func (nopDispatcher) Dispatch(context.Context, webhook.Event, any) {}
+func (nopDispatcher) Wait()                                        {}
+
+func playlistItem(id, artist, title string) spotifyapi.PlaylistItem {
+	track := &spotifyapi.FullTrack{}
+	track.ID = spotifyapi.ID(id)
+	track.Name = title
+	track.Artists = []spotifyapi.SimpleArtist{{Name: artist}}
+	return spotifyapi.PlaylistItem{Track: spotifyapi.PlaylistItemTrack{Track: track}}
+}
+
+// newSyncTest sets up a subscription last synced at snapshot "snap" with
+// "One" in the library and "Two" missing
+func newSyncTest(t *testing.T, library []models.MusicFile, snapshotID string) (*SubscribedPlaylistsProcessor, *fakeSubscriptionsDB, *fakePlaylistSpotify, models.SubscribedPlaylist, string) {
+	t.Helper()
+
+	db := &fakeSubscriptionsDB{
+		library: library,
+		previous: &models.SubscriptionSnapshot{SubscriptionID: "sub", Tracks: []models.SnapshotTrack{
+			{ID: "t1", Artist: "Artist", Title: "One", InLibrary: true},
+			{ID: "t2", Artist: "Artist", Title: "Two"},
+		}},
+	}
+	spotifyService := &fakePlaylistSpotify{
+		snapshotID: snapshotID,
+		items: []spotifyapi.PlaylistItem{
+			playlistItem("t1", "Artist", "One"),
+			playlistItem("t2", "Artist", "Two"),
+			playlistItem("t3", "Artist", "Three"),
+		},
+	}
+	outputPath := t.TempDir()
+	processor := NewSubscribedPlaylistsProcessor(db, spotifyService, nil, "/music", outputPath, nopDispatcher{}, zap.NewNop())
+	playlist := models.SubscribedPlaylist{ID: "sub", Name: "Mix", SpotifyURL: "https://open.spotify.com/playlist/mix", SnapshotID: "snap", LastSynced: 1}
+
+	return processor, db, spotifyService, playlist, filepath.Join(outputPath, "Mix.m3u")
+}
+
+func TestProcessSubscriptionSkipsUnchangedPlaylist(t *testing.T) {
+	processor, db, spotifyService, playlist, m3uPath := newSyncTest(t, []models.MusicFile{
+		{Artist: "Artist", Title: "One", Path: "/music/one.mp3"},
+		{Artist: "Artist", Title: "Two (Live)", Path: "/music/two-live.mp3"},
+	}, "snap")
+
+	if err := processor.processSubscription(context.Background(), playlist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spotifyService.fetched != 0 || len(db.snapshots) != 0 {
+		t.Fatalf("expected the sync to be skipped, fetched %d snapshots %+v", spotifyService.fetched, db.snapshots)
+	}
+	if len(db.updated) != 1 || db.updated[0].LastSynced == playlist.LastSynced {
+		t.Fatalf("expected only the sync time to be updated, got %+v", db.updated)
+	}
+	if _, err := os.Stat(m3uPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no m3u to be written, got %v", err)
+	}
+}
+
+func TestProcessSubscriptionRegeneratesWhenLibraryGainedTracks(t *testing.T) {
+	processor, db, spotifyService, playlist, m3uPath := newSyncTest(t, []models.MusicFile{
+		{Artist: "Artist", Title: "One", Path: "/music/one.mp3"},
+		{Artist: "Artist", Title: "Two", Path: "/music/two.mp3"},
+	}, "snap")
+
+	if err := processor.processSubscription(context.Background(), playlist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spotifyService.fetched != 0 {
+		t.Fatalf("expected the unchanged playlist not to be fetched, fetched %d", spotifyService.fetched)
+	}
+	if len(db.requests) != 0 {
+		t.Fatalf("expected no download requests, got %v", db.requests)
+	}
+	if len(db.snapshots) != 1 || len(db.snapshots[0].Available) != 1 || db.snapshots[0].Available[0].ID != "t2" {
+		t.Fatalf("expected the new track to be reported available, got %+v", db.snapshots)
+	}
+
+	data, err := os.ReadFile(m3uPath)
+	if err != nil {
+		t.Fatalf("expected the m3u to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "Artist - One") || !strings.Contains(string(data), "Artist - Two") {
+		t.Fatalf("unexpected m3u: %q", data)
+	}
+}
+
+func TestProcessSubscriptionFetchesChangedPlaylist(t *testing.T) {
+	processor, db, spotifyService, playlist, m3uPath := newSyncTest(t, []models.MusicFile{
+		{Artist: "Artist", Title: "One", Path: "/music/one.mp3"},
+	}, "snap-2")
+
+	if err := processor.processSubscription(context.Background(), playlist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spotifyService.fetched != 1 {
+		t.Fatalf("expected the changed playlist to be fetched, fetched %d", spotifyService.fetched)
+	}
+	expected := []string{"https://open.spotify.com/track/t2", "https://open.spotify.com/track/t3"}
+	if strings.Join(db.requests, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected the missing tracks to be requested, got %v", db.requests)
+	}
+	if len(db.updated) != 1 || db.updated[0].SnapshotID != "snap-2" {
+		t.Fatalf("expected the new snapshot id to be stored, got %+v", db.updated)
+	}
+	if len(db.snapshots) != 1 || len(db.snapshots[0].Added) != 1 || db.snapshots[0].Added[0].ID != "t3" {
+		t.Fatalf("expected the added track in the snapshot, got %+v", db.snapshots)
+	}
+	if _, err := os.Stat(m3uPath); err != nil {
+		t.Fatalf("expected the m3u to be written: %v", err)
+	}
+}
+
+func TestProcessSubscriptionRemovesEmptyArchive(t *testing.T) {
+	processor, db, spotifyService, playlist, m3uPath := newSyncTest(t, []models.MusicFile{
+		{Artist: "Artist", Title: "One", Path: "/music/one.mp3"},
+	}, "snap-2")
+	playlist.RemovalPolicy = models.RemovalArchive
+	playlist.RemovedTracks = []models.SnapshotTrack{{ID: "t3", Artist: "Artist", Title: "Three"}}
+
+	archivePath := strings.TrimSuffix(m3uPath, ".m3u") + " (removed).m3u"
+	if err := os.WriteFile(archivePath, []byte("#EXTM3U\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the removed track was added back to the playlist
+	if err := processor.processSubscription(context.Background(), playlist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spotifyService.fetched != 1 || len(db.updated) != 1 || len(db.updated[0].RemovedTracks) != 0 {
+		t.Fatalf("expected the removed track to be cleared, got %+v", db.updated)
+	}
+	if _, err := os.Stat(archivePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale archive to be removed, got %v", err)
+	}
+}
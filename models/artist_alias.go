@@ -0,0 +1,121 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ArtistAlias maps an alternate artist spelling to the canonical artist name.
+// Suggested aliases are generated from near-miss failed tracks and are not
+// used for matching until someone confirms them.
+type ArtistAlias struct {
+	ID        string `json:"id" bson:"_id"`
+	Alias     string `json:"alias" bson:"alias"`         // e.g. "weeknd"
+	Canonical string `json:"canonical" bson:"canonical"` // e.g. "the weeknd"
+	Suggested bool   `json:"suggested" bson:"suggested"`
+	CreatorID int64  `json:"creator_id" bson:"creator_id"`
+	CreatedAt int64  `json:"created_at" bson:"created_at"`
+}
+
+// ArtistAliases is a lookup of lowercase alias -> lowercase canonical artist.
+// A nil ArtistAliases is valid and behaves as an empty table.
+type ArtistAliases map[string]string
+
+// NewArtistAliases builds a lookup table from confirmed aliases.
+func NewArtistAliases(aliases []ArtistAlias) ArtistAliases {
+	table := make(ArtistAliases, len(aliases))
+	for _, alias := range aliases {
+		if alias.Suggested {
+			continue
+		}
+		from := NormalizeArtist(alias.Alias)
+		to := NormalizeArtist(alias.Canonical)
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		table[from] = to
+	}
+	return table
+}
+
+// NormalizeArtist lowercases and trims an artist string.
+func NormalizeArtist(artist string) string {
+	return strings.ToLower(strings.TrimSpace(artist))
+}
+
+// Canonical resolves an artist string to its canonical spelling. The whole
+// string is looked up first so that "a, b" can be aliased as a unit, then
+// each comma-separated artist is resolved individually.
+func (a ArtistAliases) Canonical(artist string) string {
+	normalized := NormalizeArtist(artist)
+	if canonical, ok := a[normalized]; ok {
+		return canonical
+	}
+
+	parts := strings.Split(normalized, ", ")
+	for i, part := range parts {
+		if canonical, ok := a[part]; ok {
+			parts[i] = canonical
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Variants returns every spelling that should match the given artist in the
+// library: the artist as given, its canonical form, the first credited artist
+// and all known aliases of those.
+func (a ArtistAliases) Variants(artist string) []string {
+	normalized := NormalizeArtist(artist)
+	candidates := []string{normalized, a.Canonical(normalized)}
+	if first, _, found := strings.Cut(normalized, ", "); found {
+		candidates = append(candidates, first, a.Canonical(first))
+	}
+
+	seen := make(map[string]bool)
+	variants := make([]string, 0, len(candidates))
+	add := func(v string) {
+		if v == "" || seen[v] {
+			return
+		}
+		seen[v] = true
+		variants = append(variants, v)
+	}
+
+	targets := make(map[string]bool)
+	for _, candidate := range candidates {
+		add(candidate)
+		targets[a.Canonical(candidate)] = true
+	}
+
+	aliases := make([]string, 0)
+	for alias, canonical := range a {
+		if targets[canonical] {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		add(alias)
+	}
+
+	return variants
+}
+
+// Key returns the matching key for an artist/title pair, resolving the
+// artist through the alias table.
+func (a ArtistAliases) Key(artist, title string) string {
+	return a.Canonical(artist) + " " + strings.ToLower(title)
+}
+
+// Keys returns the matching keys to try for a track, most specific first:
+// the full credited artist list and then only the first artist, since the
+// library may store either form.
+func (a ArtistAliases) Keys(artist, title string) []string {
+	keys := []string{a.Key(artist, title)}
+	if first, _, found := strings.Cut(NormalizeArtist(artist), ", "); found {
+		if key := a.Key(first, title); key != keys[0] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestArtistAliases_Canonical(t *testing.T) {
+	aliases := NewArtistAliases([]ArtistAlias{
+		{Alias: "Weeknd", Canonical: "The Weeknd"},
+		{Alias: "daft punk, pharrell williams", Canonical: "daft punk"},
+		{Alias: "pending", Canonical: "ignored", Suggested: true},
+	})
+
+	tests := []struct {
+		name     string
+		artist   string
+		expected string
+	}{
+		{name: "single alias", artist: "Weeknd", expected: "the weeknd"},
+		{name: "alias inside artist list", artist: "weeknd, daft punk", expected: "the weeknd, daft punk"},
+		{name: "whole list aliased", artist: "Daft Punk, Pharrell Williams", expected: "daft punk"},
+		{name: "unknown artist", artist: " Radiohead ", expected: "radiohead"},
+		{name: "suggested alias is not applied", artist: "pending", expected: "pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aliases.Canonical(tt.artist); got != tt.expected {
+				t.Errorf("Canonical(%q) = %q, want %q", tt.artist, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArtistAliases_Variants(t *testing.T) {
+	aliases := NewArtistAliases([]ArtistAlias{
+		{Alias: "weeknd", Canonical: "the weeknd"},
+		{Alias: "abel", Canonical: "the weeknd"},
+	})
+
+	variants := aliases.Variants("The Weeknd, Daft Punk")
+	for _, expected := range []string{"the weeknd, daft punk", "the weeknd", "weeknd", "abel"} {
+		if !slices.Contains(variants, expected) {
+			t.Errorf("expected variant %q in %v", expected, variants)
+		}
+	}
+
+	variants = aliases.Variants("weeknd")
+	for _, expected := range []string{"weeknd", "the weeknd", "abel"} {
+		if !slices.Contains(variants, expected) {
+			t.Errorf("expected variant %q in %v", expected, variants)
+		}
+	}
+}
+
+func TestArtistAliases_NilTable(t *testing.T) {
+	var aliases ArtistAliases
+
+	if got := aliases.Key("Artist", "Title"); got != "artist title" {
+		t.Errorf("Key on nil table = %q, want %q", got, "artist title")
+	}
+	if got := aliases.Variants("artist"); len(got) != 1 || got[0] != "artist" {
+		t.Errorf("Variants on nil table = %v, want [artist]", got)
+	}
+}
+
+func TestArtistAliases_Keys(t *testing.T) {
+	aliases := NewArtistAliases([]ArtistAlias{{Alias: "weeknd", Canonical: "the weeknd"}})
+
+	keys := aliases.Keys("Weeknd, Daft Punk", "Starboy")
+	expected := []string{"the weeknd, daft punk starboy", "the weeknd starboy"}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("Keys() = %v, want %v", keys, expected)
+	}
+
+	keys = aliases.Keys("Weeknd", "Starboy")
+	if !slices.Equal(keys, []string{"the weeknd starboy"}) {
+		t.Errorf("Keys() for single artist = %v", keys)
+	}
+}
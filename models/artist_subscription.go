@@ -0,0 +1,16 @@
+package models
+
+// ArtistSubscription is an artist a user follows from the bot. dynamic-playlists
+// queues the albums and singles the artist releases after FollowedAt.
+type ArtistSubscription struct {
+	ID         string `json:"id" bson:"_id"`
+	CreatorID  int64  `json:"creator_id" bson:"creator_id"`
+	SpotifyURL string `json:"spotify_url" bson:"spotify_url"`
+	Name       string `json:"name" bson:"name"`
+	Active     bool   `json:"active" bson:"active"`
+	// FollowedAt is when the artist was followed, older releases are not queued
+	FollowedAt  int64 `json:"followed_at" bson:"followed_at"`
+	LastChecked int64 `json:"last_checked" bson:"last_checked"`
+	CreatedAt   int64 `json:"created_at" bson:"created_at"`
+	UpdatedAt   int64 `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func (d *db) GetArtistAliases(ctx context.Context) (models.ArtistAliases, error) {
+	cur, err := d.artistAliasesCollection().Find(ctx, bson.M{"suggested": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	aliases := make([]models.ArtistAlias, 0)
+	for cur.Next(ctx) {
+		var alias models.ArtistAlias
+		if err := cur.Decode(&alias); err != nil {
+			d.log.Error("failed to decode artist alias", zap.Error(err))
+			continue
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return models.NewArtistAliases(aliases), nil
+}
+
+func (d *db) NewArtistAlias(ctx context.Context, alias, canonical string, creatorID int64) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	alias = models.NormalizeArtist(alias)
+	_, err = d.artistAliasesCollection().UpdateOne(ctx, bson.M{"alias": alias}, bson.M{
+		"$set": bson.M{
+			"canonical":  models.NormalizeArtist(canonical),
+			"suggested":  false,
+			"creator_id": creatorID,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        id.String(),
+			"created_at": time.Now().Unix(),
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
@@ -25,6 +25,9 @@ type Database interface {
 
 	GetIndexStatus(ctx context.Context) (models.IndexStatus, error)
 	UpdateIndexStatus(ctx context.Context, status models.IndexStatus) error
+
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	NewArtistAlias(ctx context.Context, alias, canonical string, creatorID int64) error
 }
 
 type db struct {
@@ -81,6 +81,7 @@ func (d *db) UpdateActiveRequest(ctx context.Context, request models.DownloadQue
 		"sync_count":  request.SyncCount,
 		"errored":     request.Errored,
 		"retry_count": request.RetryCount,
+		"updated_at":  time.Now().Unix(),
 	}})
 	if err != nil {
 		return err
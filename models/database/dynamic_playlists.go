@@ -3,7 +3,7 @@ package database
 import (
 	"context"
 
-	"github.com/supperdoggy/SmartHomeServer/music-services/models"
+	"github.com/supperdoggy/spot-models"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 	"gopkg.in/mgo.v2/bson"
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/supperdoggy/spot-models"
@@ -9,10 +10,16 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
-// IndexMusicFile indexes a music file in the database
+// IndexMusicFile indexes a music file in the database, with its size read
+// from the file when the caller didn't set it
 func (d *db) IndexMusicFile(ctx context.Context, file models.MusicFile) error {
 	file.ID = uuid.Must(uuid.NewV4()).String()
 	file.CreatedAt = time.Now().Unix()
+	if file.Size == 0 {
+		if info, err := os.Stat(file.Path); err == nil {
+			file.Size = info.Size()
+		}
+	}
 	_, err := d.musicFilesCollection().InsertOne(ctx, file)
 	return err
 }
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"regexp"
+	"strings"
 
 	"github.com/supperdoggy/spot-models"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -10,11 +12,22 @@ import (
 )
 
 func (d *db) FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error) {
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		d.log.Warn("failed to load artist aliases, matching without them", zap.Error(err))
+	}
+
 	orPairs := make([]bson.M, 0, len(artists))
 	for i := range artists {
+		// Match case-insensitively, accepting every known spelling of the artist
+		variants := aliases.Variants(artists[i])
+		escapedArtists := make([]string, 0, len(variants))
+		for _, variant := range variants {
+			escapedArtists = append(escapedArtists, regexp.QuoteMeta(variant))
+		}
 		orPairs = append(orPairs, bson.M{
-			"artist": artists[i],
-			"title":  titles[i],
+			"artist": bson.M{"$regex": "^(?:" + strings.Join(escapedArtists, "|") + ")$", "$options": "i"},
+			"title":  bson.M{"$regex": "^" + regexp.QuoteMeta(titles[i]) + "$", "$options": "i"},
 		})
 	}
 
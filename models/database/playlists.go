@@ -33,6 +33,7 @@ func (d *db) UpdatePlaylistRequest(ctx context.Context, request models.PlaylistR
 		"active":      request.Active,
 		"errored":     request.Errored,
 		"retry_count": request.RetryCount,
+		"updated_at":  time.Now().Unix(),
 	}})
 
 	if info.MatchedCount == 0 {
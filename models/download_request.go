@@ -1,21 +1,38 @@
 package models
 
-import "github.com/supperdoggy/spot-models/spotify"
+import (
+	"github.com/supperdoggy/spot-models/source"
+	"github.com/supperdoggy/spot-models/spotify"
+)
 
 type DownloadQueueRequest struct {
 	ID        string `json:"id" bson:"_id"`
 	CreatorID int64  `json:"creator_id" bson:"creator_id"`
 
+	// SpotifyURL holds the request URL for every source
 	SpotifyURL string                    `json:"spotify_url" bson:"spotify_url"`
 	ObjectType spotify.SpotifyObjectType `json:"object_type" bson:"object_type"`
-	Name       string                    `json:"name" bson:"name"`
-	Active     bool                      `json:"active" bson:"active"`
-	Errored    bool                      `json:"errored" bson:"errored"`
+	// Source is empty for requests queued before non-Spotify sources existed
+	Source  source.Source `json:"source,omitempty" bson:"source,omitempty"`
+	Name    string        `json:"name" bson:"name"`
+	Active  bool          `json:"active" bson:"active"`
+	Errored bool          `json:"errored" bson:"errored"`
+	// Cancelled is set when the request was deactivated from the bot before
+	// the downloader finished it
+	Cancelled bool `json:"cancelled,omitempty" bson:"cancelled,omitempty"`
 
 	CreatedAt  int64 `json:"created_at" bson:"created_at"`
 	UpdatedAt  int64 `json:"updated_at" bson:"updated_at"`
 	SyncCount  int   `json:"sync_count" bson:"sync_count"`
 	RetryCount int   `json:"retry_count" bson:"retry_count"`
+	// Priority is raised from the bot, higher priorities are downloaded first
+	Priority int `json:"priority" bson:"priority"`
+	// TracksOnly requests download the tracks in TrackMetadata one by one
+	// instead of the whole album or playlist
+	TracksOnly bool `json:"tracks_only,omitempty" bson:"tracks_only,omitempty"`
+	// ImportedPlaylistID is set on the track requests of an imported playlist,
+	// quotas count them as one active request
+	ImportedPlaylistID string `json:"imported_playlist_id,omitempty" bson:"imported_playlist_id,omitempty"`
 
 	// Track tracking fields
 	ExpectedTrackCount int                     `json:"expected_track_count" bson:"expected_track_count"`
@@ -33,6 +50,9 @@ type PlaylistRequest struct {
 	RetryCount int  `json:"retry_count" bson:"retry_count"`
 	// NoPull indicates that the playlist missing songs should not be pulled from Spotify
 	NoPull bool `json:"no_pull" bson:"no_pull"`
+	// ExpectedTrackCount is the playlist size when it was requested, counted
+	// against the creator's daily track quota
+	ExpectedTrackCount int `json:"expected_track_count" bson:"expected_track_count"`
 
 	CreatedAt int64 `json:"created_at" bson:"created_at"`
 	UpdatedAt int64 `json:"updated_at" bson:"updated_at"`
@@ -2,8 +2,11 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/supperdoggy/spot-models/source"
 )
 
 func TestDownloadQueueRequest_JSON(t *testing.T) {
@@ -68,3 +71,31 @@ func TestPlaylistRequest_JSON(t *testing.T) {
 		t.Errorf("NoPull mismatch: got %v, want %v", decoded.NoPull, req.NoPull)
 	}
 }
+
+func TestDownloadQueueRequest_Source(t *testing.T) {
+	legacy := DownloadQueueRequest{ID: "legacy", SpotifyURL: "https://open.spotify.com/album/test"}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if strings.Contains(string(data), `"source"`) {
+		t.Errorf("expected empty source to be omitted, got %s", data)
+	}
+	if !legacy.Source.IsSpotify() {
+		t.Errorf("expected request without source to be a Spotify request")
+	}
+
+	req := DownloadQueueRequest{ID: "bandcamp", SpotifyURL: "https://artist.bandcamp.com/album/record", Source: source.Bandcamp}
+	data, err = json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var decoded DownloadQueueRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if decoded.Source != source.Bandcamp || decoded.Source.IsSpotify() {
+		t.Errorf("Source mismatch: got %q, want %q", decoded.Source, source.Bandcamp)
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+// ImportedTrack is a row of an imported playlist file
+type ImportedTrack struct {
+	Artist string `json:"artist" bson:"artist"`
+	Title  string `json:"title" bson:"title"`
+	Album  string `json:"album,omitempty" bson:"album,omitempty"`
+	ISRC   string `json:"isrc,omitempty" bson:"isrc,omitempty"`
+	// SpotifyURL is set when the file names the Spotify track, missing tracks
+	// without one are searched for on Spotify when they are queued
+	SpotifyURL string `json:"spotify_url,omitempty" bson:"spotify_url,omitempty"`
+	// InLibrary is set when the track was found in the library on the last run
+	InLibrary bool `json:"in_library" bson:"in_library"`
+	// Requested is set once the missing track was queued for download, or
+	// Spotify had nothing to queue, so it isn't looked up again
+	Requested bool `json:"requested,omitempty" bson:"requested,omitempty"`
+}
+
+// ImportedPlaylist is a playlist imported from an M3U, CSV or Spotify export
+// file. dynamic-playlists writes the tracks found in the library to an M3U on
+// every run, so tracks downloaded later are added to it.
+type ImportedPlaylist struct {
+	ID        string          `json:"id" bson:"_id"`
+	CreatorID int64           `json:"creator_id" bson:"creator_id"`
+	Name      string          `json:"name" bson:"name"`
+	FileName  string          `json:"file_name" bson:"file_name"`
+	Tracks    []ImportedTrack `json:"tracks" bson:"tracks"`
+	// QueueMissing queues a download of every track missing from the library
+	QueueMissing bool   `json:"queue_missing" bson:"queue_missing"`
+	Active       bool   `json:"active" bson:"active"`
+	OutputPath   string `json:"output_path" bson:"output_path"`
+	// TrackCount is how many of the tracks were in the library on the last run
+	TrackCount    int   `json:"track_count" bson:"track_count"`
+	LastGenerated int64 `json:"last_generated" bson:"last_generated"`
+	CreatedAt     int64 `json:"created_at" bson:"created_at"`
+	UpdatedAt     int64 `json:"updated_at" bson:"updated_at"`
+}
+
+// MatchImportedTracks sets InLibrary on each track found among the library
+// files and returns the files of the found ones in playlist order
+func MatchImportedTracks(tracks []ImportedTrack, files []MusicFile, aliases ArtistAliases) []MusicFile {
+	byKey := make(map[string]MusicFile, len(files))
+	for _, file := range files {
+		byKey[aliases.Key(file.Artist, file.Title)] = file
+	}
+
+	found := make([]MusicFile, 0, len(tracks))
+	for i, track := range tracks {
+		tracks[i].InLibrary = false
+		for _, key := range aliases.Keys(track.Artist, track.Title) {
+			if file, ok := byKey[key]; ok {
+				found = append(found, file)
+				tracks[i].InLibrary = true
+				break
+			}
+		}
+	}
+	return found
+}
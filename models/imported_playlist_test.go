@@ -0,0 +1,25 @@
+package models
+
+import "testing"
+
+func TestMatchImportedTracks(t *testing.T) {
+	aliases := NewArtistAliases([]ArtistAlias{{Alias: "weeknd", Canonical: "the weeknd"}})
+	tracks := []ImportedTrack{
+		{Artist: "Daft Punk, Pharrell Williams", Title: "Get Lucky"},
+		{Artist: "Missing", Title: "Track", InLibrary: true},
+		{Artist: "Weeknd", Title: "starboy"},
+	}
+	files := []MusicFile{
+		{ID: "starboy", Artist: "The Weeknd", Title: "Starboy"},
+		{ID: "lucky", Artist: "Daft Punk", Title: "Get Lucky"},
+	}
+
+	found := MatchImportedTracks(tracks, files, aliases)
+
+	if len(found) != 2 || found[0].ID != "lucky" || found[1].ID != "starboy" {
+		t.Fatalf("expected the found files in playlist order, got %+v", found)
+	}
+	if !tracks[0].InLibrary || tracks[1].InLibrary || !tracks[2].InLibrary {
+		t.Errorf("unexpected InLibrary flags: %+v", tracks)
+	}
+}
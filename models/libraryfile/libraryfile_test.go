@@ -0,0 +1,43 @@
+package libraryfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanName(t *testing.T) {
+	tests := map[string]string{
+		"AC/DC":         "AC_DC",
+		`Back\Slash`:    "Back_Slash",
+		" ..Hidden ":    "Hidden",
+		"Artist - Song": "Artist - Song",
+	}
+	for in, want := range tests {
+		if got := CleanName(in); got != want {
+			t.Errorf("CleanName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src.mp3"), filepath.Join(dir, "dst.mp3")
+	if err := os.WriteFile(src, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Move(src, dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected the source to be gone, got %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "audio" {
+		t.Fatalf("unexpected destination %q: %v", data, err)
+	}
+
+	if err := Move(src, dst); err == nil {
+		t.Fatal("expected an error for a missing source")
+	}
+}
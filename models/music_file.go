@@ -8,7 +8,9 @@ type MusicFile struct {
 	Title  string `json:"title" bson:"title"`
 	Genre  string `json:"genre" bson:"genre"`
 
-	Path     string         `json:"path" bson:"path"`
+	Path string `json:"path" bson:"path"`
+	// Size is the file size in bytes, 0 when the indexer didn't record it
+	Size     int64          `json:"size,omitempty" bson:"size,omitempty"`
 	MetaData map[string]any `json:"meta_data" bson:"meta_data"`
 
 	CreatedAt int64 `json:"created_at" bson:"created_at"`
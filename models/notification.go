@@ -0,0 +1,116 @@
+package models
+
+type NotificationKind string
+
+const (
+	// NotificationDownloadDone is sent when a download request is deactivated
+	NotificationDownloadDone NotificationKind = "download_done"
+	// NotificationPlaylistDone is sent when the M3U of a playlist request is written
+	NotificationPlaylistDone NotificationKind = "playlist_done"
+	// NotificationSubscriptionChanges is sent when a sync finds changes in a
+	// subscribed playlist whose subscriber asked for them
+	NotificationSubscriptionChanges NotificationKind = "subscription_changes"
+	// NotificationNewReleases is sent when new releases of a followed artist
+	// are queued
+	NotificationNewReleases NotificationKind = "new_releases"
+)
+
+// Notification is an outbox entry written by the workers and delivered to
+// UserID by album-queue. SentAt stays 0 until the message is delivered.
+type Notification struct {
+	ID        string           `json:"id" bson:"_id"`
+	UserID    int64            `json:"user_id" bson:"user_id"`
+	Kind      NotificationKind `json:"kind" bson:"kind"`
+	RequestID string           `json:"request_id" bson:"request_id"`
+	Name      string           `json:"name" bson:"name"`
+	URL       string           `json:"url" bson:"url"`
+
+	Found   int `json:"found" bson:"found"`
+	Missing int `json:"missing" bson:"missing"`
+	Skipped int `json:"skipped" bson:"skipped"`
+	// PlaylistPath is the generated M3U file, empty for plain downloads
+	PlaylistPath string `json:"playlist_path,omitempty" bson:"playlist_path,omitempty"`
+	// Added, Removed and Available list "artist - title" of the tracks that
+	// changed in a subscribed playlist
+	Added     []string `json:"added,omitempty" bson:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty" bson:"removed,omitempty"`
+	Available []string `json:"available,omitempty" bson:"available,omitempty"`
+	// Releases lists the names of the queued releases of a followed artist
+	Releases []string `json:"releases,omitempty" bson:"releases,omitempty"`
+
+	Attempts  int   `json:"attempts" bson:"attempts"`
+	Failed    bool  `json:"failed" bson:"failed"`
+	CreatedAt int64 `json:"created_at" bson:"created_at"`
+	SentAt    int64 `json:"sent_at" bson:"sent_at"`
+}
+
+// NewDownloadNotification builds the notification for a finished download
+// request, counting found, missing and skipped tracks from its metadata
+func NewDownloadNotification(request DownloadQueueRequest) Notification {
+	notification := Notification{
+		UserID:    request.CreatorID,
+		Kind:      NotificationDownloadDone,
+		RequestID: request.ID,
+		Name:      request.Name,
+		URL:       request.SpotifyURL,
+	}
+
+	if len(request.TrackMetadata) == 0 {
+		notification.Found = request.FoundTrackCount
+		notification.Missing = max(request.ExpectedTrackCount-request.FoundTrackCount, 0)
+		return notification
+	}
+
+	for _, track := range request.TrackMetadata {
+		switch {
+		case track.Found:
+			notification.Found++
+		case track.Skipped:
+			notification.Skipped++
+		default:
+			notification.Missing++
+		}
+	}
+
+	return notification
+}
+
+// NewSubscriptionChangesNotification builds the notification for the changes
+// a sync found in a subscribed playlist
+func NewSubscriptionChangesNotification(playlist SubscribedPlaylist, snapshot SubscriptionSnapshot) Notification {
+	return Notification{
+		UserID:       playlist.CreatorID,
+		Kind:         NotificationSubscriptionChanges,
+		RequestID:    playlist.ID,
+		Name:         playlist.Name,
+		URL:          playlist.SpotifyURL,
+		PlaylistPath: playlist.OutputPath,
+		Added:        snapshotTrackNames(snapshot.Added),
+		Removed:      snapshotTrackNames(snapshot.Removed),
+		Available:    snapshotTrackNames(snapshot.Available),
+	}
+}
+
+// NewReleasesNotification builds the notification for the releases of a
+// followed artist that were queued
+func NewReleasesNotification(artist ArtistSubscription, releases []string) Notification {
+	return Notification{
+		UserID:    artist.CreatorID,
+		Kind:      NotificationNewReleases,
+		RequestID: artist.ID,
+		Name:      artist.Name,
+		URL:       artist.SpotifyURL,
+		Releases:  releases,
+	}
+}
+
+func snapshotTrackNames(tracks []SnapshotTrack) []string {
+	if len(tracks) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		names = append(names, track.Artist+" - "+track.Title)
+	}
+	return names
+}
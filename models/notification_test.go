@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/supperdoggy/spot-models/spotify"
+)
+
+func TestNewDownloadNotification(t *testing.T) {
+	request := DownloadQueueRequest{
+		ID:         "req-1",
+		CreatorID:  42,
+		SpotifyURL: "https://open.spotify.com/album/test",
+		Name:       "Test Album",
+		TrackMetadata: []spotify.TrackMetadata{
+			{Artist: "a", Title: "1", Found: true},
+			{Artist: "a", Title: "2", Found: true},
+			{Artist: "a", Title: "3", Skipped: true},
+			{Artist: "a", Title: "4"},
+		},
+	}
+
+	n := NewDownloadNotification(request)
+
+	if n.UserID != 42 || n.Kind != NotificationDownloadDone || n.RequestID != "req-1" || n.Name != "Test Album" {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if n.Found != 2 || n.Skipped != 1 || n.Missing != 1 {
+		t.Errorf("expected 2 found, 1 skipped, 1 missing, got %d, %d, %d", n.Found, n.Skipped, n.Missing)
+	}
+}
+
+func TestNewDownloadNotification_NoMetadata(t *testing.T) {
+	n := NewDownloadNotification(DownloadQueueRequest{ExpectedTrackCount: 10, FoundTrackCount: 7})
+
+	if n.Found != 7 || n.Missing != 3 || n.Skipped != 0 {
+		t.Errorf("expected 7 found, 3 missing, got %+v", n)
+	}
+}
+
+func TestNewSubscriptionChangesNotification(t *testing.T) {
+	playlist := SubscribedPlaylist{ID: "sub-1", CreatorID: 42, Name: "Mix", OutputPath: "/playlists/Mix.m3u"}
+	snapshot := SubscriptionSnapshot{
+		Added:   []SnapshotTrack{{Artist: "a", Title: "1"}, {Artist: "b", Title: "2"}},
+		Removed: []SnapshotTrack{{Artist: "c", Title: "3"}},
+	}
+
+	n := NewSubscriptionChangesNotification(playlist, snapshot)
+
+	if n.UserID != 42 || n.Kind != NotificationSubscriptionChanges || n.RequestID != "sub-1" || n.PlaylistPath != "/playlists/Mix.m3u" {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if len(n.Added) != 2 || n.Added[1] != "b - 2" || len(n.Removed) != 1 || n.Available != nil {
+		t.Errorf("unexpected tracks: %+v", n)
+	}
+}
+
+func TestNewReleasesNotification(t *testing.T) {
+	artist := ArtistSubscription{ID: "art-1", CreatorID: 42, Name: "Daft Punk", SpotifyURL: "https://open.spotify.com/artist/ar1"}
+
+	n := NewReleasesNotification(artist, []string{"Discovery"})
+
+	if n.UserID != 42 || n.Kind != NotificationNewReleases || n.RequestID != "art-1" || n.Name != "Daft Punk" {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if len(n.Releases) != 1 || n.Releases[0] != "Discovery" {
+		t.Errorf("unexpected releases: %+v", n.Releases)
+	}
+}
@@ -0,0 +1,319 @@
+// Package playlistfile reads playlists exported from other players and from
+// Spotify: M3U/M3U8 files, CSV dumps such as Exportify's and the JSON files of
+// a Spotify account data export.
+package playlistfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	models "github.com/supperdoggy/spot-models"
+)
+
+// Extensions are the playlist files Parse reads
+var Extensions = []string{".m3u", ".m3u8", ".csv", ".json"}
+
+var (
+	// ErrUnsupported is returned for files that aren't a supported playlist format
+	ErrUnsupported = errors.New("unsupported playlist file")
+	// ErrNoColumns is returned for a CSV without artist and title columns,
+	// such as a plain list of links
+	ErrNoColumns = errors.New("no artist and title columns in csv")
+	// ErrNoTracks is returned when the file has no track with an artist and
+	// a title
+	ErrNoTracks = errors.New("no tracks in playlist file")
+)
+
+// trackNumber matches the track number file names start with, e.g. "01 - ",
+// "1-03. " or "07_"
+var trackNumber = regexp.MustCompile(`^(?:\d{1,2}-)?\d{1,3}[\s._-]+`)
+
+// Playlist is one playlist of a file, an account export holds several
+type Playlist struct {
+	Name   string
+	Tracks []models.ImportedTrack
+}
+
+// Supported reports whether the file name has a playlist extension
+func Supported(fileName string) bool {
+	return slices.Contains(Extensions, strings.ToLower(filepath.Ext(fileName)))
+}
+
+// Parse reads the playlists of the file, the format is picked by its
+// extension. Playlists of M3U and CSV files are named after the file unless
+// an M3U names itself, rows without an artist or a title are left out.
+func Parse(fileName string, r io.Reader) ([]Playlist, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playlist file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	name := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+
+	var playlists []Playlist
+	switch ext {
+	case ".m3u", ".m3u8":
+		playlists = []Playlist{parseM3U(name, data)}
+	case ".csv":
+		playlist, err := parseCSV(name, data)
+		if err != nil {
+			return nil, err
+		}
+		playlists = []Playlist{playlist}
+	case ".json":
+		playlists, err = parseJSON(name, data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+
+	playlists = slices.DeleteFunc(playlists, func(p Playlist) bool { return len(p.Tracks) == 0 })
+	if len(playlists) == 0 {
+		return nil, ErrNoTracks
+	}
+	return playlists, nil
+}
+
+func parseM3U(name string, data []byte) Playlist {
+	playlist := Playlist{Name: name}
+
+	var extinf string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			if title := strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:")); title != "" {
+				playlist.Name = title
+			}
+		case strings.HasPrefix(line, "#EXTINF:"):
+			// #EXTINF:<seconds>,<Artist - Title>
+			_, extinf, _ = strings.Cut(line, ",")
+		case strings.HasPrefix(line, "#"):
+		default:
+			playlist.Tracks = appendTrack(playlist.Tracks, m3uTrack(extinf, line))
+			extinf = ""
+		}
+	}
+
+	return playlist
+}
+
+// m3uTrack takes the artist and title from the #EXTINF line and falls back to
+// the file name, and to the folders of an Artist/Album/Title layout. URLs only
+// have the #EXTINF line to go by.
+func m3uTrack(extinf, location string) models.ImportedTrack {
+	track := models.ImportedTrack{SpotifyURL: spotifyTrackURL(location)}
+	track.Artist, track.Title = splitArtistTitle(extinf)
+	if track.Artist != "" || track.SpotifyURL != "" || strings.Contains(location, "://") {
+		return track
+	}
+
+	location = strings.ReplaceAll(location, `\`, "/")
+	base := path.Base(location)
+	base = trackNumber.ReplaceAllString(strings.TrimSuffix(base, path.Ext(base)), "")
+
+	if artist, title := splitArtistTitle(base); artist != "" {
+		track.Artist, track.Title = artist, title
+		return track
+	}
+
+	dir := path.Dir(location)
+	if parent := path.Dir(dir); parent != "." && parent != "/" {
+		track.Artist, track.Album, track.Title = path.Base(parent), path.Base(dir), strings.TrimSpace(base)
+	}
+	return track
+}
+
+func parseCSV(name string, data []byte) (Playlist, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(header, []byte(";")) && !bytes.Contains(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	columns, err := reader.Read()
+	if err == io.EOF {
+		return Playlist{}, ErrNoTracks
+	}
+	if err != nil {
+		return Playlist{}, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	artist := column(columns, "artist name(s)", "artist name", "artists", "artist")
+	title := column(columns, "track name", "title", "track", "name", "song")
+	if artist < 0 || title < 0 {
+		return Playlist{}, ErrNoColumns
+	}
+	album := column(columns, "album name", "album")
+	isrc := column(columns, "isrc")
+	uri := column(columns, "track uri", "spotify uri", "uri", "spotify url", "url")
+
+	playlist := Playlist{Name: name}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Playlist{}, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		playlist.Tracks = appendTrack(playlist.Tracks, models.ImportedTrack{
+			Artist:     joinArtists(field(record, artist)),
+			Title:      field(record, title),
+			Album:      field(record, album),
+			ISRC:       strings.ToUpper(field(record, isrc)),
+			SpotifyURL: spotifyTrackURL(field(record, uri)),
+		})
+	}
+
+	return playlist, nil
+}
+
+// column returns the index of the first of the names found in the header,
+// -1 when it has none of them
+func column(header []string, names ...string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// joinArtists joins the artists of a CSV row the way the library stores them.
+// Exportify separates them with bare commas and escapes commas inside names.
+func joinArtists(artists string) string {
+	parts := strings.Split(strings.ReplaceAll(artists, `\,`, "\x00"), ",")
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(strings.TrimSpace(part), "\x00", ",")
+	}
+	return strings.Join(slices.DeleteFunc(parts, func(p string) bool { return p == "" }), ", ")
+}
+
+// spotifyExport covers Playlist1.json and YourLibrary.json of a Spotify
+// account data export
+type spotifyExport struct {
+	Playlists []struct {
+		Name  string `json:"name"`
+		Items []struct {
+			// Track is null for podcast episodes and local files
+			Track *struct {
+				TrackName  string `json:"trackName"`
+				ArtistName string `json:"artistName"`
+				AlbumName  string `json:"albumName"`
+				TrackURI   string `json:"trackUri"`
+			} `json:"track"`
+		} `json:"items"`
+	} `json:"playlists"`
+	Tracks []struct {
+		Artist string `json:"artist"`
+		Album  string `json:"album"`
+		Track  string `json:"track"`
+		URI    string `json:"uri"`
+	} `json:"tracks"`
+}
+
+func parseJSON(name string, data []byte) ([]Playlist, error) {
+	var export spotifyExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+
+	playlists := make([]Playlist, 0, len(export.Playlists)+1)
+	for _, exported := range export.Playlists {
+		playlist := Playlist{Name: strings.TrimSpace(exported.Name)}
+		if playlist.Name == "" {
+			playlist.Name = name
+		}
+		for _, item := range exported.Items {
+			if item.Track == nil {
+				continue
+			}
+			playlist.Tracks = appendTrack(playlist.Tracks, models.ImportedTrack{
+				Artist:     strings.TrimSpace(item.Track.ArtistName),
+				Title:      strings.TrimSpace(item.Track.TrackName),
+				Album:      strings.TrimSpace(item.Track.AlbumName),
+				SpotifyURL: spotifyTrackURL(item.Track.TrackURI),
+			})
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	if len(export.Tracks) > 0 {
+		library := Playlist{Name: name}
+		for _, track := range export.Tracks {
+			library.Tracks = appendTrack(library.Tracks, models.ImportedTrack{
+				Artist:     strings.TrimSpace(track.Artist),
+				Title:      strings.TrimSpace(track.Track),
+				Album:      strings.TrimSpace(track.Album),
+				SpotifyURL: spotifyTrackURL(track.URI),
+			})
+		}
+		playlists = append(playlists, library)
+	}
+
+	return playlists, nil
+}
+
+func appendTrack(tracks []models.ImportedTrack, track models.ImportedTrack) []models.ImportedTrack {
+	if track.Artist == "" || track.Title == "" {
+		return tracks
+	}
+	return append(tracks, track)
+}
+
+// splitArtistTitle splits "Artist - Title", both are empty when s isn't
+// in that form
+func splitArtistTitle(s string) (string, string) {
+	artist, title, found := strings.Cut(s, " - ")
+	artist, title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	if !found || artist == "" || title == "" {
+		return "", ""
+	}
+	return artist, title
+}
+
+// spotifyTrackURL returns the open.spotify.com URL of a spotify:track: URI or
+// track URL, empty for anything else
+func spotifyTrackURL(uri string) string {
+	if id, found := strings.CutPrefix(uri, "spotify:track:"); found && id != "" {
+		return "https://open.spotify.com/track/" + id
+	}
+	if id, found := strings.CutPrefix(uri, "https://open.spotify.com/track/"); found {
+		id, _, _ = strings.Cut(id, "?")
+		if id != "" {
+			return "https://open.spotify.com/track/" + id
+		}
+	}
+	return ""
+}
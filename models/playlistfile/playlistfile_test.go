@@ -0,0 +1,141 @@
+package playlistfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	models "github.com/supperdoggy/spot-models"
+)
+
+func TestSupported(t *testing.T) {
+	for _, name := range []string{"mix.m3u", "Mix.M3U8", "export.csv", "Playlist1.json"} {
+		if !Supported(name) {
+			t.Errorf("expected %q to be supported", name)
+		}
+	}
+	if Supported("links.txt") {
+		t.Error("expected .txt to be unsupported")
+	}
+}
+
+func TestParseM3U(t *testing.T) {
+	data := "\xef\xbb\xbf#EXTM3U\n" +
+		"#PLAYLIST:Road Trip\n" +
+		"#EXTINF:231,Daft Punk - Get Lucky\n" +
+		"/music/whatever.mp3\n" +
+		"\n" +
+		"C:\\Music\\Radiohead - Creep.mp3\n" +
+		"Music/Massive Attack/Mezzanine/01 - Angel.flac\n" +
+		"#EXTINF:-1,Not a track\n" +
+		"https://example.com/stream\n" +
+		"loose.mp3\n"
+
+	playlists, err := Parse("old.m3u8", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(playlists) != 1 || playlists[0].Name != "Road Trip" {
+		t.Fatalf("unexpected playlists: %+v", playlists)
+	}
+
+	expected := []models.ImportedTrack{
+		{Artist: "Daft Punk", Title: "Get Lucky"},
+		{Artist: "Radiohead", Title: "Creep"},
+		{Artist: "Massive Attack", Album: "Mezzanine", Title: "Angel"},
+	}
+	assertTracks(t, playlists[0].Tracks, expected)
+}
+
+func TestParseCSV(t *testing.T) {
+	data := `"Track URI","Track Name","Artist Name(s)","Album Name","ISRC"
+"spotify:track:t1","Get Lucky","Daft Punk,Pharrell Williams","Random Access Memories","usqx91300108"
+"spotify:track:t2","Yonkers","Tyler\, The Creator","Goblin","USUM71100636"
+"spotify:local:x","","Nobody","",""
+`
+
+	playlists, err := Parse("Liked.csv", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(playlists) != 1 || playlists[0].Name != "Liked" {
+		t.Fatalf("unexpected playlists: %+v", playlists)
+	}
+
+	expected := []models.ImportedTrack{
+		{Artist: "Daft Punk, Pharrell Williams", Title: "Get Lucky", Album: "Random Access Memories", ISRC: "USQX91300108", SpotifyURL: "https://open.spotify.com/track/t1"},
+		{Artist: "Tyler, The Creator", Title: "Yonkers", Album: "Goblin", ISRC: "USUM71100636", SpotifyURL: "https://open.spotify.com/track/t2"},
+	}
+	assertTracks(t, playlists[0].Tracks, expected)
+}
+
+func TestParseCSVSemicolons(t *testing.T) {
+	playlists, err := Parse("mix.csv", strings.NewReader("artist;title;album\nRadiohead;Creep;Pablo Honey\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertTracks(t, playlists[0].Tracks, []models.ImportedTrack{{Artist: "Radiohead", Title: "Creep", Album: "Pablo Honey"}})
+}
+
+func TestParseCSVWithoutColumns(t *testing.T) {
+	_, err := Parse("links.csv", strings.NewReader("url\nhttps://open.spotify.com/album/a1\n"))
+	if !errors.Is(err, ErrNoColumns) {
+		t.Fatalf("expected ErrNoColumns, got %v", err)
+	}
+}
+
+func TestParseSpotifyExport(t *testing.T) {
+	data := `{"playlists":[
+		{"name":"Gym","items":[
+			{"track":{"trackName":"Get Lucky","artistName":"Daft Punk","albumName":"Random Access Memories","trackUri":"spotify:track:t1"}},
+			{"track":null,"episode":{"episodeName":"Podcast"}}
+		]},
+		{"name":"Empty","items":[]}
+	]}`
+
+	playlists, err := Parse("Playlist1.json", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(playlists) != 1 || playlists[0].Name != "Gym" {
+		t.Fatalf("expected only the playlist with tracks, got %+v", playlists)
+	}
+	assertTracks(t, playlists[0].Tracks, []models.ImportedTrack{
+		{Artist: "Daft Punk", Title: "Get Lucky", Album: "Random Access Memories", SpotifyURL: "https://open.spotify.com/track/t1"},
+	})
+
+	playlists, err = Parse("YourLibrary.json", strings.NewReader(`{"tracks":[{"artist":"Radiohead","album":"Pablo Honey","track":"Creep","uri":"spotify:track:t2"}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(playlists) != 1 || playlists[0].Name != "YourLibrary" || len(playlists[0].Tracks) != 1 {
+		t.Fatalf("unexpected library playlist: %+v", playlists)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("mix.txt", strings.NewReader("")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := Parse("mix.m3u", strings.NewReader("#EXTM3U\n")); !errors.Is(err, ErrNoTracks) {
+		t.Errorf("expected ErrNoTracks, got %v", err)
+	}
+	if _, err := Parse("mix.json", strings.NewReader(`{"other":true}`)); !errors.Is(err, ErrNoTracks) {
+		t.Errorf("expected ErrNoTracks, got %v", err)
+	}
+	if _, err := Parse("mix.json", strings.NewReader(`[`)); err == nil {
+		t.Error("expected an error for broken json")
+	}
+}
+
+func assertTracks(t *testing.T, got, expected []models.ImportedTrack) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d tracks, got %+v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("track %d = %+v, want %+v", i, got[i], expected[i])
+		}
+	}
+}
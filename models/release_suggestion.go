@@ -0,0 +1,31 @@
+package models
+
+type ReleaseSuggestionStatus string
+
+const (
+	ReleaseSuggestionPending   ReleaseSuggestionStatus = "pending"
+	ReleaseSuggestionAccepted  ReleaseSuggestionStatus = "accepted"
+	ReleaseSuggestionDismissed ReleaseSuggestionStatus = "dismissed"
+)
+
+// ReleaseSuggestion is a release of an artist from the library that came out
+// after the artist's newest album in it. dynamic-playlists adds them once per
+// release and users accept or dismiss them from the bot.
+type ReleaseSuggestion struct {
+	ID         string `json:"id" bson:"_id"`
+	Artist     string `json:"artist" bson:"artist"`
+	ArtistURL  string `json:"artist_url" bson:"artist_url"`
+	SpotifyURL string `json:"spotify_url" bson:"spotify_url"`
+	Name       string `json:"name" bson:"name"`
+	// Type is "album" or "single"
+	Type        string `json:"type" bson:"type"`
+	ReleaseDate int64  `json:"release_date" bson:"release_date"`
+	// LatestLocal is the newest album of the artist found in the library
+	LatestLocal string `json:"latest_local" bson:"latest_local"`
+
+	Status ReleaseSuggestionStatus `json:"status" bson:"status"`
+	// DecidedBy is the user who accepted or dismissed the suggestion
+	DecidedBy int64 `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	CreatedAt int64 `json:"created_at" bson:"created_at"`
+	UpdatedAt int64 `json:"updated_at" bson:"updated_at"`
+}
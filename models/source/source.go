@@ -0,0 +1,79 @@
+package source
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/supperdoggy/spot-models/spotify"
+)
+
+// Source is the provider a download request was queued from
+type Source string
+
+const (
+	Spotify      Source = "spotify"
+	YouTubeMusic Source = "youtube_music"
+	SoundCloud   Source = "soundcloud"
+	Bandcamp     Source = "bandcamp"
+)
+
+// IsSpotify reports whether the source is Spotify. Requests created before
+// sources were introduced have an empty source and are Spotify requests.
+func (s Source) IsSpotify() bool {
+	return s == "" || s == Spotify
+}
+
+// String returns a human readable provider name
+func (s Source) String() string {
+	switch s {
+	case "", Spotify:
+		return "Spotify"
+	case YouTubeMusic:
+		return "YouTube Music"
+	case SoundCloud:
+		return "SoundCloud"
+	case Bandcamp:
+		return "Bandcamp"
+	default:
+		return string(s)
+	}
+}
+
+// Resolver turns a URL into the metadata stored on a download request.
+// spotify.SpotifyService satisfies it.
+type Resolver interface {
+	GetObjectName(ctx context.Context, url string) (string, error)
+	GetObjectType(ctx context.Context, url string) (spotify.SpotifyObjectType, error)
+	GetTrackCount(ctx context.Context, url string) (int, []spotify.TrackMetadata, error)
+}
+
+// Provider resolves and downloads URLs of a non-Spotify source
+type Provider interface {
+	Resolver
+	Download(ctx context.Context, url, destination string) error
+}
+
+// Detect returns the source of the URL, or false when no provider supports it
+func Detect(rawURL string) (Source, bool) {
+	if strings.HasPrefix(rawURL, "https://open.spotify.com/") {
+		return Spotify, true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "music.youtube.com", host == "youtube.com", host == "m.youtube.com", host == "youtu.be":
+		return YouTubeMusic, true
+	case host == "soundcloud.com", host == "m.soundcloud.com", host == "on.soundcloud.com":
+		return SoundCloud, true
+	case strings.HasSuffix(host, ".bandcamp.com"):
+		return Bandcamp, true
+	}
+
+	return "", false
+}
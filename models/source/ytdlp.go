@@ -0,0 +1,328 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+)
+
+// dumpTTL is how long the info of a URL is reused: queueing a link asks for
+// its name, type and tracks one after another
+const dumpTTL = time.Minute
+
+// Runner runs a command and returns its stdout
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+type YtDlpOption func(*ytDlpProvider)
+
+// WithRunner replaces the command runner, e.g. with a stub in tests
+func WithRunner(runner Runner) YtDlpOption {
+	return func(p *ytDlpProvider) {
+		p.run = runner
+	}
+}
+
+// WithBinary overrides the yt-dlp executable
+func WithBinary(binary string) YtDlpOption {
+	return func(p *ytDlpProvider) {
+		p.binary = binary
+	}
+}
+
+type ytDlpProvider struct {
+	binary string
+	run    Runner
+	log    *zap.Logger
+
+	mu    sync.Mutex
+	dumps map[string]cachedDump
+}
+
+type cachedDump struct {
+	info    ytDlpInfo
+	expires time.Time
+}
+
+// NewYtDlpProvider returns a Provider for YouTube Music, SoundCloud and
+// Bandcamp backed by the yt-dlp command line tool
+func NewYtDlpProvider(log *zap.Logger, opts ...YtDlpOption) Provider {
+	p := &ytDlpProvider{
+		binary: "yt-dlp",
+		run:    runCommand,
+		log:    log,
+		dumps:  make(map[string]cachedDump),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ytDlpInfo is the subset of the yt-dlp info JSON we use
+type ytDlpInfo struct {
+	Type          string      `json:"_type"`
+	ID            string      `json:"id"`
+	Title         string      `json:"title"`
+	URL           string      `json:"url"`
+	WebpageURL    string      `json:"webpage_url"`
+	Track         string      `json:"track"`
+	Artist        string      `json:"artist"`
+	Album         string      `json:"album"`
+	Uploader      string      `json:"uploader"`
+	Channel       string      `json:"channel"`
+	PlaylistCount int         `json:"playlist_count"`
+	Entries       []ytDlpInfo `json:"entries"`
+}
+
+// dump runs yt-dlp for the info of the URL, reusing the info of a run in the
+// last dumpTTL
+func (p *ytDlpProvider) dump(ctx context.Context, rawURL string) (ytDlpInfo, error) {
+	if _, ok := Detect(rawURL); !ok {
+		return ytDlpInfo{}, errors.New("unsupported url")
+	}
+
+	p.mu.Lock()
+	cached, ok := p.dumps[rawURL]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.info, nil
+	}
+
+	out, err := p.run(ctx, p.binary, "--dump-single-json", "--flat-playlist", "--no-warnings", rawURL)
+	if err != nil {
+		p.log.Error("failed to resolve url with yt-dlp", zap.Error(err), zap.String("url", rawURL))
+		return ytDlpInfo{}, fmt.Errorf("failed to resolve url: %w", err)
+	}
+
+	var info ytDlpInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return ytDlpInfo{}, fmt.Errorf("failed to decode yt-dlp output: %w", err)
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	for key, entry := range p.dumps {
+		if now.After(entry.expires) {
+			delete(p.dumps, key)
+		}
+	}
+	p.dumps[rawURL] = cachedDump{info: info, expires: now.Add(dumpTTL)}
+	p.mu.Unlock()
+
+	return info, nil
+}
+
+func (p *ytDlpProvider) GetObjectName(ctx context.Context, rawURL string) (string, error) {
+	info, err := p.dump(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	name := info.Title
+	if info.Type != "playlist" {
+		if artist, title := trackArtistTitle(info, ""); artist != "" {
+			name = artist + " - " + title
+		}
+	}
+	if name == "" {
+		return "", errors.New("object has no title")
+	}
+
+	return name, nil
+}
+
+// GetObjectType derives the type from the URL shape, falling back to what
+// yt-dlp reports for URLs it cannot tell apart
+func (p *ytDlpProvider) GetObjectType(ctx context.Context, rawURL string) (spotify.SpotifyObjectType, error) {
+	if objectType, ok := objectTypeFromURL(rawURL); ok {
+		return objectType, nil
+	}
+
+	info, err := p.dump(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Type == "playlist" {
+		return spotify.SpotifyObjectTypePlaylist, nil
+	}
+	return spotify.SpotifyObjectTypeTrack, nil
+}
+
+func (p *ytDlpProvider) GetTrackCount(ctx context.Context, rawURL string) (int, []spotify.TrackMetadata, error) {
+	info, err := p.dump(ctx, rawURL)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if info.Type != "playlist" {
+		artist, title := trackArtistTitle(info, "")
+		return 1, []spotify.TrackMetadata{{
+			SpotifyURL: trackURL(info, rawURL),
+			Artist:     strings.ToLower(artist),
+			Title:      strings.ToLower(title),
+		}}, nil
+	}
+
+	// Album entries often carry no artist of their own
+	fallbackArtist := firstNonEmpty(info.Artist, cleanChannel(info.Uploader), cleanChannel(info.Channel))
+
+	tracks := make([]spotify.TrackMetadata, 0, len(info.Entries))
+	for _, entry := range info.Entries {
+		artist, title := trackArtistTitle(entry, fallbackArtist)
+		if title == "" {
+			continue
+		}
+		tracks = append(tracks, spotify.TrackMetadata{
+			SpotifyURL: trackURL(entry, ""),
+			Artist:     strings.ToLower(artist),
+			Title:      strings.ToLower(title),
+		})
+	}
+
+	return len(tracks), tracks, nil
+}
+
+// Download extracts audio into destination as <artist>/<album>/<title>.mp3
+func (p *ytDlpProvider) Download(ctx context.Context, rawURL, destination string) error {
+	if _, ok := Detect(rawURL); !ok {
+		return errors.New("unsupported url")
+	}
+
+	output := filepath.Join(destination, "%(artist,uploader,channel)s", "%(album,playlist_title,title)s", "%(track,title)s.%(ext)s")
+	args := []string{
+		"--extract-audio",
+		"--audio-format", "mp3",
+		"--audio-quality", "0",
+		"--embed-metadata",
+		"--embed-thumbnail",
+		"--no-overwrites",
+		"--ignore-errors",
+		"--no-warnings",
+		"--output", output,
+		rawURL,
+	}
+
+	p.log.Info("executing yt-dlp", zap.String("url", rawURL))
+
+	out, err := p.run(ctx, p.binary, args...)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			p.log.Info("yt-dlp", zap.String("output", line))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+
+	return nil
+}
+
+// objectTypeFromURL maps the known URL shapes of each provider to an object type
+func objectTypeFromURL(rawURL string) (spotify.SpotifyObjectType, bool) {
+	src, ok := Detect(rawURL)
+	if !ok {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	path := strings.Trim(u.Path, "/")
+
+	switch src {
+	case YouTubeMusic:
+		if list := u.Query().Get("list"); list != "" && path == "playlist" {
+			// YouTube Music album playlists use the OLAK5uy_ prefix
+			if strings.HasPrefix(list, "OLAK5uy_") {
+				return spotify.SpotifyObjectTypeAlbum, true
+			}
+			return spotify.SpotifyObjectTypePlaylist, true
+		}
+		if strings.HasPrefix(path, "browse/MPREb_") {
+			return spotify.SpotifyObjectTypeAlbum, true
+		}
+		if path == "watch" || u.Hostname() == "youtu.be" {
+			return spotify.SpotifyObjectTypeTrack, true
+		}
+	case SoundCloud:
+		parts := strings.Split(path, "/")
+		if len(parts) >= 3 && parts[1] == "sets" {
+			return spotify.SpotifyObjectTypePlaylist, true
+		}
+		if len(parts) == 2 {
+			return spotify.SpotifyObjectTypeTrack, true
+		}
+	case Bandcamp:
+		if strings.HasPrefix(path, "album/") {
+			return spotify.SpotifyObjectTypeAlbum, true
+		}
+		if strings.HasPrefix(path, "track/") {
+			return spotify.SpotifyObjectTypeTrack, true
+		}
+	}
+
+	return "", false
+}
+
+// trackArtistTitle prefers the music metadata yt-dlp extracts and falls back
+// to splitting "Artist - Title" video titles
+func trackArtistTitle(info ytDlpInfo, fallbackArtist string) (string, string) {
+	title := firstNonEmpty(info.Track, info.Title)
+	artist := info.Artist
+
+	if artist == "" && info.Track == "" {
+		if a, t, found := strings.Cut(title, " - "); found {
+			artist, title = a, t
+		}
+	}
+	if artist == "" {
+		artist = firstNonEmpty(cleanChannel(info.Uploader), cleanChannel(info.Channel), fallbackArtist)
+	}
+
+	return strings.TrimSpace(artist), strings.TrimSpace(title)
+}
+
+func trackURL(info ytDlpInfo, fallback string) string {
+	return firstNonEmpty(info.WebpageURL, info.URL, fallback)
+}
+
+// cleanChannel strips the suffix of auto-generated YouTube artist channels
+func cleanChannel(name string) string {
+	return strings.TrimSuffix(name, " - Topic")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/supperdoggy/spot-models/spotify"
+	"go.uber.org/zap"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected Source
+		ok       bool
+	}{
+		{"https://open.spotify.com/album/123", Spotify, true},
+		{"https://music.youtube.com/playlist?list=OLAK5uy_abc", YouTubeMusic, true},
+		{"https://www.youtube.com/watch?v=abc", YouTubeMusic, true},
+		{"https://youtu.be/abc", YouTubeMusic, true},
+		{"https://soundcloud.com/artist/sets/mixtape", SoundCloud, true},
+		{"https://artist.bandcamp.com/album/record", Bandcamp, true},
+		{"http://open.spotify.com/album/123", "", false},
+		{"https://google.com", "", false},
+		{"ftp://artist.bandcamp.com/album/record", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		src, ok := Detect(tt.url)
+		if src != tt.expected || ok != tt.ok {
+			t.Errorf("Detect(%q) = %q, %v, want %q, %v", tt.url, src, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestObjectTypeFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected spotify.SpotifyObjectType
+		ok       bool
+	}{
+		{"https://music.youtube.com/playlist?list=OLAK5uy_abc", spotify.SpotifyObjectTypeAlbum, true},
+		{"https://music.youtube.com/playlist?list=PLabc", spotify.SpotifyObjectTypePlaylist, true},
+		{"https://music.youtube.com/browse/MPREb_abc", spotify.SpotifyObjectTypeAlbum, true},
+		{"https://music.youtube.com/watch?v=abc", spotify.SpotifyObjectTypeTrack, true},
+		{"https://youtu.be/abc", spotify.SpotifyObjectTypeTrack, true},
+		{"https://soundcloud.com/artist/sets/mixtape", spotify.SpotifyObjectTypePlaylist, true},
+		{"https://soundcloud.com/artist/song", spotify.SpotifyObjectTypeTrack, true},
+		{"https://artist.bandcamp.com/album/record", spotify.SpotifyObjectTypeAlbum, true},
+		{"https://artist.bandcamp.com/track/song", spotify.SpotifyObjectTypeTrack, true},
+		{"https://artist.bandcamp.com/", "", false},
+	}
+
+	for _, tt := range tests {
+		objectType, ok := objectTypeFromURL(tt.url)
+		if objectType != tt.expected || ok != tt.ok {
+			t.Errorf("objectTypeFromURL(%q) = %q, %v, want %q, %v", tt.url, objectType, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func stubRunner(output string, calls *[][]string) Runner {
+	return func(_ context.Context, name string, args ...string) ([]byte, error) {
+		*calls = append(*calls, append([]string{name}, args...))
+		return []byte(output), nil
+	}
+}
+
+func TestYtDlpProviderResolvesPlaylist(t *testing.T) {
+	var calls [][]string
+	output := `{"_type":"playlist","title":"Album - Record","uploader":"Some Band - Topic","entries":[
+		{"title":"First Song","url":"https://music.youtube.com/watch?v=1"},
+		{"title":"Guest - Second Song","url":"https://music.youtube.com/watch?v=2"},
+		{"title":"","url":"https://music.youtube.com/watch?v=3"}
+	]}`
+	provider := NewYtDlpProvider(zap.NewNop(), WithRunner(stubRunner(output, &calls)))
+	ctx := context.Background()
+
+	name, err := provider.GetObjectName(ctx, "https://music.youtube.com/playlist?list=OLAK5uy_abc")
+	if err != nil || name != "Album - Record" {
+		t.Fatalf("GetObjectName() = %q, %v", name, err)
+	}
+
+	count, tracks, err := provider.GetTrackCount(ctx, "https://music.youtube.com/playlist?list=OLAK5uy_abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || len(tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d (%d)", count, len(tracks))
+	}
+	if tracks[0].Artist != "some band" || tracks[0].Title != "first song" || tracks[0].SpotifyURL != "https://music.youtube.com/watch?v=1" {
+		t.Fatalf("unexpected first track: %+v", tracks[0])
+	}
+	if tracks[1].Artist != "guest" || tracks[1].Title != "second song" {
+		t.Fatalf("unexpected second track: %+v", tracks[1])
+	}
+
+	// the name and the tracks come from the same dump
+	if len(calls) != 1 || !slices.Contains(calls[0], "--dump-single-json") || calls[0][0] != "yt-dlp" {
+		t.Fatalf("unexpected yt-dlp calls: %v", calls)
+	}
+}
+
+func TestYtDlpProviderResolvesSingleTrack(t *testing.T) {
+	var calls [][]string
+	output := `{"_type":"video","title":"Song (Official Video)","track":"Song","artist":"Artist","webpage_url":"https://soundcloud.com/artist/song"}`
+	provider := NewYtDlpProvider(zap.NewNop(), WithRunner(stubRunner(output, &calls)))
+	ctx := context.Background()
+
+	name, err := provider.GetObjectName(ctx, "https://soundcloud.com/artist/song")
+	if err != nil || name != "Artist - Song" {
+		t.Fatalf("GetObjectName() = %q, %v", name, err)
+	}
+
+	count, tracks, err := provider.GetTrackCount(ctx, "https://soundcloud.com/artist/song")
+	if err != nil || count != 1 {
+		t.Fatalf("GetTrackCount() = %d, %v", count, err)
+	}
+	if tracks[0].Artist != "artist" || tracks[0].Title != "song" {
+		t.Fatalf("unexpected track: %+v", tracks[0])
+	}
+
+	if _, err := provider.GetObjectName(ctx, "https://soundcloud.com/artist/other"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected one yt-dlp run per url, got %v", calls)
+	}
+}
+
+func TestYtDlpProviderRejectsUnsupportedURL(t *testing.T) {
+	var calls [][]string
+	provider := NewYtDlpProvider(zap.NewNop(), WithRunner(stubRunner("{}", &calls)))
+
+	if _, err := provider.GetObjectName(context.Background(), "https://google.com"); err == nil {
+		t.Fatal("expected error for unsupported url")
+	}
+	if err := provider.Download(context.Background(), "https://google.com", "/music"); err == nil {
+		t.Fatal("expected error for unsupported url")
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected yt-dlp not to run, got %v", calls)
+	}
+}
+
+func TestYtDlpProviderDownload(t *testing.T) {
+	var calls [][]string
+	provider := NewYtDlpProvider(zap.NewNop(), WithBinary("/usr/bin/yt-dlp"), WithRunner(stubRunner("", &calls)))
+
+	if err := provider.Download(context.Background(), "https://artist.bandcamp.com/album/record", "/music"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0][0] != "/usr/bin/yt-dlp" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	if !slices.Contains(calls[0], "/music/%(artist,uploader,channel)s/%(album,playlist_title,title)s/%(track,title)s.%(ext)s") {
+		t.Fatalf("expected output template under destination, got %v", calls[0])
+	}
+
+	failing := NewYtDlpProvider(zap.NewNop(), WithRunner(func(context.Context, string, ...string) ([]byte, error) {
+		return nil, errors.New("boom")
+	}))
+	if err := failing.Download(context.Background(), "https://artist.bandcamp.com/album/record", "/music"); err == nil {
+		t.Fatal("expected download error")
+	}
+}
@@ -0,0 +1,310 @@
+package spotify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// LikedSongsURL is the URL used to subscribe to a user's saved tracks
+	LikedSongsURL  = "https://open.spotify.com/collection/tracks"
+	likedSongsName = "Liked Songs"
+
+	defaultAPIBaseURL = "https://api.spotify.com/v1/"
+	stateMaxAge       = 15 * time.Minute
+)
+
+var (
+	ErrUserAuthRequired = errors.New("spotify account is not linked")
+	ErrTokenNotFound    = errors.New("spotify token not found")
+	ErrInvalidState     = errors.New("invalid oauth state")
+)
+
+// UserScopes are the scopes requested when a user links their account
+var UserScopes = []string{
+	spotifyauth.ScopePlaylistReadPrivate,
+	spotifyauth.ScopePlaylistReadCollaborative,
+	spotifyauth.ScopeUserLibraryRead,
+}
+
+// Endpoints are the Spotify endpoints used by the services. They can be
+// pointed at a local stub server in tests.
+type Endpoints struct {
+	AuthURL    string
+	TokenURL   string
+	APIBaseURL string // must end with a slash
+}
+
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		AuthURL:    spotifyauth.AuthURL,
+		TokenURL:   spotifyauth.TokenURL,
+		APIBaseURL: defaultAPIBaseURL,
+	}
+}
+
+type Option func(*Endpoints)
+
+// WithEndpoints overrides the Spotify endpoints. Empty fields keep their defaults.
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(e *Endpoints) {
+		if endpoints.AuthURL != "" {
+			e.AuthURL = endpoints.AuthURL
+		}
+		if endpoints.TokenURL != "" {
+			e.TokenURL = endpoints.TokenURL
+		}
+		if endpoints.APIBaseURL != "" {
+			e.APIBaseURL = endpoints.APIBaseURL
+			if !strings.HasSuffix(e.APIBaseURL, "/") {
+				e.APIBaseURL += "/"
+			}
+		}
+	}
+}
+
+func applyOptions(opts []Option) Endpoints {
+	endpoints := DefaultEndpoints()
+	for _, opt := range opts {
+		opt(&endpoints)
+	}
+	return endpoints
+}
+
+// UserToken is a Spotify OAuth token linked to a Telegram user
+type UserToken struct {
+	UserID        int64  `json:"user_id" bson:"user_id"`
+	SpotifyUserID string `json:"spotify_user_id" bson:"spotify_user_id"`
+	AccessToken   string `json:"access_token" bson:"access_token"`
+	RefreshToken  string `json:"refresh_token" bson:"refresh_token"`
+	TokenType     string `json:"token_type" bson:"token_type"`
+	Expiry        int64  `json:"expiry" bson:"expiry"`
+	CreatedAt     int64  `json:"created_at" bson:"created_at"`
+	UpdatedAt     int64  `json:"updated_at" bson:"updated_at"`
+}
+
+func (t UserToken) oauth2Token() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		TokenType:    t.TokenType,
+	}
+	if t.Expiry > 0 {
+		token.Expiry = time.Unix(t.Expiry, 0)
+	}
+	return token
+}
+
+func (t *UserToken) update(token *oauth2.Token) {
+	t.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		t.RefreshToken = token.RefreshToken
+	}
+	t.TokenType = token.TokenType
+	t.Expiry = 0
+	if !token.Expiry.IsZero() {
+		t.Expiry = token.Expiry.Unix()
+	}
+	t.UpdatedAt = time.Now().Unix()
+}
+
+// TokenStore persists user tokens. GetSpotifyToken returns ErrTokenNotFound
+// when the user has not linked an account.
+type TokenStore interface {
+	GetSpotifyToken(ctx context.Context, userID int64) (UserToken, error)
+	SaveSpotifyToken(ctx context.Context, token UserToken) error
+}
+
+// Authenticator runs the OAuth authorization code flow for Telegram users
+type Authenticator struct {
+	config      *oauth2.Config
+	endpoints   Endpoints
+	stateSecret []byte
+	log         *zap.Logger
+}
+
+func NewAuthenticator(clientID, clientSecret, redirectURL, stateSecret string, log *zap.Logger, opts ...Option) *Authenticator {
+	endpoints := applyOptions(opts)
+
+	return &Authenticator{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       UserScopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   endpoints.AuthURL,
+				TokenURL:  endpoints.TokenURL,
+				AuthStyle: oauth2.AuthStyleInHeader,
+			},
+		},
+		endpoints:   endpoints,
+		stateSecret: []byte(stateSecret),
+		log:         log,
+	}
+}
+
+// AuthURL returns the Spotify consent page URL for the given Telegram user
+func (a *Authenticator) AuthURL(userID int64) string {
+	return a.config.AuthCodeURL(a.signState(userID, time.Now()))
+}
+
+func (a *Authenticator) signState(userID int64, issuedAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", userID, issuedAt.Unix())
+	mac := hmac.New(sha256.New, a.stateSecret)
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ParseState verifies the state returned to the callback and returns the Telegram user it was issued for
+func (a *Authenticator) ParseState(state string) (int64, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return 0, ErrInvalidState
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidState
+	}
+	issuedAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidState
+	}
+
+	expected := a.signState(userID, time.Unix(issuedAt, 0))
+	if !hmac.Equal([]byte(expected), []byte(state)) {
+		return 0, ErrInvalidState
+	}
+
+	if time.Since(time.Unix(issuedAt, 0)) > stateMaxAge {
+		return 0, fmt.Errorf("%w: expired", ErrInvalidState)
+	}
+
+	return userID, nil
+}
+
+// Link exchanges the authorization code and returns the token for the user
+func (a *Authenticator) Link(ctx context.Context, userID int64, code string) (UserToken, error) {
+	token, err := a.config.Exchange(ctx, code)
+	if err != nil {
+		return UserToken{}, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	client := spotify.New(a.config.Client(ctx, token), spotify.WithBaseURL(a.endpoints.APIBaseURL))
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return UserToken{}, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	userToken := UserToken{
+		UserID:        userID,
+		SpotifyUserID: user.ID,
+		CreatedAt:     time.Now().Unix(),
+	}
+	userToken.update(token)
+
+	return userToken, nil
+}
+
+// NewUserSpotifyService returns a SpotifyService acting on behalf of the user.
+// Refreshed tokens are written back to the store.
+func (a *Authenticator) NewUserSpotifyService(ctx context.Context, token UserToken, store TokenStore) SpotifyService {
+	source := &persistingTokenSource{
+		base:  a.config.TokenSource(ctx, token.oauth2Token()),
+		token: token,
+		store: store,
+		log:   a.log,
+	}
+
+	return &spotifyService{
+		spotifyClient:  spotify.New(oauth2.NewClient(ctx, source), spotify.WithBaseURL(a.endpoints.APIBaseURL)),
+		log:            a.log,
+		userAuthorized: true,
+	}
+}
+
+// persistingTokenSource saves the token whenever the underlying source refreshes it
+type persistingTokenSource struct {
+	mu    sync.Mutex
+	base  oauth2.TokenSource
+	token UserToken
+	store TokenStore
+	log   *zap.Logger
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.AccessToken == s.token.AccessToken {
+		return token, nil
+	}
+
+	s.token.update(token)
+	if s.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.store.SaveSpotifyToken(ctx, s.token); err != nil {
+			s.log.Error("failed to save refreshed spotify token", zap.Error(err), zap.Int64("user_id", s.token.UserID))
+		}
+	}
+
+	return token, nil
+}
+
+// UserServices hands out SpotifyService instances scoped to a Telegram user
+type UserServices struct {
+	auth     *Authenticator
+	store    TokenStore
+	fallback SpotifyService
+	log      *zap.Logger
+}
+
+func NewUserServices(auth *Authenticator, store TokenStore, fallback SpotifyService, log *zap.Logger) *UserServices {
+	return &UserServices{
+		auth:     auth,
+		store:    store,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+// ForUser returns a user-authorised service when the user has linked an
+// account, otherwise the app-level service.
+func (u *UserServices) ForUser(ctx context.Context, userID int64) SpotifyService {
+	if u == nil {
+		return nil
+	}
+	if u.auth == nil || u.store == nil || userID == 0 {
+		return u.fallback
+	}
+
+	token, err := u.store.GetSpotifyToken(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, ErrTokenNotFound) {
+			u.log.Error("failed to load spotify token", zap.Error(err), zap.Int64("user_id", userID))
+		}
+		return u.fallback
+	}
+
+	return u.auth.NewUserSpotifyService(ctx, token, u.store)
+}
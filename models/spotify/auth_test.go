@@ -0,0 +1,262 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type memoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[int64]UserToken
+	saves  int
+}
+
+func (m *memoryTokenStore) GetSpotifyToken(_ context.Context, userID int64) (UserToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[userID]
+	if !ok {
+		return UserToken{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (m *memoryTokenStore) SaveSpotifyToken(_ context.Context, token UserToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[token.UserID] = token
+	m.saves++
+	return nil
+}
+
+// newStubSpotify serves the token endpoint and the handful of Web API
+// endpoints used for Liked Songs and album, artist and track search.
+func newStubSpotify(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var access string
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "good-code" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			access = "access-1"
+		case "refresh_token":
+			access = "access-2"
+		case "client_credentials":
+			access = "access-app"
+		default:
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  access,
+			"refresh_token": "refresh-1",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	})
+	mux.HandleFunc("/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer access-") {
+			http.Error(w, `{"error":{"status":401,"message":"no token"}}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"spotify-user"}`))
+	})
+	mux.HandleFunc("/v1/me/tracks", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer access-") {
+			http.Error(w, `{"error":{"status":401,"message":"no token"}}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"total":2,"limit":50,"offset":0,"items":[
+			{"added_at":"2024-01-01T00:00:00Z","track":{"id":"t1","name":"Starboy","artists":[{"name":"The Weeknd"}]}},
+			{"added_at":"2024-01-02T00:00:00Z","track":{"id":"t2","name":"Get Lucky","artists":[{"name":"Daft Punk"},{"name":"Pharrell Williams"}]}}
+		]}`))
+	})
+
+	mux.HandleFunc("/v1/playlists/p1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"snapshot_id":"snap-1"}`))
+	})
+
+	mux.HandleFunc("/v1/artists/ar1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"ar1","name":"Daft Punk"}`))
+	})
+	mux.HandleFunc("/v1/artists/ar1/albums", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("include_groups") != "album,single" {
+			http.Error(w, `{"error":{"status":400,"message":"bad include_groups"}}`, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"total":2,"limit":50,"offset":0,"items":[
+			{"id":"a1","name":"Discovery","album_type":"album","release_date":"2001-03-12","release_date_precision":"day","artists":[{"name":"Daft Punk"}]},
+			{"id":"s1","name":"Infinity Repeating","album_type":"single","release_date":"2023-05-12","release_date_precision":"day","artists":[{"name":"Daft Punk"},{"name":"Julian Casablancas"}]}
+		]}`))
+	})
+
+	mux.HandleFunc("/v1/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") == "artist" {
+			if !strings.Contains(r.URL.Query().Get("q"), "daft punk") {
+				_, _ = w.Write([]byte(`{"artists":{"total":0,"items":[]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"artists":{"total":1,"items":[{"id":"ar1","name":"Daft Punk"}]}}`))
+			return
+		}
+		if r.URL.Query().Get("type") == "track" {
+			q := r.URL.Query().Get("q")
+			if q != "isrc:USQX91300108" && !strings.Contains(q, "track:Get Lucky") {
+				_, _ = w.Write([]byte(`{"tracks":{"total":0,"items":[]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"tracks":{"total":1,"items":[{"id":"t2","name":"Get Lucky","artists":[{"name":"Daft Punk"}]}]}}`))
+			return
+		}
+		if !strings.Contains(r.URL.Query().Get("q"), "Discovery") {
+			_, _ = w.Write([]byte(`{"albums":{"total":0,"items":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"albums":{"total":1,"items":[{"id":"a1","name":"Discovery","artists":[{"name":"Daft Punk"}]}]}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func stubEndpoints(server *httptest.Server) Option {
+	return WithEndpoints(Endpoints{
+		AuthURL:    server.URL + "/authorize",
+		TokenURL:   server.URL + "/api/token",
+		APIBaseURL: server.URL + "/v1",
+	})
+}
+
+func TestAuthenticatorStateRoundTrip(t *testing.T) {
+	auth := NewAuthenticator("id", "secret", "http://localhost/callback", "state-secret", zap.NewNop())
+
+	authURL, err := url.Parse(auth.AuthURL(42))
+	if err != nil {
+		t.Fatalf("failed to parse auth url: %v", err)
+	}
+	if scope := authURL.Query().Get("scope"); !strings.Contains(scope, "user-library-read") {
+		t.Fatalf("expected user-library-read scope, got %q", scope)
+	}
+
+	userID, err := auth.ParseState(authURL.Query().Get("state"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID != 42 {
+		t.Fatalf("expected user 42, got %d", userID)
+	}
+
+	other := NewAuthenticator("id", "secret", "http://localhost/callback", "other-secret", zap.NewNop())
+	if _, err := other.ParseState(authURL.Query().Get("state")); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected invalid state for foreign secret, got %v", err)
+	}
+
+	expired := auth.signState(42, time.Now().Add(-time.Hour))
+	if _, err := auth.ParseState(expired); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected expired state to be rejected, got %v", err)
+	}
+}
+
+func TestAuthenticatorLinkAndLikedSongs(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	auth := NewAuthenticator("id", "secret", "http://localhost/callback", "state-secret", zap.NewNop(), stubEndpoints(server))
+
+	if _, err := auth.Link(ctx, 7, "bad-code"); err == nil {
+		t.Fatal("expected error for bad code")
+	}
+
+	token, err := auth.Link(ctx, 7, "good-code")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.UserID != 7 || token.SpotifyUserID != "spotify-user" || token.RefreshToken != "refresh-1" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	store := &memoryTokenStore{tokens: map[int64]UserToken{7: token}}
+	services := NewUserServices(auth, store, nil, zap.NewNop())
+	service := services.ForUser(ctx, 7)
+
+	name, err := service.GetObjectName(ctx, LikedSongsURL)
+	if err != nil || name != likedSongsName {
+		t.Fatalf("GetObjectName() = %q, %v", name, err)
+	}
+
+	count, tracks, err := service.GetTrackCount(ctx, LikedSongsURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || len(tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d (%d)", count, len(tracks))
+	}
+	if tracks[1].Artist != "daft punk, pharrell williams" || tracks[1].SpotifyURL != "https://open.spotify.com/track/t2" {
+		t.Fatalf("unexpected track metadata: %+v", tracks[1])
+	}
+}
+
+func TestUserSpotifyServicePersistsRefreshedToken(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	auth := NewAuthenticator("id", "secret", "http://localhost/callback", "state-secret", zap.NewNop(), stubEndpoints(server))
+
+	expired := UserToken{
+		UserID:       7,
+		AccessToken:  "stale",
+		RefreshToken: "refresh-1",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(-time.Hour).Unix(),
+	}
+	store := &memoryTokenStore{tokens: map[int64]UserToken{7: expired}}
+
+	service := NewUserServices(auth, store, nil, zap.NewNop()).ForUser(ctx, 7)
+	if _, err := service.GetPlaylistTracks(ctx, LikedSongsURL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if store.saves != 1 {
+		t.Fatalf("expected refreshed token to be saved once, got %d", store.saves)
+	}
+	if store.tokens[7].AccessToken != "access-2" {
+		t.Fatalf("expected refreshed access token, got %q", store.tokens[7].AccessToken)
+	}
+}
+
+func TestLikedSongsRequireLinkedAccount(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	app := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	services := NewUserServices(nil, nil, app, zap.NewNop())
+	if _, err := services.ForUser(ctx, 7).GetPlaylistTracks(ctx, LikedSongsURL); !errors.Is(err, ErrUserAuthRequired) {
+		t.Fatalf("expected ErrUserAuthRequired, got %v", err)
+	}
+
+	objectType, err := app.GetObjectType(ctx, LikedSongsURL)
+	if err != nil || objectType != SpotifyObjectTypeCollection {
+		t.Fatalf("GetObjectType() = %q, %v", objectType, err)
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/zmb3/spotify/v2"
-	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2/clientcredentials"
 )
@@ -27,26 +28,56 @@ type SpotifyService interface {
 	GetObjectName(ctx context.Context, url string) (string, error)
 	GetObjectType(ctx context.Context, url string) (SpotifyObjectType, error)
 	GetPlaylistTracks(ctx context.Context, url string) ([]spotify.PlaylistItem, error)
+	GetPlaylistSnapshotID(ctx context.Context, url string) (string, error)
 	GetTrackCount(ctx context.Context, url string) (int, []TrackMetadata, error)
+	SearchAlbum(ctx context.Context, artist, album string) (string, error)
+	GetArtistReleases(ctx context.Context, url string) ([]ArtistRelease, error)
+	SearchArtist(ctx context.Context, name string) (string, error)
+	SearchTrack(ctx context.Context, artist, title, isrc string) (string, error)
 }
 
+// ArtistRelease is an album or single of an artist
+type ArtistRelease struct {
+	URL    string
+	Name   string
+	Artist string
+	// Type is "album" or "single"
+	Type        string
+	ReleaseDate time.Time
+}
+
+// ErrAlbumNotFound is returned by SearchAlbum when Spotify has no matching album
+var ErrAlbumNotFound = errors.New("album not found on spotify")
+
+// ErrArtistNotFound is returned by SearchArtist when Spotify has no matching artist
+var ErrArtistNotFound = errors.New("artist not found on spotify")
+
+// ErrTrackNotFound is returned by SearchTrack when Spotify has no matching track
+var ErrTrackNotFound = errors.New("track not found on spotify")
+
 type spotifyService struct {
 	ClientID      string
 	ClientSecret  string
 	spotifyClient *spotify.Client
 	log           *zap.Logger
+
+	// userAuthorized is set when the client acts on behalf of a user,
+	// which is required for Liked Songs
+	userAuthorized bool
 }
 
-func NewSpotifyService(ctx context.Context, clientID, clientSecret string, log *zap.Logger) SpotifyService {
+func NewSpotifyService(ctx context.Context, clientID, clientSecret string, log *zap.Logger, opts ...Option) SpotifyService {
+	endpoints := applyOptions(opts)
+
 	spotifyConfig := clientcredentials.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
-		TokenURL:     spotifyauth.TokenURL,
+		TokenURL:     endpoints.TokenURL,
 	}
 
 	// Use Client() instead of Token() - this automatically refreshes expired tokens
 	httpClient := spotifyConfig.Client(ctx)
-	spotifyClient := spotify.New(httpClient)
+	spotifyClient := spotify.New(httpClient, spotify.WithBaseURL(endpoints.APIBaseURL))
 
 	return &spotifyService{
 		spotifyClient: spotifyClient,
@@ -67,6 +98,13 @@ func (s *spotifyService) GetObjectName(ctx context.Context, url string) (string,
 		return "", err
 	}
 
+	if objectType == SpotifyObjectTypeCollection {
+		if !s.userAuthorized {
+			return "", ErrUserAuthRequired
+		}
+		return likedSongsName, nil
+	}
+
 	id := s.getSpotifyID(url)
 	if id == "" {
 		s.log.Error("failed to get spotify id", zap.String("url", url))
@@ -96,6 +134,13 @@ func (s *spotifyService) GetObjectName(ctx context.Context, url string) (string,
 			return "", err
 		}
 		name = track.Name
+	case SpotifyObjectTypeArtist:
+		artist, err := s.spotifyClient.GetArtist(ctx, id)
+		if err != nil {
+			s.log.Error("failed to get artist", zap.Error(err), zap.String("id", string(id)))
+			return "", err
+		}
+		name = artist.Name
 	default:
 		return "", errors.New("unknown object type")
 	}
@@ -104,6 +149,10 @@ func (s *spotifyService) GetObjectName(ctx context.Context, url string) (string,
 }
 
 func (s *spotifyService) GetObjectType(ctx context.Context, url string) (SpotifyObjectType, error) {
+	// checked first since "collection/tracks" also contains "track"
+	if strings.Contains(url, "/collection/tracks") {
+		return SpotifyObjectTypeCollection, nil
+	}
 	if strings.Contains(url, "playlist") {
 		return SpotifyObjectTypePlaylist, nil
 	}
@@ -140,6 +189,10 @@ func (s *spotifyService) GetPlaylistTracks(ctx context.Context, url string) ([]s
 		return nil, errors.New("invalid spotify url")
 	}
 
+	if objectType, _ := s.GetObjectType(ctx, url); objectType == SpotifyObjectTypeCollection {
+		return s.getSavedTracks(ctx)
+	}
+
 	id := s.getSpotifyID(url)
 	if id == "" {
 		return nil, errors.New("invalid spotify url")
@@ -169,6 +222,63 @@ func (s *spotifyService) GetPlaylistTracks(ctx context.Context, url string) ([]s
 	return playlistItems, nil
 }
 
+// GetPlaylistSnapshotID returns the snapshot_id Spotify changes on every edit
+// of the playlist. It is empty for Liked Songs, which have none.
+func (s *spotifyService) GetPlaylistSnapshotID(ctx context.Context, url string) (string, error) {
+	if !s.isValidSpotifyURL(url) {
+		return "", errors.New("invalid spotify url")
+	}
+
+	if objectType, _ := s.GetObjectType(ctx, url); objectType == SpotifyObjectTypeCollection {
+		return "", nil
+	}
+
+	id := s.getSpotifyID(url)
+	if id == "" {
+		return "", errors.New("invalid spotify url")
+	}
+
+	playlist, err := s.spotifyClient.GetPlaylist(ctx, id, spotify.Fields("snapshot_id"))
+	if err != nil {
+		s.log.Error("failed to get playlist snapshot id", zap.Error(err), zap.String("id", string(id)))
+		return "", err
+	}
+
+	return playlist.SnapshotID, nil
+}
+
+// getSavedTracks returns the user's Liked Songs as playlist items so they can
+// be handled like any other playlist
+func (s *spotifyService) getSavedTracks(ctx context.Context) ([]spotify.PlaylistItem, error) {
+	if !s.userAuthorized {
+		return nil, ErrUserAuthRequired
+	}
+
+	var playlistItems []spotify.PlaylistItem
+	limit := 50
+	for offset := 0; ; offset += limit {
+		page, err := s.spotifyClient.CurrentUsersTracks(ctx, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			s.log.Error("failed to get saved tracks", zap.Error(err))
+			return nil, err
+		}
+
+		for i := range page.Tracks {
+			track := page.Tracks[i].FullTrack
+			playlistItems = append(playlistItems, spotify.PlaylistItem{
+				AddedAt: page.Tracks[i].AddedAt,
+				Track:   spotify.PlaylistItemTrack{Track: &track},
+			})
+		}
+
+		if len(page.Tracks) < limit || offset+limit >= int(page.Total) {
+			break
+		}
+	}
+
+	return playlistItems, nil
+}
+
 // GetTrackCount returns the total track count and metadata for a Spotify URL (album, playlist, or track)
 func (s *spotifyService) GetTrackCount(ctx context.Context, url string) (int, []TrackMetadata, error) {
 	if !s.isValidSpotifyURL(url) {
@@ -189,7 +299,7 @@ func (s *spotifyService) GetTrackCount(ctx context.Context, url string) (int, []
 	var tracks []TrackMetadata
 
 	switch objectType {
-	case SpotifyObjectTypePlaylist:
+	case SpotifyObjectTypePlaylist, SpotifyObjectTypeCollection:
 		playlistItems, err := s.GetPlaylistTracks(ctx, url)
 		if err != nil {
 			return 0, nil, fmt.Errorf("failed to get playlist tracks: %w", err)
@@ -267,3 +377,106 @@ func (s *spotifyService) GetTrackCount(ctx context.Context, url string) (int, []
 
 	return count, tracks, nil
 }
+
+// SearchAlbum returns the URL of the best Spotify match for the album
+func (s *spotifyService) SearchAlbum(ctx context.Context, artist, album string) (string, error) {
+	query := fmt.Sprintf("album:%s artist:%s", album, artist)
+	result, err := s.spotifyClient.Search(ctx, query, spotify.SearchTypeAlbum, spotify.Limit(1))
+	if err != nil {
+		s.log.Error("failed to search album", zap.Error(err), zap.String("query", query))
+		return "", err
+	}
+
+	if result.Albums == nil || len(result.Albums.Albums) == 0 {
+		return "", ErrAlbumNotFound
+	}
+
+	return fmt.Sprintf("https://open.spotify.com/album/%s", result.Albums.Albums[0].ID), nil
+}
+
+// SearchArtist returns the URL of the best Spotify match for the artist
+func (s *spotifyService) SearchArtist(ctx context.Context, name string) (string, error) {
+	query := fmt.Sprintf("artist:%s", name)
+	result, err := s.spotifyClient.Search(ctx, query, spotify.SearchTypeArtist, spotify.Limit(1))
+	if err != nil {
+		s.log.Error("failed to search artist", zap.Error(err), zap.String("query", query))
+		return "", err
+	}
+
+	if result.Artists == nil || len(result.Artists.Artists) == 0 {
+		return "", ErrArtistNotFound
+	}
+
+	return fmt.Sprintf("https://open.spotify.com/artist/%s", result.Artists.Artists[0].ID), nil
+}
+
+// SearchTrack returns the URL of the best Spotify match for the track. The
+// ISRC is looked up first when there is one since it names the exact recording.
+func (s *spotifyService) SearchTrack(ctx context.Context, artist, title, isrc string) (string, error) {
+	queries := make([]string, 0, 2)
+	if isrc != "" {
+		queries = append(queries, fmt.Sprintf("isrc:%s", isrc))
+	}
+	queries = append(queries, fmt.Sprintf("track:%s artist:%s", title, artist))
+
+	for _, query := range queries {
+		result, err := s.spotifyClient.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(1))
+		if err != nil {
+			s.log.Error("failed to search track", zap.Error(err), zap.String("query", query))
+			return "", err
+		}
+
+		if result.Tracks != nil && len(result.Tracks.Tracks) > 0 {
+			return s.getTrackURL(result.Tracks.Tracks[0].ID), nil
+		}
+	}
+
+	return "", ErrTrackNotFound
+}
+
+// GetArtistReleases returns the albums and singles of the artist, newest first
+func (s *spotifyService) GetArtistReleases(ctx context.Context, url string) ([]ArtistRelease, error) {
+	if !s.isValidSpotifyURL(url) {
+		return nil, errors.New("invalid spotify url")
+	}
+
+	id := s.getSpotifyID(url)
+	if id == "" {
+		return nil, errors.New("invalid spotify url")
+	}
+
+	var releases []ArtistRelease
+	limit := 50
+	for offset := 0; ; offset += limit {
+		page, err := s.spotifyClient.GetArtistAlbums(ctx, id, []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			s.log.Error("failed to get artist albums", zap.Error(err), zap.String("id", string(id)))
+			return nil, err
+		}
+
+		for i := range page.Albums {
+			album := page.Albums[i]
+			artists := make([]string, 0, len(album.Artists))
+			for _, artist := range album.Artists {
+				artists = append(artists, artist.Name)
+			}
+			releases = append(releases, ArtistRelease{
+				URL:         fmt.Sprintf("https://open.spotify.com/album/%s", album.ID),
+				Name:        album.Name,
+				Artist:      strings.Join(artists, ", "),
+				Type:        album.AlbumType,
+				ReleaseDate: album.ReleaseDateTime(),
+			})
+		}
+
+		if len(page.Albums) < limit || offset+limit >= int(page.Total) {
+			break
+		}
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].ReleaseDate.After(releases[j].ReleaseDate)
+	})
+
+	return releases, nil
+}
@@ -0,0 +1,113 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSearchAlbum(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	service := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	url, err := service.SearchAlbum(ctx, "daft punk", "Discovery")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url != "https://open.spotify.com/album/a1" {
+		t.Fatalf("unexpected album url: %q", url)
+	}
+
+	if _, err := service.SearchAlbum(ctx, "daft punk", "Homework"); !errors.Is(err, ErrAlbumNotFound) {
+		t.Fatalf("expected ErrAlbumNotFound, got %v", err)
+	}
+}
+
+func TestSearchArtist(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	service := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	url, err := service.SearchArtist(ctx, "daft punk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url != "https://open.spotify.com/artist/ar1" {
+		t.Fatalf("unexpected artist url: %q", url)
+	}
+
+	if _, err := service.SearchArtist(ctx, "nobody"); !errors.Is(err, ErrArtistNotFound) {
+		t.Fatalf("expected ErrArtistNotFound, got %v", err)
+	}
+}
+
+func TestSearchTrack(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	service := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	url, err := service.SearchTrack(ctx, "Daft Punk", "Lucky", "USQX91300108")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url != "https://open.spotify.com/track/t2" {
+		t.Fatalf("unexpected track url: %q", url)
+	}
+
+	if _, err := service.SearchTrack(ctx, "Daft Punk", "Get Lucky", "UNKNOWN"); err != nil {
+		t.Fatalf("expected the artist and title search after an unknown ISRC, got %v", err)
+	}
+
+	if _, err := service.SearchTrack(ctx, "Daft Punk", "Homework", ""); !errors.Is(err, ErrTrackNotFound) {
+		t.Fatalf("expected ErrTrackNotFound, got %v", err)
+	}
+}
+
+func TestGetPlaylistSnapshotID(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	service := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	snapshotID, err := service.GetPlaylistSnapshotID(ctx, "https://open.spotify.com/playlist/p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshotID != "snap-1" {
+		t.Fatalf("unexpected snapshot id: %q", snapshotID)
+	}
+
+	snapshotID, err = service.GetPlaylistSnapshotID(ctx, LikedSongsURL)
+	if err != nil || snapshotID != "" {
+		t.Fatalf("expected no snapshot id for Liked Songs, got %q, %v", snapshotID, err)
+	}
+}
+
+func TestGetArtistReleases(t *testing.T) {
+	server := newStubSpotify(t)
+	ctx := context.Background()
+	service := NewSpotifyService(ctx, "id", "secret", zap.NewNop(), stubEndpoints(server))
+
+	name, err := service.GetObjectName(ctx, "https://open.spotify.com/artist/ar1")
+	if err != nil || name != "Daft Punk" {
+		t.Fatalf("GetObjectName() = %q, %v", name, err)
+	}
+
+	releases, err := service.GetArtistReleases(ctx, "https://open.spotify.com/artist/ar1?si=x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("expected two releases, got %+v", releases)
+	}
+
+	newest := releases[0]
+	if newest.URL != "https://open.spotify.com/album/s1" || newest.Type != "single" || newest.Artist != "Daft Punk, Julian Casablancas" {
+		t.Fatalf("expected the newest release first, got %+v", newest)
+	}
+	if newest.ReleaseDate.Year() != 2023 {
+		t.Fatalf("unexpected release date: %v", newest.ReleaseDate)
+	}
+}
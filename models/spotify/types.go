@@ -7,4 +7,6 @@ const (
 	SpotifyObjectTypeAlbum    SpotifyObjectType = "album"
 	SpotifyObjectTypeTrack    SpotifyObjectType = "track"
 	SpotifyObjectTypeArtist   SpotifyObjectType = "artist"
+	// SpotifyObjectTypeCollection is a user's Liked Songs, only available with a linked account
+	SpotifyObjectTypeCollection SpotifyObjectType = "collection"
 )
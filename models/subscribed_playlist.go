@@ -1,16 +1,41 @@
 package models
 
+// RemovalPolicy decides what a sync does with tracks removed from a
+// subscribed Spotify playlist
+type RemovalPolicy string
+
+const (
+	// RemovalMirror drops removed tracks from the M3U. Subscriptions created
+	// before policies existed have an empty policy and mirror.
+	RemovalMirror RemovalPolicy = "mirror"
+	// RemovalKeep keeps removed tracks at the end of the M3U
+	RemovalKeep RemovalPolicy = "keep"
+	// RemovalArchive moves removed tracks to a companion "<name> (removed)" M3U
+	RemovalArchive RemovalPolicy = "archive"
+)
+
+// TracksRemoved reports whether removed tracks are kept track of, which
+// the mirror policy doesn't need
+func (p RemovalPolicy) TracksRemoved() bool {
+	return p == RemovalKeep || p == RemovalArchive
+}
+
 type SubscribedPlaylist struct {
-	ID              string `json:"id" bson:"_id"`
-	CreatorID       int64  `json:"creator_id" bson:"creator_id"`
-	SpotifyURL      string `json:"spotify_url" bson:"spotify_url"`
-	Name            string `json:"name" bson:"name"`
-	Active          bool   `json:"active" bson:"active"`
-	RefreshInterval string `json:"refresh_interval" bson:"refresh_interval"` // "hourly", "daily", "weekly"
-	NoPull          bool   `json:"no_pull" bson:"no_pull"`
-	LastSynced      int64  `json:"last_synced" bson:"last_synced"`
-	LastTrackCount  int    `json:"last_track_count" bson:"last_track_count"`
-	OutputPath      string `json:"output_path" bson:"output_path"`
-	CreatedAt       int64  `json:"created_at" bson:"created_at"`
-	UpdatedAt       int64  `json:"updated_at" bson:"updated_at"`
+	ID              string          `json:"id" bson:"_id"`
+	CreatorID       int64           `json:"creator_id" bson:"creator_id"`
+	SpotifyURL      string          `json:"spotify_url" bson:"spotify_url"`
+	Name            string          `json:"name" bson:"name"`
+	Active          bool            `json:"active" bson:"active"`
+	RefreshInterval string          `json:"refresh_interval" bson:"refresh_interval"` // "hourly", "daily", "weekly"
+	NoPull          bool            `json:"no_pull" bson:"no_pull"`
+	Paused          bool            `json:"paused" bson:"paused"`                 // kept but not synced until resumed
+	NotifyChanges   bool            `json:"notify_changes" bson:"notify_changes"` // message the subscriber when a sync finds changes
+	RemovalPolicy   RemovalPolicy   `json:"removal_policy" bson:"removal_policy"`
+	RemovedTracks   []SnapshotTrack `json:"removed_tracks,omitempty" bson:"removed_tracks,omitempty"` // removed so far, only for keep and archive
+	LastSynced      int64           `json:"last_synced" bson:"last_synced"`
+	LastTrackCount  int             `json:"last_track_count" bson:"last_track_count"`
+	SnapshotID      string          `json:"snapshot_id" bson:"snapshot_id"` // Spotify snapshot_id seen on the last sync
+	OutputPath      string          `json:"output_path" bson:"output_path"`
+	CreatedAt       int64           `json:"created_at" bson:"created_at"`
+	UpdatedAt       int64           `json:"updated_at" bson:"updated_at"`
 }
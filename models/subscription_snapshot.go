@@ -0,0 +1,97 @@
+package models
+
+import "slices"
+
+// SnapshotTrack is a track of a subscribed playlist as seen on one sync
+type SnapshotTrack struct {
+	// ID is the Spotify track ID
+	ID     string `json:"id" bson:"id"`
+	Artist string `json:"artist" bson:"artist"`
+	Title  string `json:"title" bson:"title"`
+	// InLibrary is set when the track was found in the library on that sync
+	InLibrary bool `json:"in_library" bson:"in_library"`
+}
+
+// SubscriptionSnapshot is the track list of a subscribed playlist stored on
+// every sync, together with what changed since the previous snapshot
+type SubscriptionSnapshot struct {
+	ID             string `json:"id" bson:"_id"`
+	SubscriptionID string `json:"subscription_id" bson:"subscription_id"`
+	CreatorID      int64  `json:"creator_id" bson:"creator_id"`
+	Name           string `json:"name" bson:"name"`
+	// Tracks is only kept on the latest snapshot of a subscription, older
+	// ones are change records
+	Tracks []SnapshotTrack `json:"tracks" bson:"tracks"`
+
+	Added   []SnapshotTrack `json:"added,omitempty" bson:"added,omitempty"`
+	Removed []SnapshotTrack `json:"removed,omitempty" bson:"removed,omitempty"`
+	// Available are tracks that were missing from the library on the previous
+	// sync and are in it now
+	Available []SnapshotTrack `json:"available,omitempty" bson:"available,omitempty"`
+	// Changed is false for the first snapshot of a subscription and for syncs
+	// where nothing changed
+	Changed bool `json:"changed" bson:"changed"`
+
+	CreatedAt int64 `json:"created_at" bson:"created_at"`
+}
+
+// NewSubscriptionSnapshot builds the snapshot of the subscription with the
+// changes since previous, which is nil on the first sync
+func NewSubscriptionSnapshot(playlist SubscribedPlaylist, tracks []SnapshotTrack, previous *SubscriptionSnapshot) SubscriptionSnapshot {
+	snapshot := SubscriptionSnapshot{
+		SubscriptionID: playlist.ID,
+		CreatorID:      playlist.CreatorID,
+		Name:           playlist.Name,
+		Tracks:         tracks,
+	}
+	if previous == nil {
+		return snapshot
+	}
+
+	before := make(map[string]SnapshotTrack, len(previous.Tracks))
+	for _, track := range previous.Tracks {
+		before[track.ID] = track
+	}
+
+	now := make(map[string]bool, len(tracks))
+	for _, track := range tracks {
+		now[track.ID] = true
+
+		old, ok := before[track.ID]
+		switch {
+		case !ok:
+			snapshot.Added = append(snapshot.Added, track)
+		case track.InLibrary && !old.InLibrary:
+			snapshot.Available = append(snapshot.Available, track)
+		}
+	}
+
+	for _, track := range previous.Tracks {
+		if !now[track.ID] {
+			snapshot.Removed = append(snapshot.Removed, track)
+		}
+	}
+
+	snapshot.Changed = len(snapshot.Added) > 0 || len(snapshot.Removed) > 0 || len(snapshot.Available) > 0
+	return snapshot
+}
+
+// MergeRemovedTracks adds the tracks the snapshot found removed to the ones
+// removed on earlier syncs, leaving out tracks that are back in the playlist
+func MergeRemovedTracks(removed []SnapshotTrack, snapshot SubscriptionSnapshot) []SnapshotTrack {
+	current := make(map[string]bool, len(snapshot.Tracks))
+	for _, track := range snapshot.Tracks {
+		current[track.ID] = true
+	}
+
+	merged := make([]SnapshotTrack, 0, len(removed)+len(snapshot.Removed))
+	seen := make(map[string]bool, cap(merged))
+	for _, track := range slices.Concat(removed, snapshot.Removed) {
+		if current[track.ID] || seen[track.ID] {
+			continue
+		}
+		seen[track.ID] = true
+		merged = append(merged, track)
+	}
+	return merged
+}
@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+func TestNewSubscriptionSnapshot_First(t *testing.T) {
+	playlist := SubscribedPlaylist{ID: "sub-1", CreatorID: 42, Name: "Mix"}
+
+	s := NewSubscriptionSnapshot(playlist, []SnapshotTrack{{ID: "a"}}, nil)
+
+	if s.SubscriptionID != "sub-1" || s.CreatorID != 42 || s.Name != "Mix" || len(s.Tracks) != 1 {
+		t.Errorf("unexpected snapshot: %+v", s)
+	}
+	if s.Changed || len(s.Added) != 0 {
+		t.Errorf("expected the first snapshot to have no changes, got %+v", s)
+	}
+}
+
+func TestNewSubscriptionSnapshot_Diff(t *testing.T) {
+	previous := &SubscriptionSnapshot{Tracks: []SnapshotTrack{
+		{ID: "kept"},
+		{ID: "downloaded"},
+		{ID: "gone", Title: "Gone"},
+	}}
+
+	s := NewSubscriptionSnapshot(SubscribedPlaylist{ID: "sub-1"}, []SnapshotTrack{
+		{ID: "kept"},
+		{ID: "downloaded", InLibrary: true},
+		{ID: "new", InLibrary: true},
+	}, previous)
+
+	if !s.Changed {
+		t.Fatal("expected changes")
+	}
+	if len(s.Added) != 1 || s.Added[0].ID != "new" {
+		t.Errorf("expected 'new' to be added, got %+v", s.Added)
+	}
+	if len(s.Removed) != 1 || s.Removed[0].Title != "Gone" {
+		t.Errorf("expected 'gone' to be removed, got %+v", s.Removed)
+	}
+	if len(s.Available) != 1 || s.Available[0].ID != "downloaded" {
+		t.Errorf("expected 'downloaded' to be available, got %+v", s.Available)
+	}
+}
+
+func TestNewSubscriptionSnapshot_Unchanged(t *testing.T) {
+	tracks := []SnapshotTrack{{ID: "a", InLibrary: true}, {ID: "b"}}
+
+	s := NewSubscriptionSnapshot(SubscribedPlaylist{}, tracks, &SubscriptionSnapshot{Tracks: tracks})
+
+	if s.Changed {
+		t.Errorf("expected no changes, got %+v", s)
+	}
+}
+
+func TestMergeRemovedTracks(t *testing.T) {
+	removed := []SnapshotTrack{{ID: "old"}, {ID: "back"}}
+	snapshot := SubscriptionSnapshot{
+		Tracks:  []SnapshotTrack{{ID: "back"}, {ID: "kept"}},
+		Removed: []SnapshotTrack{{ID: "new"}, {ID: "old"}},
+	}
+
+	merged := MergeRemovedTracks(removed, snapshot)
+
+	if len(merged) != 2 || merged[0].ID != "old" || merged[1].ID != "new" {
+		t.Errorf("expected old and new, got %+v", merged)
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+// Role controls what a user may do in the bot
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "read-only"
+)
+
+var roleRanks = map[Role]int{
+	RoleReadOnly: 1,
+	RoleMember:   2,
+	RoleAdmin:    3,
+}
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether a user with role r may use something that requires the given role.
+// Unknown roles allow nothing.
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// User holds the bot settings of a Telegram user
+type User struct {
+	ID       int64  `json:"id" bson:"_id"` // Telegram user ID
+	Name     string `json:"name" bson:"name"`
+	Role     Role   `json:"role" bson:"role"`
+	Language string `json:"language" bson:"language"`
+	AddedBy  int64  `json:"added_by" bson:"added_by"`
+
+	CreatedAt int64 `json:"created_at" bson:"created_at"`
+	UpdatedAt int64 `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "testing"
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		expected bool
+	}{
+		{RoleAdmin, RoleAdmin, true},
+		{RoleAdmin, RoleReadOnly, true},
+		{RoleMember, RoleMember, true},
+		{RoleMember, RoleAdmin, false},
+		{RoleReadOnly, RoleReadOnly, true},
+		{RoleReadOnly, RoleMember, false},
+		{Role(""), RoleReadOnly, false},
+		{Role("owner"), RoleReadOnly, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.expected {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.role, tt.required, got, tt.expected)
+		}
+	}
+}
+
+func TestRoleValid(t *testing.T) {
+	for _, role := range []Role{RoleAdmin, RoleMember, RoleReadOnly} {
+		if !role.Valid() {
+			t.Errorf("expected %q to be valid", role)
+		}
+	}
+	if Role("owner").Valid() {
+		t.Error("expected unknown role to be invalid")
+	}
+}
@@ -0,0 +1,321 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+// Event is the type of a webhook event
+type Event string
+
+const (
+	// EventRequestCreated is sent by album-queue when something is queued
+	EventRequestCreated Event = "request.created"
+	// EventTrackDownloaded is sent by spotdl-wapper when a track of a request
+	// shows up in the library
+	EventTrackDownloaded Event = "track.downloaded"
+	// EventRequestCompleted is sent by spotdl-wapper when every track of a
+	// request that wasn't skipped was found
+	EventRequestCompleted Event = "request.completed"
+	// EventRequestFailed is sent by spotdl-wapper when a request is given up
+	// on with tracks still missing
+	EventRequestFailed Event = "request.failed"
+	// EventSubscriptionSynced is sent by dynamic-playlists after a subscribed
+	// playlist was synced
+	EventSubscriptionSynced Event = "subscription.synced"
+)
+
+// Events lists every event a subscription can ask for
+var Events = []Event{
+	EventRequestCreated,
+	EventTrackDownloaded,
+	EventRequestCompleted,
+	EventRequestFailed,
+	EventSubscriptionSynced,
+}
+
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body
+	// keyed with the subscription secret
+	SignatureHeader = "X-Harmoniq-Signature"
+	EventHeader     = "X-Harmoniq-Event"
+	DeliveryHeader  = "X-Harmoniq-Delivery"
+)
+
+// Subscription is an endpoint that receives the events it asked for
+type Subscription struct {
+	ID        string  `json:"id" bson:"_id"`
+	URL       string  `json:"url" bson:"url"`
+	Secret    string  `json:"secret" bson:"secret"`
+	Events    []Event `json:"events" bson:"events"`
+	Active    bool    `json:"active" bson:"active"`
+	CreatedBy int64   `json:"created_by" bson:"created_by"`
+	CreatedAt int64   `json:"created_at" bson:"created_at"`
+	UpdatedAt int64   `json:"updated_at" bson:"updated_at"`
+}
+
+// Wants reports whether the subscription asked for the event
+func (s Subscription) Wants(event Event) bool {
+	return s.Active && slices.Contains(s.Events, event)
+}
+
+// Delivery is the delivery log entry of one event sent to one subscription
+type Delivery struct {
+	ID             string `json:"id" bson:"_id"`
+	SubscriptionID string `json:"subscription_id" bson:"subscription_id"`
+	Event          Event  `json:"event" bson:"event"`
+	URL            string `json:"url" bson:"url"`
+	Attempts       int    `json:"attempts" bson:"attempts"`
+	// StatusCode is the response status of the last attempt, 0 when no
+	// response came back
+	StatusCode int    `json:"status_code" bson:"status_code"`
+	Error      string `json:"error,omitempty" bson:"error,omitempty"`
+	Delivered  bool   `json:"delivered" bson:"delivered"`
+	CreatedAt  int64  `json:"created_at" bson:"created_at"`
+	FinishedAt int64  `json:"finished_at" bson:"finished_at"`
+}
+
+// Payload is the JSON body of every webhook
+type Payload struct {
+	ID        string `json:"id"`
+	Event     Event  `json:"event"`
+	CreatedAt int64  `json:"created_at"`
+	Data      any    `json:"data"`
+}
+
+// RequestData is the data of the request events
+type RequestData struct {
+	ID         string `json:"id,omitempty"`
+	URL        string `json:"url"`
+	Name       string `json:"name,omitempty"`
+	CreatorID  int64  `json:"creator_id"`
+	ObjectType string `json:"object_type,omitempty"`
+	// Tracks and Found are the expected and found track counts, Found is only
+	// set once spotdl-wapper worked on the request
+	Tracks int `json:"tracks"`
+	Found  int `json:"found"`
+}
+
+// NewRequestData returns the event data of a stored request
+func NewRequestData(request models.DownloadQueueRequest) RequestData {
+	return RequestData{
+		ID:         request.ID,
+		URL:        request.SpotifyURL,
+		Name:       request.Name,
+		CreatorID:  request.CreatorID,
+		ObjectType: string(request.ObjectType),
+		Tracks:     request.ExpectedTrackCount,
+		Found:      request.FoundTrackCount,
+	}
+}
+
+// TrackData is the data of track.downloaded
+type TrackData struct {
+	RequestID   string `json:"request_id"`
+	RequestName string `json:"request_name"`
+	URL         string `json:"url"`
+	Artist      string `json:"artist"`
+	Title       string `json:"title"`
+}
+
+// SubscriptionData is the data of subscription.synced
+type SubscriptionData struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	CreatorID int64  `json:"creator_id"`
+	Tracks    int    `json:"tracks"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Available int    `json:"available"`
+}
+
+// Store returns the subscriptions and keeps the delivery log. The databases of
+// the services sending webhooks implement it.
+type Store interface {
+	GetWebhookSubscriptions(ctx context.Context, event Event) ([]Subscription, error)
+	SaveWebhookDelivery(ctx context.Context, delivery Delivery) error
+}
+
+// NewSecret returns a random secret to sign the webhooks of a subscription with
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the signature header value of the body for the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends events to the subscriptions that asked for them
+type Dispatcher interface {
+	// Dispatch sends the event in the background, retrying failed attempts
+	// with backoff
+	Dispatch(ctx context.Context, event Event, data any)
+	// Wait blocks until every dispatched event was delivered or given up on
+	Wait()
+}
+
+type dispatcher struct {
+	store       Store
+	client      *http.Client
+	backoff     time.Duration
+	maxAttempts int
+	log         *zap.Logger
+
+	wg sync.WaitGroup
+}
+
+// Option changes how a Dispatcher delivers events
+type Option func(*dispatcher)
+
+// WithHTTPClient replaces the default client with a 10 second timeout
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *dispatcher) {
+		d.client = client
+	}
+}
+
+// WithRetries sets how many attempts are made and the wait before the first
+// retry, which doubles on every following one
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(d *dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.backoff = backoff
+	}
+}
+
+func NewDispatcher(store Store, log *zap.Logger, opts ...Option) Dispatcher {
+	d := &dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		backoff:     time.Second,
+		maxAttempts: 5,
+		log:         log,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, event Event, data any) {
+	subscriptions, err := d.store.GetWebhookSubscriptions(ctx, event)
+	if err != nil {
+		d.log.Error("failed to get webhook subscriptions", zap.Error(err), zap.String("event", string(event)))
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event) {
+			continue
+		}
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			d.log.Error("failed to generate webhook delivery id", zap.Error(err))
+			return
+		}
+
+		payload := Payload{ID: id.String(), Event: event, CreatedAt: time.Now().Unix(), Data: data}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			d.log.Error("failed to marshal webhook payload", zap.Error(err), zap.String("event", string(event)))
+			return
+		}
+
+		delivery := Delivery{
+			ID:             payload.ID,
+			SubscriptionID: subscription.ID,
+			Event:          event,
+			URL:            subscription.URL,
+			CreatedAt:      payload.CreatedAt,
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(context.WithoutCancel(ctx), subscription, delivery, body)
+		}()
+	}
+}
+
+func (d *dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// deliver posts the body until the endpoint answers with a 2xx status or the
+// attempts run out, then writes the outcome to the delivery log
+func (d *dispatcher) deliver(ctx context.Context, subscription Subscription, delivery Delivery, body []byte) {
+	backoff := d.backoff
+	for delivery.Attempts < d.maxAttempts {
+		if delivery.Attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		delivery.Attempts++
+
+		statusCode, err := d.post(ctx, subscription, delivery, body)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			break
+		}
+
+		delivery.Error = err.Error()
+		d.log.Warn("webhook delivery attempt failed",
+			zap.Error(err),
+			zap.String("event", string(delivery.Event)),
+			zap.String("url", delivery.URL),
+			zap.Int("attempt", delivery.Attempts))
+	}
+
+	delivery.FinishedAt = time.Now().Unix()
+	if err := d.store.SaveWebhookDelivery(ctx, delivery); err != nil {
+		d.log.Error("failed to save webhook delivery", zap.Error(err), zap.String("delivery_id", delivery.ID))
+	}
+}
+
+func (d *dispatcher) post(ctx context.Context, subscription Subscription, delivery Delivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type memoryStore struct {
+	subscriptions []Subscription
+
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+func (s *memoryStore) GetWebhookSubscriptions(context.Context, Event) ([]Subscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *memoryStore) SaveWebhookDelivery(_ context.Context, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func TestDispatchSignsAndPostsPayload(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	store := &memoryStore{subscriptions: []Subscription{
+		{ID: "hook-1", URL: server.URL, Secret: "secret", Events: []Event{EventRequestCreated}, Active: true},
+		{ID: "hook-2", URL: server.URL, Secret: "secret", Events: []Event{EventRequestFailed}, Active: true},
+	}}
+	d := NewDispatcher(store, zap.NewNop())
+
+	d.Dispatch(context.Background(), EventRequestCreated, map[string]string{"name": "Discovery"})
+	d.Wait()
+
+	r := <-got
+	if r.header.Get(SignatureHeader) != Sign("secret", r.body) {
+		t.Errorf("unexpected signature %q", r.header.Get(SignatureHeader))
+	}
+	if r.header.Get(EventHeader) != string(EventRequestCreated) || r.header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", r.header)
+	}
+
+	var payload struct {
+		ID    string            `json:"id"`
+		Event Event             `json:"event"`
+		Data  map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(r.body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Event != EventRequestCreated || payload.Data["name"] != "Discovery" || payload.ID != r.header.Get(DeliveryHeader) {
+		t.Errorf("unexpected payload: %s", r.body)
+	}
+
+	if len(store.deliveries) != 1 || !store.deliveries[0].Delivered || store.deliveries[0].SubscriptionID != "hook-1" {
+		t.Errorf("expected one delivered entry for hook-1, got %+v", store.deliveries)
+	}
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	store := &memoryStore{subscriptions: []Subscription{
+		{ID: "hook-1", URL: server.URL, Events: []Event{EventRequestCompleted}, Active: true},
+	}}
+	d := NewDispatcher(store, zap.NewNop(), WithRetries(3, time.Millisecond))
+
+	d.Dispatch(context.Background(), EventRequestCompleted, nil)
+	d.Wait()
+
+	if len(store.deliveries) != 1 {
+		t.Fatalf("expected one delivery log entry, got %+v", store.deliveries)
+	}
+	if delivery := store.deliveries[0]; !delivery.Delivered || delivery.Attempts != 3 || delivery.StatusCode != http.StatusOK {
+		t.Errorf("expected delivery on the third attempt, got %+v", delivery)
+	}
+}
+
+func TestDispatchGivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{subscriptions: []Subscription{
+		{ID: "hook-1", URL: server.URL, Events: Events, Active: true},
+	}}
+	d := NewDispatcher(store, zap.NewNop(), WithRetries(2, time.Millisecond))
+
+	d.Dispatch(context.Background(), EventTrackDownloaded, nil)
+	d.Wait()
+
+	if delivery := store.deliveries[0]; delivery.Delivered || delivery.Attempts != 2 || delivery.StatusCode != http.StatusInternalServerError || delivery.Error == "" {
+		t.Errorf("expected a failed delivery after two attempts, got %+v", delivery)
+	}
+}
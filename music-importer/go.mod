@@ -0,0 +1,35 @@
+module github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/music-importer
+
+go 1.23.0
+
+toolchain go1.23.4
+
+replace github.com/supperdoggy/spot-models => ../models
+
+require (
+	github.com/Sorrow446/go-mp4tag v0.0.0-20240130220823-68ce31d53e37
+	github.com/bogem/id3v2 v1.2.0
+	github.com/go-flac/flacvorbis v0.2.0
+	github.com/go-flac/go-flac v1.0.0
+	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/satori/go.uuid v1.2.0
+	github.com/supperdoggy/spot-models v0.0.0
+	go.mongodb.org/mongo-driver v1.17.3
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/klauspost/compress v1.16.7 // indirect
+	github.com/montanaflynn/stats v0.7.1 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
+	github.com/zmb3/spotify/v2 v2.4.3 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/oauth2 v0.30.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+)
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/music-importer/pkg/config"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/music-importer/pkg/db"
+	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/music-importer/pkg/importer"
+	"go.uber.org/zap"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = log.Sync() }()
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	database, err := db.NewDatabase(ctx, log, cfg.DatabaseURL, cfg.DatabaseName)
+	if err != nil {
+		log.Fatal("Failed to create database connection", zap.Error(err))
+	}
+	defer func() {
+		if err := database.Close(context.Background()); err != nil {
+			log.Error("Failed to close database connection", zap.Error(err))
+		}
+	}()
+
+	log.Info("Watching inbox",
+		zap.String("inbox", cfg.InboxPath),
+		zap.String("library", cfg.MusicLibraryPath),
+		zap.Duration("interval", cfg.ScanInterval),
+	)
+
+	importer.NewImporter(database, log, cfg.InboxPath, cfg.MusicLibraryPath, cfg.ReportsPath, cfg.SettleTime).Run(ctx, cfg.ScanInterval)
+
+	log.Info("Shutdown complete")
+}
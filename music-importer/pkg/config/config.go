@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	DatabaseURL  string `envconfig:"DATABASE_URL" required:"true"`
+	DatabaseName string `envconfig:"DATABASE_NAME" required:"true"`
+
+	InboxPath        string `envconfig:"INBOX_PATH" required:"true"`
+	MusicLibraryPath string `envconfig:"MUSIC_LIBRARY_PATH" required:"true"`
+	// ReportsPath defaults to <INBOX_PATH>/.reports
+	ReportsPath string `envconfig:"REPORTS_PATH"`
+
+	ScanInterval time.Duration `envconfig:"SCAN_INTERVAL" default:"1m"`
+	// Files modified more recently than this are left for a later scan, they
+	// may still be copying
+	SettleTime time.Duration `envconfig:"SETTLE_TIME" default:"2m"`
+}
+
+func NewConfig() (*Config, error) {
+	cfg := new(Config)
+	err := envconfig.Process("", cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	models "github.com/supperdoggy/spot-models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type Database interface {
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	FindMusicFiles(ctx context.Context, artists []string, title string) ([]models.MusicFile, error)
+	IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error)
+	Close(ctx context.Context) error
+	Ping(ctx context.Context) error
+}
+
+type db struct {
+	conn *mongo.Client
+	log  *zap.Logger
+
+	// Collections
+	musicFilesCollection    *mongo.Collection
+	artistAliasesCollection *mongo.Collection
+}
+
+func NewDatabase(ctx context.Context, log *zap.Logger, url, dbname string) (Database, error) {
+	conn, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	// Verify connection
+	if err := conn.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	return &db{
+		conn: conn,
+		log:  log,
+
+		musicFilesCollection:    conn.Database(dbname).Collection("music-files"),
+		artistAliasesCollection: conn.Database(dbname).Collection("artist_aliases"),
+	}, nil
+}
+
+func (d *db) Close(ctx context.Context) error {
+	return d.conn.Disconnect(ctx)
+}
+
+func (d *db) Ping(ctx context.Context) error {
+	return d.conn.Ping(ctx, nil)
+}
+
+func (d *db) GetArtistAliases(ctx context.Context) (models.ArtistAliases, error) {
+	var aliases []models.ArtistAlias
+
+	cursor, err := d.artistAliasesCollection.Find(ctx, bson.M{"suggested": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find artist aliases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode artist aliases: %w", err)
+	}
+
+	return models.NewArtistAliases(aliases), nil
+}
+
+// FindMusicFiles returns the library files by any of the artists with the
+// title, both matched case-insensitively
+func (d *db) FindMusicFiles(ctx context.Context, artists []string, title string) ([]models.MusicFile, error) {
+	exact := func(s string) primitive.Regex {
+		return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(s) + "$", Options: "i"}
+	}
+
+	in := make(bson.A, 0, len(artists))
+	for _, artist := range artists {
+		in = append(in, exact(artist))
+	}
+
+	cursor, err := d.musicFilesCollection.Find(ctx, bson.M{
+		"artist": bson.M{"$in": in},
+		"title":  exact(title),
+	}, options.Find().SetProjection(bson.M{"meta_data": 0}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find music files: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	files := make([]models.MusicFile, 0)
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode music files: %w", err)
+	}
+
+	return files, nil
+}
+
+// IndexMusicFile adds a file that was put into the library to music-files
+func (d *db) IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error) {
+	now := time.Now().Unix()
+	file.ID = uuid.NewV4().String()
+	file.CreatedAt = now
+	file.UpdatedAt = now
+
+	if _, err := d.musicFilesCollection.InsertOne(ctx, file); err != nil {
+		return models.MusicFile{}, fmt.Errorf("failed to index music file: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,341 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/libraryfile"
+	"go.uber.org/zap"
+)
+
+const (
+	rejectedDir   = ".rejected"
+	duplicatesDir = ".duplicates"
+	reportsDir    = ".reports"
+)
+
+// Store is the part of the database the importer needs
+type Store interface {
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
+	FindMusicFiles(ctx context.Context, artists []string, title string) ([]models.MusicFile, error)
+	IndexMusicFile(ctx context.Context, file models.MusicFile) (models.MusicFile, error)
+}
+
+// Importer moves audio files dropped into the inbox into the library
+type Importer interface {
+	// Run imports the inbox every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+	// Import scans the inbox once
+	Import(ctx context.Context) (*Report, error)
+}
+
+type importer struct {
+	store   Store
+	log     *zap.Logger
+	inbox   string
+	library string
+	reports string
+	settle  time.Duration
+	now     func() time.Time
+}
+
+// NewImporter creates the importer. Reports go to reports, or to
+// <inbox>/.reports when it is empty. Files changed within settle of a scan are
+// left for the next one.
+func NewImporter(store Store, log *zap.Logger, inbox, library, reports string, settle time.Duration) Importer {
+	if reports == "" {
+		reports = filepath.Join(inbox, reportsDir)
+	}
+
+	return &importer{
+		store:   store,
+		log:     log,
+		inbox:   inbox,
+		library: library,
+		reports: reports,
+		settle:  settle,
+		now:     time.Now,
+	}
+}
+
+func (i *importer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := i.Import(ctx); err != nil {
+			i.log.Error("Failed to import inbox", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (i *importer) Import(ctx context.Context) (*Report, error) {
+	report := &Report{StartedAt: i.now()}
+
+	aliases, err := i.store.GetArtistAliases(ctx)
+	if err != nil {
+		i.log.Warn("Failed to load artist aliases, matching exact artists only", zap.Error(err))
+	}
+
+	paths, err := i.scan(report)
+	if err != nil {
+		return nil, err
+	}
+
+	// keys of the files imported on this scan, the library query can't see
+	// them until they are indexed
+	imported := make(map[string]string)
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		i.importFile(ctx, report, aliases, imported, path)
+	}
+
+	i.removeEmptyDirs()
+
+	report.FinishedAt = i.now()
+	if report.Empty() {
+		return report, nil
+	}
+
+	path, err := report.write(i.reports)
+	if err != nil {
+		return report, err
+	}
+
+	i.log.Info("Imported inbox",
+		zap.Int("imported", len(report.Imported)),
+		zap.Int("duplicates", len(report.Duplicates)),
+		zap.Int("rejected", len(report.Rejected)),
+		zap.Int("failed", len(report.Failed)),
+		zap.Int("pending", report.Pending),
+		zap.String("report", path),
+	)
+	return report, nil
+}
+
+// scan returns the audio files of the inbox that are ready to import, hidden
+// files and folders such as the ones files are moved aside to are skipped
+func (i *importer) scan(report *Report) ([]string, error) {
+	var paths []string
+
+	err := filepath.WalkDir(i.inbox, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == i.inbox {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !slices.Contains(Extensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if i.now().Sub(info.ModTime()) < i.settle {
+			report.Pending++
+			return nil
+		}
+
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan inbox: %w", err)
+	}
+
+	return paths, nil
+}
+
+func (i *importer) importFile(ctx context.Context, report *Report, aliases models.ArtistAliases, imported map[string]string, path string) {
+	ext := strings.ToLower(filepath.Ext(path))
+	entry := Entry{Source: i.rel(i.inbox, path)}
+
+	reject := func(reason string) {
+		entry.Reason = reason
+		entry.Destination = i.moveAside(path, rejectedDir)
+		report.Rejected = append(report.Rejected, entry)
+	}
+
+	if err := validate(path, ext); err != nil {
+		reject(err.Error())
+		return
+	}
+
+	tags, err := readTags(path, ext)
+	if err != nil {
+		reject(err.Error())
+		return
+	}
+	tags = fillFromFileName(tags, filepath.Base(path))
+	entry.Artist, entry.Title, entry.Album = tags.Artist, tags.Title, tags.Album
+
+	if tags.Artist == "" || tags.Title == "" {
+		reject("no artist or title in the tags or the file name")
+		return
+	}
+
+	destination := i.libraryPath(tags, ext)
+	duplicateOf, err := i.findDuplicate(ctx, aliases, imported, tags, destination)
+	if err != nil {
+		entry.Reason = err.Error()
+		report.Failed = append(report.Failed, entry)
+		return
+	}
+	if duplicateOf != "" {
+		entry.DuplicateOf = duplicateOf
+		entry.Destination = i.moveAside(path, duplicatesDir)
+		report.Duplicates = append(report.Duplicates, entry)
+		return
+	}
+
+	entry.Destination = i.rel(i.library, destination)
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		entry.Reason = err.Error()
+		report.Failed = append(report.Failed, entry)
+		return
+	}
+	if err := libraryfile.Move(path, destination); err != nil {
+		entry.Reason = err.Error()
+		report.Failed = append(report.Failed, entry)
+		return
+	}
+
+	var size int64
+	if info, err := os.Stat(destination); err == nil {
+		size = info.Size()
+	}
+
+	_, err = i.store.IndexMusicFile(ctx, models.MusicFile{
+		Artist:   tags.Artist,
+		Title:    tags.Title,
+		Album:    tags.Album,
+		Genre:    tags.Genre,
+		Path:     destination,
+		Size:     size,
+		MetaData: map[string]any{"source": "import", "inbox_path": entry.Source},
+	})
+	if err != nil {
+		entry.Reason = err.Error()
+		report.Failed = append(report.Failed, entry)
+		return
+	}
+
+	for _, key := range aliases.Keys(tags.Artist, tags.Title) {
+		imported[key] = destination
+	}
+	report.Imported = append(report.Imported, entry)
+}
+
+// findDuplicate returns the path of the library file, or the file imported
+// earlier on this scan, that has the matching key of tags, empty when there is
+// none
+func (i *importer) findDuplicate(ctx context.Context, aliases models.ArtistAliases, imported map[string]string, tags Tags, destination string) (string, error) {
+	keys := aliases.Keys(tags.Artist, tags.Title)
+	for _, key := range keys {
+		if path, ok := imported[key]; ok {
+			return path, nil
+		}
+	}
+
+	artists := aliases.Variants(tags.Artist)
+	files, err := i.store.FindMusicFiles(ctx, artists, tags.Title)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if slices.Contains(keys, aliases.Key(file.Artist, file.Title)) {
+			return file.Path, nil
+		}
+	}
+
+	// not indexed yet but already at its place in the library
+	if _, err := os.Stat(destination); err == nil {
+		return destination, nil
+	}
+
+	return "", nil
+}
+
+// libraryPath is <library>/<artist>/<album>/<artist> - <title>.<ext>, without
+// the album folder when there is no album
+func (i *importer) libraryPath(tags Tags, ext string) string {
+	parts := []string{i.library, libraryfile.CleanName(tags.Artist)}
+	if tags.Album != "" {
+		parts = append(parts, libraryfile.CleanName(tags.Album))
+	}
+	parts = append(parts, libraryfile.CleanName(tags.Artist+" - "+tags.Title)+ext)
+	return filepath.Join(parts...)
+}
+
+// moveAside moves an inbox file into one of the hidden inbox folders, keeping
+// its path inside the inbox, and returns where it went relative to the inbox
+func (i *importer) moveAside(path, dir string) string {
+	destination := filepath.Join(i.inbox, dir, i.rel(i.inbox, path))
+	if _, err := os.Stat(destination); err == nil {
+		ext := filepath.Ext(destination)
+		destination = fmt.Sprintf("%s.%d%s", strings.TrimSuffix(destination, ext), i.now().Unix(), ext)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		i.log.Error("Failed to create folder", zap.Error(err), zap.String("path", destination))
+		return i.rel(i.inbox, path)
+	}
+	if err := libraryfile.Move(path, destination); err != nil {
+		i.log.Error("Failed to move file aside", zap.Error(err), zap.String("path", path))
+		return i.rel(i.inbox, path)
+	}
+
+	return i.rel(i.inbox, destination)
+}
+
+// removeEmptyDirs removes the folders the import emptied, such as the album
+// folder of a rip, deepest first
+func (i *importer) removeEmptyDirs() {
+	var dirs []string
+	_ = filepath.WalkDir(i.inbox, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || path == i.inbox {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+
+	for j := len(dirs) - 1; j >= 0; j-- {
+		// Remove refuses folders that aren't empty
+		_ = os.Remove(dirs[j])
+	}
+}
+
+func (i *importer) rel(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bogem/id3v2"
+	models "github.com/supperdoggy/spot-models"
+	"go.uber.org/zap"
+)
+
+type fakeStore struct {
+	aliases []models.ArtistAlias
+	files   []models.MusicFile
+}
+
+func (f *fakeStore) GetArtistAliases(context.Context) (models.ArtistAliases, error) {
+	return models.NewArtistAliases(f.aliases), nil
+}
+
+func (f *fakeStore) FindMusicFiles(_ context.Context, artists []string, title string) ([]models.MusicFile, error) {
+	var result []models.MusicFile
+	for _, file := range f.files {
+		for _, artist := range artists {
+			if strings.EqualFold(file.Artist, artist) && strings.EqualFold(file.Title, title) {
+				result = append(result, file)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) IndexMusicFile(_ context.Context, file models.MusicFile) (models.MusicFile, error) {
+	f.files = append(f.files, file)
+	return file, nil
+}
+
+type testDirs struct {
+	inbox, library string
+}
+
+func newTestImporter(t *testing.T, store Store) (*importer, testDirs) {
+	t.Helper()
+	dirs := testDirs{inbox: t.TempDir(), library: t.TempDir()}
+	i := NewImporter(store, zap.NewNop(), dirs.inbox, dirs.library, "", time.Minute).(*importer)
+	// everything written by the test has settled
+	i.now = func() time.Time { return time.Now().Add(time.Hour) }
+	return i, dirs
+}
+
+func writeMP3(t *testing.T, path string, tags Tags) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the sync word of an MPEG frame followed by silence
+	if err := os.WriteFile(path, append([]byte{0xff, 0xfb, 0x90, 0x00}, make([]byte, 256)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tag.Close()
+	tag.SetArtist(tags.Artist)
+	tag.SetTitle(tags.Title)
+	tag.SetAlbum(tags.Album)
+	tag.SetGenre(tags.Genre)
+	if err := tag.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportMovesIndexesAndReports(t *testing.T) {
+	store := &fakeStore{}
+	i, dirs := newTestImporter(t, store)
+
+	writeMP3(t, filepath.Join(dirs.inbox, "Rip", "01 track.mp3"), Tags{Artist: "Artist", Title: "Song", Album: "Album", Genre: "Rock"})
+	writeFile(t, filepath.Join(dirs.inbox, "Rip", "cover.jpg"), "jpeg")
+	writeFile(t, filepath.Join(dirs.inbox, "Other - Untagged.ogg"), "OggS rest of the stream")
+
+	report, err := i.Import(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Imported) != 2 || len(report.Rejected) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	tagged := filepath.Join(dirs.library, "Artist", "Album", "Artist - Song.mp3")
+	untagged := filepath.Join(dirs.library, "Other", "Other - Untagged.ogg")
+	for _, path := range []string{tagged, untagged} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s in the library: %v", path, err)
+		}
+	}
+
+	if len(store.files) != 2 {
+		t.Fatalf("expected two indexed files, got %+v", store.files)
+	}
+	mp3 := store.files[1]
+	if mp3.Path != tagged || mp3.Album != "Album" || mp3.Genre != "Rock" || mp3.Size == 0 || mp3.MetaData["source"] != "import" {
+		t.Errorf("unexpected indexed file: %+v", mp3)
+	}
+
+	// the cover stays and so does the rip folder holding it
+	if _, err := os.Stat(filepath.Join(dirs.inbox, "Rip", "cover.jpg")); err != nil {
+		t.Errorf("expected non-audio files to be left alone: %v", err)
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(dirs.inbox, reportsDir, "import-*.json"))
+	if len(reports) != 1 {
+		t.Fatalf("expected one report, got %v", reports)
+	}
+	data, _ := os.ReadFile(reports[0])
+	var written Report
+	if err := json.Unmarshal(data, &written); err != nil || len(written.Imported) != 2 {
+		t.Fatalf("unexpected report file %s: %v", data, err)
+	}
+}
+
+func TestImportDetectsDuplicates(t *testing.T) {
+	store := &fakeStore{
+		aliases: []models.ArtistAlias{{Alias: "The Artist", Canonical: "Artist"}},
+		files:   []models.MusicFile{{Artist: "Artist", Title: "Known", Path: "/library/Artist/Artist - Known.mp3"}},
+	}
+	i, dirs := newTestImporter(t, store)
+
+	writeMP3(t, filepath.Join(dirs.inbox, "known.mp3"), Tags{Artist: "The Artist", Title: "known"})
+	writeMP3(t, filepath.Join(dirs.inbox, "a.mp3"), Tags{Artist: "Artist", Title: "New"})
+	writeMP3(t, filepath.Join(dirs.inbox, "b.mp3"), Tags{Artist: "Artist", Title: "New"})
+
+	report, err := i.Import(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Imported) != 1 || len(report.Duplicates) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := report.Duplicates[1].DuplicateOf; got != "/library/Artist/Artist - Known.mp3" {
+		t.Errorf("expected the alias to match the library file, got %q", got)
+	}
+	if got := report.Duplicates[0].DuplicateOf; got != filepath.Join(dirs.library, "Artist", "Artist - New.mp3") {
+		t.Errorf("expected the second copy to match the first, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dirs.inbox, duplicatesDir, "known.mp3")); err != nil {
+		t.Errorf("expected the duplicate to be moved aside: %v", err)
+	}
+}
+
+func TestImportRejectsAndWaits(t *testing.T) {
+	store := &fakeStore{}
+	i, dirs := newTestImporter(t, store)
+
+	writeFile(t, filepath.Join(dirs.inbox, "Album", "fake.flac"), "not a flac file")
+	writeFile(t, filepath.Join(dirs.inbox, "notags.wav"), "RIFF\x00\x00\x00\x00WAVE")
+
+	report, err := i.Import(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Rejected) != 2 || len(store.files) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(dirs.inbox, rejectedDir, "Album", "fake.flac")); err != nil {
+		t.Errorf("expected the rejected file to be moved aside: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dirs.inbox, "Album")); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied folder to be removed, got %v", err)
+	}
+
+	// a file that is still being copied
+	i.now = time.Now
+	writeMP3(t, filepath.Join(dirs.inbox, "fresh.mp3"), Tags{Artist: "Artist", Title: "Fresh"})
+
+	report, err = i.Import(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pending != 1 || !report.Empty() {
+		t.Fatalf("expected the fresh file to wait, got %+v", report)
+	}
+}
+
+func TestFillFromFileName(t *testing.T) {
+	tags := fillFromFileName(Tags{Title: "Tagged"}, "03 - Artist - Song.flac")
+	if tags.Artist != "Artist" || tags.Title != "Tagged" {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+
+	if tags := fillFromFileName(Tags{}, "Song.mp3"); tags.Artist != "" || tags.Title != "Song" {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
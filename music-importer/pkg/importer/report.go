@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Report is what one scan of the inbox did with each audio file, written as
+// JSON to the reports folder
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	Imported   []Entry `json:"imported"`
+	Duplicates []Entry `json:"duplicates"`
+	Rejected   []Entry `json:"rejected"`
+	// Failed are files that couldn't be moved or indexed, they stay where
+	// Destination says
+	Failed []Entry `json:"failed"`
+	// Pending counts the files left for a later scan because they changed
+	// within the settle time
+	Pending int `json:"pending"`
+}
+
+type Entry struct {
+	// Source is the path of the file relative to the inbox
+	Source string `json:"source"`
+	// Destination is where the file was moved: into the library for imported
+	// files, aside in the inbox for duplicates and rejected files
+	Destination string `json:"destination,omitempty"`
+
+	Artist string `json:"artist,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Album  string `json:"album,omitempty"`
+
+	// DuplicateOf is the library path of the file a duplicate matched
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Empty reports whether the scan found no file to process
+func (r *Report) Empty() bool {
+	return len(r.Imported)+len(r.Duplicates)+len(r.Rejected)+len(r.Failed) == 0
+}
+
+// write saves the report as import-<time>.json in dir and returns its path
+func (r *Report) write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports folder: %w", err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	path := filepath.Join(dir, "import-"+r.StartedAt.UTC().Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return path, nil
+}
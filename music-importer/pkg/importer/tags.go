@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mp4tag "github.com/Sorrow446/go-mp4tag"
+	"github.com/bogem/id3v2"
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+)
+
+// Extensions are the audio formats taken from the inbox, tags are read from
+// MP3, FLAC and M4A files
+var Extensions = []string{".mp3", ".flac", ".m4a", ".ogg", ".opus", ".wav"}
+
+var errNotAudio = errors.New("content doesn't match the extension")
+
+// Tags are the fields of a file's tags the library is organised by
+type Tags struct {
+	Artist string
+	Title  string
+	Album  string
+	Genre  string
+}
+
+// validate checks that the file starts the way its format does
+func validate(path, ext string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, 12)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	header = header[:n]
+
+	var ok bool
+	switch ext {
+	case ".mp3":
+		// an ID3v2 tag or the sync word of the first frame
+		ok = bytes.HasPrefix(header, []byte("ID3")) || (len(header) >= 2 && header[0] == 0xff && header[1]&0xe0 == 0xe0)
+	case ".flac":
+		ok = bytes.HasPrefix(header, []byte("fLaC"))
+	case ".m4a":
+		ok = len(header) >= 8 && string(header[4:8]) == "ftyp"
+	case ".ogg", ".opus":
+		ok = bytes.HasPrefix(header, []byte("OggS"))
+	case ".wav":
+		ok = len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE"
+	}
+
+	if !ok {
+		return errNotAudio
+	}
+	return nil
+}
+
+// readTags reads the tags of MP3, FLAC and M4A files, other formats have none
+func readTags(path, ext string) (Tags, error) {
+	switch ext {
+	case ".mp3":
+		return readMP3Tags(path)
+	case ".flac":
+		return readFLACTags(path)
+	case ".m4a":
+		return readM4ATags(path)
+	default:
+		return Tags{}, nil
+	}
+}
+
+func readMP3Tags(path string) (Tags, error) {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return Tags{}, fmt.Errorf("failed to open MP3 file: %w", err)
+	}
+	defer tag.Close()
+
+	return Tags{Artist: tag.Artist(), Title: tag.Title(), Album: tag.Album(), Genre: tag.Genre()}, nil
+}
+
+func readFLACTags(path string) (Tags, error) {
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		return Tags{}, fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	for _, meta := range f.Meta {
+		if meta.Type != flac.VorbisComment {
+			continue
+		}
+		comments, err := flacvorbis.ParseFromMetaDataBlock(*meta)
+		if err != nil {
+			return Tags{}, fmt.Errorf("failed to parse vorbis comment: %w", err)
+		}
+
+		first := func(field string) string {
+			values, err := comments.Get(field)
+			if err != nil || len(values) == 0 {
+				return ""
+			}
+			return values[0]
+		}
+		return Tags{
+			Artist: first(flacvorbis.FIELD_ARTIST),
+			Title:  first(flacvorbis.FIELD_TITLE),
+			Album:  first(flacvorbis.FIELD_ALBUM),
+			Genre:  first(flacvorbis.FIELD_GENRE),
+		}, nil
+	}
+
+	return Tags{}, nil
+}
+
+func readM4ATags(path string) (Tags, error) {
+	mp4, err := mp4tag.Open(path)
+	if err != nil {
+		return Tags{}, fmt.Errorf("failed to open M4A file: %w", err)
+	}
+	defer mp4.Close()
+
+	tags, err := mp4.Read()
+	if err != nil {
+		return Tags{}, fmt.Errorf("failed to read M4A tags: %w", err)
+	}
+
+	// the predefined iTunes genres are only numbered, the free text one is
+	// what taggers write
+	return Tags{Artist: tags.Artist, Title: tags.Title, Album: tags.Album, Genre: tags.CustomGenre}, nil
+}
+
+// fillFromFileName takes what the tags are missing from an "Artist - Title"
+// file name, a leading track number is dropped
+func fillFromFileName(tags Tags, fileName string) Tags {
+	stem := strings.TrimSpace(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	stem = strings.TrimLeft(stem, "0123456789 .-_")
+
+	artist, title, found := strings.Cut(stem, " - ")
+	if !found {
+		artist, title = "", stem
+	}
+
+	tags.Artist = strings.TrimSpace(tags.Artist)
+	tags.Title = strings.TrimSpace(tags.Title)
+	tags.Album = strings.TrimSpace(tags.Album)
+	tags.Genre = strings.TrimSpace(tags.Genre)
+	if tags.Artist == "" {
+		tags.Artist = strings.TrimSpace(artist)
+	}
+	if tags.Title == "" {
+		tags.Title = strings.TrimSpace(title)
+	}
+	return tags
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/spotdl-wapper/pkg/db"
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/spotdl-wapper/pkg/loki"
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/spotdl-wapper/pkg/service"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -42,12 +44,19 @@ func run() {
 
 	log.Info("connected to database")
 
-	srv := service.NewService(database, log, spotifyService, cfg.Destination, cfg.MusicLibraryPath, cfg.SleepInMinutes)
+	sourceProvider := source.NewYtDlpProvider(log, source.WithBinary(cfg.YtDlpPath))
+
+	webhooks := webhook.NewDispatcher(database, log)
+
+	srv := service.NewService(database, log, spotifyService, sourceProvider, webhooks, cfg.Destination, cfg.MusicLibraryPath, cfg.SleepInMinutes)
 
 	if err := srv.StartProcessing(ctx); err != nil {
 		log.Fatal("failed to start processing", zap.Error(err))
 	}
 
+	// let the webhooks of this run finish their retries before exiting
+	webhooks.Wait()
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Error("recovered from panic", zap.Any("panic", r))
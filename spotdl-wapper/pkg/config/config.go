@@ -21,6 +21,7 @@ type Config struct {
 	Destination      string `envconfig:"DESTINATION" required:"true"`
 	MusicLibraryPath string `envconfig:"MUSIC_LIBRARY_PATH" required:"true"`
 	SleepInMinutes   int    `envconfig:"SLEEP_IN_MINUTES" required:"true"`
+	YtDlpPath        string `envconfig:"YT_DLP_PATH" default:"yt-dlp"`
 }
 
 func NewConfig() (*Config, error) {
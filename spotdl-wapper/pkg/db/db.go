@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	models "github.com/supperdoggy/spot-models"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -21,15 +23,22 @@ type Database interface {
 	CheckIfRequestAlreadySynced(ctx context.Context, url string) (bool, error)
 	NewDownloadRequest(ctx context.Context, url, name string, creatorID int64, objectType spotify.SpotifyObjectType) error
 	UpdateActiveRequest(ctx context.Context, request models.DownloadQueueRequest) error
+	FinishRequestSync(ctx context.Context, id string, errored bool) (models.DownloadQueueRequest, error)
+	CompleteRequest(ctx context.Context, id string) error
 
 	GetActivePlaylists(ctx context.Context) ([]models.PlaylistRequest, error)
 	UpdatePlaylistRequest(ctx context.Context, request models.PlaylistRequest) error
 
 	FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error)
+	GetArtistAliases(ctx context.Context) (models.ArtistAliases, error)
 	IndexMusicFile(ctx context.Context, file models.MusicFile) error
 
 	GetIndexStatus(ctx context.Context) (models.IndexStatus, error)
 	UpdateIndexStatus(ctx context.Context, status models.IndexStatus) error
+
+	NewNotification(ctx context.Context, notification models.Notification) error
+
+	webhook.Store
 }
 
 type db struct {
@@ -128,26 +137,31 @@ func (d *db) GetActivePlaylists(ctx context.Context) ([]models.PlaylistRequest,
 	return requests, nil
 }
 
+// UpdatePlaylistRequest saves the state of a processed playlist request,
+// updated_at is when /history shows it finished
 func (d *db) UpdatePlaylistRequest(ctx context.Context, request models.PlaylistRequest) error {
 	info, err := d.playlistsCollection().UpdateOne(ctx, bson.M{"_id": request.ID}, bson.M{"$set": bson.M{
 		"active":      request.Active,
 		"errored":     request.Errored,
 		"retry_count": request.RetryCount,
+		"updated_at":  time.Now().Unix(),
 	}})
+	if err != nil {
+		return err
+	}
 
 	if info.MatchedCount == 0 {
 		return errors.New("not found")
 	}
 
-	return err
+	return nil
 }
 
+// UpdateActiveRequest saves the download progress of an active request. Its
+// state and counters are left alone, the bot cancels and retries requests
+// while they are downloaded, see FinishRequestSync and CompleteRequest.
 func (d *db) UpdateActiveRequest(ctx context.Context, request models.DownloadQueueRequest) error {
-	info, err := d.downloadQueueRequestCollection().UpdateOne(ctx, bson.M{"_id": request.ID}, bson.M{"$set": bson.M{
-		"active":               request.Active,
-		"sync_count":           request.SyncCount,
-		"errored":              request.Errored,
-		"retry_count":          request.RetryCount,
+	info, err := d.downloadQueueRequestCollection().UpdateOne(ctx, bson.M{"_id": request.ID, "active": true}, bson.M{"$set": bson.M{
 		"expected_track_count": request.ExpectedTrackCount,
 		"found_track_count":    request.FoundTrackCount,
 		"track_metadata":       request.TrackMetadata,
@@ -164,6 +178,48 @@ func (d *db) UpdateActiveRequest(ctx context.Context, request models.DownloadQue
 	return nil
 }
 
+// FinishRequestSync counts a sync of an active request, and a retry when it
+// errored, on top of the stored counters and returns the request as stored.
+// It returns mongo.ErrNoDocuments when the request is no longer active.
+func (d *db) FinishRequestSync(ctx context.Context, id string, errored bool) (models.DownloadQueueRequest, error) {
+	update := bson.M{"$inc": bson.M{"sync_count": 1}}
+	if errored {
+		update = bson.M{
+			"$inc": bson.M{"sync_count": 1, "retry_count": 1},
+			"$set": bson.M{"errored": true},
+		}
+	}
+
+	var request models.DownloadQueueRequest
+	err := d.downloadQueueRequestCollection().FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "active": true},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&request)
+	if err != nil {
+		return models.DownloadQueueRequest{}, err
+	}
+
+	return request, nil
+}
+
+// CompleteRequest deactivates a request that is still active, it returns
+// mongo.ErrNoDocuments when the bot cancelled it in the meantime
+func (d *db) CompleteRequest(ctx context.Context, id string) error {
+	info, err := d.downloadQueueRequestCollection().UpdateOne(ctx,
+		bson.M{"_id": id, "active": true},
+		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if info.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 // IndexMusicFile indexes a music file in the database
 func (d *db) IndexMusicFile(ctx context.Context, file models.MusicFile) error {
 	file.ID = uuid.Must(uuid.NewV4()).String()
@@ -197,6 +253,47 @@ func (d *db) reconnectToDB() error {
 	return nil
 }
 
+// NewNotification adds a notification to the outbox that album-queue delivers
+func (d *db) NewNotification(ctx context.Context, notification models.Notification) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	notification.ID = id.String()
+	notification.CreatedAt = time.Now().Unix()
+	notification.SentAt = 0
+
+	_, err = d.notificationsCollection().InsertOne(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhookSubscriptions returns the active subscriptions to the event,
+// they are managed from album-queue
+func (d *db) GetWebhookSubscriptions(ctx context.Context, event webhook.Event) ([]webhook.Subscription, error) {
+	cur, err := d.webhookSubscriptionsCollection().Find(ctx, bson.M{"active": true, "events": event})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var subscriptions []webhook.Subscription
+	if err := cur.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (d *db) SaveWebhookDelivery(ctx context.Context, delivery webhook.Delivery) error {
+	_, err := d.webhookDeliveriesCollection().InsertOne(ctx, delivery)
+	return err
+}
+
 // Collections
 
 // downloadQueueRequestCollection returns the download queue request collection
@@ -244,22 +341,92 @@ func (d *db) musicFilesCollection() *mongo.Collection {
 	return d.conn.Database(d.dbname).Collection("music-files")
 }
 
+func (d *db) artistAliasesCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+
+	return d.conn.Database(d.dbname).Collection("artist_aliases")
+}
+
+func (d *db) notificationsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+
+	return d.conn.Database(d.dbname).Collection("notifications")
+}
+
+func (d *db) webhookSubscriptionsCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+
+	return d.conn.Database(d.dbname).Collection("webhook_subscriptions")
+}
+
+func (d *db) webhookDeliveriesCollection() *mongo.Collection {
+	if err := d.conn.Ping(context.Background(), nil); err != nil {
+		d.log.Error("failed to ping database. reconnecting.", zap.Error(err))
+		if reconnectErr := d.reconnectToDB(); reconnectErr != nil {
+			d.log.Error("failed to reconnect to database", zap.Error(reconnectErr))
+		}
+	}
+
+	return d.conn.Database(d.dbname).Collection("webhook_deliveries")
+}
+
 // escapeRegex escapes special regex characters in a string
 func escapeRegex(s string) string {
 	return regexp.QuoteMeta(s)
 }
 
+// GetArtistAliases loads the confirmed artist aliases
+func (d *db) GetArtistAliases(ctx context.Context) (models.ArtistAliases, error) {
+	cur, err := d.artistAliasesCollection().Find(ctx, bson.M{"suggested": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var aliases []models.ArtistAlias
+	if err := cur.All(ctx, &aliases); err != nil {
+		return nil, err
+	}
+
+	return models.NewArtistAliases(aliases), nil
+}
+
 func (d *db) FindMusicFiles(ctx context.Context, artists, titles []string) ([]models.MusicFile, error) {
+	aliases, err := d.GetArtistAliases(ctx)
+	if err != nil {
+		d.log.Warn("failed to load artist aliases, matching exact artists only", zap.Error(err))
+	}
+
 	orPairs := make([]bson.M, 0, len(artists))
 	for i := range artists {
 		// Use case-insensitive regex matching for both artist and title
 		// This handles cases where database stores original case but we query with lowercase
 		// Escape special regex characters to ensure exact matching
-		escapedArtist := escapeRegex(artists[i])
+		// Every known spelling of the artist is accepted
+		variants := aliases.Variants(artists[i])
+		escapedArtists := make([]string, 0, len(variants))
+		for _, variant := range variants {
+			escapedArtists = append(escapedArtists, escapeRegex(variant))
+		}
 		escapedTitle := escapeRegex(titles[i])
 		orPairs = append(orPairs, bson.M{
 			"$and": []bson.M{
-				{"artist": bson.M{"$regex": "^" + escapedArtist + "$", "$options": "i"}},
+				{"artist": bson.M{"$regex": "^(?:" + strings.Join(escapedArtists, "|") + ")$", "$options": "i"}},
 				{"title": bson.M{"$regex": "^" + escapedTitle + "$", "$options": "i"}},
 			},
 		})
@@ -3,15 +3,19 @@ package service
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os/exec"
+	"slices"
 	"sort"
-	"strings"
 	"syscall"
 	"time"
 
 	models "github.com/supperdoggy/spot-models"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
@@ -29,8 +33,11 @@ func (s *service) ProcessDownloadRequest(ctx context.Context) error {
 
 	s.log.Info("processing active requests", zap.Any("requests", len(active)))
 
-	// sort active by errored so errored requests are processed last
+	// sort active by priority, then errored so errored requests are processed last
 	sort.Slice(active, func(i, j int) bool {
+		if active[i].Priority != active[j].Priority {
+			return active[i].Priority > active[j].Priority
+		}
 		if active[i].Errored && !active[j].Errored {
 			return false
 		}
@@ -45,40 +52,60 @@ func (s *service) ProcessDownloadRequest(ctx context.Context) error {
 	for _, request := range active {
 		time.Sleep(time.Duration(s.sleepInMinutes) * time.Minute)
 
-		request.SyncCount++
+		// The bot may have cancelled or retried the request while we slept
+		current, err := s.database.GetActiveRequest(ctx, request.SpotifyURL)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			s.log.Info("request is no longer active, skipping", zap.String("request_id", request.ID))
+			continue
+		}
+		if err != nil {
+			s.log.Error("failed to re-fetch request", zap.Error(err), zap.String("request_id", request.ID))
+			continue
+		}
+		request = current
+
+		errored := false
 		if err := s.ProcessRequest(ctx, request); err != nil {
 			s.log.Error("failed to process request", zap.Error(err), zap.Any("request", request))
-			request.Errored = true
-			request.RetryCount++
-			s.log.Warn("request processing encountered an error", zap.Any("request", request))
+			errored = true
 		}
 
-		// Re-fetch request to get updated track metadata (Found/Skipped status)
-		updatedRequest, err := s.database.GetActiveRequest(ctx, request.SpotifyURL)
+		// Returns the stored request with the track metadata (Found/Skipped status)
+		// of this sync and the counters of the bot
+		synced, err := s.database.FinishRequestSync(ctx, request.ID, errored)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			s.log.Info("request was cancelled while processing", zap.String("request_id", request.ID))
+			continue
+		}
 		if err != nil {
-			s.log.Error("failed to re-fetch request", zap.Error(err))
-		} else {
-			request.TrackMetadata = updatedRequest.TrackMetadata
-			request.FoundTrackCount = updatedRequest.FoundTrackCount
+			s.log.Error("failed to update request", zap.Error(err), zap.String("request_id", request.ID))
+			continue
 		}
+		request = synced
 
-		// Check if all non-skipped tracks are found (early completion)
-		if s.isRequestComplete(request) {
+		s.log.Info("updated request status", zap.Any("request", request))
+
+		// Check if all non-skipped tracks are found (early completion),
+		// otherwise deactivate after max sync attempts
+		complete := s.isRequestComplete(request)
+		if !complete && request.SyncCount < 3 {
+			continue
+		}
+		if complete {
 			s.log.Info("all non-skipped tracks found, marking request as complete",
 				zap.String("request_id", request.ID))
-			request.Active = false
 		}
 
-		// Fallback: deactivate after max sync attempts
-		if request.SyncCount >= 3 {
-			request.Active = false
+		if err := s.database.CompleteRequest(ctx, request.ID); err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				s.log.Error("failed to complete request", zap.Error(err), zap.String("request_id", request.ID))
+			}
+			continue
 		}
 
-		s.log.Info("updated request status", zap.Any("request", request))
-
-		if err := s.database.UpdateActiveRequest(ctx, request); err != nil {
-			s.log.Error("failed to update request", zap.Error(err), zap.Any("request", request))
-		}
+		request.Active = false
+		s.notify(ctx, models.NewDownloadNotification(request))
+		s.requestFinished(ctx, request)
 	}
 
 	indexStatus, err := s.database.GetIndexStatus(ctx)
@@ -105,10 +132,15 @@ func (s *service) ProcessRequest(ctx context.Context, request models.DownloadQue
 		}
 	}()
 
+	resolver := s.resolverFor(request.Source)
+	if resolver == nil {
+		return fmt.Errorf("no provider for source %q", request.Source)
+	}
+
 	// Fetch track count and metadata if not already set
 	if request.ExpectedTrackCount == 0 || len(request.TrackMetadata) == 0 {
 		s.log.Info("fetching track count and metadata", zap.String("url", request.SpotifyURL))
-		trackCount, trackMetadata, err := s.spotifyService.GetTrackCount(ctx, request.SpotifyURL)
+		trackCount, trackMetadata, err := resolver.GetTrackCount(ctx, request.SpotifyURL)
 		if err != nil {
 			s.log.Error("failed to get track count", zap.Error(err), zap.String("url", request.SpotifyURL))
 			// Continue anyway, we'll just not have progress tracking
@@ -128,7 +160,7 @@ func (s *service) ProcessRequest(ctx context.Context, request models.DownloadQue
 	objectType := request.ObjectType
 	if objectType == "" {
 		var err error
-		objectType, err = s.spotifyService.GetObjectType(ctx, request.SpotifyURL)
+		objectType, err = resolver.GetObjectType(ctx, request.SpotifyURL)
 		if err != nil {
 			s.log.Error("failed to get object type", zap.Error(err), zap.String("url", request.SpotifyURL))
 			// Fall back to old behavior if we can't determine type
@@ -143,8 +175,13 @@ func (s *service) ProcessRequest(ctx context.Context, request models.DownloadQue
 		}
 	}
 
-	if objectType == spotify.SpotifyObjectTypePlaylist && len(request.TrackMetadata) > 0 {
-		// For playlists: pre-check DB and download missing tracks individually
+	// Other sources are downloaded by yt-dlp as a whole, it skips files that already exist
+	if !request.Source.IsSpotify() {
+		return s.processSourceDownload(ctx, request)
+	}
+
+	if (objectType == spotify.SpotifyObjectTypePlaylist || request.TracksOnly) && len(request.TrackMetadata) > 0 {
+		// For playlists and picked tracks: pre-check DB and download missing tracks individually
 		return s.processPlaylistRequest(ctx, request)
 	}
 
@@ -202,6 +239,8 @@ func (s *service) processPlaylistRequest(ctx context.Context, request models.Dow
 			if err := s.checkSingleTrackInDB(ctx, track); err != nil {
 				s.log.Error("failed to check track in database after download", zap.Error(err))
 				// Don't mark as found if check fails, will retry next sync
+			} else if track.Found {
+				s.trackDownloaded(ctx, request, *track)
 			}
 		}
 
@@ -277,6 +316,29 @@ func (s *service) processBulkDownload(ctx context.Context, request models.Downlo
 	return nil
 }
 
+// processSourceDownload handles requests from YouTube Music, SoundCloud and Bandcamp
+func (s *service) processSourceDownload(ctx context.Context, request models.DownloadQueueRequest) error {
+	s.log.Info("processing source download request",
+		zap.String("url", request.SpotifyURL),
+		zap.String("source", string(request.Source)))
+
+	if s.sourceProvider == nil {
+		return fmt.Errorf("no provider for source %q", request.Source)
+	}
+
+	if err := s.sourceProvider.Download(ctx, request.SpotifyURL, s.destination); err != nil {
+		return err
+	}
+
+	if request.ExpectedTrackCount > 0 && len(request.TrackMetadata) > 0 {
+		if err := s.UpdateFoundTrackCount(ctx, request); err != nil {
+			s.log.Error("failed to update found track count", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
 // preCheckTracksInDB checks which tracks already exist in the database and marks them as Found
 func (s *service) preCheckTracksInDB(ctx context.Context, request *models.DownloadQueueRequest) error {
 	if len(request.TrackMetadata) == 0 {
@@ -297,19 +359,18 @@ func (s *service) preCheckTracksInDB(ctx context.Context, request *models.Downlo
 		return err
 	}
 
-	// Create a map for quick lookup
+	// Create a map for quick lookup, resolving artist aliases
+	aliases := s.artistAliases(ctx)
 	foundMap := make(map[string]bool)
 	for _, music := range foundMusic {
-		key := strings.ToLower(music.Artist) + " " + strings.ToLower(music.Title)
-		foundMap[key] = true
+		foundMap[aliases.Key(music.Artist, music.Title)] = true
 	}
 
 	// Mark tracks that already exist as Found
 	foundCount := 0
 	for i := range request.TrackMetadata {
 		track := &request.TrackMetadata[i]
-		key := strings.ToLower(track.Artist) + " " + strings.ToLower(track.Title)
-		if foundMap[key] {
+		if foundAny(foundMap, aliases.Keys(track.Artist, track.Title)) {
 			track.Found = true
 			track.FailedAttempts = 0
 			foundCount++
@@ -337,10 +398,10 @@ func (s *service) checkSingleTrackInDB(ctx context.Context, track *spotify.Track
 	}
 
 	// Check if we found a match
+	aliases := s.artistAliases(ctx)
+	trackKeys := aliases.Keys(track.Artist, track.Title)
 	for _, music := range foundMusic {
-		key := strings.ToLower(music.Artist) + " " + strings.ToLower(music.Title)
-		trackKey := strings.ToLower(track.Artist) + " " + strings.ToLower(track.Title)
-		if key == trackKey {
+		if slices.Contains(trackKeys, aliases.Key(music.Artist, music.Title)) {
 			track.Found = true
 			track.FailedAttempts = 0
 			return nil
@@ -373,16 +434,17 @@ func (s *service) UpdateFoundTrackCount(ctx context.Context, request models.Down
 		return err
 	}
 
-	// Create a map for quick lookup
+	// Create a map for quick lookup, resolving artist aliases
+	aliases := s.artistAliases(ctx)
 	foundMap := make(map[string]bool)
 	for _, music := range foundMusic {
-		key := strings.ToLower(music.Artist) + " " + strings.ToLower(music.Title)
-		foundMap[key] = true
+		foundMap[aliases.Key(music.Artist, music.Title)] = true
 	}
 
 	// Update individual track status
 	foundCount := 0
 	skippedCount := 0
+	var downloaded []spotify.TrackMetadata
 	for i := range request.TrackMetadata {
 		track := &request.TrackMetadata[i]
 
@@ -392,8 +454,10 @@ func (s *service) UpdateFoundTrackCount(ctx context.Context, request models.Down
 			continue
 		}
 
-		key := strings.ToLower(track.Artist) + " " + strings.ToLower(track.Title)
-		if foundMap[key] {
+		if foundAny(foundMap, aliases.Keys(track.Artist, track.Title)) {
+			if !track.Found {
+				downloaded = append(downloaded, *track)
+			}
 			track.Found = true
 			track.FailedAttempts = 0 // reset on success
 			foundCount++
@@ -421,6 +485,10 @@ func (s *service) UpdateFoundTrackCount(ctx context.Context, request models.Down
 		return err
 	}
 
+	for _, track := range downloaded {
+		s.trackDownloaded(ctx, request, track)
+	}
+
 	// Calculate effective expected count (excluding skipped)
 	effectiveExpected := request.ExpectedTrackCount - skippedCount
 
@@ -435,6 +503,18 @@ func (s *service) UpdateFoundTrackCount(ctx context.Context, request models.Down
 	return nil
 }
 
+// trackDownloaded sends track.downloaded for a track of the request that
+// showed up in the library
+func (s *service) trackDownloaded(ctx context.Context, request models.DownloadQueueRequest, track spotify.TrackMetadata) {
+	s.webhooks.Dispatch(ctx, webhook.EventTrackDownloaded, webhook.TrackData{
+		RequestID:   request.ID,
+		RequestName: request.Name,
+		URL:         track.SpotifyURL,
+		Artist:      track.Artist,
+		Title:       track.Title,
+	})
+}
+
 // isRequestComplete checks if all non-skipped tracks have been found
 func (s *service) isRequestComplete(request models.DownloadQueueRequest) bool {
 	if len(request.TrackMetadata) == 0 {
@@ -505,3 +585,13 @@ func (s *service) DownloadSingleTrack(ctx context.Context, trackURL string) erro
 
 	return nil
 }
+
+// foundAny reports whether any of the matching keys is present in foundMap
+func foundAny(foundMap map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if foundMap[key] {
+			return true
+		}
+	}
+	return false
+}
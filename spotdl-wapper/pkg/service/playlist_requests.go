@@ -18,6 +18,14 @@ var (
 	ErrMissingFiles = errors.New("missing files")
 )
 
+// playlistResult describes the M3U written for a playlist request
+type playlistResult struct {
+	name    string
+	path    string
+	found   int
+	missing int
+}
+
 func (s *service) ProcessPlaylistRequest(ctx context.Context) error {
 
 	indexStatus, err := s.database.GetIndexStatus(ctx)
@@ -40,7 +48,8 @@ func (s *service) ProcessPlaylistRequest(ctx context.Context) error {
 	s.log.Info("processing active playlists", zap.Any("playlists", len(playlists)))
 
 	for _, playlist := range playlists {
-		if err := s.ProcessPlaylist(ctx, playlist); err != nil {
+		result, err := s.ProcessPlaylist(ctx, playlist)
+		if err != nil {
 			s.log.Error("failed to process playlist", zap.Error(err), zap.Any("playlist", playlist))
 			playlist.Errored = true
 			playlist.RetryCount++
@@ -54,6 +63,20 @@ func (s *service) ProcessPlaylistRequest(ctx context.Context) error {
 
 		if err := s.database.UpdatePlaylistRequest(ctx, playlist); err != nil {
 			s.log.Error("failed to update playlist", zap.Error(err), zap.Any("playlist", playlist))
+			continue
+		}
+
+		if result.path != "" {
+			s.notify(ctx, models.Notification{
+				UserID:       playlist.CreatorID,
+				Kind:         models.NotificationPlaylistDone,
+				RequestID:    playlist.ID,
+				Name:         result.name,
+				URL:          playlist.SpotifyURL,
+				Found:        result.found,
+				Missing:      result.missing,
+				PlaylistPath: result.path,
+			})
 		}
 	}
 
@@ -61,7 +84,7 @@ func (s *service) ProcessPlaylistRequest(ctx context.Context) error {
 	return nil
 }
 
-func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistRequest) error {
+func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistRequest) (playlistResult, error) {
 	s.log.Info("processing playlist", zap.Any("playlist", playlist))
 
 	// checking if playlist is ready to be processed
@@ -69,24 +92,24 @@ func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistR
 	downloadRequest, err := s.database.GetActiveRequest(ctx, playlist.SpotifyURL)
 	if err != nil && err != mongo.ErrNoDocuments {
 		s.log.Error("failed to get active request", zap.Error(err))
-		return err
+		return playlistResult{}, err
 	}
 
 	if downloadRequest.Active {
 		s.log.Info("download request is still active, will continue to process playlist once done", zap.Any("playlist", playlist))
-		return ErrMissingFiles
+		return playlistResult{}, ErrMissingFiles
 	}
 
 	playlistName, err := s.spotifyService.GetObjectName(ctx, playlist.SpotifyURL)
 	if err != nil {
 		s.log.Error("failed to get playlist name", zap.Error(err))
-		return err
+		return playlistResult{}, err
 	}
 
 	songList, err := s.spotifyService.GetPlaylistTracks(ctx, playlist.SpotifyURL)
 	if err != nil {
 		s.log.Error("failed to get playlist data", zap.Error(err))
-		return err
+		return playlistResult{}, err
 	}
 
 	artists := []string{}
@@ -108,22 +131,19 @@ func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistR
 	foundMusic, err := s.database.FindMusicFiles(ctx, artists, titles)
 	if err != nil {
 		s.log.Error("failed to find music file paths", zap.Error(err))
-		return err
+		return playlistResult{}, err
 	}
 
 	if len(foundMusic) == 0 {
 		s.log.Error("no indexed paths found for playlist", zap.Any("playlistName", playlistName))
-		return errors.New("no indexed paths found for playlist")
+		return playlistResult{}, errors.New("no indexed paths found for playlist")
 	}
 
+	aliases := s.artistAliases(ctx)
 	foundMusicMap := make(map[string]models.MusicFile)
 	for _, music := range foundMusic {
-		// Normalize to lowercase for consistent matching
-		// Handle both single artist and comma-separated formats
-		normalizedArtist := strings.ToLower(music.Artist)
-		normalizedTitle := strings.ToLower(music.Title)
-		key := normalizedArtist + " " + normalizedTitle
-		foundMusicMap[key] = music
+		// Key by canonical artist so alternate spellings in the library still match
+		foundMusicMap[aliases.Key(music.Artist, music.Title)] = music
 	}
 
 	missingMusicFiles := []spotifyapi.PlaylistItem{}
@@ -142,31 +162,21 @@ func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistR
 
 		songName := strings.ToLower(song.Track.Track.Name)
 
-		key := artist + " " + songName
-
-		// Also try with single artist format (first artist) in case database stores it that way
-		var singleArtistKey string
-		if len(artists) > 0 {
-			singleArtistKey = artists[0] + " " + songName
-		}
-
 		var foundFile models.MusicFile
 		var found bool
 
-		// Try comma-separated artist format first
-		if file, ok := foundMusicMap[key]; ok {
-			foundFile = file
-			found = true
-		} else if singleArtistKey != "" {
-			// Try single artist format (database might store only first artist)
-			if file, ok := foundMusicMap[singleArtistKey]; ok {
+		// Try the full artist list first, then the first artist only in case
+		// the database stores it that way
+		for _, key := range aliases.Keys(artist, songName) {
+			if file, ok := foundMusicMap[key]; ok {
 				foundFile = file
 				found = true
+				break
 			}
 		}
 
 		if !found {
-			s.log.Error("song not found in indexed paths", zap.Any("artist", artist), zap.Any("songName", songName), zap.Any("singleArtist", singleArtistKey))
+			s.log.Error("song not found in indexed paths", zap.Any("artist", artist), zap.Any("songName", songName))
 			missingMusicFiles = append(missingMusicFiles, song)
 			// return errors.New("song not found in indexed paths")
 			continue
@@ -220,7 +230,7 @@ func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistR
 
 		if createdCount > 0 {
 			s.log.Info("created download requests for missing tracks", zap.Int("count", createdCount), zap.Int("total_missing", len(missingMusicFiles)))
-			return ErrMissingFiles
+			return playlistResult{}, ErrMissingFiles
 		} else {
 			s.log.Info("all missing tracks already synced or failed to create requests", zap.Int("total_missing", len(missingMusicFiles)))
 		}
@@ -237,10 +247,15 @@ func (s *service) ProcessPlaylist(ctx context.Context, playlist models.PlaylistR
 
 	if err := utils.CreateM3UPlaylist(indexedPaths, s.libraryPath, outputPath); err != nil {
 		s.log.Error("failed to create m3u playlist", zap.Error(err))
-		return err
+		return playlistResult{}, err
 	}
 
 	s.log.Info("created m3u playlist", zap.Any("outputPath", outputPath))
 
-	return nil
+	return playlistResult{
+		name:    playlistName,
+		path:    outputPath,
+		found:   len(indexedPaths),
+		missing: len(missingMusicFiles),
+	}, nil
 }
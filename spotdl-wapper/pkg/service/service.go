@@ -5,7 +5,10 @@ import (
 	"errors"
 
 	"github.com/supperdoggy/SmartHomeServer/harmoniq-maestro/spotdl-wapper/pkg/db"
+	models "github.com/supperdoggy/spot-models"
+	"github.com/supperdoggy/spot-models/source"
 	"github.com/supperdoggy/spot-models/spotify"
+	"github.com/supperdoggy/spot-models/webhook"
 	"go.uber.org/zap"
 )
 
@@ -17,17 +20,21 @@ type service struct {
 	database       db.Database
 	log            *zap.Logger
 	spotifyService spotify.SpotifyService
+	sourceProvider source.Provider
+	webhooks       webhook.Dispatcher
 
 	destination    string
 	sleepInMinutes int
 	libraryPath    string
 }
 
-func NewService(database db.Database, log *zap.Logger, spotifyService spotify.SpotifyService, destination, libraryPath string, sleepInMinutes int) Service {
+func NewService(database db.Database, log *zap.Logger, spotifyService spotify.SpotifyService, sourceProvider source.Provider, webhooks webhook.Dispatcher, destination, libraryPath string, sleepInMinutes int) Service {
 	return &service{
 		database:       database,
 		log:            log,
 		spotifyService: spotifyService,
+		sourceProvider: sourceProvider,
+		webhooks:       webhooks,
 		destination:    destination,
 		sleepInMinutes: sleepInMinutes,
 		libraryPath:    libraryPath,
@@ -42,3 +49,43 @@ func (s *service) StartProcessing(ctx context.Context) error {
 
 	return errors.Join(downloadError, playlistError)
 }
+
+// artistAliases loads the artist alias table. Matching still works without it,
+// so a failure is only logged.
+func (s *service) artistAliases(ctx context.Context) models.ArtistAliases {
+	aliases, err := s.database.GetArtistAliases(ctx)
+	if err != nil {
+		s.log.Warn("failed to load artist aliases", zap.Error(err))
+	}
+	return aliases
+}
+
+// resolverFor returns the resolver for requests of the given source
+func (s *service) resolverFor(src source.Source) source.Resolver {
+	if src.IsSpotify() {
+		return s.spotifyService
+	}
+	return s.sourceProvider
+}
+
+// notify queues a notification for the requester. Requests created by the
+// workers themselves have no creator and are skipped.
+func (s *service) notify(ctx context.Context, notification models.Notification) {
+	if notification.UserID == 0 {
+		return
+	}
+
+	if err := s.database.NewNotification(ctx, notification); err != nil {
+		s.log.Error("failed to queue notification", zap.Error(err), zap.String("request_id", notification.RequestID))
+	}
+}
+
+// requestFinished sends request.completed when every track that wasn't
+// skipped was found and request.failed when the request was given up on
+func (s *service) requestFinished(ctx context.Context, request models.DownloadQueueRequest) {
+	event := webhook.EventRequestFailed
+	if s.isRequestComplete(request) {
+		event = webhook.EventRequestCompleted
+	}
+	s.webhooks.Dispatch(ctx, event, webhook.NewRequestData(request))
+}